@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/doodads"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/scripting"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/uix"
+	"github.com/urfave/cli/v2"
+)
+
+// Check validates the scripts of doodad files.
+var Check *cli.Command
+
+func init() {
+	Check = &cli.Command{
+		Name:      "check",
+		Usage:     "validate the JavaScript of doodads against the scripting API",
+		ArgsUsage: "<filename.doodad or script.js...>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "strict",
+				Aliases: []string{"s"},
+				Usage:   "treat warnings as errors",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() < 1 {
+				return cli.Exit(
+					"Usage: doodad check <filename.doodad or script.js...>",
+					1,
+				)
+			}
+
+			var (
+				api    = uix.ScriptAPINames()
+				failed bool
+			)
+			for _, filename := range c.Args().Slice() {
+				report, err := checkScript(filename, api)
+				if err != nil {
+					log.Error("%s: %s", filename, err)
+					failed = true
+					continue
+				}
+
+				for _, msg := range report.Errors {
+					fmt.Printf("%s: error: %s\n", filename, msg)
+				}
+				for _, msg := range report.Warnings {
+					fmt.Printf("%s: warning: %s\n", filename, msg)
+				}
+
+				if !report.OK() || (c.Bool("strict") && len(report.Warnings) > 0) {
+					failed = true
+				} else {
+					fmt.Printf("%s: OK\n", filename)
+				}
+			}
+
+			if failed {
+				return cli.Exit("Some scripts failed the check", 1)
+			}
+			return nil
+		},
+	}
+}
+
+// checkScript loads the script from a doodad file, or a plain JavaScript file,
+// and validates it.
+func checkScript(filename string, api map[string][]string) (*scripting.CheckReport, error) {
+	var source string
+
+	if strings.ToLower(filepath.Ext(filename)) == extDoodad {
+		dd, err := doodads.LoadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load doodad: %s", err)
+		}
+		if dd.Script == "" {
+			return nil, fmt.Errorf("doodad has no script")
+		}
+		source = dd.Script
+	} else {
+		bin, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		source = string(bin)
+	}
+
+	return scripting.CheckScript(filepath.Base(filename), source, api), nil
+}
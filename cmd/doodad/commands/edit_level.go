@@ -3,6 +3,7 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
@@ -70,6 +71,14 @@ func init() {
 				Name:  "remove-actor",
 				Usage: "Remove all instances of the actor from the level. Value is their filename or UUID.",
 			},
+			&cli.StringFlag{
+				Name:  "replace-swatch",
+				Usage: "replace all pixels of one palette color with another, by name. Format like: grass=ice",
+			},
+			&cli.StringFlag{
+				Name:  "replace-within",
+				Usage: "limit --replace-swatch to a region of the level: its name, or a rect like X,Y,W,H",
+			},
 			&cli.BoolFlag{
 				Name:  "touch",
 				Usage: "simply load and re-save the level, to migrate it to a zipfile",
@@ -197,6 +206,14 @@ func editLevel(c *cli.Context, filename string) error {
 		}
 	}
 
+	if c.String("replace-swatch") != "" {
+		if err := replaceSwatch(lvl, c.String("replace-swatch"), c.String("replace-within")); err != nil {
+			log.Error("--replace-swatch: %s", err)
+		} else {
+			modified = true
+		}
+	}
+
 	/******************************
 	* Save level changes to disk *
 	******************************/
@@ -263,3 +280,34 @@ func rechunkLevel(c *cli.Context, filename string, lvl *level.Level) error {
 
 	return nil
 }
+
+// doodad edit-level --replace-swatch FROM=TO [--replace-within REGION]
+func replaceSwatch(lvl *level.Level, value, within string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return errors.New("expected a format like: grass=ice")
+	}
+
+	from, ok := lvl.Palette.Get(parts[0])
+	if !ok {
+		return fmt.Errorf("no color named '%s' in the level palette", parts[0])
+	}
+	to, ok := lvl.Palette.Get(parts[1])
+	if !ok {
+		return fmt.Errorf("no color named '%s' in the level palette", parts[1])
+	}
+
+	// Limit it to a region or rect?
+	var rect render.Rect
+	if within != "" {
+		if region := lvl.Regions.ByName(within); region != nil {
+			rect = region.Rect()
+		} else if _, err := fmt.Sscanf(within, "%d,%d,%d,%d", &rect.X, &rect.Y, &rect.W, &rect.H); err != nil {
+			return fmt.Errorf("--replace-within: no region named '%s' and not a rect like X,Y,W,H", within)
+		}
+	}
+
+	points := lvl.Chunker.ReplaceSwatch(from, to, rect)
+	log.Info("Replaced %d pixels of '%s' with '%s'", len(points), from.Name, to.Name)
+	return nil
+}
@@ -0,0 +1,219 @@
+package commands
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/doodads"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/native"
+	"git.kirsle.net/go/render"
+	"github.com/urfave/cli/v2"
+)
+
+// Init scaffolds a new doodad from a template.
+var Init *cli.Command
+
+//go:embed templates/*.js
+var templateScripts embed.FS
+
+// doodadTemplate describes a starter doodad for `doodad init`.
+type doodadTemplate struct {
+	Script   string              // filename under templates/
+	Category string              // category tag for the editor palette
+	Layers   []string            // named layers to create
+	Options  []doodadTemplateOpt // default options
+}
+
+type doodadTemplateOpt struct {
+	Name    string
+	Type    string
+	Default string
+}
+
+// Available templates for `doodad init`.
+var doodadTemplates = map[string]doodadTemplate{
+	"enemy": {
+		Script:   "enemy.js",
+		Category: "creatures",
+		Layers:   []string{"left-1", "left-2", "right-1", "right-2"},
+		Options: []doodadTemplateOpt{
+			{"No A.I.", "bool", "false"},
+			{"Speed", "int", "2"},
+		},
+	},
+	"button": {
+		Script:   "button.js",
+		Category: "technical",
+		Layers:   []string{"up", "down"},
+		Options: []doodadTemplateOpt{
+			{"Sticky", "bool", "false"},
+		},
+	},
+	"door": {
+		Script:   "door.js",
+		Category: "doors",
+		Layers:   []string{"closed", "open"},
+		Options: []doodadTemplateOpt{
+			{"Inverted", "bool", "false"},
+		},
+	},
+	"collectible": {
+		Script:   "collectible.js",
+		Category: "objects",
+		Layers:   []string{"main"},
+		Options: []doodadTemplateOpt{
+			{"Quantity", "int", "1"},
+		},
+	},
+}
+
+func init() {
+	Init = &cli.Command{
+		Name:      "init",
+		Usage:     "create a new doodad from a template with a starter script",
+		ArgsUsage: "<filename.doodad>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "template",
+				Usage: "template to start from: " + strings.Join(templateNames(), ", "),
+				Value: "collectible",
+			},
+			&cli.StringFlag{
+				Name:    "title",
+				Aliases: []string{"t"},
+				Usage:   "set the doodad title",
+			},
+			&cli.StringFlag{
+				Name:    "author",
+				Aliases: []string{"a"},
+				Usage:   "set the doodad author",
+				Value:   native.DefaultAuthor,
+			},
+			&cli.IntFlag{
+				Name:  "size",
+				Usage: "doodad width and height in pixels",
+				Value: 64,
+			},
+			&cli.StringFlag{
+				Name:  "script",
+				Usage: "also write the starter script to this file, to edit and `install-script` later",
+			},
+			&cli.BoolFlag{
+				Name:    "force",
+				Aliases: []string{"f"},
+				Usage:   "overwrite the output file if it already exists",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return cli.Exit(
+					"Usage: doodad init [--template name] <filename.doodad>",
+					1,
+				)
+			}
+
+			var (
+				filename = c.Args().Get(0)
+				name     = strings.ToLower(c.String("template"))
+			)
+
+			tmpl, ok := doodadTemplates[name]
+			if !ok {
+				return cli.Exit(
+					fmt.Sprintf("Unknown template '%s'; choose from: %s", name, strings.Join(templateNames(), ", ")),
+					1,
+				)
+			}
+
+			if strings.ToLower(filepath.Ext(filename)) != extDoodad {
+				return cli.Exit("The output file must have a .doodad extension", 1)
+			}
+
+			if _, err := os.Stat(filename); err == nil && !c.Bool("force") {
+				return cli.Exit(
+					fmt.Sprintf("%s already exists; use --force to overwrite it", filename),
+					1,
+				)
+			}
+
+			script, err := templateScripts.ReadFile("templates/" + tmpl.Script)
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			dd := newDoodadFromTemplate(tmpl, c.Int("size"))
+			dd.Script = string(script)
+			dd.Author = c.String("author")
+			dd.Title = c.String("title")
+			if dd.Title == "" {
+				dd.Title = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+			}
+
+			if err := dd.WriteFile(filename); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+			log.Info("Created %s doodad: %s", name, filename)
+
+			// Write the script out too?
+			if scriptFile := c.String("script"); scriptFile != "" {
+				if err := os.WriteFile(scriptFile, script, 0644); err != nil {
+					return cli.Exit(err.Error(), 1)
+				}
+				log.Info("Wrote starter script: %s", scriptFile)
+			}
+
+			return nil
+		},
+	}
+}
+
+// newDoodadFromTemplate creates the doodad layers, tags and options of a template.
+//
+// Each layer gets a placeholder outline so the doodad is visible in the editor
+// until you draw its real sprites.
+func newDoodadFromTemplate(tmpl doodadTemplate, size int) *doodads.Doodad {
+	dd := doodads.New(size)
+	dd.Tags["category"] = tmpl.Category
+
+	for i, name := range tmpl.Layers {
+		var layer doodads.Layer
+		if i == 0 {
+			dd.Layers[0].Name = name
+			layer = dd.Layers[0]
+		} else {
+			layer = dd.AddLayer(name, nil)
+		}
+
+		var (
+			swatch = dd.Palette.Swatches[0]
+			last   = size - 1
+		)
+		for n := 0; n < size; n++ {
+			layer.Chunker.Set(render.NewPoint(n, 0), swatch)
+			layer.Chunker.Set(render.NewPoint(n, last), swatch)
+			layer.Chunker.Set(render.NewPoint(0, n), swatch)
+			layer.Chunker.Set(render.NewPoint(last, n), swatch)
+		}
+	}
+
+	for _, opt := range tmpl.Options {
+		dd.SetOption(opt.Name, opt.Type, opt.Default)
+	}
+
+	return dd
+}
+
+// templateNames returns the sorted names of the doodad templates.
+func templateNames() []string {
+	var names = []string{}
+	for name := range doodadTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"archive/zip"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -58,6 +59,10 @@ func init() {
 						Usage:   "which doodads to embed: none, custom, all",
 						Value:   "custom",
 					},
+					&cli.StringFlag{
+						Name:  "achievements",
+						Usage: "a JSON file with a list of achievements for the players of your levelpack",
+					},
 				},
 				Action: levelpackCreate,
 			},
@@ -105,6 +110,14 @@ func levelpackShow(c *cli.Context) error {
 		fmt.Printf("%d. %s: %s\n", i+1, lvl.Filename, lvl.Title)
 	}
 
+	// List the achievements.
+	if len(lp.Achievements) > 0 {
+		fmt.Println("\nAchievements:")
+		for i, def := range lp.Achievements {
+			fmt.Printf("%d. %s: %s\n", i+1, def.ID, def.Title)
+		}
+	}
+
 	// List the doodads.
 	dl := lp.ListFiles("doodads/")
 	if len(dl) > 0 {
@@ -135,6 +148,7 @@ func levelpackCreate(c *cli.Context) error {
 		description  = c.String("description")
 		free         = c.Int("free")
 		embedDoodads = c.String("doodads")
+		achievements = c.String("achievements")
 	)
 
 	// Validate params.
@@ -156,6 +170,20 @@ func levelpackCreate(c *cli.Context) error {
 		Created:     time.Now().UTC(),
 	}
 
+	// Read the achievements.
+	if achievements != "" {
+		data, err := os.ReadFile(achievements)
+		if err != nil {
+			return cli.Exit(err, 1)
+		}
+		if err := json.Unmarshal(data, &lp.Achievements); err != nil {
+			return cli.Exit(
+				fmt.Sprintf("--achievements: %s: %s", achievements, err),
+				1,
+			)
+		}
+	}
+
 	// Create a temp directory to work with.
 	workdir, err := os.MkdirTemp(userdir.CacheDirectory, "levelpack-*")
 	if err != nil {
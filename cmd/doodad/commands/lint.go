@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level/lint"
+	"github.com/urfave/cli/v2"
+)
+
+// Lint checks level files for common problems.
+var Lint *cli.Command
+
+func init() {
+	Lint = &cli.Command{
+		Name:      "lint",
+		Usage:     "check levels for problems like a missing start flag or unreachable exits",
+		ArgsUsage: "<filename.level...>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "print the reports as JSON, e.g. for CI scripts",
+			},
+			&cli.BoolFlag{
+				Name:    "strict",
+				Aliases: []string{"s"},
+				Usage:   "treat warnings as errors",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() < 1 {
+				return cli.Exit(
+					"Usage: doodad lint <filename.level...>",
+					1,
+				)
+			}
+
+			var (
+				reports = []*lint.Report{}
+				failed  bool
+			)
+			for _, filename := range c.Args().Slice() {
+				lvl, err := level.LoadFile(filename)
+				if err != nil {
+					// Report a level that can't be loaded like any other error.
+					reports = append(reports, &lint.Report{
+						Filename: filename,
+						Problems: []lint.Problem{
+							{
+								Severity: lint.Error,
+								Check:    lint.CheckLoad,
+								Message:  err.Error(),
+							},
+						},
+					})
+					failed = true
+					continue
+				}
+
+				report := lint.Check(lvl)
+				report.Filename = filename
+				reports = append(reports, report)
+
+				if !report.OK() || (c.Bool("strict") && report.Count(lint.Warning) > 0) {
+					failed = true
+				}
+			}
+
+			if c.Bool("json") {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "\t")
+				if err := enc.Encode(reports); err != nil {
+					return err
+				}
+			} else {
+				for _, report := range reports {
+					for _, problem := range report.Problems {
+						fmt.Printf("%s: %s\n", report.Filename, problem)
+					}
+					if len(report.Problems) == 0 {
+						fmt.Printf("%s: OK\n", report.Filename)
+					}
+				}
+			}
+
+			if failed {
+				return cli.Exit("Some levels failed the check", 1)
+			}
+			return nil
+		},
+	}
+}
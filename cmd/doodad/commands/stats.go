@@ -0,0 +1,190 @@
+package commands
+
+import (
+	"fmt"
+	"image/png"
+	"os"
+	"sort"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level/stats"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"github.com/urfave/cli/v2"
+)
+
+// Stats reports the complexity and file size of a level.
+var Stats *cli.Command
+
+func init() {
+	Stats = &cli.Command{
+		Name:      "stats",
+		Usage:     "report pixel, chunk, actor and file size statistics about a level",
+		ArgsUsage: "<filename.level>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "chunks",
+				Usage: "list the pixels and bytes of every chunk",
+			},
+			&cli.StringFlag{
+				Name:  "heatmap",
+				Usage: "write a PNG heatmap of the level's chunks to this filename",
+			},
+			&cli.StringFlag{
+				Name:  "heatmap-by",
+				Usage: "what the heatmap colors by: density or size (compressed bytes)",
+				Value: "density",
+			},
+			&cli.IntFlag{
+				Name:  "heatmap-scale",
+				Usage: "size in pixels of each chunk in the heatmap",
+				Value: 8,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return cli.Exit(
+					"Usage: doodad stats [--heatmap out.png] <filename.level>",
+					1,
+				)
+			}
+
+			var metric stats.Metric
+			switch c.String("heatmap-by") {
+			case "density":
+				metric = stats.Density
+			case "size":
+				metric = stats.Size
+			default:
+				return cli.Exit("--heatmap-by must be density or size", 1)
+			}
+
+			filename := c.Args().First()
+			lvl, err := level.LoadFile(filename)
+			if err != nil {
+				log.Error(err.Error())
+				return cli.Exit("Error", 1)
+			}
+
+			result := stats.Compute(lvl)
+			showStats(c, filename, lvl, result)
+
+			if output := c.String("heatmap"); output != "" {
+				if err := writeHeatmap(result, metric, c.Int("heatmap-scale"), output); err != nil {
+					return cli.Exit(err.Error(), 1)
+				}
+				fmt.Printf("Wrote heatmap to %s\n", output)
+			}
+
+			return nil
+		},
+	}
+}
+
+// showStats prints the statistics of a level.
+func showStats(c *cli.Context, filename string, lvl *level.Level, s *stats.Stats) {
+	fmt.Printf("===== Level: %s =====\n", filename)
+
+	// Pixels by swatch, in palette order.
+	fmt.Println("Pixels:")
+	fmt.Printf("  Total: %d\n", s.Pixels)
+	var seen = map[string]bool{}
+	for _, swatch := range lvl.Palette.Swatches {
+		seen[swatch.Name] = true
+		fmt.Printf("  %s: %d\n", swatch.Name, s.Swatches[swatch.Name])
+	}
+	var unknown = []string{}
+	for name := range s.Swatches {
+		if !seen[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		fmt.Printf("  %s (not in palette): %d\n", name, s.Swatches[name])
+	}
+	fmt.Println("")
+
+	// Chunks.
+	bytes, compressed := s.ChunkBytes()
+	fmt.Println("Chunks:")
+	fmt.Printf("  Chunk size: %d\n", s.ChunkSize)
+	fmt.Printf("  Chunk count: %d\n", len(s.Chunks))
+	fmt.Printf("  Total size: %s\n", formatBytes(bytes, compressed))
+	if len(s.Chunks) > 0 {
+		fmt.Printf("  Average size: %d bytes\n", bytes/uint64(len(s.Chunks)))
+	}
+	if c.Bool("chunks") {
+		for _, chunk := range s.Chunks {
+			fmt.Printf("  - %s: %d pixels (%.1f%%), %s\n",
+				chunk.Point,
+				chunk.Pixels,
+				chunk.Density(s.ChunkSize)*100,
+				formatBytes(chunk.Bytes, chunk.Compressed),
+			)
+		}
+	} else {
+		fmt.Println("  Use --chunks to list every chunk")
+	}
+	fmt.Println("")
+
+	// Actors by doodad, most used first.
+	fmt.Println("Actors:")
+	fmt.Printf("  Total: %d\n", s.Actors)
+	var doodads = []string{}
+	for name := range s.Doodads {
+		doodads = append(doodads, name)
+	}
+	sort.Slice(doodads, func(i, j int) bool {
+		a, b := doodads[i], doodads[j]
+		if s.Doodads[a] != s.Doodads[b] {
+			return s.Doodads[a] > s.Doodads[b]
+		}
+		return a < b
+	})
+	for _, name := range doodads {
+		fmt.Printf("  %s: %d\n", name, s.Doodads[name])
+	}
+	fmt.Println("")
+
+	fmt.Println("Links:")
+	fmt.Printf("  Linked pairs: %d\n", s.Links.Edges)
+	fmt.Printf("  Linked actors: %d\n", s.Links.Actors)
+	fmt.Printf("  Linked groups: %d\n", s.Links.Groups)
+	fmt.Printf("  Region links: %d\n", s.RegionLinks)
+	fmt.Println("")
+
+	fmt.Println("Embedded Files:")
+	if len(s.Files) > 0 {
+		for _, file := range s.Files {
+			fmt.Printf("  %s: %s\n", file.Name, formatBytes(file.Bytes, file.Compressed))
+		}
+		bytes, compressed := s.FileBytes()
+		fmt.Printf("  Total size: %s\n", formatBytes(bytes, compressed))
+	} else {
+		fmt.Println("  None")
+	}
+	fmt.Println("")
+}
+
+// formatBytes formats a size, with its compressed size if known.
+func formatBytes(bytes, compressed uint64) string {
+	if compressed > 0 {
+		return fmt.Sprintf("%d bytes (%d compressed)", bytes, compressed)
+	}
+	return fmt.Sprintf("%d bytes", bytes)
+}
+
+// writeHeatmap saves the chunk heatmap to a PNG file.
+func writeHeatmap(s *stats.Stats, metric stats.Metric, scale int, filename string) error {
+	if scale < 1 {
+		return fmt.Errorf("--heatmap-scale must be at least 1")
+	}
+
+	fh, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	return png.Encode(fh, s.Heatmap(metric, scale))
+}
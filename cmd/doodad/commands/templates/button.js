@@ -0,0 +1,38 @@
+// Button Doodad Script
+/*
+A button that sends power to its linked doodads while pressed.
+
+Layers: up, down
+Options:
+- Sticky (bool): stay pressed down after the first touch.
+*/
+
+var pressed = false;
+
+function main() {
+    var sticky = Self.GetOption("Sticky") === true;
+
+    Events.OnCollide(function (e) {
+        if (!e.Settled || !e.Actor.IsMobile()) {
+            return;
+        }
+
+        if (!pressed) {
+            pressed = true;
+            Self.ShowLayerNamed("down");
+            Sound.Play("button-down.wav");
+            Message.Publish("power", true);
+        }
+    });
+
+    Events.OnLeave(function (e) {
+        if (sticky || !pressed) {
+            return;
+        }
+
+        pressed = false;
+        Self.ShowLayerNamed("up");
+        Sound.Play("button-up.wav");
+        Message.Publish("power", false);
+    });
+}
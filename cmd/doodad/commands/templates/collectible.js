@@ -0,0 +1,30 @@
+// Collectible Doodad Script
+/*
+An item the player can pick up into their inventory.
+
+Layers: main
+Options:
+- Quantity (int): how many of the item are picked up, or 0 to make
+  it a 'key item'.
+*/
+
+function main() {
+    // Make the hitbox be the full canvas size of this doodad.
+    if (Self.Hitbox().IsZero()) {
+        var size = Self.Size();
+        Self.SetHitbox(0, 0, size.W, size.H);
+    }
+
+    var quantity = Self.GetOption("Quantity");
+    if (quantity === null || quantity === undefined) {
+        quantity = 1;
+    }
+
+    Events.OnCollide(function (e) {
+        if (e.Settled && e.Actor.HasInventory()) {
+            Sound.Play("item-get.wav");
+            e.Actor.AddItem(Self.Filename, quantity);
+            Self.Destroy();
+        }
+    });
+}
@@ -0,0 +1,38 @@
+// Door Doodad Script
+/*
+A door that is solid until it receives power from a linked doodad,
+such as a button or switch.
+
+Layers: closed, open
+Options:
+- Inverted (bool): the door starts open and closes when powered.
+*/
+
+var opened = false;
+
+function main() {
+    // Make the hitbox be the full canvas size of this doodad.
+    if (Self.Hitbox().IsZero()) {
+        var size = Self.Size();
+        Self.SetHitbox(0, 0, size.W, size.H);
+    }
+
+    var inverted = Self.GetOption("Inverted") === true;
+    setOpened(inverted);
+
+    Message.Subscribe("power", function (powered) {
+        setOpened(inverted ? !powered : powered);
+    });
+
+    // Solid while closed.
+    Events.OnCollide(function (e) {
+        if (!opened && e.InHitbox) {
+            return false;
+        }
+    });
+}
+
+function setOpened(v) {
+    opened = v;
+    Self.ShowLayerNamed(v ? "open" : "closed");
+}
@@ -0,0 +1,52 @@
+// Enemy Doodad Script
+/*
+A mobile enemy that walks back and forth and is fatal to the player.
+
+Layers: left-1, left-2, right-1, right-2
+Options:
+- No A.I. (bool): stand still instead of patrolling.
+- Speed (int): walking speed in pixels per tick.
+*/
+
+var direction = 1;
+
+function main() {
+    Self.SetMobile(true);
+    Self.SetGravity(true);
+
+    // Make the hitbox be the full canvas size of this doodad.
+    if (Self.Hitbox().IsZero()) {
+        var size = Self.Size();
+        Self.SetHitbox(0, 0, size.W, size.H);
+    }
+
+    Self.AddAnimation("left", 100, ["left-1", "left-2"]);
+    Self.AddAnimation("right", 100, ["right-1", "right-2"]);
+
+    // Hurt the player on contact.
+    Events.OnCollide(function (e) {
+        if (e.Settled && e.InHitbox && e.Actor.IsPlayer()) {
+            FailLevel("Watch out for " + Self.Title + "!");
+        }
+    });
+
+    if (Self.GetOption("No A.I.") === true) {
+        return;
+    }
+
+    // Patrol: turn around when we stop making progress.
+    var speed = Self.GetOption("Speed") || 2;
+    var lastX = Self.Position().X;
+    setInterval(function () {
+        var nowX = Self.Position().X;
+        if (nowX === lastX) {
+            direction = -direction;
+        }
+        lastX = nowX;
+
+        Self.SetVelocity(Vector(speed * direction, Self.Velocity().Y));
+        if (!Self.IsAnimating()) {
+            Self.PlayAnimation(direction < 0 ? "left" : "right", null);
+        }
+    }, 100);
+}
@@ -47,13 +47,17 @@ func main() {
 	}
 
 	app.Commands = []*cli.Command{
+		commands.Check,
 		commands.Convert,
+		commands.Init,
 		commands.Show,
 		commands.Resave,
 		commands.EditLevel,
 		commands.EditDoodad,
 		commands.InstallScript,
 		commands.LevelPack,
+		commands.Lint,
+		commands.Stats,
 	}
 
 	sort.Sort(cli.FlagsByName(app.Flags))
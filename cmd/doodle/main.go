@@ -18,11 +18,11 @@ import (
 	"git.kirsle.net/SketchyMaze/doodle/pkg/branding"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/branding/builds"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/chatbot"
-	"git.kirsle.net/SketchyMaze/doodle/pkg/gamepad"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/native"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/plus/bootstrap"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/plus/dpp"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/savegame"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/sound"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/sprites"
@@ -73,8 +73,15 @@ func main() {
 		usercfg.Save()
 	}
 
-	// Set GameController style.
-	gamepad.SetStyle(gamepad.Style(usercfg.Current.ControllerStyle))
+	// Apply the settings of the player's save profile.
+	if profiles, err := savegame.LoadProfiles(); err != nil {
+		log.Error("Error loading save profiles: %s", err)
+	} else if err := profiles.ApplySettings(); err != nil {
+		log.Error("Error applying the save profile's settings: %s", err)
+	}
+
+	// Apply the audio volume settings.
+	sound.SetVolume(usercfg.Current.MusicVolume, usercfg.Current.SoundVolume, usercfg.Current.MuteAudio)
 
 	app.Version = fmt.Sprintf("%s build %s. Built on %s",
 		builds.Version,
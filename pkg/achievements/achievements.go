@@ -0,0 +1,281 @@
+/*
+Package achievements gives players goals beyond the best and perfect times of
+each level.
+
+Achievements are defined in the game's built-in achievements.json and in the
+index.json manifest of a levelpack. Each one is unlocked by a Trigger: a game
+event like completing levels, completing them without dying or collecting
+items, or else only by a doodad script calling Achievements.Unlock(id).
+
+The player's unlocks and event counters are a Progress, which is stored in
+their savegame.
+*/
+package achievements
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"git.kirsle.net/SketchyMaze/doodle/assets"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/branding"
+)
+
+// BuiltinFile is the embedded asset of the game's built-in achievements.
+const BuiltinFile = "assets/achievements.json"
+
+// Game events that unlock achievements.
+const (
+	EventScript         = ""               // only unlocked by Achievements.Unlock(id)
+	EventLevelCompleted = "levelCompleted" // beat a level
+	EventNoDeath        = "noDeath"        // beat a level without dying
+	EventItemCollected  = "itemCollected"  // the player picked up an item
+)
+
+// Definition of an achievement.
+type Definition struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Hidden      bool    `json:"hidden,omitempty"` // secret until unlocked
+	Trigger     Trigger `json:"trigger"`
+}
+
+/*
+Trigger is the game event that unlocks an achievement.
+
+Level events count the distinct levels the event happened in: a specific
+Level by UUID, or else Count of the Levels given (all of them if Count is
+0), or else Count of any levels in the Book. Item events count the quantity
+collected of the Item (by doodad filename), or of all items if not given, in
+a specific Level, or else the Levels given, or else the levels of the Book
+(or any level for the built-in achievements).
+*/
+type Trigger struct {
+	Event  string   `json:"event,omitempty"`
+	Level  string   `json:"level,omitempty"`
+	Levels []string `json:"levels,omitempty"`
+	Item   string   `json:"item,omitempty"`
+	Count  int      `json:"count,omitempty"`
+}
+
+// Event that happened in the game.
+type Event struct {
+	Name     string
+	Level    string // UUID of the level being played
+	Item     string // doodad filename of an item
+	Quantity int    // of items collected
+}
+
+/*
+Book is a set of achievements: the built-in ones, or those of a levelpack.
+
+The achievements of a levelpack are saved under its filename, so their IDs
+don't collide with another levelpack's. For levelpacks, Levels are the UUIDs
+of its levels, which its level events count by default.
+*/
+type Book struct {
+	Pack        string // levelpack filename, "" for the built-in ones
+	Title       string
+	Definitions []Definition
+	Levels      []string
+}
+
+// Builtin loads the game's built-in achievements.
+func Builtin() (*Book, error) {
+	data, err := assets.Asset(BuiltinFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var book = &Book{
+		Title: branding.AppName,
+	}
+	if err := json.Unmarshal(data, &book.Definitions); err != nil {
+		return nil, fmt.Errorf("%s: %s", BuiltinFile, err)
+	}
+	return book, nil
+}
+
+// Key is the ID of an achievement in the savegame.
+func (b *Book) Key(id string) string {
+	if b.Pack == "" {
+		return id
+	}
+	return filepath.Base(b.Pack) + "/" + id
+}
+
+// Get an achievement by ID.
+func (b *Book) Get(id string) (Definition, bool) {
+	for _, def := range b.Definitions {
+		if def.ID == id {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Progress is the player's unlocked achievements, by their Book keys, and
+// counters of the game events.
+type Progress struct {
+	Unlocked map[string]time.Time `json:"unlocked"`
+	Counters map[string]int       `json:"counters,omitempty"`
+}
+
+// NewProgress initializes a Progress.
+func NewProgress() *Progress {
+	return &Progress{
+		Unlocked: map[string]time.Time{},
+		Counters: map[string]int{},
+	}
+}
+
+// IsUnlocked checks whether an achievement is unlocked, by its Book key.
+func (p *Progress) IsUnlocked(key string) bool {
+	_, ok := p.Unlocked[key]
+	return ok
+}
+
+// Unlock an achievement by its Book key. Returns false if it was already
+// unlocked.
+func (p *Progress) Unlock(key string) bool {
+	if p.IsUnlocked(key) {
+		return false
+	}
+	if p.Unlocked == nil {
+		p.Unlocked = map[string]time.Time{}
+	}
+	p.Unlocked[key] = time.Now()
+	return true
+}
+
+// Record a game event in the counters.
+func (p *Progress) Record(ev Event) {
+	if p.Counters == nil {
+		p.Counters = map[string]int{}
+	}
+
+	switch ev.Name {
+	case EventLevelCompleted, EventNoDeath:
+		if ev.Level != "" {
+			p.Counters[counter(ev.Name, ev.Level)]++
+		}
+	case EventItemCollected:
+		// Key items have a quantity of 0, but count as one.
+		p.Counters[counter(ev.Name, ev.Level+":"+ev.Item)] += max(ev.Quantity, 1)
+	}
+}
+
+/*
+Check unlocks the achievements of the books whose triggers are met, and
+returns the ones which were newly unlocked.
+
+Call it after Record, with the built-in book and the book of the levelpack
+being played.
+*/
+func (p *Progress) Check(books ...*Book) []Definition {
+	var result = []Definition{}
+	for _, book := range books {
+		if book == nil {
+			continue
+		}
+		for _, def := range book.Definitions {
+			if def.Trigger.Event == EventScript || p.IsUnlocked(book.Key(def.ID)) {
+				continue
+			}
+			if p.met(def.Trigger, book) {
+				p.Unlock(book.Key(def.ID))
+				result = append(result, def)
+			}
+		}
+	}
+	return result
+}
+
+// met checks whether a trigger's counters have been reached.
+func (p *Progress) met(t Trigger, book *Book) bool {
+	var need = max(t.Count, 1)
+
+	switch t.Event {
+	case EventLevelCompleted, EventNoDeath:
+		if t.Level != "" {
+			return p.Counters[counter(t.Event, t.Level)] >= need
+		}
+
+		var levels = t.Levels
+		if len(levels) > 0 && t.Count == 0 {
+			need = len(levels)
+		} else if len(levels) == 0 {
+			levels = book.Levels
+		}
+
+		// Count the distinct levels: of the list, or else all of them.
+		var n int
+		if len(levels) > 0 {
+			for _, uuid := range levels {
+				if p.Counters[counter(t.Event, uuid)] > 0 {
+					n++
+				}
+			}
+		} else {
+			n = len(p.counters(t.Event))
+		}
+		return n >= need
+	case EventItemCollected:
+		var levels = t.Levels
+		if t.Level != "" {
+			levels = []string{t.Level}
+		} else if len(levels) == 0 {
+			levels = book.Levels
+		}
+
+		var n int
+		for _, key := range p.counters(t.Event) {
+			level, item := itemCounter(key)
+			if t.Item != "" && item != t.Item {
+				continue
+			}
+			if len(levels) > 0 && !slices.Contains(levels, level) {
+				continue
+			}
+			n += p.Counters[key]
+		}
+		return n >= need
+	}
+	return false
+}
+
+// counters returns the sorted keys of the counters of an event.
+func (p *Progress) counters(event string) []string {
+	var (
+		prefix = event + ":"
+		result = []string{}
+	)
+	for key, n := range p.Counters {
+		if strings.HasPrefix(key, prefix) && n > 0 {
+			result = append(result, key)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// counter is the key of an event counter, like "levelCompleted:<level UUID>"
+// or "itemCollected:<level UUID>:key-blue.doodad"
+func counter(event, name string) string {
+	return event + ":" + name
+}
+
+// itemCounter splits the key of an item counter into the level UUID and the
+// item it was collected in.
+func itemCounter(key string) (level, item string) {
+	var name = strings.TrimPrefix(key, EventItemCollected+":")
+	if level, item, ok := strings.Cut(name, ":"); ok {
+		return level, item
+	}
+	return "", name
+}
@@ -0,0 +1,131 @@
+package achievements
+
+import "testing"
+
+func TestProgress(t *testing.T) {
+	var (
+		builtin = &Book{
+			Definitions: []Definition{
+				{ID: "first-steps", Trigger: Trigger{Event: EventLevelCompleted}},
+				{ID: "explorer", Trigger: Trigger{Event: EventLevelCompleted, Count: 3}},
+				{ID: "untouchable", Trigger: Trigger{Event: EventNoDeath}},
+				{ID: "keys", Trigger: Trigger{Event: EventItemCollected, Item: "key-blue.doodad", Count: 2}},
+				{ID: "pocket-full", Trigger: Trigger{Event: EventItemCollected, Count: 5}},
+				{ID: "secret", Hidden: true},
+			},
+		}
+		pack = &Book{
+			Pack:   "/home/user/levelpacks/tutorial.levelpack",
+			Levels: []string{"level-1", "level-2"},
+			Definitions: []Definition{
+				{ID: "graduate", Trigger: Trigger{Event: EventLevelCompleted, Levels: []string{"level-1", "level-2"}}},
+				{ID: "the-end", Trigger: Trigger{Event: EventLevelCompleted, Level: "level-2"}},
+				{ID: "secret"},
+			},
+		}
+		p = NewProgress()
+	)
+
+	// Check the unlocks in the order of the books and their definitions.
+	var unlocked = func(expect ...string) {
+		t.Helper()
+		got := p.Check(builtin, pack, nil)
+		if len(got) != len(expect) {
+			t.Errorf("expected %d unlocks %v but got %+v", len(expect), expect, got)
+			return
+		}
+		for i, id := range expect {
+			if got[i].ID != id {
+				t.Errorf("expected unlock %s but got %s", id, got[i].ID)
+			}
+		}
+	}
+
+	// Nothing yet.
+	unlocked()
+
+	p.Record(Event{Name: EventLevelCompleted, Level: "level-1"})
+	unlocked("first-steps")
+
+	// Beating the same level again doesn't count towards distinct levels.
+	p.Record(Event{Name: EventLevelCompleted, Level: "level-1"})
+	p.Record(Event{Name: EventNoDeath, Level: "level-1"})
+	unlocked("untouchable")
+
+	p.Record(Event{Name: EventLevelCompleted, Level: "level-2"})
+	unlocked("graduate", "the-end")
+
+	p.Record(Event{Name: EventLevelCompleted, Level: "level-3"})
+	unlocked("explorer")
+
+	// Key items have a quantity of 0 and count as one.
+	p.Record(Event{Name: EventItemCollected, Level: "level-3", Item: "key-blue.doodad"})
+	unlocked()
+	p.Record(Event{Name: EventItemCollected, Level: "level-4", Item: "key-blue.doodad"})
+	unlocked("keys")
+	p.Record(Event{Name: EventItemCollected, Level: "level-4", Item: "gem.doodad", Quantity: 3})
+	unlocked("pocket-full")
+
+	// Script-only achievements are unlocked by Unlock, under the book's key.
+	if !p.Unlock(pack.Key("secret")) {
+		t.Errorf("expected to unlock the levelpack's secret")
+	}
+	if p.Unlock(pack.Key("secret")) {
+		t.Errorf("expected the secret to already be unlocked")
+	}
+	if pack.Key("secret") != "tutorial.levelpack/secret" {
+		t.Errorf("unexpected levelpack key: %s", pack.Key("secret"))
+	}
+	if p.IsUnlocked(builtin.Key("secret")) {
+		t.Errorf("the levelpack's secret should not unlock the built-in one")
+	}
+	unlocked()
+}
+
+func TestProgressPackItems(t *testing.T) {
+	var (
+		pack = &Book{
+			Pack:   "/home/user/levelpacks/tutorial.levelpack",
+			Levels: []string{"level-1", "level-2"},
+			Definitions: []Definition{
+				{ID: "gems", Trigger: Trigger{Event: EventItemCollected, Item: "gem.doodad", Count: 3}},
+				{ID: "first-gem", Trigger: Trigger{Event: EventItemCollected, Item: "gem.doodad", Level: "level-2"}},
+			},
+		}
+		p = NewProgress()
+	)
+
+	// Items collected outside of the levelpack don't count towards it.
+	p.Record(Event{Name: EventItemCollected, Level: "level-3", Item: "gem.doodad", Quantity: 5})
+	if got := p.Check(pack); len(got) != 0 {
+		t.Errorf("expected no unlocks from another level's items but got %+v", got)
+	}
+
+	p.Record(Event{Name: EventItemCollected, Level: "level-1", Item: "gem.doodad", Quantity: 2})
+	if got := p.Check(pack); len(got) != 0 {
+		t.Errorf("expected no unlocks yet but got %+v", got)
+	}
+
+	p.Record(Event{Name: EventItemCollected, Level: "level-2", Item: "gem.doodad"})
+	if got := p.Check(pack); len(got) != 2 || got[0].ID != "gems" || got[1].ID != "first-gem" {
+		t.Errorf("expected the gems and first-gem unlocks but got %+v", got)
+	}
+}
+
+func TestBuiltin(t *testing.T) {
+	book, err := Builtin()
+	if err != nil {
+		t.Fatalf("Builtin: %s", err)
+	}
+
+	var seen = map[string]interface{}{}
+	for _, def := range book.Definitions {
+		if def.ID == "" || def.Title == "" {
+			t.Errorf("achievement needs an ID and title: %+v", def)
+		}
+		if _, ok := seen[def.ID]; ok {
+			t.Errorf("duplicate achievement ID: %s", def.ID)
+		}
+		seen[def.ID] = nil
+	}
+}
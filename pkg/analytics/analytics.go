@@ -0,0 +1,200 @@
+/*
+Package analytics records where players go and die in a level, to help level
+designers tune its difficulty.
+
+While a level with a UUID is being played, a Session samples the player's
+position into a grid of cells and records their deaths (with the cause),
+checkpoint hits and completion times. At the end of the play session it is
+merged into the analytics store next to the savegame in the user's profile
+directory, keyed by the level UUID.
+
+The level editor draws the stored data over the level as a heatmap, see
+Level.Heatmap.
+*/
+package analytics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/userdir"
+	"git.kirsle.net/go/render"
+)
+
+// Store holds the analytics of all levels, by level UUID.
+type Store struct {
+	Levels map[string]*Level `json:"levels"`
+}
+
+// Level holds the analytics of one level from all of its play sessions.
+type Level struct {
+	Title       string       `json:"title"`
+	Sessions    int          `json:"sessions"`
+	Positions   map[Cell]int `json:"positions,omitempty"` // player position samples by cell
+	Deaths      []Death      `json:"deaths,omitempty"`
+	Checkpoints []Checkpoint `json:"checkpoints,omitempty"`
+	Completions []Completion `json:"completions,omitempty"`
+}
+
+// Death of the player.
+type Death struct {
+	Point   render.Point  `json:"point"`
+	Cause   string        `json:"cause"`
+	Elapsed time.Duration `json:"elapsed"` // time into the level
+}
+
+// Checkpoint that the player has touched, with the number of hits.
+type Checkpoint struct {
+	Point render.Point `json:"point"`
+	Hits  int          `json:"hits"`
+}
+
+// Completion of the level.
+type Completion struct {
+	Elapsed time.Duration `json:"elapsed"`
+	Perfect bool          `json:"perfect,omitempty"`
+	Cheated bool          `json:"cheated,omitempty"`
+	Date    time.Time     `json:"date"`
+}
+
+// Cell of the analytics grid, which divides the level into squares of
+// balance.AnalyticsCellSize pixels. In JSON it is written like "4,-2" so it
+// can be the key of a map.
+type Cell struct {
+	X int
+	Y int
+}
+
+// CellAt returns the cell containing a point of the level.
+func CellAt(p render.Point) Cell {
+	var floor = func(v int) int {
+		if v < 0 {
+			return (v - balance.AnalyticsCellSize + 1) / balance.AnalyticsCellSize
+		}
+		return v / balance.AnalyticsCellSize
+	}
+	return Cell{floor(p.X), floor(p.Y)}
+}
+
+// Rect returns the area of the level covered by the cell.
+func (c Cell) Rect() render.Rect {
+	return render.Rect{
+		X: c.X * balance.AnalyticsCellSize,
+		Y: c.Y * balance.AnalyticsCellSize,
+		W: balance.AnalyticsCellSize,
+		H: balance.AnalyticsCellSize,
+	}
+}
+
+// MarshalText encodes the cell like "4,-2"
+func (c Cell) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d,%d", c.X, c.Y)), nil
+}
+
+// UnmarshalText decodes a cell like "4,-2"
+func (c *Cell) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "%d,%d", &c.X, &c.Y)
+	return err
+}
+
+// New creates an empty Store.
+func New() *Store {
+	return &Store{
+		Levels: map[string]*Level{},
+	}
+}
+
+// NewLevel creates an empty Level record.
+func NewLevel(title string) *Level {
+	return &Level{
+		Title:     title,
+		Positions: map[Cell]int{},
+	}
+}
+
+// Load the analytics store from the user's profile directory. If the file
+// doesn't exist yet, an empty store is returned.
+func Load() (*Store, error) {
+	data, err := os.ReadFile(userdir.AnalyticsFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return New(), nil
+		}
+		return nil, err
+	}
+
+	var store = New()
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, err
+	}
+	if store.Levels == nil {
+		store.Levels = map[string]*Level{}
+	}
+	return store, nil
+}
+
+// Save the analytics store to the user's profile directory.
+func (s *Store) Save() error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(userdir.AnalyticsFile, data, 0644)
+}
+
+// Get the analytics of a level by its UUID, or nil if it was never played.
+func (s *Store) Get(uuid string) *Level {
+	return s.Levels[uuid]
+}
+
+// Reset deletes the analytics of a level.
+func (s *Store) Reset(uuid string) {
+	delete(s.Levels, uuid)
+}
+
+// merge adds the data of another Level record to this one, keeping only the
+// newest balance.AnalyticsMaxEvents deaths and completions.
+func (l *Level) merge(other *Level) {
+	if other.Title != "" {
+		l.Title = other.Title
+	}
+	l.Sessions += other.Sessions
+
+	if l.Positions == nil {
+		l.Positions = map[Cell]int{}
+	}
+	for cell, n := range other.Positions {
+		l.Positions[cell] += n
+	}
+
+	l.Deaths = append(l.Deaths, other.Deaths...)
+	if n := len(l.Deaths) - balance.AnalyticsMaxEvents; n > 0 {
+		l.Deaths = l.Deaths[n:]
+	}
+	l.Completions = append(l.Completions, other.Completions...)
+	if n := len(l.Completions) - balance.AnalyticsMaxEvents; n > 0 {
+		l.Completions = l.Completions[n:]
+	}
+
+	for _, cp := range other.Checkpoints {
+		l.addCheckpoint(cp.Point, cp.Hits)
+	}
+}
+
+// addCheckpoint counts hits on a checkpoint.
+func (l *Level) addCheckpoint(p render.Point, hits int) {
+	for i := range l.Checkpoints {
+		if l.Checkpoints[i].Point == p {
+			l.Checkpoints[i].Hits += hits
+			return
+		}
+	}
+	l.Checkpoints = append(l.Checkpoints, Checkpoint{
+		Point: p,
+		Hits:  hits,
+	})
+}
@@ -0,0 +1,95 @@
+package analytics
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/go/render"
+)
+
+func TestCellAt(t *testing.T) {
+	var size = balance.AnalyticsCellSize
+	tests := []struct {
+		point  render.Point
+		expect Cell
+	}{
+		{render.NewPoint(0, 0), Cell{0, 0}},
+		{render.NewPoint(size-1, size), Cell{0, 1}},
+		{render.NewPoint(-1, -size), Cell{-1, -1}},
+		{render.NewPoint(-size-1, 5), Cell{-2, 0}},
+	}
+	for i, test := range tests {
+		if actual := CellAt(test.point); actual != test.expect {
+			t.Errorf("Test %d: CellAt(%s): expected %+v but got %+v", i, test.point, test.expect, actual)
+		}
+	}
+}
+
+func TestLevelJSON(t *testing.T) {
+	lvl := NewLevel("Test")
+	lvl.Positions[Cell{4, -2}] = 10
+
+	data, err := json.Marshal(lvl)
+	if err != nil {
+		t.Fatalf("Marshal: %s", err)
+	}
+
+	var actual = NewLevel("")
+	if err := json.Unmarshal(data, actual); err != nil {
+		t.Fatalf("Unmarshal: %s", err)
+	}
+	if actual.Positions[Cell{4, -2}] != 10 {
+		t.Errorf("positions didn't survive JSON: %s", data)
+	}
+}
+
+func TestSession(t *testing.T) {
+	var (
+		size    = balance.AnalyticsCellSize
+		store   = NewLevel("Test")
+		session = NewSession("uuid", "Test")
+	)
+
+	// Two play sessions of the level.
+	for i := 0; i < 2; i++ {
+		session.Position(render.NewPoint(1, 1))
+		session.Position(render.NewPoint(size+1, 1))
+		session.Checkpoint(render.NewPoint(100, 100))
+		session.Death(render.NewPoint(size+1, 1), "fire", time.Second)
+		session.Complete(time.Duration(10-i)*time.Second, false, false)
+
+		store.merge(session.data)
+		session.data = NewLevel("Test")
+		session.data.Sessions = 1
+	}
+
+	if store.Sessions != 2 || store.Positions[Cell{0, 0}] != 2 || len(store.Deaths) != 2 {
+		t.Errorf("unexpected analytics: %+v", store)
+	}
+	if len(store.Checkpoints) != 1 || store.Checkpoints[0].Hits != 2 {
+		t.Errorf("expected one checkpoint with two hits: %+v", store.Checkpoints)
+	}
+	if best, ok := store.BestTime(); !ok || best != 9*time.Second {
+		t.Errorf("unexpected best time: %s", best)
+	}
+	if causes := store.Causes(); len(causes) != 1 || causes[0] != (Cause{"fire", 2}) {
+		t.Errorf("unexpected causes: %+v", causes)
+	}
+
+	// Heatmaps: deaths only happened in the second cell.
+	if heatmap := store.Heatmap(Deaths); len(heatmap.Cells) != 1 {
+		t.Errorf("expected one cell in the death heatmap: %+v", heatmap.Cells)
+	}
+	if heatmap := store.Heatmap(Exploration); len(heatmap.Cells) != 2 || len(heatmap.Checkpoints) != 1 {
+		t.Errorf("unexpected exploration heatmap: %+v", heatmap)
+	}
+
+	// A nil session, for levels without a UUID, records nothing.
+	var none = NewSession("", "Untitled")
+	none.Position(render.Origin)
+	if err := none.Save(); err != nil || none != nil {
+		t.Errorf("expected a nil session")
+	}
+}
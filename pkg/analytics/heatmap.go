@@ -0,0 +1,173 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level/stats"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/savegame"
+	"git.kirsle.net/go/render"
+)
+
+// Overlay selects which analytics the editor draws over the level.
+type Overlay int
+
+// Overlay modes.
+const (
+	NoOverlay   Overlay = iota // draw nothing
+	Exploration                // where the player has been
+	Deaths                     // where the player has died
+)
+
+// Overlays lists the overlay modes, e.g. for the editor's View menu.
+var Overlays = []Overlay{
+	NoOverlay,
+	Exploration,
+	Deaths,
+}
+
+var overlayNames = []string{
+	"Off",
+	"Exploration",
+	"Deaths",
+}
+
+func (o Overlay) String() string {
+	return overlayNames[o]
+}
+
+// Heatmap of a level's analytics to draw over the level: the cells of the
+// analytics grid colored from blue (least) to red (most), and the
+// checkpoints that were touched.
+type Heatmap struct {
+	Cells       map[Cell]render.Color
+	Checkpoints []render.Point
+}
+
+/*
+Heatmap computes the heatmap of an overlay mode.
+
+The Exploration heatmap counts the samples of the player's position in each
+cell, on a log scale since the player spends much more time near the start
+than anywhere else; cells which were never reached are left blank. The
+Deaths heatmap counts the deaths in each cell.
+*/
+func (l *Level) Heatmap(overlay Overlay) *Heatmap {
+	var (
+		heatmap = &Heatmap{
+			Cells: map[Cell]render.Color{},
+		}
+		counts = map[Cell]int{}
+		scale  = func(v float64) float64 { return v }
+	)
+
+	switch overlay {
+	case Exploration:
+		counts = l.Positions
+		scale = math.Log1p
+	case Deaths:
+		for _, death := range l.Deaths {
+			counts[CellAt(death.Point)]++
+		}
+	default:
+		return heatmap
+	}
+
+	var highest int
+	for _, n := range counts {
+		highest = max(highest, n)
+	}
+	for cell, n := range counts {
+		var t float64
+		if highest > 0 {
+			t = scale(float64(n)) / scale(float64(highest))
+		}
+		c := stats.HeatColor(t)
+		heatmap.Cells[cell] = render.RGBA(c.R, c.G, c.B, balance.HeatmapAlpha)
+	}
+
+	for _, cp := range l.Checkpoints {
+		heatmap.Checkpoints = append(heatmap.Checkpoints, cp.Point)
+	}
+
+	return heatmap
+}
+
+// Cause of death and how many times the player died of it.
+type Cause struct {
+	Name  string
+	Count int
+}
+
+// Causes of death, the most common first.
+func (l *Level) Causes() []Cause {
+	var counts = map[string]int{}
+	for _, death := range l.Deaths {
+		counts[death.Cause]++
+	}
+
+	var result = []Cause{}
+	for name, n := range counts {
+		result = append(result, Cause{name, n})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// BestTime returns the fastest completion of the level without cheats, or
+// false if it was never completed.
+func (l *Level) BestTime() (time.Duration, bool) {
+	var (
+		best time.Duration
+		ok   bool
+	)
+	for _, c := range l.Completions {
+		if !c.Cheated && (!ok || c.Elapsed < best) {
+			best = c.Elapsed
+			ok = true
+		}
+	}
+	return best, ok
+}
+
+// Summary describes the analytics of the level in a few lines of text.
+func (l *Level) Summary() string {
+	var lines = []string{
+		fmt.Sprintf("Play sessions: %d", l.Sessions),
+		fmt.Sprintf("Deaths: %d", len(l.Deaths)),
+	}
+
+	if causes := l.Causes(); len(causes) > 0 {
+		var top = []string{}
+		for i, cause := range causes {
+			if i == 3 {
+				break
+			}
+			top = append(top, fmt.Sprintf("%s (%d)", cause.Name, cause.Count))
+		}
+		lines = append(lines, "Top causes: "+strings.Join(top, ", "))
+	}
+
+	var hits int
+	for _, cp := range l.Checkpoints {
+		hits += cp.Hits
+	}
+	lines = append(lines, fmt.Sprintf("Checkpoints: %d touched %d times", len(l.Checkpoints), hits))
+
+	var completed = fmt.Sprintf("Completions: %d", len(l.Completions))
+	if best, ok := l.BestTime(); ok {
+		completed += ", best time " + savegame.FormatDuration(best)
+	}
+	lines = append(lines, completed)
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,102 @@
+package analytics
+
+import (
+	"time"
+
+	"git.kirsle.net/go/render"
+)
+
+/*
+Session records the analytics of one play session of a level, to be merged
+into the store with Save.
+
+A nil Session records nothing, e.g. for levels that have no UUID.
+*/
+type Session struct {
+	UUID string
+	data *Level
+}
+
+// NewSession starts recording a play session of a level. Returns nil if the
+// level has no UUID to file the analytics under.
+func NewSession(uuid, title string) *Session {
+	if uuid == "" {
+		return nil
+	}
+
+	data := NewLevel(title)
+	data.Sessions = 1
+	return &Session{
+		UUID: uuid,
+		data: data,
+	}
+}
+
+// Position samples the player's position.
+func (s *Session) Position(p render.Point) {
+	if s == nil {
+		return
+	}
+	s.data.Positions[CellAt(p)]++
+}
+
+// Death records the player dying at a point, with the cause (e.g. the name
+// of the fire swatch, or the message of the doodad that killed them).
+func (s *Session) Death(p render.Point, cause string, elapsed time.Duration) {
+	if s == nil {
+		return
+	}
+	s.data.Deaths = append(s.data.Deaths, Death{
+		Point:   p,
+		Cause:   cause,
+		Elapsed: elapsed,
+	})
+}
+
+// Checkpoint records the player touching a checkpoint.
+func (s *Session) Checkpoint(p render.Point) {
+	if s == nil {
+		return
+	}
+	s.data.addCheckpoint(p, 1)
+}
+
+// Complete records the player completing the level.
+func (s *Session) Complete(elapsed time.Duration, perfect, cheated bool) {
+	if s == nil {
+		return
+	}
+	s.data.Completions = append(s.data.Completions, Completion{
+		Elapsed: elapsed,
+		Perfect: perfect,
+		Cheated: cheated,
+		Date:    time.Now(),
+	})
+}
+
+// Save merges the session into the analytics store on disk. The session may
+// go on recording, and the next Save only adds what was recorded since.
+func (s *Session) Save() error {
+	if s == nil {
+		return nil
+	}
+
+	store, err := Load()
+	if err != nil {
+		return err
+	}
+
+	row, ok := store.Levels[s.UUID]
+	if !ok {
+		row = NewLevel(s.data.Title)
+		store.Levels[s.UUID] = row
+	}
+	row.merge(s.data)
+
+	if err := store.Save(); err != nil {
+		return err
+	}
+
+	s.data = NewLevel(s.data.Title)
+	return nil
+}
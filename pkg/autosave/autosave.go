@@ -0,0 +1,332 @@
+/*
+Package autosave manages the editor's autosave snapshots and crash recovery.
+
+Each time the editor autosaves a drawing, it writes a new timestamped
+snapshot to the autosave folder of the user's profile directory, and only
+the newest few snapshots of each drawing are kept (balance.AutoSaveRevisions).
+Snapshots are filed under a hash of the drawing's full path, and each unsaved
+drawing is given a name of its own (see Untitled). The full path itself is
+kept next to the snapshots (see Remember) so that a restored snapshot saves
+back over the drawing it was taken of.
+
+While the game is running, a lock file is kept in the autosave folder and
+it is removed on a clean exit. If the lock file is still there when the
+game starts up, the last session crashed and the game may offer to restore
+the newest snapshot.
+*/
+package autosave
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/enum"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/userdir"
+)
+
+// Snapshot is one autosave file of a drawing.
+type Snapshot struct {
+	Filename string    // absolute path to the snapshot file
+	Drawing  string    // name of the drawing it was taken of, e.g. "example.level"
+	Key      string    // unique name its snapshots are filed under, e.g. "example#1a2b3c4d.level"
+	Time     time.Time // when the snapshot was taken
+}
+
+// Snapshot filenames look like "example#1a2b3c4d@20060102-150405.level", where
+// the hex digits after the # are a hash of the full path to the drawing.
+const (
+	separator  = "@"
+	hashMark   = "#"
+	timeFormat = "20060102-150405"
+	lockFile   = "running.lock"
+	untitled   = "untitled"
+	pathExt    = ".path" // the full path to a drawing, named after its key
+)
+
+// Extension returns the file extension of the snapshot, ".level" or ".doodad"
+func (s Snapshot) Extension() string {
+	return filepath.Ext(s.Filename)
+}
+
+// DrawingName returns the name of a drawing to show to the user: the base
+// filename of the drawing with its extension, e.g. "example.level". An unsaved
+// drawing is "untitled", and the filename of a snapshot gives the name of the
+// drawing it was taken of.
+func DrawingName(filename, ext string) string {
+	if filename == "" || isUntitled(filename) {
+		return untitled + ext
+	}
+
+	if snap, ok := parse(filename); ok && filepath.Dir(filename) == userdir.AutosaveDirectory {
+		return snap.Drawing
+	}
+
+	return baseName(filename, ext) + ext
+}
+
+// DrawingKey returns the unique name that the snapshots of a drawing are
+// filed under: its DrawingName with a hash of the full path to the drawing,
+// so that two drawings of the same name in different folders don't share
+// their snapshots. The filename of a snapshot gives the key of the drawing it
+// was taken of, and a name from Untitled is its own key.
+func DrawingKey(filename, ext string) string {
+	if filename == "" {
+		return untitled + ext
+	}
+
+	if isUntitled(filename) {
+		return filename + ext
+	}
+
+	if snap, ok := parse(filename); ok && filepath.Dir(filename) == userdir.AutosaveDirectory {
+		return snap.Key
+	}
+
+	var path = filename
+	if abs, err := filepath.Abs(filename); err == nil {
+		path = abs
+	}
+
+	var hash = fmt.Sprintf("%x", sha1.Sum([]byte(path)))
+	return baseName(filename, ext) + hashMark + hash[:8] + ext
+}
+
+// Untitled returns a new name to file the snapshots of an unsaved drawing
+// under, in place of its filename, so that every unsaved drawing keeps
+// snapshots of its own.
+func Untitled() string {
+	var id = make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		log.Error("autosave.Untitled: %s", err)
+	}
+	return fmt.Sprintf("%s%s%x", untitled, hashMark, id)
+}
+
+// NewFilename returns the path to write a new snapshot of the drawing to.
+func NewFilename(filename, ext string) string {
+	var name = strings.TrimSuffix(DrawingKey(filename, ext), ext)
+	return filepath.Join(
+		userdir.AutosaveDirectory,
+		name+separator+time.Now().Format(timeFormat)+ext,
+	)
+}
+
+// Remember keeps the full path to a drawing next to its snapshots, for
+// RestoreFilename. Unsaved drawings have no path to remember.
+func Remember(filename, ext string) error {
+	if filename == "" || isUntitled(filename) || runtime.GOOS == "js" {
+		return nil
+	}
+	if _, ok := parse(filename); ok && filepath.Dir(filename) == userdir.AutosaveDirectory {
+		return nil
+	}
+
+	var path = filename
+	if abs, err := filepath.Abs(filename); err == nil {
+		path = abs
+	}
+
+	return os.WriteFile(
+		filepath.Join(userdir.AutosaveDirectory, DrawingKey(filename, ext)+pathExt),
+		[]byte(path),
+		0644,
+	)
+}
+
+// RestoreFilename returns the filename to save a snapshot as after it is
+// opened in the editor: the full path to the drawing it was taken of, or
+// blank if the drawing was never saved. Snapshots whose path wasn't
+// remembered (e.g. from older versions) give the name of their drawing.
+// Returns false if the filename isn't an autosave snapshot.
+func RestoreFilename(filename string) (string, bool) {
+	snap, ok := parse(filename)
+	if !ok || filepath.Dir(filename) != userdir.AutosaveDirectory {
+		return "", false
+	}
+
+	if strings.TrimSuffix(snap.Drawing, snap.Extension()) == untitled {
+		return "", true
+	}
+
+	// The remembered path must still hash to the same key.
+	if data, err := os.ReadFile(filepath.Join(userdir.AutosaveDirectory, snap.Key+pathExt)); err == nil {
+		var path = string(data)
+		if DrawingKey(path, snap.Extension()) == snap.Key {
+			return path, true
+		}
+	}
+	return snap.Drawing, true
+}
+
+// List the snapshots of a drawing, newest first.
+func List(filename, ext string) ([]Snapshot, error) {
+	all, err := ListAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		key    = DrawingKey(filename, ext)
+		result = []Snapshot{}
+	)
+	for _, snap := range all {
+		if snap.Key == key {
+			result = append(result, snap)
+		}
+	}
+	return result, nil
+}
+
+// ListAll returns the snapshots of every drawing, newest first.
+func ListAll() ([]Snapshot, error) {
+	var result = []Snapshot{}
+
+	// WASM: autosave snapshots are a desktop feature.
+	if runtime.GOOS == "js" {
+		return result, nil
+	}
+
+	files, err := os.ReadDir(userdir.AutosaveDirectory)
+	if err != nil {
+		return result, err
+	}
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		if snap, ok := parse(filepath.Join(userdir.AutosaveDirectory, file.Name())); ok {
+			result = append(result, snap)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time.After(result[j].Time)
+	})
+	return result, nil
+}
+
+// Latest returns the newest snapshot of any drawing.
+func Latest() (Snapshot, bool) {
+	all, err := ListAll()
+	if err != nil || len(all) == 0 {
+		return Snapshot{}, false
+	}
+	return all[0], true
+}
+
+// Prune deletes the oldest snapshots of a drawing, keeping the newest ones.
+func Prune(filename, ext string, keep int) error {
+	snapshots, err := List(filename, ext)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := keep; i < len(snapshots); i++ {
+		log.Debug("autosave.Prune: delete old snapshot %s", snapshots[i].Filename)
+		if err := os.Remove(snapshots[i].Filename); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Start marks the game as running. If the previous session did not shut
+// down cleanly, returns true and the time that session had started.
+func Start() (lastStarted time.Time, unclean bool) {
+	if runtime.GOOS == "js" {
+		return lastStarted, false
+	}
+
+	var filename = filepath.Join(userdir.AutosaveDirectory, lockFile)
+	if stat, err := os.Stat(filename); err == nil {
+		log.Warn("autosave.Start: the last session did not shut down cleanly")
+		lastStarted = stat.ModTime()
+		unclean = true
+	}
+
+	var content = fmt.Sprintf("pid %d started at %s\n", os.Getpid(), time.Now().Format(time.RFC3339))
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		log.Error("autosave.Start: couldn't write lock file: %s", err)
+	}
+
+	return lastStarted, unclean
+}
+
+// Stop marks the game as having shut down cleanly.
+func Stop() {
+	if runtime.GOOS == "js" {
+		return
+	}
+
+	if err := os.Remove(filepath.Join(userdir.AutosaveDirectory, lockFile)); err != nil && !os.IsNotExist(err) {
+		log.Error("autosave.Stop: couldn't remove lock file: %s", err)
+	}
+}
+
+// baseName returns the base filename of a drawing without its extension, safe
+// to use in a snapshot filename.
+func baseName(filename, ext string) string {
+	var name = strings.TrimSuffix(filepath.Base(filename), ext)
+	name = strings.ReplaceAll(name, separator, "_")
+	name = strings.ReplaceAll(name, hashMark, "_")
+	return name
+}
+
+// isUntitled returns whether the filename is a name from Untitled.
+func isUntitled(filename string) bool {
+	id, ok := strings.CutPrefix(filename, untitled+hashMark)
+	if !ok || len(id) != 8 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+// parse a snapshot filename. Snapshots from older versions have no hash in
+// their name and are filed under their plain drawing name.
+func parse(filename string) (Snapshot, bool) {
+	var (
+		base = filepath.Base(filename)
+		ext  = filepath.Ext(base)
+	)
+	if ext != enum.LevelExt && ext != enum.DoodadExt {
+		return Snapshot{}, false
+	}
+
+	var idx = strings.LastIndex(base, separator)
+	if idx < 0 {
+		return Snapshot{}, false
+	}
+
+	dt, err := time.ParseInLocation(timeFormat, strings.TrimSuffix(base[idx+1:], ext), time.Local)
+	if err != nil {
+		return Snapshot{}, false
+	}
+
+	var (
+		key     = base[:idx]
+		drawing = key
+	)
+	if i := strings.LastIndex(key, hashMark); i >= 0 {
+		drawing = key[:i]
+	}
+
+	return Snapshot{
+		Filename: filename,
+		Drawing:  drawing + ext,
+		Key:      key + ext,
+		Time:     dt,
+	}, true
+}
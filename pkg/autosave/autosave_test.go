@@ -0,0 +1,182 @@
+package autosave
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/userdir"
+)
+
+func TestDrawingName(t *testing.T) {
+	userdir.AutosaveDirectory = t.TempDir()
+
+	tests := []struct {
+		filename string
+		ext      string
+		expect   string
+	}{
+		{"", ".level", "untitled.level"},
+		{"example.level", ".level", "example.level"},
+		{"/home/user/levels/example.level", ".level", "example.level"},
+		{"big@head.doodad", ".doodad", "big_head.doodad"},
+		{"big#head.doodad", ".doodad", "big_head.doodad"},
+		{"untitled#1a2b3c4d", ".level", "untitled.level"},
+		{filepath.Join(userdir.AutosaveDirectory, "example@20240102-030405.level"), ".level", "example.level"},
+		{filepath.Join(userdir.AutosaveDirectory, "example#1a2b3c4d@20240102-030405.level"), ".level", "example.level"},
+	}
+	for i, test := range tests {
+		if actual := DrawingName(test.filename, test.ext); actual != test.expect {
+			t.Errorf("Test %d: DrawingName(%s): expected %s but got %s",
+				i, test.filename, test.expect, actual,
+			)
+		}
+	}
+}
+
+func TestDrawingKey(t *testing.T) {
+	userdir.AutosaveDirectory = t.TempDir()
+
+	// The same name in different folders gets different keys.
+	var (
+		first  = DrawingKey("/home/user/levels/example.level", ".level")
+		second = DrawingKey("/home/user/downloads/example.level", ".level")
+	)
+	if first == second {
+		t.Errorf("expected different keys for drawings in different folders: %s", first)
+	}
+	for _, key := range []string{first, second} {
+		if !strings.HasPrefix(key, "example#") || !strings.HasSuffix(key, ".level") || len(key) != len("example#1a2b3c4d.level") {
+			t.Errorf("unexpected key: %s", key)
+		}
+	}
+	if DrawingKey("/home/user/levels/example.level", ".level") != first {
+		t.Errorf("expected the same key for the same drawing")
+	}
+
+	// A snapshot gives the key of the drawing it was taken of.
+	var snapshot = NewFilename("/home/user/levels/example.level", ".level")
+	if actual := DrawingKey(snapshot, ".level"); actual != first {
+		t.Errorf("DrawingKey(%s): expected %s but got %s", snapshot, first, actual)
+	}
+
+	// Every unsaved drawing gets a key of its own.
+	var a, b = Untitled(), Untitled()
+	if a == b || DrawingKey(a, ".level") == DrawingKey(b, ".level") {
+		t.Errorf("expected different keys for unsaved drawings: %s, %s", a, b)
+	}
+	if actual := DrawingKey(a, ".level"); actual != a+".level" {
+		t.Errorf("DrawingKey(%s): expected %s.level but got %s", a, a, actual)
+	}
+}
+
+func TestRestoreFilename(t *testing.T) {
+	userdir.AutosaveDirectory = t.TempDir()
+
+	tests := []struct {
+		filename string
+		expect   string
+		ok       bool
+	}{
+		{filepath.Join(userdir.AutosaveDirectory, "example@20240102-030405.level"), "example.level", true},
+		{filepath.Join(userdir.AutosaveDirectory, "example#1a2b3c4d@20240102-030405.level"), "example.level", true},
+		{filepath.Join(userdir.AutosaveDirectory, "untitled@20240102-030405.doodad"), "", true},
+		{filepath.Join(userdir.AutosaveDirectory, "untitled#1a2b3c4d@20240102-030405.doodad"), "", true},
+		{"example@20240102-030405.level", "", false},
+		{filepath.Join(userdir.AutosaveDirectory, "example.level"), "", false},
+	}
+	for i, test := range tests {
+		actual, ok := RestoreFilename(test.filename)
+		if actual != test.expect || ok != test.ok {
+			t.Errorf("Test %d: RestoreFilename(%s): expected %s, %t but got %s, %t",
+				i, test.filename, test.expect, test.ok, actual, ok,
+			)
+		}
+	}
+
+	// A snapshot of a drawing whose path was remembered restores its full
+	// path, which still gives the same key.
+	var drawing = "/home/user/levels/example.level"
+	if err := Remember(drawing, ".level"); err != nil {
+		t.Fatalf("Remember: %s", err)
+	}
+	var snapshot = NewFilename(drawing, ".level")
+	actual, ok := RestoreFilename(snapshot)
+	if actual != drawing || !ok {
+		t.Errorf("RestoreFilename(%s): expected %s, true but got %s, %t", snapshot, drawing, actual, ok)
+	}
+	if DrawingKey(actual, ".level") != DrawingKey(snapshot, ".level") {
+		t.Errorf("expected the restored drawing to keep the key of its snapshots")
+	}
+
+	// The remembered paths are not listed as snapshots.
+	if all, _ := ListAll(); len(all) != 0 {
+		t.Errorf("expected no snapshots, got %+v", all)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	userdir.AutosaveDirectory = t.TempDir()
+
+	// Write a few snapshots of drawings that share a name, and of two
+	// unsaved drawings.
+	var (
+		start    = time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)
+		drawings = []string{
+			"/home/user/levels/example.level",
+			"/home/user/downloads/example.level",
+			Untitled(),
+			Untitled(),
+		}
+	)
+	for i := 0; i < 5; i++ {
+		for _, drawing := range drawings {
+			var filename = filepath.Join(
+				userdir.AutosaveDirectory,
+				strings.TrimSuffix(DrawingKey(drawing, ".level"), ".level")+
+					separator+start.Add(time.Duration(i)*time.Minute).Format(timeFormat)+".level",
+			)
+			if err := os.WriteFile(filename, []byte("{}"), 0644); err != nil {
+				t.Fatalf("write %s: %s", filename, err)
+			}
+		}
+	}
+
+	for _, drawing := range []string{drawings[0], drawings[2]} {
+		if err := Prune(drawing, ".level", 2); err != nil {
+			t.Errorf("Prune(%s): %s", drawing, err)
+		}
+	}
+
+	for i, drawing := range drawings {
+		var expect = 5
+		if i%2 == 0 {
+			expect = 2
+		}
+
+		snapshots, _ := List(drawing, ".level")
+		if len(snapshots) != expect {
+			t.Fatalf("expected %d snapshots of %s, got %d", expect, drawing, len(snapshots))
+		}
+		if newest := start.Add(4 * time.Minute); !snapshots[0].Time.Equal(newest) {
+			t.Errorf("expected the newest snapshot of %s first (%s), got %s", drawing, newest, snapshots[0].Time)
+		}
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	userdir.AutosaveDirectory = t.TempDir()
+
+	if _, unclean := Start(); unclean {
+		t.Errorf("the first start should be clean")
+	}
+	if since, unclean := Start(); !unclean || since.IsZero() {
+		t.Errorf("starting again without Stop should be unclean")
+	}
+	Stop()
+	if _, unclean := Start(); unclean {
+		t.Errorf("starting after Stop should be clean")
+	}
+}
@@ -3,6 +3,7 @@ package balance
 import (
 	"time"
 
+	"git.kirsle.net/SketchyMaze/doodle/pkg/usercfg"
 	"git.kirsle.net/go/render"
 )
 
@@ -37,6 +38,33 @@ var (
 		Y: 60,
 	}
 
+	// Scripted camera: default pan speed (pixels per tick), and the
+	// allowed zoom levels in Play Mode (see Canvas.GetZoomMultiplier).
+	CameraPanSpeed = 12
+	CameraMinZoom  = -1
+	CameraMaxZoom  = 2
+
+	// Editor: a drag shorter than this with the Camera Region tool is a
+	// click to remove a region.
+	CameraRegionMinDrag = 8
+
+	// Editor: a drag shorter than this with the Region tool counts as a
+	// click on the region.
+	RegionMinDrag = 8
+
+	// Editor: size of the resize handle of a Region.
+	RegionHandleSize = 10
+
+	// Editor: ticks between updates of the Navigator window's overview.
+	NavigatorUpdateInterval uint64 = 30
+
+	// Editor: chunks the Navigator loads per tick to draw the overview of
+	// a level, the first time or after its palette changed.
+	NavigatorChunksPerUpdate = 8
+
+	// Editor: size of the control point handles of the Curve and Polygon tools.
+	VectorHandleSize = 6
+
 	// Threshold of how many ticks should pass between the last Fingers Up
 	// event and a mouse movement, to indicate that TouchScreenMode should end.
 	TouchScreenModeLastFingerDownTicks uint64 = 10
@@ -68,7 +96,8 @@ var (
 	// Default size for a new Doodad.
 	DoodadSize = 100
 
-	// Size of Undo/Redo history for map editor.
+	// Size of Undo/Redo history for map editor, unless the user changed it
+	// in their settings. See UndoHistoryDepth.
 	UndoHistory = 20
 
 	// Options for brush size.
@@ -94,6 +123,17 @@ var (
 	// Interval for auto-save in the editor
 	AutoSaveInterval = 5 * time.Minute
 
+	// Number of autosave snapshots to keep of each drawing; the oldest
+	// ones are deleted as new ones are taken.
+	AutoSaveRevisions = 10
+
+	// Playtest analytics: the player's position is sampled every this many
+	// ticks into a grid of square cells this many pixels wide, and only the
+	// newest deaths and completions of each level are kept.
+	AnalyticsSampleTicks uint64 = 15
+	AnalyticsCellSize           = 32
+	AnalyticsMaxEvents          = 500
+
 	// Default player character doodad in Play Mode.
 	PlayerCharacterDoodad = "boy.doodad"
 
@@ -114,6 +154,11 @@ var (
 	// Publishing: Doodads-embedded-within-levels.
 	EmbeddedDoodadsBasePath   = "assets/doodads/"
 	EmbeddedWallpaperBasePath = "assets/wallpapers/"
+	EmbeddedMusicBasePath     = "assets/music/"
+
+	// Sound effects played by actors fade out over this distance (in pixels)
+	// beyond the edge of the screen.
+	SoundFalloffDistance = 800
 
 	// File formats: save new levels and doodads gzip compressed
 	DrawingFormat = FormatZipfile
@@ -134,6 +179,9 @@ var (
 	// enemies are spawn camping.
 	RespawnGodModeTimer = 3 * time.Second
 
+	// How long the toast of an unlocked achievement stays on screen in Play Mode.
+	AchievementToastTime = 4 * time.Second
+
 	// GameController thresholds.
 	GameControllerMouseMoveMax float64 = 20  // Max pixels per tick to simulate mouse movement.
 	GameControllerScrollMin    float64 = 0.3 // Minimum threshold for a right-stick scroll event.
@@ -186,3 +234,12 @@ var (
 	// 50% off the top/right edge.
 	UICanvasDoodadButtonSpaceNeeded = 20
 )
+
+// UndoHistoryDepth returns the size of the Undo/Redo history from the user's
+// settings, or the default UndoHistory.
+func UndoHistoryDepth() int {
+	if usercfg.Current.UndoHistory > 0 {
+		return usercfg.Current.UndoHistory
+	}
+	return UndoHistory
+}
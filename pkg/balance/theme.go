@@ -221,6 +221,52 @@ var (
 	LinkLighten          = 128
 	LinkAnimSpeed uint64 = 30 // ticks
 
+	// Camera regions drawn in the level editor.
+	CameraRegionColor = render.RGBA(0, 153, 255, 255)
+
+	// Mirror axis of the Symmetry drawing mode.
+	SymmetryAxisColor = render.RGBA(255, 0, 255, 153)
+
+	// Control points of the Curve and Polygon tools.
+	VectorHandleColor = render.RGBA(0, 153, 255, 255)
+
+	// Selection rect of the Copy Brush tool.
+	BrushSelectColor = render.RGBA(153, 255, 153, 255)
+
+	// Editor Navigator window.
+	NavigatorBackground    = render.RGBA(255, 255, 255, 255)
+	NavigatorActorColor    = render.RGBA(255, 153, 0, 255)
+	NavigatorViewportColor = render.RGBA(255, 0, 0, 255)
+
+	// Selection rect of the Recolor tool.
+	RecolorSelectColor = render.RGBA(255, 255, 0, 255)
+
+	// Behavior view of the level editor: pixels are recolored by their swatch
+	// attributes, and actor hitboxes are outlined.
+	BehaviorSolidColor     = render.RGBA(0, 0, 0, 255)
+	BehaviorSemiSolidColor = render.RGBA(153, 102, 51, 255)
+	BehaviorFireColor      = render.RGBA(255, 51, 0, 255)
+	BehaviorWaterColor     = render.RGBA(0, 102, 255, 255)
+	BehaviorSlipperyColor  = render.RGBA(0, 204, 204, 255)
+	BehaviorMutedColor     = render.RGBA(221, 221, 221, 255)
+	BehaviorHitboxColor    = render.RGBA(255, 0, 255, 255)
+
+	// Playtest analytics heatmap drawn over the level in the editor: the
+	// opacity of its cells, and the outline of the checkpoints.
+	HeatmapAlpha      uint8 = 128
+	HeatmapCheckpoint       = render.RGBA(0, 255, 0, 255)
+
+	// Toast of an unlocked achievement in Play Mode.
+	AchievementToastBackground = render.RGBA(255, 255, 204, 230)
+
+	// Trigger regions drawn in the level editor.
+	RegionColor = render.RGBA(255, 153, 0, 255)
+	RegionFont  = render.Text{
+		Size:   10,
+		Color:  render.RGBA(255, 153, 0, 255),
+		Stroke: render.RGBA(0, 0, 0, 128),
+	}
+
 	PlayButtonFont = render.Text{
 		FontFilename: SansBoldFont,
 		Size:         16,
@@ -15,6 +15,7 @@ import (
 	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/modal"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/native"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/scripting"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/scripting/exceptions"
 	"github.com/dop251/goja"
 )
@@ -82,6 +83,8 @@ func (c Command) Run(d *Doodle) error {
 		out, err := c.RunScript(d, c.ArgsLiteral)
 		d.Flash("%+v", out)
 		return err
+	case "debug":
+		return c.Debug(d)
 	case "repl":
 		d.shell.Repl = true
 		d.shell.Text = "$ "
@@ -181,7 +184,7 @@ func (c Command) ExtractBindata(d *Doodle, path string) error {
 func (c Command) Help(d *Doodle) error {
 	if len(c.Args) == 0 {
 		d.Flash("Available commands: new save edit play quit echo error")
-		d.Flash("     alert clear help boolProp eval repl")
+		d.Flash("     alert clear help boolProp eval repl debug")
 		d.Flash("Type `help` and then the command, like: `help edit`")
 		return nil
 	}
@@ -224,6 +227,9 @@ func (c Command) Help(d *Doodle) error {
 		d.Flash("Enter a JavaScript shell on the in-game interpreter")
 	case "boolprop":
 		d.Flash("Toggle boolean values. `boolProp list` lists available")
+	case "debug":
+		d.Flash("Usage: debug <vms|eval|timers|subs|break|clear|step|continue|trace>")
+		d.Flash("Inspect and step through doodad scripts in Play Mode")
 	case "titlescreen":
 		d.Flash("Usage: titlescreen <filename.level>")
 		d.Flash("Open the title screen with a level")
@@ -390,6 +396,17 @@ func (c Command) RunScript(d *Doodle, code string) (goja.Value, error) {
 	return out, err
 }
 
+// runScriptIn evaluates some JavaScript code safely inside a doodad's VM.
+func (c Command) runScriptIn(d *Doodle, vm *scripting.VM, code string) (out goja.Value, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic in %s: %s", vm.Name, e)
+		}
+	}()
+
+	return vm.Run(code)
+}
+
 // Default command.
 func (c Command) Default(d *Doodle) error {
 	// Give the easter egg RiveScript bot a chance.
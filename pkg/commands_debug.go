@@ -0,0 +1,221 @@
+package doodle
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/cursor"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/keybind"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/scripting"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
+	"git.kirsle.net/go/render"
+)
+
+// Debug runs the `debug` command for the interactive script debugger.
+//
+// Usage: debug <subcommand> [args...]
+//
+//	debug vms                     List the live script VMs in Play Mode.
+//	debug eval <actor> <code>     Evaluate JavaScript inside an actor's VM.
+//	debug timers <actor>          Show an actor's pending setTimeout/setInterval.
+//	debug subs <actor>            Show an actor's PubSub subscriptions.
+//	debug break <actor|*> [event] Set a breakpoint (default event: OnCollide).
+//	debug clear [actor]           Clear breakpoints on an actor, or all of them.
+//	debug step                    Run the handler and halt before the next one.
+//	debug continue                Resume until the next breakpoint.
+//	debug trace [on|off]          Toggle PubSub logging, or show recent messages.
+//
+// A breakpoint halts the game right before the event handler runs, until the
+// developer steps or continues from the shell.
+//
+// The <actor> may be the full actor ID, a unique prefix of it, or a doodad
+// filename when only one actor uses that doodad.
+func (c Command) Debug(d *Doodle) error {
+	if len(c.Args) == 0 {
+		return errors.New("Usage: debug <vms|eval|timers|subs|break|clear|step|continue|trace>")
+	}
+
+	scene, ok := d.Scene.(*PlayScene)
+	if !ok {
+		return errors.New("debug: only available in Play Mode")
+	}
+
+	var (
+		supervisor = scene.ScriptSupervisor()
+		debugger   = supervisor.Debugger
+		subcommand = strings.ToLower(c.Args[0])
+		args       = c.Args[1:]
+	)
+
+	// Subcommands that target a specific actor VM.
+	findVM := func() (*scripting.VM, error) {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("Usage: debug %s <actor>", subcommand)
+		}
+		return supervisor.FindVM(args[0])
+	}
+
+	switch subcommand {
+	case "vms":
+		vms := supervisor.VMs()
+		d.Flash("%d live script VMs:", len(vms))
+		for _, vm := range vms {
+			d.Flash("  %s (%d timers)", vm.Name, len(vm.Timers()))
+		}
+	case "eval":
+		vm, err := findVM()
+		if err != nil {
+			return err
+		}
+
+		// The code is everything after the actor name.
+		code := strings.TrimSpace(strings.TrimPrefix(
+			strings.TrimSpace(strings.TrimPrefix(c.ArgsLiteral, c.Args[0])),
+			args[0],
+		))
+		if code == "" {
+			return errors.New("Usage: debug eval <actor> <code>")
+		}
+
+		scene.SetCheated()
+		out, err := c.runScriptIn(d, vm, code)
+		if err != nil {
+			return err
+		}
+		d.Flash("%s: %+v", vm.Name, out)
+	case "timers":
+		vm, err := findVM()
+		if err != nil {
+			return err
+		}
+
+		timers := vm.Timers()
+		d.Flash("%s has %d pending timers (tick is %d):", vm.Name, len(timers), shmem.Tick)
+		for _, t := range timers {
+			kind := "setTimeout"
+			if t.Repeat {
+				kind = "setInterval"
+			}
+			d.Flash("  #%d %s every %d ticks, next at tick %d", t.ID, kind, t.Ticks, t.NextTick)
+		}
+	case "subs":
+		vm, err := findVM()
+		if err != nil {
+			return err
+		}
+
+		subs := vm.Subscriptions()
+		names := make([]string, 0, len(subs))
+		for name := range subs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		d.Flash("%s subscribes to %d messages:", vm.Name, len(names))
+		for _, name := range names {
+			d.Flash("  %s (%d handlers)", name, subs[name])
+		}
+	case "break":
+		if len(args) == 0 {
+			for _, bp := range debugger.Breakpoints() {
+				d.Flash("Breakpoint: %s", bp)
+			}
+			return nil
+		}
+
+		var (
+			id    = "*"
+			event = scripting.CollideEvent
+		)
+		if args[0] != "*" {
+			vm, err := supervisor.FindVM(args[0])
+			if err != nil {
+				return err
+			}
+			id = vm.ID()
+		}
+		if len(args) > 1 {
+			event = args[1]
+		}
+
+		debugger.SetBreakpoint(id, event)
+		d.Flash("Breakpoint set: %s %s", id, event)
+	case "clear":
+		var id string
+		if len(args) > 0 && args[0] != "*" {
+			vm, err := supervisor.FindVM(args[0])
+			if err != nil {
+				return err
+			}
+			id = vm.ID()
+		}
+
+		debugger.ClearBreakpoints(id)
+		d.Flash("Breakpoints cleared.")
+	case "step":
+		if err := debugger.Step(); err != nil {
+			return err
+		}
+	case "continue":
+		debugger.Continue()
+		d.Flash("Resumed.")
+	case "trace":
+		if len(args) == 0 {
+			for _, line := range debugger.Messages() {
+				d.Flash(line)
+			}
+			return nil
+		}
+
+		debugger.SetTrace(args[0] == "on" || args[0] == "true")
+		d.Flash("PubSub trace: %+v", debugger.Trace())
+	default:
+		return fmt.Errorf("debug: unknown subcommand '%s'", subcommand)
+	}
+
+	return nil
+}
+
+// holdAtBreakpoint keeps the game drawing its frames and the shell while the
+// script debugger is halted at a breakpoint, until it is resumed. The scene's
+// logic is in the middle of running the event, so it doesn't loop meanwhile.
+func (d *Doodle) holdAtBreakpoint(debugger *scripting.Debugger) {
+	for d.running && debugger.Paused() {
+		ev, err := d.Engine.Poll()
+		if err != nil {
+			log.Error("event poll error: %s", err)
+			debugger.Continue()
+			return
+		}
+		d.event = ev
+
+		if ev.WindowResized {
+			d.width, d.height = d.Engine.WindowSize()
+		}
+		shmem.Cursor = render.NewPoint(ev.CursorX, ev.CursorY)
+
+		// The shell steps or continues from the breakpoint. Quitting the game
+		// resumes the scripts so the main loop can shut down.
+		if !d.shell.Open && keybind.ShellKey(ev) {
+			d.shell.Open = true
+		} else if !d.shell.Open && keybind.Shutdown(ev) {
+			debugger.Continue()
+		}
+
+		d.Scene.Draw(d)
+		if err := d.shell.Draw(d, ev); err != nil {
+			log.Error("shell error: %s", err)
+		}
+		d.DrawDebugOverlay()
+		cursor.Draw(d.Engine)
+
+		if err := d.Engine.Present(); err != nil {
+			log.Error("draw error: %s", err)
+		}
+		d.Engine.Delay(uint32(balance.TargetClockRate))
+	}
+}
@@ -3,7 +3,9 @@ package doodle
 import (
 	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/branding"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/keybind"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/native"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/usercfg"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/windows"
 	"git.kirsle.net/go/render"
 	"git.kirsle.net/go/ui"
@@ -15,7 +17,7 @@ import (
 // across any scene.
 func (d *Doodle) MakeHelpMenu(menu *ui.MenuBar, supervisor *ui.Supervisor) *ui.MenuButton {
 	helpMenu := menu.AddMenu("Help")
-	helpMenu.AddItemAccel("User Manual", "F1", func() {
+	helpMenu.AddItemAccel("User Manual", keybind.Shortcut(usercfg.ActionHelp), func() {
 		native.OpenLocalURL(balance.GuidebookPath)
 	})
 	helpMenu.AddItem("About", func() {
@@ -0,0 +1,45 @@
+package doodads
+
+import (
+	"fmt"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
+)
+
+// Diff describes how a doodad changed from an older version of it (such as
+// an autosave snapshot), one line per difference. See level.Diff.
+func Diff(old, new *Doodad) []string {
+	var result = []string{}
+
+	if old.Title != new.Title {
+		result = append(result, fmt.Sprintf("Title changed: %s -> %s", old.Title, new.Title))
+	}
+	if old.Author != new.Author {
+		result = append(result, fmt.Sprintf("Author changed: %s -> %s", old.Author, new.Author))
+	}
+	if old.Size != new.Size {
+		result = append(result, fmt.Sprintf("Size changed: %dx%d -> %dx%d", old.Size.W, old.Size.H, new.Size.W, new.Size.H))
+	}
+	if old.Script != new.Script {
+		result = append(result, "Script changed")
+	}
+
+	result = append(result, level.DiffPalettes(old.Palette, new.Palette)...)
+
+	// Layers, by their position.
+	for i, layer := range new.Layers {
+		if i >= len(old.Layers) {
+			result = append(result, fmt.Sprintf("Layer added: %s", layer.Name))
+			continue
+		}
+
+		for _, line := range level.DiffChunkers(old.Layers[i].Chunker, layer.Chunker) {
+			result = append(result, fmt.Sprintf("Layer %s: %s", layer.Name, line))
+		}
+	}
+	for i := len(new.Layers); i < len(old.Layers); i++ {
+		result = append(result, fmt.Sprintf("Layer removed: %s", old.Layers[i].Name))
+	}
+
+	return result
+}
@@ -91,7 +91,7 @@ func New(dimensions ...int) *Doodad {
 		},
 		Tags:        map[string]string{},
 		Options:     map[string]*Option{},
-		UndoHistory: drawtool.NewHistory(balance.UndoHistory),
+		UndoHistory: drawtool.NewHistory(balance.UndoHistoryDepth()),
 	}
 }
 
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"git.kirsle.net/SketchyMaze/doodle/pkg/autosave"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/branding"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/cursor"
@@ -122,6 +123,20 @@ func (d *Doodle) Run() error {
 		d.Goto(&MainScene{})
 	}
 
+	// Did the game crash last time? Offer to restore the drawing that was
+	// being edited.
+	if since, unclean := autosave.Start(); unclean {
+		d.offerRecovery(since)
+	}
+
+	// If the game crashes, try and save the drawing being edited first.
+	defer func() {
+		if err := recover(); err != nil {
+			d.rescueDrawing()
+			panic(err)
+		}
+	}()
+
 	log.Info("Enter Main Loop")
 	for d.running {
 		// d.Engine.Clear(render.White)
@@ -153,6 +168,10 @@ func (d *Doodle) Run() error {
 		// Globally store the cursor position.
 		shmem.Cursor = render.NewPoint(ev.CursorX, ev.CursorY)
 
+		// Rebinding a key in the Settings window? Take the key press
+		// before any hotkeys see it.
+		keybind.CaptureLoop(ev)
+
 		// Command line shell.
 		if d.shell.Open {
 		} else if keybind.ShellKey(ev) {
@@ -241,6 +260,7 @@ func (d *Doodle) Run() error {
 	}
 
 	log.Warn("Main Loop Exited! Shutting down...")
+	autosave.Stop()
 	return nil
 }
 
@@ -252,7 +272,12 @@ func (d *Doodle) MakeSettingsWindow(supervisor *ui.Supervisor) *ui.Window {
 		Engine:     d.Engine,
 		SceneName:  d.Scene.Name(),
 		OnApply: func() {
-
+			// Resize the undo history of the drawing open in the editor.
+			if scene, ok := d.Scene.(*EditorScene); ok && scene.UI != nil && scene.UI.Canvas != nil {
+				if history := scene.UI.Canvas.History(); history != nil {
+					history.SetLimit(balance.UndoHistoryDepth())
+				}
+			}
 		},
 		OnOpenCheatsWindow: func() *ui.Window {
 			return d.MakeCheatsWindow(supervisor)
@@ -267,7 +292,12 @@ func (d *Doodle) MakeSettingsWindow(supervisor *ui.Supervisor) *ui.Window {
 		CrosshairColor:     &usercfg.Current.CrosshairColor,
 		HideTouchHints:     &usercfg.Current.HideTouchHints,
 		DisableAutosave:    &usercfg.Current.DisableAutosave,
+		UndoHistory:        &usercfg.Current.UndoHistory,
+		Keymap:             &usercfg.Current.Keymap,
 		ControllerStyle:    &usercfg.Current.ControllerStyle,
+		MusicVolume:        &usercfg.Current.MusicVolume,
+		SoundVolume:        &usercfg.Current.SoundVolume,
+		MuteAudio:          &usercfg.Current.MuteAudio,
 	}
 	return windows.MakeSettingsWindow(d.width, d.height, cfg)
 }
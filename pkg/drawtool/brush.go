@@ -0,0 +1,309 @@
+package drawtool
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"git.kirsle.net/go/render"
+)
+
+// BrushShape is the shape of the tip of the drawing tools.
+type BrushShape int
+
+// Brush shapes.
+const (
+	SquareBrush BrushShape = iota // a square of the brush size (default)
+	RoundBrush                    // a circle with the brush size as its radius
+	CustomBrush                   // a mask of pixels from an image or the drawing
+)
+
+var brushShapeNames = []string{
+	"Square",
+	"Round",
+	"Custom",
+}
+
+func (s BrushShape) String() string {
+	return brushShapeNames[s]
+}
+
+// MaxCustomBrushSize is the largest width or height of a custom brush.
+const MaxCustomBrushSize = 128
+
+// The square brush used by strokes without a Brush.
+var defaultBrush = NewBrush(SquareBrush)
+
+/*
+Brush is the tip of the Pencil, Line, Rectangle, Ellipse and Eraser tools.
+
+Square and round brushes are sized by the Stroke.Thickness. A custom brush is
+a fixed mask of pixels, e.g. loaded from a small PNG image or copied from the
+drawing, and ignores the thickness.
+
+Strokes stamp the brush at each of their points. The brush is described as a
+list of Rects relative to its center (one per row of pixels) so that thick
+strokes can still be drawn and committed one rectangle at a time.
+*/
+type Brush struct {
+	Shape BrushShape
+	Name  string         // custom brushes
+	Mask  []render.Point // custom brushes: pixels relative to the center
+
+	// Cached rects and outlines by thickness.
+	cache map[int]*brushCache
+	mu    sync.Mutex
+}
+
+type brushCache struct {
+	rects   []render.Rect
+	outline []render.Point
+}
+
+// NewBrush returns a square or round brush.
+func NewBrush(shape BrushShape) *Brush {
+	return &Brush{
+		Shape: shape,
+	}
+}
+
+// BrushFromImage makes a custom brush from an image: every pixel which isn't
+// (mostly) transparent is part of the brush.
+func BrushFromImage(name string, img image.Image) (*Brush, error) {
+	var (
+		bounds = img.Bounds()
+		points = []render.Point{}
+	)
+	if bounds.Dx() > MaxCustomBrushSize || bounds.Dy() > MaxCustomBrushSize {
+		return nil, errors.New("the brush image is too large")
+	}
+
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a > 0x7FFF {
+				points = append(points, render.NewPoint(x, y))
+			}
+		}
+	}
+
+	return BrushFromPoints(name, points)
+}
+
+// LoadBrushImage makes a custom brush from a PNG image file.
+func LoadBrushImage(filename string) (*Brush, error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	img, err := png.Decode(fh)
+	if err != nil {
+		return nil, err
+	}
+
+	return BrushFromImage(filepath.Base(filename), img)
+}
+
+// BrushFromPoints makes a custom brush from a set of pixels, e.g. copied
+// from the drawing. The brush is centered on the middle of the pixels.
+func BrushFromPoints(name string, points []render.Point) (*Brush, error) {
+	if len(points) == 0 {
+		return nil, errors.New("the brush has no pixels")
+	}
+
+	// Find the bounding box.
+	var min, max = points[0], points[0]
+	for _, pt := range points {
+		if pt.X < min.X {
+			min.X = pt.X
+		}
+		if pt.Y < min.Y {
+			min.Y = pt.Y
+		}
+		if pt.X > max.X {
+			max.X = pt.X
+		}
+		if pt.Y > max.Y {
+			max.Y = pt.Y
+		}
+	}
+	if max.X-min.X >= MaxCustomBrushSize || max.Y-min.Y >= MaxCustomBrushSize {
+		return nil, errors.New("the brush is too large")
+	}
+
+	var (
+		center = render.NewPoint((min.X+max.X)/2, (min.Y+max.Y)/2)
+		mask   = make([]render.Point, 0, len(points))
+	)
+	for _, pt := range points {
+		mask = append(mask, render.NewPoint(pt.X-center.X, pt.Y-center.Y))
+	}
+
+	return &Brush{
+		Shape: CustomBrush,
+		Name:  name,
+		Mask:  mask,
+	}, nil
+}
+
+// IsThick returns whether a stroke with this brush and thickness must be
+// drawn with rects rather than single pixels.
+func (b *Brush) IsThick(thickness int) bool {
+	if b != nil && b.Shape == CustomBrush {
+		return true
+	}
+	return thickness > 0
+}
+
+// Rects returns the rectangles, relative to the center of the brush, that
+// make up its shape at a given thickness.
+func (b *Brush) Rects(thickness int) []render.Rect {
+	// The default square brush.
+	if b == nil || b.Shape == SquareBrush {
+		return []render.Rect{
+			{
+				X: -thickness,
+				Y: -thickness,
+				W: thickness * 2,
+				H: thickness * 2,
+			},
+		}
+	}
+
+	return b.cached(thickness).rects
+}
+
+// Outline returns the edge pixels of the brush, relative to its center, to
+// preview it under the mouse cursor.
+func (b *Brush) Outline(thickness int) []render.Point {
+	if b == nil {
+		b = defaultBrush
+	}
+	return b.cached(thickness).outline
+}
+
+// Transform returns a custom brush of this brush's pixels at a thickness,
+// moved through a pixel transform such as a mirror (see Symmetry). The
+// transform is relative to the brush's center, so that stamping the new brush
+// at transform(p) covers the transformed pixels of stamping this one at p.
+func (b *Brush) Transform(thickness int, transform func(render.Point) render.Point) *Brush {
+	var (
+		origin = transform(render.Origin)
+		mask   = []render.Point{}
+		name   = "Square"
+	)
+	if b != nil {
+		name = b.Name
+		if name == "" {
+			name = b.Shape.String()
+		}
+	}
+
+	for _, rect := range b.Rects(thickness) {
+		for x := rect.X; x < rect.X+rect.W; x++ {
+			for y := rect.Y; y < rect.Y+rect.H; y++ {
+				pt := transform(render.NewPoint(x, y))
+				mask = append(mask, render.NewPoint(pt.X-origin.X, pt.Y-origin.Y))
+			}
+		}
+	}
+
+	return &Brush{
+		Shape: CustomBrush,
+		Name:  name + " (mirrored)",
+		Mask:  mask,
+	}
+}
+
+// cached computes the rects and outline of the brush.
+func (b *Brush) cached(thickness int) *brushCache {
+	if b.Shape == CustomBrush {
+		thickness = 0 // custom brushes don't scale
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.cache == nil {
+		b.cache = map[int]*brushCache{}
+	} else if c, ok := b.cache[thickness]; ok {
+		return c
+	}
+
+	// Collect the brush pixels.
+	var pixels = map[render.Point]interface{}{}
+	switch b.Shape {
+	case SquareBrush:
+		for x := -thickness; x < thickness; x++ {
+			for y := -thickness; y < thickness; y++ {
+				pixels[render.NewPoint(x, y)] = nil
+			}
+		}
+	case RoundBrush:
+		for x := -thickness; x <= thickness; x++ {
+			for y := -thickness; y <= thickness; y++ {
+				if x*x+y*y <= thickness*thickness {
+					pixels[render.NewPoint(x, y)] = nil
+				}
+			}
+		}
+	case CustomBrush:
+		for _, pt := range b.Mask {
+			pixels[pt] = nil
+		}
+	}
+
+	var c = &brushCache{
+		rects:   pixelRows(pixels),
+		outline: pixelOutline(pixels),
+	}
+	b.cache[thickness] = c
+	return c
+}
+
+// pixelRows merges the runs of pixels on each row into rects.
+func pixelRows(pixels map[render.Point]interface{}) []render.Rect {
+	var sorted = make([]render.Point, 0, len(pixels))
+	for pt := range pixels {
+		sorted = append(sorted, pt)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Y != sorted[j].Y {
+			return sorted[i].Y < sorted[j].Y
+		}
+		return sorted[i].X < sorted[j].X
+	})
+
+	var rects = []render.Rect{}
+	for _, pt := range sorted {
+		if n := len(rects) - 1; n >= 0 && rects[n].Y == pt.Y && rects[n].X+rects[n].W == pt.X {
+			rects[n].W++
+			continue
+		}
+		rects = append(rects, render.Rect{X: pt.X, Y: pt.Y, W: 1, H: 1})
+	}
+	return rects
+}
+
+// pixelOutline returns the pixels with a neighbor outside of the shape.
+func pixelOutline(pixels map[render.Point]interface{}) []render.Point {
+	var outline = []render.Point{}
+	for pt := range pixels {
+		for _, neighbor := range []render.Point{
+			{X: pt.X - 1, Y: pt.Y},
+			{X: pt.X + 1, Y: pt.Y},
+			{X: pt.X, Y: pt.Y - 1},
+			{X: pt.X, Y: pt.Y + 1},
+		} {
+			if _, ok := pixels[neighbor]; !ok {
+				outline = append(outline, pt)
+				break
+			}
+		}
+	}
+	return outline
+}
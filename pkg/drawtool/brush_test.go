@@ -0,0 +1,113 @@
+package drawtool
+
+import (
+	"testing"
+
+	"git.kirsle.net/go/render"
+)
+
+func TestBrushRects(t *testing.T) {
+	// Count the pixels covered by a brush's rects.
+	area := func(rects []render.Rect) int {
+		var total int
+		for _, rect := range rects {
+			total += rect.W * rect.H
+		}
+		return total
+	}
+
+	var tests = []struct {
+		Note      string
+		Brush     *Brush
+		Thickness int
+		Thick     bool
+		Area      int
+	}{
+		{
+			Note:      "nil brush is a square",
+			Brush:     nil,
+			Thickness: 2,
+			Thick:     true,
+			Area:      16,
+		},
+		{
+			Note:      "thin square brush",
+			Brush:     NewBrush(SquareBrush),
+			Thickness: 0,
+			Thick:     false,
+			Area:      0,
+		},
+		{
+			Note:      "round brush of radius 1 is a plus sign",
+			Brush:     NewBrush(RoundBrush),
+			Thickness: 1,
+			Thick:     true,
+			Area:      5,
+		},
+		{
+			Note:      "round brush of radius 2",
+			Brush:     NewBrush(RoundBrush),
+			Thickness: 2,
+			Thick:     true,
+			Area:      13,
+		},
+	}
+
+	for _, test := range tests {
+		if thick := test.Brush.IsThick(test.Thickness); thick != test.Thick {
+			t.Errorf("%s: expected IsThick=%v but got %v", test.Note, test.Thick, thick)
+		}
+		if !test.Thick {
+			continue
+		}
+		if got := area(test.Brush.Rects(test.Thickness)); got != test.Area {
+			t.Errorf("%s: expected area %d but got %d", test.Note, test.Area, got)
+		}
+	}
+}
+
+func TestBrushFromPoints(t *testing.T) {
+	// An L shape of 4 pixels at an offset in the drawing.
+	brush, err := BrushFromPoints("test", []render.Point{
+		render.NewPoint(100, 100),
+		render.NewPoint(100, 101),
+		render.NewPoint(100, 102),
+		render.NewPoint(101, 102),
+	})
+	if err != nil {
+		t.Fatalf("BrushFromPoints: %s", err)
+	}
+
+	// Custom brushes are always thick and ignore the thickness.
+	if !brush.IsThick(0) {
+		t.Errorf("custom brush should be thick")
+	}
+
+	var rects = brush.Rects(10)
+	if len(rects) != 3 {
+		t.Errorf("expected 3 rows of pixels but got %d: %+v", len(rects), rects)
+	}
+
+	// Centered on the middle pixel.
+	if rects[0].X != 0 || rects[0].Y != -1 {
+		t.Errorf("expected the first row at 0,-1 but got %+v", rects[0])
+	}
+	if rects[2].W != 2 {
+		t.Errorf("expected the last row to be 2 pixels wide but got %+v", rects[2])
+	}
+
+	if len(brush.Outline(0)) != 4 {
+		t.Errorf("expected every pixel on the outline")
+	}
+
+	// Empty and oversized brushes.
+	if _, err := BrushFromPoints("empty", nil); err == nil {
+		t.Errorf("expected an error for an empty brush")
+	}
+	if _, err := BrushFromPoints("big", []render.Point{
+		render.NewPoint(0, 0),
+		render.NewPoint(MaxCustomBrushSize, 0),
+	}); err == nil {
+		t.Errorf("expected an error for a brush that's too large")
+	}
+}
@@ -1,19 +1,39 @@
 package drawtool
 
-// History manages a history of Strokes added to a drawing.
+// History manages a history of Strokes added to a drawing, along with other
+// Commands that changed the drawing (placing doodads, editing the palette...)
 type History struct {
-	limit int
-	head  *HistoryElement // oldest history element, top of linked list
-	tail  *HistoryElement // newest element added to history
+	limit   int
+	head    *HistoryElement // oldest history element, top of linked list
+	tail    *HistoryElement // newest element added to history
+	version uint64          // counts every change, see Version()
 }
 
-// HistoryElement is a doubly linked list of stroke history.
+// HistoryElement is a doubly linked list of stroke history. Each element holds
+// either a Stroke or a Command.
 type HistoryElement struct {
 	stroke   *Stroke
+	command  *Command
 	next     *HistoryElement
 	previous *HistoryElement
 }
 
+// Command is an undoable change to a drawing that isn't made of pixels, such
+// as placing a doodad or editing the palette. The caller provides the
+// functions that undo and redo the change.
+type Command struct {
+	Name string
+	Undo func()
+	Redo func()
+}
+
+// HistoryStep summarizes an element of the history, e.g. for the editor's
+// History window.
+type HistoryStep struct {
+	Name    string
+	Applied bool // false for steps that were undone and may be redone
+}
+
 // NewHistory initializes a History list.
 func NewHistory(limit int) *History {
 	return &History{
@@ -21,10 +41,25 @@ func NewHistory(limit int) *History {
 	}
 }
 
+// SetLimit changes the maximum size of the history. The oldest steps are
+// forgotten if the history is already bigger.
+func (h *History) SetLimit(limit int) {
+	h.limit = limit
+	h.version++
+	h.trim()
+}
+
 // Reset clears the history.
 func (h *History) Reset() {
 	h.head = nil
 	h.tail = nil
+	h.version++
+}
+
+// Version counts the changes to the history (adding, undoing and redoing
+// steps), so a caller can tell whether it changed since they last looked.
+func (h *History) Version() uint64 {
+	return h.version
 }
 
 // Size returns the current size of the history list.
@@ -42,9 +77,48 @@ func (h *History) Size() int {
 	return size
 }
 
+// Position returns the number of steps in the history that are currently
+// applied, i.e. the size of the history minus the steps that were undone.
+func (h *History) Position() int {
+	var (
+		position int
+		node     = h.tail
+	)
+
+	for node != nil {
+		position++
+		node = node.previous
+	}
+
+	return position
+}
+
+// Steps returns a summary of every element of the history, oldest first.
+func (h *History) Steps() []HistoryStep {
+	var (
+		steps    = []HistoryStep{}
+		position = h.Position()
+	)
+
+	for node := h.head; node != nil; node = node.next {
+		var step = HistoryStep{
+			Applied: len(steps) < position,
+		}
+		if node.command != nil {
+			step.Name = node.command.Name
+		} else if node.stroke != nil {
+			step.Name = node.stroke.Shape.String()
+		}
+		steps = append(steps, step)
+	}
+
+	return steps
+}
+
 // Latest returns the tail of the history (the most recent stroke). If you had
 // recently called Undo, the latest stroke may still have a 'next' stroke.
-// Returns nil if there was no stroke in history.
+// Returns nil if there was no stroke in history, or if the most recent
+// element is a Command.
 func (h *History) Latest() *Stroke {
 	if h.tail == nil {
 		return nil
@@ -52,6 +126,14 @@ func (h *History) Latest() *Stroke {
 	return h.tail.stroke
 }
 
+// LatestCommand returns the tail of the history if it is a Command, or nil.
+func (h *History) LatestCommand() *Command {
+	if h.tail == nil {
+		return nil
+	}
+	return h.tail.command
+}
+
 // Oldest returns the head of the history (the earliest stroke added). If the
 // history size limit had been reached, the oldest stroke will creep along
 // forward and not necessarily be the FIRST EVER stroke added.
@@ -65,39 +147,53 @@ func (h *History) Oldest() *Stroke {
 // AddStroke adds a stroke to the history, becoming the new tail at the end
 // of the history data.
 func (h *History) AddStroke(s *Stroke) {
-	var (
-		elem = &HistoryElement{
-			stroke: s,
-		}
-		tail = h.tail
-	)
+	h.add(&HistoryElement{
+		stroke: s,
+	})
+}
+
+// AddCommand adds a Command to the history, becoming the new tail at the end
+// of the history data. The change should already be applied to the drawing.
+func (h *History) AddCommand(c *Command) {
+	h.add(&HistoryElement{
+		command: c,
+	})
+}
+
+// add an element to the end of the history.
+func (h *History) add(elem *HistoryElement) {
+	var tail = h.tail
 
 	// Make the current tail point to this one.
 	if tail != nil {
 		tail.next = elem
 		elem.previous = tail
-	}
-
-	// First stroke of the history? Make it the head of the linked list.
-	if h.head == nil {
+	} else {
+		// Everything was undone (or it's the first stroke of the history):
+		// this becomes the head of the linked list.
 		h.head = elem
 	}
 
 	h.tail = elem
+	h.version++
+	h.trim()
+}
 
-	// Have we reached the history storage limit?
-	var size = h.Size()
-	if size > h.limit {
-		var node = h.tail
-		for i := 0; i < h.limit-1; i++ {
-			if node.previous == nil {
-				break
-			}
-			node = node.previous
+// trim the oldest elements when the history storage limit is reached.
+func (h *History) trim() {
+	if h.tail == nil || h.Size() <= h.limit {
+		return
+	}
+
+	var node = h.tail
+	for i := 0; i < h.limit-1; i++ {
+		if node.previous == nil {
+			break
 		}
-		h.head = node
-		h.head.previous = nil
+		node = node.previous
 	}
+	h.head = node
+	h.head.previous = nil
 }
 
 // Undo steps back a step in the history. This sets the current tail to point
@@ -117,15 +213,27 @@ func (h *History) Undo() bool {
 	// }
 
 	h.tail = h.tail.previous
+	h.version++
 	return true
 }
 
 // Redo advances forwards after a recent Undo. Note that if you added new strokes
 // after an Undo, the new tail has no next node to move to and Redo returns false.
 func (h *History) Redo() bool {
-	if h.tail == nil || h.tail.next == nil {
+	// Everything was undone? Redo the oldest element.
+	if h.tail == nil {
+		if h.head == nil {
+			return false
+		}
+		h.tail = h.head
+		h.version++
+		return true
+	}
+
+	if h.tail.next == nil {
 		return false
 	}
 	h.tail = h.tail.next
+	h.version++
 	return true
 }
@@ -130,3 +130,79 @@ func TestHistory(t *testing.T) {
 	shouldBool("after bulk undo, tail", true, H.Latest() == nil)
 	shouldBool("can't undo further", false, H.Undo())
 }
+
+func TestHistoryCommands(t *testing.T) {
+	var (
+		H     = NewHistory(10)
+		value = 0
+		set   = func(name string, from, to int) *Command {
+			value = to
+			return &Command{
+				Name: name,
+				Undo: func() { value = from },
+				Redo: func() { value = to },
+			}
+		}
+	)
+
+	H.AddStroke(&Stroke{Shape: Line})
+	H.AddCommand(set("Set to 1", 0, 1))
+	H.AddCommand(set("Set to 2", 1, 2))
+
+	if H.Latest() != nil {
+		t.Errorf("the latest element is a Command, Latest() should be nil")
+	}
+
+	// Undo the latest command.
+	if cmd := H.LatestCommand(); cmd == nil || cmd.Name != "Set to 2" {
+		t.Errorf("unexpected latest command: %+v", cmd)
+	} else {
+		cmd.Undo()
+		H.Undo()
+	}
+	if value != 1 || H.Position() != 2 {
+		t.Errorf("after undo: expected value 1 at position 2, got %d at %d", value, H.Position())
+	}
+
+	// The steps show the undone one.
+	var steps = H.Steps()
+	expect := []HistoryStep{
+		{Name: "Line", Applied: true},
+		{Name: "Set to 1", Applied: true},
+		{Name: "Set to 2", Applied: false},
+	}
+	if len(steps) != len(expect) {
+		t.Fatalf("expected %d steps but got %d", len(expect), len(steps))
+	}
+	for i, step := range steps {
+		if step != expect[i] {
+			t.Errorf("step %d: expected %+v but got %+v", i, expect[i], step)
+		}
+	}
+
+	// Undo everything, then Redo from the very beginning.
+	H.Undo()
+	H.Undo()
+	if H.Position() != 0 {
+		t.Errorf("expected position 0 after undoing everything, got %d", H.Position())
+	}
+	if !H.Redo() || H.Latest() == nil || H.Latest().Shape != Line {
+		t.Errorf("expected to redo the first stroke")
+	}
+
+	// A new element after undoing everything starts the history over.
+	H.Undo()
+	H.AddCommand(set("Set to 3", 0, 3))
+	if H.Size() != 1 {
+		t.Errorf("expected the history to start over, got size %d", H.Size())
+	}
+
+	// A smaller limit forgets the oldest steps.
+	for i := 0; i < 5; i++ {
+		H.AddStroke(&Stroke{Shape: Freehand})
+	}
+	H.SetLimit(3)
+	if H.Size() != 3 {
+		t.Errorf("expected size 3 after SetLimit, got %d", H.Size())
+	}
+}
@@ -9,5 +9,23 @@ const (
 	Line
 	Rectangle
 	Ellipse
-	Eraser // not really a shape but communicates the intention
+	Eraser   // not really a shape but communicates the intention
+	Curve    // Bezier curve through the Points
+	Polyline // lines connecting the Points
+	Polygon  // closed Polyline
 )
+
+var shapeNames = []string{
+	"Freehand",
+	"Line",
+	"Rectangle",
+	"Ellipse",
+	"Eraser",
+	"Curve",
+	"Polyline",
+	"Polygon",
+}
+
+func (s Shape) String() string {
+	return shapeNames[s]
+}
@@ -20,13 +20,16 @@ type Stroke struct {
 	Color     render.Color
 	Pattern   string
 	Thickness int         // 0 = 1px; thickness creates a box N pixels away from each point
+	Brush     *Brush      // shape of the thick brush, nil for a square
+	Fill      bool        // fill the inside of a Rectangle, Ellipse or Polygon
 	ExtraData interface{} // arbitrary storage for extra data to attach
 
 	// Start and end points for Lines, Rectangles, etc.
 	PointA render.Point
 	PointB render.Point
 
-	// Array of points for Freehand shapes.
+	// Array of points for Freehand shapes, or the control points of the
+	// vector shapes (Curve, Polyline and Polygon).
 	Points    []render.Point
 	uniqPoint map[render.Point]interface{} // deduplicate points added
 
@@ -39,6 +42,14 @@ type Stroke struct {
 	// The data is implementation defined and controlled by the caller. This
 	// package does not modify OriginalPoints or do anything with it.
 	OriginalPoints map[render.Point]interface{}
+
+	// Mirrored copies of the stroke drawn in a Symmetry mode. They are part
+	// of the same undo step as this stroke.
+	Mirrors []*Stroke
+
+	// The inside of a mirrored copy of a filled shape, worked out once by
+	// Symmetry.Mirror; FillSpans returns these when set.
+	Spans []render.Rect
 }
 
 var nextStrokeID int
@@ -67,7 +78,10 @@ func (s *Stroke) Copy() *Stroke {
 		Shape:     s.Shape,
 		Color:     s.Color,
 		Thickness: s.Thickness,
+		Brush:     s.Brush,
+		Fill:      s.Fill,
 		ExtraData: s.ExtraData,
+		Spans:     s.Spans,
 
 		Points:    []render.Point{},
 		uniqPoint: map[render.Point]interface{}{},
@@ -77,8 +91,30 @@ func (s *Stroke) Copy() *Stroke {
 // IterPoints returns an iterator of points represented by the stroke.
 //
 // For a Line, returns all of the points between PointA and PointB. For freehand,
-// returns every point added to the stroke.
+// returns every point added to the stroke. Filled shapes return their outline
+// followed by their FillSpans.
 func (s *Stroke) IterPoints() chan render.Point {
+	ch := make(chan render.Point)
+	go func() {
+		for point := range s.IterOutline() {
+			ch <- point
+		}
+		if s.Fill {
+			for _, span := range s.FillSpans() {
+				for y := span.Y; y < span.Y+span.H; y++ {
+					for x := span.X; x < span.X+span.W; x++ {
+						ch <- render.NewPoint(x, y)
+					}
+				}
+			}
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+// IterOutline returns the points of the stroke, not including the fill.
+func (s *Stroke) IterOutline() chan render.Point {
 	ch := make(chan render.Point)
 	go func() {
 		switch s.Shape {
@@ -100,22 +136,76 @@ func (s *Stroke) IterPoints() chan render.Point {
 			for point := range render.IterEllipse(s.PointA, s.PointB) {
 				ch <- point
 			}
+		case Curve:
+			for point := range IterBezier(s.Points) {
+				ch <- point
+			}
+		case Polyline, Polygon:
+			for point := range IterPolyline(s.Points, s.Shape == Polygon) {
+				ch <- point
+			}
 		}
 		close(ch)
 	}()
 	return ch
 }
 
+// IsThick returns whether the stroke is drawn with a brush (see
+// IterThickPoints) rather than single pixels.
+func (s *Stroke) IsThick() bool {
+	return s.Brush.IsThick(s.Thickness)
+}
+
 // IterThickPoints iterates over the points and yield Rects of each one.
+//
+// The brush is stamped at each point, as one or more Rects depending on its
+// shape. The inside of a filled shape is not stamped with the brush, but
+// comes as its FillSpans.
 func (s *Stroke) IterThickPoints() chan render.Rect {
 	ch := make(chan render.Rect)
 	go func() {
-		for pt := range s.IterPoints() {
-			ch <- render.Rect{
-				X: pt.X - s.Thickness,
-				Y: pt.Y - s.Thickness,
-				W: s.Thickness * 2,
-				H: s.Thickness * 2,
+		for rect := range s.IterBrushRects(nil) {
+			ch <- rect
+		}
+		if s.Fill {
+			for _, span := range s.FillSpans() {
+				ch <- span
+			}
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+// IterBrushRects stamps the brush along the outline of the stroke, like
+// IterThickPoints but without the fill. The brush is scaled by a zoom
+// function, e.g. to preview a stroke in screen coordinates on a zoomed in
+// canvas. The zoom function may be nil.
+func (s *Stroke) IterBrushRects(zoom func(int) int) chan render.Rect {
+	var rects = s.Brush.Rects(s.Thickness)
+	if zoom != nil {
+		var zoomed = make([]render.Rect, len(rects))
+		for i, rect := range rects {
+			zoomed[i] = render.Rect{
+				X: zoom(rect.X),
+				Y: zoom(rect.Y),
+				W: zoom(rect.W),
+				H: zoom(rect.H),
+			}
+		}
+		rects = zoomed
+	}
+
+	ch := make(chan render.Rect)
+	go func() {
+		for pt := range s.IterOutline() {
+			for _, rect := range rects {
+				ch <- render.Rect{
+					X: pt.X + rect.X,
+					Y: pt.Y + rect.Y,
+					W: rect.W,
+					H: rect.H,
+				}
 			}
 		}
 		close(ch)
@@ -0,0 +1,130 @@
+package drawtool
+
+import "git.kirsle.net/go/render"
+
+// Symmetry is a mirror drawing mode for the editable Canvas.
+type Symmetry int
+
+// Symmetry modes.
+const (
+	NoSymmetry         Symmetry = iota
+	HorizontalSymmetry          // mirror left and right of the axis
+	VerticalSymmetry            // mirror above and below the axis
+	RadialSymmetry              // four copies rotated around the axis
+)
+
+var symmetryNames = []string{
+	"Off",
+	"Horizontal",
+	"Vertical",
+	"Radial",
+}
+
+func (s Symmetry) String() string {
+	return symmetryNames[s]
+}
+
+// Transforms returns the pixel transforms that map a point to its mirrored
+// copies around the axis.
+//
+// The axis is on the boundary between pixels: for a Horizontal symmetry with
+// axis X=16, pixel 15 is mirrored to pixel 16 and pixel 0 to pixel 31. This
+// way a doodad's center (its width / 2) mirrors it exactly onto itself.
+func (s Symmetry) Transforms(axis render.Point) []func(render.Point) render.Point {
+	var (
+		mirrorX = func(p render.Point) render.Point {
+			return render.NewPoint(2*axis.X-1-p.X, p.Y)
+		}
+		mirrorY = func(p render.Point) render.Point {
+			return render.NewPoint(p.X, 2*axis.Y-1-p.Y)
+		}
+		rotate = func(p render.Point) render.Point {
+			// 90 degrees around the axis.
+			return render.NewPoint(axis.X+axis.Y-1-p.Y, axis.Y-axis.X+p.X)
+		}
+	)
+
+	switch s {
+	case HorizontalSymmetry:
+		return []func(render.Point) render.Point{mirrorX}
+	case VerticalSymmetry:
+		return []func(render.Point) render.Point{mirrorY}
+	case RadialSymmetry:
+		return []func(render.Point) render.Point{
+			rotate,
+			func(p render.Point) render.Point { return rotate(rotate(p)) },
+			func(p render.Point) render.Point { return rotate(rotate(rotate(p))) },
+		}
+	}
+	return nil
+}
+
+// Mirror returns the mirrored copies of a stroke around the axis, in the
+// same (world) coordinates as the stroke.
+//
+// The copies are freehand strokes of the mirrored pixels so that they match
+// the original exactly, and their brush is mirrored along with them. The
+// inside of a filled shape is mirrored by its spans into the copies' Spans.
+// The Eraser stays an eraser.
+func (s Symmetry) Mirror(stroke *Stroke, axis render.Point) []*Stroke {
+	var transforms = s.Transforms(axis)
+	if len(transforms) == 0 {
+		return nil
+	}
+
+	// Collect the stroke's outline and fill once.
+	var points = []render.Point{}
+	for pt := range stroke.IterOutline() {
+		points = append(points, pt)
+	}
+
+	var spans []render.Rect
+	if stroke.Fill {
+		spans = stroke.FillSpans()
+	}
+
+	var mirrors = make([]*Stroke, 0, len(transforms))
+	for _, transform := range transforms {
+		var shape = Freehand
+		if stroke.Shape == Eraser {
+			shape = Eraser
+		}
+
+		mirror := NewStroke(shape, stroke.Color)
+		mirror.Pattern = stroke.Pattern
+		mirror.Thickness = stroke.Thickness
+		mirror.ExtraData = stroke.ExtraData
+		if stroke.IsThick() {
+			mirror.Brush = stroke.Brush.Transform(stroke.Thickness, transform)
+		}
+
+		for _, pt := range points {
+			mirror.AddPoint(transform(pt))
+		}
+
+		if stroke.Fill {
+			mirror.Fill = true
+			mirror.Spans = make([]render.Rect, 0, len(spans))
+			for _, span := range spans {
+				mirror.Spans = append(mirror.Spans, transformRect(span, transform))
+			}
+		}
+		mirrors = append(mirrors, mirror)
+	}
+
+	return mirrors
+}
+
+// transformRect maps a Rect to its mirrored copy by its corner pixels.
+func transformRect(rect render.Rect, transform func(render.Point) render.Point) render.Rect {
+	var (
+		a = transform(render.NewPoint(rect.X, rect.Y))
+		b = transform(render.NewPoint(rect.X+rect.W-1, rect.Y+rect.H-1))
+	)
+	return render.Rect{
+		X: min(a.X, b.X),
+		Y: min(a.Y, b.Y),
+		W: max(a.X, b.X) - min(a.X, b.X) + 1,
+		H: max(a.Y, b.Y) - min(a.Y, b.Y) + 1,
+	}
+}
@@ -0,0 +1,157 @@
+package drawtool
+
+import (
+	"testing"
+
+	"git.kirsle.net/go/render"
+)
+
+func TestSymmetryTransforms(t *testing.T) {
+	var axis = render.NewPoint(16, 16) // center of a 32x32 doodad
+
+	var tests = []struct {
+		Mode   Symmetry
+		Input  render.Point
+		Expect []render.Point
+	}{
+		{
+			Mode:   NoSymmetry,
+			Input:  render.NewPoint(0, 0),
+			Expect: []render.Point{},
+		},
+		{
+			Mode:   HorizontalSymmetry,
+			Input:  render.NewPoint(0, 5),
+			Expect: []render.Point{render.NewPoint(31, 5)},
+		},
+		{
+			Mode:   HorizontalSymmetry,
+			Input:  render.NewPoint(15, 5),
+			Expect: []render.Point{render.NewPoint(16, 5)},
+		},
+		{
+			Mode:   VerticalSymmetry,
+			Input:  render.NewPoint(3, 0),
+			Expect: []render.Point{render.NewPoint(3, 31)},
+		},
+		{
+			Mode:  RadialSymmetry,
+			Input: render.NewPoint(0, 0),
+			Expect: []render.Point{
+				render.NewPoint(31, 0),
+				render.NewPoint(31, 31),
+				render.NewPoint(0, 31),
+			},
+		},
+	}
+
+	for i, test := range tests {
+		var transforms = test.Mode.Transforms(axis)
+		if len(transforms) != len(test.Expect) {
+			t.Errorf("Test %d (%s): expected %d transforms but got %d", i, test.Mode, len(test.Expect), len(transforms))
+			continue
+		}
+
+		for j, transform := range transforms {
+			if actual := transform(test.Input); actual != test.Expect[j] {
+				t.Errorf("Test %d (%s): expected %s to map to %s but got %s",
+					i, test.Mode, test.Input, test.Expect[j], actual,
+				)
+			}
+		}
+	}
+}
+
+func TestSymmetryMirrorBrush(t *testing.T) {
+	// The pixels covered by a stroke's thick brush.
+	coverage := func(s *Stroke) map[render.Point]interface{} {
+		var pixels = map[render.Point]interface{}{}
+		for rect := range s.IterThickPoints() {
+			for x := rect.X; x < rect.X+rect.W; x++ {
+				for y := rect.Y; y < rect.Y+rect.H; y++ {
+					pixels[render.NewPoint(x, y)] = nil
+				}
+			}
+		}
+		return pixels
+	}
+
+	var axis = render.NewPoint(10, 10)
+	for _, mode := range []Symmetry{HorizontalSymmetry, VerticalSymmetry, RadialSymmetry} {
+		// A square (asymmetric about its center point) thick line.
+		stroke := NewStroke(Line, render.Black)
+		stroke.Thickness = 2
+		stroke.PointA = render.NewPoint(2, 3)
+		stroke.PointB = render.NewPoint(6, 4)
+
+		var (
+			original   = coverage(stroke)
+			transforms = mode.Transforms(axis)
+			mirrors    = mode.Mirror(stroke, axis)
+		)
+		if len(mirrors) != len(transforms) {
+			t.Errorf("%s: expected %d mirrors but got %d", mode, len(transforms), len(mirrors))
+			continue
+		}
+
+		// Each mirror should cover exactly the transformed pixels of the original.
+		for i, mirror := range mirrors {
+			var actual = coverage(mirror)
+			if len(actual) != len(original) {
+				t.Errorf("%s mirror %d: expected %d pixels but got %d", mode, i, len(original), len(actual))
+			}
+			for pt := range original {
+				if _, ok := actual[transforms[i](pt)]; !ok {
+					t.Errorf("%s mirror %d: pixel %s was not mirrored to %s", mode, i, pt, transforms[i](pt))
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestSymmetryMirrorFill(t *testing.T) {
+	// The pixels of a stroke, with its fill.
+	coverage := func(s *Stroke) map[render.Point]interface{} {
+		var pixels = map[render.Point]interface{}{}
+		for pt := range s.IterPoints() {
+			pixels[pt] = nil
+		}
+		return pixels
+	}
+
+	var axis = render.NewPoint(20, 20)
+	for _, mode := range []Symmetry{HorizontalSymmetry, VerticalSymmetry, RadialSymmetry} {
+		stroke := NewStroke(Ellipse, render.Black)
+		stroke.Fill = true
+		stroke.PointA = render.NewPoint(2, 3)
+		stroke.PointB = render.NewPoint(14, 8)
+
+		var (
+			original   = coverage(stroke)
+			spans      = stroke.FillSpans()
+			transforms = mode.Transforms(axis)
+			mirrors    = mode.Mirror(stroke, axis)
+		)
+		for i, mirror := range mirrors {
+			// The fill is carried as mirrored spans, not as pixels.
+			if len(mirror.Spans) != len(spans) {
+				t.Errorf("%s mirror %d: expected %d spans but got %d", mode, i, len(spans), len(mirror.Spans))
+			}
+			if len(mirror.Points) >= len(original) {
+				t.Errorf("%s mirror %d: expected only the outline as points, got %d", mode, i, len(mirror.Points))
+			}
+
+			var actual = coverage(mirror)
+			if len(actual) != len(original) {
+				t.Errorf("%s mirror %d: expected %d pixels but got %d", mode, i, len(original), len(actual))
+			}
+			for pt := range original {
+				if _, ok := actual[transforms[i](pt)]; !ok {
+					t.Errorf("%s mirror %d: pixel %s was not mirrored to %s", mode, i, pt, transforms[i](pt))
+					break
+				}
+			}
+		}
+	}
+}
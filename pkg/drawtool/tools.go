@@ -15,6 +15,17 @@ const (
 	PanTool
 	TextTool
 	FloodTool
+	CameraRegionTool
+	TriggerRegionTool
+	BrushTool    // copy a custom brush from the drawing
+	SymmetryTool // move the mirror axis of the Symmetry mode
+	CurveTool    // click control points of a Bezier curve
+	PolylineTool
+	PolygonTool
+	FilledRectTool
+	FilledEllipseTool
+	FilledPolygonTool
+	RecolorTool // drag a selection to replace one swatch with another
 )
 
 var toolNames = []string{
@@ -28,8 +39,25 @@ var toolNames = []string{
 	"PanTool",
 	"TextTool",
 	"FloodTool",
+	"Camera Region",
+	"Trigger Region",
+	"Copy Brush",
+	"Symmetry Axis",
+	"Curve",
+	"Polyline",
+	"Polygon",
+	"Filled Rectangle",
+	"Filled Ellipse",
+	"Filled Polygon",
+	"Recolor",
 }
 
 func (t Tool) String() string {
 	return toolNames[t]
 }
+
+// IsVector returns whether the tool draws a vector shape whose control points
+// are edited before committing it.
+func (t Tool) IsVector() bool {
+	return t == CurveTool || t == PolylineTool || t == PolygonTool || t == FilledPolygonTool
+}
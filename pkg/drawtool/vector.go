@@ -0,0 +1,205 @@
+package drawtool
+
+import (
+	"math"
+	"sort"
+
+	"git.kirsle.net/go/render"
+)
+
+// Functions for the vector shapes (Curve, Polyline and Polygon) and the
+// filled variants of the Rectangle, Ellipse and Polygon.
+
+// IsVector returns whether the shape is drawn through a list of control
+// points (the Stroke.Points) which the user can edit before committing it.
+func (s Shape) IsVector() bool {
+	return s == Curve || s == Polyline || s == Polygon
+}
+
+// IterBezier returns all of the points along a Bezier curve. The first and
+// last points are the ends of the curve, and the points in between are its
+// control points; so two points make a straight line, three a quadratic
+// curve, four a cubic curve and so on.
+func IterBezier(points []render.Point) chan render.Point {
+	ch := make(chan render.Point)
+	go func() {
+		defer close(ch)
+		if len(points) == 0 {
+			return
+		}
+
+		// Sample the curve about once per pixel of its control polygon, which
+		// is always at least as long as the curve itself.
+		var length float64
+		for i := 1; i < len(points); i++ {
+			length += math.Hypot(
+				float64(points[i].X-points[i-1].X),
+				float64(points[i].Y-points[i-1].Y),
+			)
+		}
+		var steps = int(math.Ceil(length))
+		if steps < 1 {
+			steps = 1
+		}
+
+		// Connect the samples with lines so the curve has no gaps.
+		var prev = points[0]
+		ch <- prev
+		for i := 1; i <= steps; i++ {
+			pt := bezierPoint(points, float64(i)/float64(steps))
+			if pt == prev {
+				continue
+			}
+			for lp := range render.IterLine(prev, pt) {
+				if lp != prev {
+					ch <- lp
+				}
+			}
+			prev = pt
+		}
+	}()
+	return ch
+}
+
+// bezierPoint evaluates the Bezier curve at 0 <= t <= 1 (de Casteljau).
+func bezierPoint(points []render.Point, t float64) render.Point {
+	var (
+		xs = make([]float64, len(points))
+		ys = make([]float64, len(points))
+	)
+	for i, pt := range points {
+		xs[i] = float64(pt.X)
+		ys[i] = float64(pt.Y)
+	}
+
+	for k := len(points) - 1; k > 0; k-- {
+		for i := 0; i < k; i++ {
+			xs[i] += (xs[i+1] - xs[i]) * t
+			ys[i] += (ys[i+1] - ys[i]) * t
+		}
+	}
+
+	return render.NewPoint(int(math.Round(xs[0])), int(math.Round(ys[0])))
+}
+
+// IterPolyline returns the points along the lines connecting each point to
+// the next. If closed, the last point is connected back to the first.
+func IterPolyline(points []render.Point, closed bool) chan render.Point {
+	ch := make(chan render.Point)
+	go func() {
+		defer close(ch)
+		if len(points) == 0 {
+			return
+		}
+
+		var vertices = points
+		if closed && len(points) > 2 {
+			vertices = append(append([]render.Point{}, points...), points[0])
+		}
+
+		ch <- vertices[0]
+		for i := 1; i < len(vertices); i++ {
+			for pt := range render.IterLine(vertices[i-1], vertices[i]) {
+				if pt != vertices[i-1] {
+					ch <- pt
+				}
+			}
+		}
+	}()
+	return ch
+}
+
+// FillSpans returns the interior of a filled shape as a list of 1 pixel tall
+// Rects, one per row of pixels. The outline of the shape is not included.
+//
+// Shapes that can't be filled return nil. A mirrored copy returns its Spans,
+// which may be 1 pixel wide columns instead.
+func (s *Stroke) FillSpans() []render.Rect {
+	if s.Spans != nil {
+		return s.Spans
+	}
+
+	switch s.Shape {
+	case Rectangle:
+		var (
+			lo = render.NewPoint(min(s.PointA.X, s.PointB.X), min(s.PointA.Y, s.PointB.Y))
+			hi = render.NewPoint(max(s.PointA.X, s.PointB.X), max(s.PointA.Y, s.PointB.Y))
+		)
+		var spans = []render.Rect{}
+		for y := lo.Y + 1; y < hi.Y; y++ {
+			if hi.X-lo.X > 1 {
+				spans = append(spans, render.Rect{X: lo.X + 1, Y: y, W: hi.X - lo.X - 1, H: 1})
+			}
+		}
+		return spans
+	case Ellipse:
+		// The ellipse is convex: fill between the outline on each row.
+		var rows = map[int][2]int{}
+		for pt := range render.IterEllipse(s.PointA, s.PointB) {
+			if row, ok := rows[pt.Y]; ok {
+				rows[pt.Y] = [2]int{min(row[0], pt.X), max(row[1], pt.X)}
+			} else {
+				rows[pt.Y] = [2]int{pt.X, pt.X}
+			}
+		}
+
+		var spans = []render.Rect{}
+		for y, row := range rows {
+			if row[1]-row[0] > 1 {
+				spans = append(spans, render.Rect{X: row[0] + 1, Y: y, W: row[1] - row[0] - 1, H: 1})
+			}
+		}
+		sort.Slice(spans, func(i, j int) bool {
+			return spans[i].Y < spans[j].Y
+		})
+		return spans
+	case Polygon:
+		return polygonSpans(s.Points)
+	}
+	return nil
+}
+
+// polygonSpans fills a (possibly concave) polygon with the even-odd rule,
+// testing the center of each pixel.
+func polygonSpans(points []render.Point) []render.Rect {
+	if len(points) < 3 {
+		return nil
+	}
+
+	var minY, maxY = points[0].Y, points[0].Y
+	for _, pt := range points {
+		minY = min(minY, pt.Y)
+		maxY = max(maxY, pt.Y)
+	}
+
+	var spans = []render.Rect{}
+	for y := minY; y <= maxY; y++ {
+		// Where the edges of the polygon cross this row.
+		var crossings = []float64{}
+		for i := range points {
+			var (
+				a = points[i]
+				b = points[(i+1)%len(points)]
+			)
+			if (a.Y <= y) == (b.Y <= y) {
+				continue
+			}
+			crossings = append(crossings,
+				float64(a.X)+float64(y-a.Y)*float64(b.X-a.X)/float64(b.Y-a.Y),
+			)
+		}
+		sort.Float64s(crossings)
+
+		for i := 0; i+1 < len(crossings); i += 2 {
+			var (
+				x1 = int(math.Ceil(crossings[i]))
+				x2 = int(math.Floor(crossings[i+1]))
+			)
+			if x2 >= x1 {
+				spans = append(spans, render.Rect{X: x1, Y: y, W: x2 - x1 + 1, H: 1})
+			}
+		}
+	}
+
+	return spans
+}
@@ -0,0 +1,108 @@
+package drawtool
+
+import (
+	"testing"
+
+	"git.kirsle.net/go/render"
+)
+
+func TestBezierPoint(t *testing.T) {
+	var curve = []render.Point{
+		render.NewPoint(0, 0),
+		render.NewPoint(10, 20),
+		render.NewPoint(20, 0),
+	}
+
+	var tests = []struct {
+		T      float64
+		Expect render.Point
+	}{
+		{0, render.NewPoint(0, 0)},
+		{0.5, render.NewPoint(10, 10)},
+		{1, render.NewPoint(20, 0)},
+	}
+
+	for _, test := range tests {
+		if actual := bezierPoint(curve, test.T); actual != test.Expect {
+			t.Errorf("bezierPoint at t=%f: expected %s but got %s", test.T, test.Expect, actual)
+		}
+	}
+}
+
+func TestPolygonSpans(t *testing.T) {
+	// Count the pixels covered by the spans.
+	area := func(spans []render.Rect) int {
+		var total int
+		for _, span := range spans {
+			if span.H != 1 {
+				t.Errorf("span %+v should be 1 pixel tall", span)
+			}
+			total += span.W
+		}
+		return total
+	}
+
+	var tests = []struct {
+		Note   string
+		Points []render.Point
+		Area   int
+	}{
+		{
+			Note:   "too few points",
+			Points: []render.Point{render.NewPoint(0, 0), render.NewPoint(10, 10)},
+			Area:   0,
+		},
+		{
+			Note: "square",
+			Points: []render.Point{
+				render.NewPoint(0, 0),
+				render.NewPoint(10, 0),
+				render.NewPoint(10, 10),
+				render.NewPoint(0, 10),
+			},
+			Area: 110, // rows 0-9, columns 0-10
+		},
+		{
+			Note: "concave U shape",
+			Points: []render.Point{
+				render.NewPoint(0, 0),
+				render.NewPoint(4, 0),
+				render.NewPoint(4, 6),
+				render.NewPoint(6, 6),
+				render.NewPoint(6, 0),
+				render.NewPoint(10, 0),
+				render.NewPoint(10, 10),
+				render.NewPoint(0, 10),
+			},
+			Area: 6*(5+5) + 4*11, // the notch is not filled
+		},
+	}
+
+	for _, test := range tests {
+		if actual := area(polygonSpans(test.Points)); actual != test.Area {
+			t.Errorf("%s: expected area %d but got %d", test.Note, test.Area, actual)
+		}
+	}
+}
+
+func TestFilledRectangle(t *testing.T) {
+	stroke := NewStroke(Rectangle, render.Black)
+	stroke.Fill = true
+	stroke.PointA = render.NewPoint(5, 5)
+	stroke.PointB = render.NewPoint(0, 0)
+
+	var pixels = map[render.Point]interface{}{}
+	for _, span := range stroke.FillSpans() {
+		for x := span.X; x < span.X+span.W; x++ {
+			pixels[render.NewPoint(x, span.Y)] = nil
+		}
+	}
+
+	// The inside of a 6x6 rectangle, not including its outline.
+	if len(pixels) != 16 {
+		t.Errorf("expected 16 pixels inside the rectangle but got %d", len(pixels))
+	}
+	if _, ok := pixels[render.NewPoint(0, 0)]; ok {
+		t.Errorf("the outline should not be part of the fill")
+	}
+}
@@ -3,9 +3,11 @@ package doodle
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"git.kirsle.net/SketchyMaze/doodle/pkg/autosave"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/cursor"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/doodads"
@@ -55,6 +57,11 @@ type EditorScene struct {
 	// Last saved filename by the user.
 	filename string
 
+	// Name the autosave snapshots are filed under instead of the filename:
+	// the snapshot the drawing was restored from, or a name from
+	// autosave.Untitled for a drawing that was never saved.
+	autosaveName string
+
 	lastAutosaveAt time.Time
 
 	winOpenLevel *ui.Window
@@ -220,6 +227,16 @@ func (s *EditorScene) setupAsync(d *Doodle) error {
 		s.UI.Workspace.Compute(d.Engine)
 	}
 
+	// Opened an autosave snapshot? Saving writes over the drawing it was
+	// taken of, or asks for a filename if it was never saved.
+	if filename, ok := autosave.RestoreFilename(s.filename); ok {
+		s.autosaveName = s.filename
+		s.filename = filename
+		d.Flash("Restored from an autosave snapshot. Save the drawing to keep it.")
+	} else if s.filename == "" {
+		s.autosaveName = autosave.Untitled()
+	}
+
 	// Pre-cache all bitmap images from the level chunks.
 	// Note: we are not running on the main thread, so SDL2 Textures
 	// don't get created yet, but we do the full work of caching bitmap
@@ -533,6 +550,7 @@ func (s *EditorScene) SaveLevel(filename string) error {
 	}
 
 	s.filename = filename
+	s.autosaveName = ""
 
 	m := s.Level
 	if m.Title == "" {
@@ -581,32 +599,67 @@ func (s *EditorScene) UpdateLevelScreenshot(lvl *level.Level) error {
 
 // AutoSave takes an autosave snapshot of the level or drawing.
 func (s *EditorScene) AutoSave() error {
-	var (
-		filename = "_autosave.level"
-		err      error
-	)
-
 	s.d.FlashError("Beginning AutoSave() in a background thread")
 
 	// Trigger the auto-save in the background to not block the main thread.
 	go func() {
-		var err error
-		switch s.DrawingType {
-		case enum.LevelDrawing:
-			err = s.Level.WriteFile(filename)
-			s.d.Flash("Automatically saved level to %s", filename)
-		case enum.DoodadDrawing:
-			filename = "_autosave.doodad"
-			err = s.Doodad.WriteFile(filename)
-			s.d.Flash("Automatically saved doodad to %s", filename)
-		}
-
+		filename, err := s.writeSnapshot()
 		if err != nil {
-			s.d.FlashError("Error saving %s: %s", filename, err)
+			s.d.FlashError("Error saving %s: %s", filepath.Base(filename), err)
+			return
 		}
+		s.d.Flash("Automatically saved a snapshot to %s", filepath.Base(filename))
 	}()
 
-	return err
+	return nil
+}
+
+// writeSnapshot writes a new autosave snapshot of the level or drawing and
+// deletes its oldest ones. Returns the filename of the snapshot.
+func (s *EditorScene) writeSnapshot() (string, error) {
+	var (
+		filename string
+		ext      string
+		err      error
+	)
+
+	switch s.DrawingType {
+	case enum.LevelDrawing:
+		if s.Level == nil {
+			return "", errors.New("no level is open")
+		}
+		ext = enum.LevelExt
+		filename = autosave.NewFilename(s.autosaveFilename(), ext)
+		err = s.Level.WriteFile(filename)
+	case enum.DoodadDrawing:
+		if s.Doodad == nil {
+			return "", errors.New("no doodad is open")
+		}
+		ext = enum.DoodadExt
+		filename = autosave.NewFilename(s.autosaveFilename(), ext)
+		err = s.Doodad.WriteFile(filename)
+	}
+
+	if err != nil {
+		return filename, err
+	}
+
+	if err := autosave.Remember(s.autosaveFilename(), ext); err != nil {
+		log.Error("EditorScene.writeSnapshot: remember the drawing's path: %s", err)
+	}
+	if err := autosave.Prune(s.autosaveFilename(), ext, balance.AutoSaveRevisions); err != nil {
+		log.Error("EditorScene.writeSnapshot: prune old snapshots: %s", err)
+	}
+	return filename, nil
+}
+
+// autosaveFilename returns the filename that the autosave snapshots of the
+// drawing are filed under.
+func (s *EditorScene) autosaveFilename() string {
+	if s.autosaveName != "" {
+		return s.autosaveName
+	}
+	return s.filename
 }
 
 // LoadDoodad loads a doodad from disk.
@@ -636,6 +689,7 @@ func (s *EditorScene) SaveDoodad(filename string) error {
 	}
 
 	s.filename = filename
+	s.autosaveName = ""
 	d := s.Doodad
 	if d.Title == "" {
 		d.Title = "Untitled Doodad"
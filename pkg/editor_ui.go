@@ -12,6 +12,7 @@ import (
 	"git.kirsle.net/SketchyMaze/doodle/pkg/enum"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/modal"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/uix"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/usercfg"
@@ -57,6 +58,9 @@ type EditorUI struct {
 	filesystemWindow       *ui.Window
 	licenseWindow          *ui.Window
 	settingsWindow         *ui.Window // lazy loaded
+	navigatorWindow        *ui.Window // lazy loaded
+	historyWindow          *ui.Window // lazy loaded
+	revisionsWindow        *ui.Window // lazy loaded
 	doodadConfigWindows    map[string]*ui.Window
 
 	// Palette window.
@@ -69,6 +73,13 @@ type EditorUI struct {
 
 	// Draggable Doodad canvas.
 	DraggableActor *DraggableActor
+
+	// Undo history for changes that aren't made of pixels: see
+	// editor_ui_history.go
+	historyVersion   uint64                // of the History window
+	dragActorsBefore level.ActorMap        // actors before a doodad was picked up
+	paletteSnapshot  level.PaletteSnapshot // palette as of its last change
+	levelProperties  level.Properties      // level properties as of their last change
 }
 
 // NewEditorUI initializes the Editor UI.
@@ -154,6 +165,11 @@ func NewEditorUI(d *Doodle, s *EditorScene) *EditorUI {
 func (u *EditorUI) Teardown() {
 	log.Debug("EditorUI.Teardown()")
 	u.Canvas.Destroy()
+
+	// Closing the Navigator frees its overview texture.
+	if u.navigatorWindow != nil {
+		u.navigatorWindow.Close()
+	}
 }
 
 // FinishSetup runs the Setup tasks that must be postponed til the end, such
@@ -336,6 +352,8 @@ func (u *EditorUI) Loop(ev *event.State) error {
 	if !(stopPropagation || u.Supervisor.IsPointInWindow(u.cursor) || u.Supervisor.GetModal() != nil) {
 		u.Canvas.Loop(ev)
 	}
+
+	u.loopHistoryWindow()
 	return nil
 }
 
@@ -408,10 +426,19 @@ func (u *EditorUI) SetupCanvas(d *Doodle) *uix.Canvas {
 	// Handle the Canvas deleting our actors in edit mode.
 	drawing.OnDeleteActors = func(actors []*uix.Actor) {
 		if u.Scene.Level != nil {
+			var before = u.Scene.Level.Actors.Copy()
 			for _, actor := range actors {
 				u.Scene.Level.Actors.Remove(actor.Actor)
 			}
 			drawing.InstallActors(u.Scene.Level.Actors)
+
+			// Picked up a doodad to move it? It goes in the undo history
+			// once it is dropped.
+			if u.DraggableActor != nil && u.DraggableActor.actor != nil {
+				u.dragActorsBefore = before
+				return
+			}
+			u.addActorsCommand("Delete doodads", before)
 		}
 	}
 
@@ -431,11 +458,15 @@ func (u *EditorUI) SetupCanvas(d *Doodle) *uix.Canvas {
 
 		// Are they already linked?
 		if a.Actor.IsLinked(idB) || b.Actor.IsLinked(idA) {
-			a.Actor.Unlink(idB)
-			b.Actor.Unlink(idA)
+			u.recordActors("Unlink doodads", func() {
+				a.Actor.Unlink(idB)
+				b.Actor.Unlink(idA)
+			})
 		} else {
-			a.Actor.AddLink(idB)
-			b.Actor.AddLink(idA)
+			u.recordActors("Link doodads", func() {
+				a.Actor.AddLink(idB)
+				b.Actor.AddLink(idA)
+			})
 		}
 
 		// Reset the Link tool.
@@ -453,6 +484,9 @@ func (u *EditorUI) SetupCanvas(d *Doodle) *uix.Canvas {
 				OnRefresh: func() {
 
 				},
+				OnChange: func(apply func()) {
+					u.recordActors("Change doodad options", apply)
+				},
 			})
 			u.ConfigureWindow(d, win)
 			win.Show()
@@ -460,6 +494,41 @@ func (u *EditorUI) SetupCanvas(d *Doodle) *uix.Canvas {
 		}
 	}
 
+	// A custom brush was copied with the Copy Brush Tool: go back to the
+	// Pencil to draw with it.
+	drawing.OnBrushCopied = func(brush *drawtool.Brush) {
+		drawing.Tool = drawtool.PencilTool
+		u.activeTool = drawing.Tool.String()
+		d.Flash("Brush copied (%s). Pencil Tool selected.", brush.Name)
+	}
+
+	// A level region was clicked with the Trigger Region Tool.
+	drawing.OnRegionConfig = func(region *level.Region) {
+		var message = fmt.Sprintf("Size: %dx%d\nLinked doodads: %d", region.W, region.H, len(region.Links))
+		modal.Dialog("Region: "+region.Name, message, "Rename", "Unlink All", "Delete", "Cancel").ThenChoice(func(choice string) {
+			switch choice {
+			case "Rename":
+				shmem.PromptPre("Region name: ", region.Name, func(answer string) {
+					if answer != "" {
+						region.Name = answer
+						drawing.SetModified(true)
+						d.Flash("Renamed region to '%s'", answer)
+					}
+				})
+			case "Unlink All":
+				region.Links = []string{}
+				drawing.SetModified(true)
+				d.Flash("Unlinked all doodads from region '%s'", region.Name)
+			case "Delete":
+				if u.Scene.Level != nil {
+					u.Scene.Level.Regions.Remove(region)
+					drawing.SetModified(true)
+					d.Flash("Deleted region '%s'", region.Name)
+				}
+			}
+		})
+	}
+
 	// Set up the drop handler for draggable doodads.
 	// NOTE: The drag event begins at editor_ui_doodad.go when configuring the
 	// Doodad Palette buttons.
@@ -475,15 +544,27 @@ func (u *EditorUI) SetupCanvas(d *Doodle) *uix.Canvas {
 			defer func() {
 				u.DraggableActor.Teardown()
 				u.DraggableActor = nil
+				u.dragActorsBefore = nil
 			}()
 
+			// For the undo history: the actors from before a doodad was
+			// picked up to move it, or else from right now.
+			var before = u.dragActorsBefore
+			if before == nil && u.Scene.Level != nil {
+				before = u.Scene.Level.Actors.Copy()
+			}
+
 			if u.Scene.Level == nil {
 				u.d.Flash("Can't drop doodads onto doodad drawings!")
 				return nil
 			}
 
-			// If they dropped it onto a UI window, ignore it.
+			// If they dropped it onto a UI window, ignore it. A doodad that
+			// was picked up from the level is deleted.
 			if u.Supervisor.IsPointInWindow(ed.Point) {
+				if u.dragActorsBefore != nil {
+					u.addActorsCommand("Delete doodads", before)
+				}
 				return nil
 			}
 
@@ -514,11 +595,13 @@ func (u *EditorUI) SetupCanvas(d *Doodle) *uix.Canvas {
 
 				actor.actor.Point = position
 				u.Scene.Level.Actors.Add(actor.actor)
+				u.addActorsCommand("Move doodad", before)
 			} else {
 				u.Scene.Level.Actors.Add(level.NewActor(level.Actor{
 					Point:    position,
 					Filename: actor.doodad.Filename,
 				}))
+				u.addActorsCommand("Place doodad", before)
 			}
 
 			err := drawing.InstallActors(u.Scene.Level.Actors)
@@ -0,0 +1,74 @@
+package doodle
+
+import (
+	"git.kirsle.net/SketchyMaze/doodle/pkg/analytics"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/modal"
+)
+
+// ShowHeatmap draws the playtest analytics of the level (pkg/analytics) over
+// the canvas, and shows a summary of them.
+func (u *EditorUI) ShowHeatmap(overlay analytics.Overlay) {
+	if overlay == analytics.NoOverlay {
+		u.Canvas.Heatmap = nil
+		u.d.Flash("Heatmap: %s", overlay)
+		return
+	}
+
+	lvl, ok := u.levelAnalytics()
+	if !ok {
+		return
+	}
+
+	u.Canvas.Heatmap = lvl.Heatmap(overlay)
+	modal.Alert("%s", lvl.Summary()).WithTitle("Heatmap: " + overlay.String())
+}
+
+// ClearHeatmap deletes the playtest analytics of the level.
+func (u *EditorUI) ClearHeatmap() {
+	if _, ok := u.levelAnalytics(); !ok {
+		return
+	}
+
+	modal.Confirm("Delete the playtest analytics of this level?").WithTitle("Clear Heatmap").Then(func() {
+		store, err := analytics.Load()
+		if err != nil {
+			modal.Alert("Couldn't load the analytics: %s", err).WithTitle("Clear Heatmap")
+			return
+		}
+
+		store.Reset(u.Scene.Level.UUID)
+		if err := store.Save(); err != nil {
+			modal.Alert("Couldn't save the analytics: %s", err).WithTitle("Clear Heatmap")
+			return
+		}
+
+		u.Canvas.Heatmap = nil
+		u.d.Flash("The playtest analytics of this level were deleted.")
+	})
+}
+
+// levelAnalytics loads the playtest analytics of the level, or shows why
+// there are none.
+func (u *EditorUI) levelAnalytics() (*analytics.Level, bool) {
+	if u.Scene.Level == nil || u.Scene.Level.UUID == "" {
+		modal.Alert(
+			"This level has no UUID to record its playtest analytics by.\n" +
+				"Save the level and playtest it first.",
+		).WithTitle("Heatmap")
+		return nil, false
+	}
+
+	store, err := analytics.Load()
+	if err != nil {
+		modal.Alert("Couldn't load the analytics: %s", err).WithTitle("Heatmap")
+		return nil, false
+	}
+
+	lvl := store.Get(u.Scene.Level.UUID)
+	if lvl == nil {
+		modal.Alert("This level has not been playtested yet.").WithTitle("Heatmap")
+		return nil, false
+	}
+
+	return lvl, true
+}
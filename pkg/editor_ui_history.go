@@ -0,0 +1,201 @@
+package doodle
+
+import (
+	"reflect"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/drawtool"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/windows"
+)
+
+/*
+Undo/Redo history of the editor for changes that aren't made of pixels.
+
+Pixel strokes are recorded by the Canvas itself. The changes here (actors,
+links, palette swatches and level properties) are recorded as a
+drawtool.Command that restores a snapshot of the data taken before and
+after the change.
+
+The level's undo history outlives the EditorUI that recorded it (e.g., across
+a play test of the level), so the commands act on the current EditorUI.
+*/
+
+// currentUI returns the EditorUI of the scene that is running now.
+func (u *EditorUI) currentUI() *EditorUI {
+	if scene, ok := u.d.Scene.(*EditorScene); ok && scene.UI != nil {
+		return scene.UI
+	}
+	return u
+}
+
+// recordActors runs a change to the level's actors and records it in the
+// undo history.
+func (u *EditorUI) recordActors(name string, change func()) {
+	if u.Scene.Level == nil {
+		change()
+		return
+	}
+
+	var before = u.Scene.Level.Actors.Copy()
+	change()
+	u.addActorsCommand(name, before)
+}
+
+// addActorsCommand records a change to the level's actors that was already
+// made, given a copy of the actors from before the change.
+func (u *EditorUI) addActorsCommand(name string, before level.ActorMap) {
+	var after = u.Scene.Level.Actors.Copy()
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+
+	u.Canvas.AddCommand(&drawtool.Command{
+		Name: name,
+		Undo: func() { u.currentUI().restoreActors(before) },
+		Redo: func() { u.currentUI().restoreActors(after) },
+	})
+}
+
+// restoreActors puts the level's actors back to a snapshot.
+func (u *EditorUI) restoreActors(snapshot level.ActorMap) {
+	var actors = u.Scene.Level.Actors
+	for id := range actors {
+		delete(actors, id)
+	}
+	for id, actor := range snapshot.Copy() {
+		actors[id] = actor
+	}
+
+	// The actor properties windows edit the actors that were just replaced.
+	for id, win := range u.doodadConfigWindows {
+		win.Close()
+		delete(u.doodadConfigWindows, id)
+	}
+
+	if err := u.Canvas.InstallActors(actors); err != nil {
+		log.Error("EditorUI.restoreActors: %s", err)
+	}
+}
+
+// recordPalette records the changes made to the palette since the last time
+// it was recorded.
+func (u *EditorUI) recordPalette(pal *level.Palette) {
+	var (
+		before = u.paletteSnapshot
+		after  = pal.Snapshot()
+	)
+	u.paletteSnapshot = after
+
+	if before.Palette() != pal || before.Equal(after) {
+		return
+	}
+
+	u.Canvas.AddCommand(&drawtool.Command{
+		Name: "Edit palette",
+		Undo: func() { u.currentUI().restorePalette(pal, before) },
+		Redo: func() { u.currentUI().restorePalette(pal, after) },
+	})
+}
+
+// restorePalette puts the palette back to a snapshot.
+func (u *EditorUI) restorePalette(pal *level.Palette, snapshot level.PaletteSnapshot) {
+	pal.Restore(snapshot)
+	u.paletteSnapshot = snapshot
+	u.reloadPalette(pal)
+
+	// Rebuild the Palette Editor if it was open.
+	if u.paletteEditor != nil && !u.paletteEditor.Hidden() {
+		u.OpenPaletteWindow()
+	}
+}
+
+// recordLevelProperties records the changes made to the level's properties
+// since the last time they were recorded.
+func (u *EditorUI) recordLevelProperties() {
+	var lvl = u.Scene.Level
+	if lvl == nil {
+		return
+	}
+
+	var (
+		before = u.levelProperties
+		after  = lvl.Properties()
+	)
+	u.levelProperties = after
+
+	if before.Equal(after) {
+		return
+	}
+
+	u.Canvas.AddCommand(&drawtool.Command{
+		Name: "Edit level properties",
+		Undo: func() { u.currentUI().restoreLevelProperties(before) },
+		Redo: func() { u.currentUI().restoreLevelProperties(after) },
+	})
+}
+
+// restoreLevelProperties puts the level's properties back to a snapshot.
+func (u *EditorUI) restoreLevelProperties(props level.Properties) {
+	var lvl = u.Scene.Level
+	lvl.SetProperties(props)
+	u.levelProperties = props
+
+	// Reload the canvas in case the page type or wallpaper changed.
+	u.Canvas.Destroy()
+	u.Canvas.LoadLevel(lvl)
+
+	// Rebuild the Page Settings window to show the restored values.
+	if u.levelSettingsWindow != nil {
+		var shown = !u.levelSettingsWindow.Hidden()
+		u.levelSettingsWindow.Close()
+		u.levelSettingsWindow = nil
+		u.SetupPopups(u.d)
+		if shown {
+			u.levelSettingsWindow.Show()
+		}
+	}
+}
+
+// OpenHistoryWindow opens the undo History window.
+func (u *EditorUI) OpenHistoryWindow() {
+	var history = u.Canvas.History()
+	if history == nil {
+		return
+	}
+
+	if u.historyWindow != nil {
+		u.historyWindow.Close()
+		u.historyWindow = nil
+	}
+
+	u.historyWindow = windows.NewHistoryWindow(windows.History{
+		Supervisor: u.Supervisor,
+		Engine:     u.d.Engine,
+		History:    history,
+		OnJump: func(position int) {
+			if !u.Canvas.JumpHistory(position) {
+				u.d.FlashError("Couldn't go to step %d of the history.", position)
+			}
+		},
+		OnCancel: func() {
+			u.historyWindow.Close()
+		},
+	})
+	u.ConfigureWindow(u.d, u.historyWindow)
+	u.historyWindow.Show()
+	u.historyVersion = history.Version()
+}
+
+// loopHistoryWindow rebuilds the History window, if it is open, when the
+// history has changed.
+func (u *EditorUI) loopHistoryWindow() {
+	var history = u.Canvas.History()
+	if u.historyWindow == nil || u.historyWindow.Hidden() || history == nil {
+		return
+	}
+
+	if history.Version() != u.historyVersion {
+		u.OpenHistoryWindow()
+	}
+}
@@ -0,0 +1,43 @@
+package doodle
+
+import (
+	"fmt"
+	"strings"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level/lint"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/modal"
+)
+
+// Max number of problems to show in the Check Level alert.
+const maxLintLines = 12
+
+// CheckLevel checks the level being edited for problems (pkg/level/lint) and
+// shows the results.
+func (u *EditorUI) CheckLevel() {
+	if u.Scene.Level == nil {
+		return
+	}
+
+	var (
+		report = lint.Check(u.Scene.Level)
+		lines  = []string{}
+	)
+	for _, problem := range report.Problems {
+		lines = append(lines, "- "+problem.String())
+	}
+	if len(lines) > maxLintLines {
+		lines = append(lines[:maxLintLines], fmt.Sprintf("...and %d more.", len(lines)-maxLintLines))
+	}
+
+	if len(lines) == 0 {
+		modal.Alert("No problems were found in this level.").WithTitle("Check Level")
+		return
+	}
+
+	modal.Alert(
+		"Found %d error(s) and %d warning(s):\n\n%s",
+		report.Count(lint.Error),
+		report.Count(lint.Warning),
+		strings.Join(lines, "\n"),
+	).WithTitle("Check Level")
+}
@@ -5,15 +5,19 @@ package doodle
 // The rest of it is controlled in editor_ui.go
 
 import (
+	"git.kirsle.net/SketchyMaze/doodle/pkg/analytics"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/drawtool"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/enum"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/keybind"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/level/giant_screenshot"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/modal"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/native"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/plus/dpp"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/usercfg"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/userdir"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/windows"
 	"git.kirsle.net/go/render"
@@ -53,10 +57,10 @@ func (u *EditorUI) SetupMenuBar(d *Doodle) *ui.MenuBar {
 	////////
 	// File menu
 	fileMenu := menu.AddMenu("File")
-	fileMenu.AddItemAccel("New level", "Ctrl-N", u.Scene.MenuNewLevel)
+	fileMenu.AddItemAccel("New level", keybind.Shortcut(usercfg.ActionNewLevel), u.Scene.MenuNewLevel)
 	fileMenu.AddItem("New doodad", u.Scene.MenuNewDoodad)
-	fileMenu.AddItemAccel("Save", "Ctrl-S", u.Scene.MenuSave(false))
-	fileMenu.AddItemAccel("Save as...", "Shift-Ctrl-S", func() {
+	fileMenu.AddItemAccel("Save", keybind.Shortcut(usercfg.ActionSave), u.Scene.MenuSave(false))
+	fileMenu.AddItemAccel("Save as...", keybind.Shortcut(usercfg.ActionSaveAs), func() {
 		d.Prompt("Save as filename>", func(answer string) {
 			if answer != "" {
 				saveFunc(answer)
@@ -64,26 +68,32 @@ func (u *EditorUI) SetupMenuBar(d *Doodle) *ui.MenuBar {
 		})
 	})
 
-	fileMenu.AddItemAccel("Open...", "Ctrl-O", u.Scene.MenuOpen)
+	fileMenu.AddItemAccel("Open...", keybind.Shortcut(usercfg.ActionOpen), u.Scene.MenuOpen)
+	fileMenu.AddItem("Revisions...", func() {
+		u.OpenRevisionsWindow()
+	})
 	fileMenu.AddSeparator()
 	fileMenu.AddItem("Exit to menu", func() {
 		u.Scene.ConfirmUnload(func() {
 			d.Goto(&MainScene{})
 		})
 	})
-	fileMenu.AddItemAccel("Quit", "Escape", func() {
+	fileMenu.AddItemAccel("Quit", keybind.Shortcut(usercfg.ActionShutdown), func() {
 		d.ConfirmExit()
 	})
 
 	////////
 	// Edit menu
 	editMenu := menu.AddMenu("Edit")
-	editMenu.AddItemAccel("Undo", "Ctrl-Z", func() {
+	editMenu.AddItemAccel("Undo", keybind.Shortcut(usercfg.ActionUndo), func() {
 		u.Canvas.UndoStroke()
 	})
-	editMenu.AddItemAccel("Redo", "Ctrl-Y", func() {
+	editMenu.AddItemAccel("Redo", keybind.Shortcut(usercfg.ActionRedo), func() {
 		u.Canvas.RedoStroke()
 	})
+	editMenu.AddItem("History", func() {
+		u.OpenHistoryWindow()
+	})
 	editMenu.AddSeparator()
 	editMenu.AddItem("Settings", func() {
 		if u.settingsWindow == nil {
@@ -109,9 +119,12 @@ func (u *EditorUI) SetupMenuBar(d *Doodle) *ui.MenuBar {
 			log.Info("Opening the FileSystem window")
 			u.OpenFileSystemWindow()
 		})
-		levelMenu.AddItemAccel("Playtest", "P", func() {
+		levelMenu.AddItemAccel("Playtest", keybind.Shortcut(usercfg.ActionGotoPlay), func() {
 			u.Scene.Playtest()
 		})
+		levelMenu.AddItem("Check level", func() {
+			u.CheckLevel()
+		})
 		if balance.DPP {
 			levelMenu.AddItem("Publish", func() {
 				u.OpenPublishWindow()
@@ -160,7 +173,7 @@ func (u *EditorUI) SetupMenuBar(d *Doodle) *ui.MenuBar {
 
 		if balance.Feature.ViewportWindow {
 			levelMenu.AddSeparator()
-			levelMenu.AddItemAccel("New viewport", "v", func() {
+			levelMenu.AddItemAccel("New viewport", keybind.Shortcut(usercfg.ActionNewViewport), func() {
 				pip := windows.MakePiPWindow(d.width, d.height, windows.PiP{
 					Supervisor: u.Supervisor,
 					Engine:     u.d.Engine,
@@ -198,18 +211,45 @@ func (u *EditorUI) SetupMenuBar(d *Doodle) *ui.MenuBar {
 	////////
 	// View menu
 	viewMenu := menu.AddMenu("View")
-	viewMenu.AddItemAccel("Zoom in", "+", func() {
+	viewMenu.AddItemAccel("Zoom in", keybind.Shortcut(usercfg.ActionZoomIn), func() {
 		u.Canvas.Zoom++
 	})
-	viewMenu.AddItemAccel("Zoom out", "-", func() {
+	viewMenu.AddItemAccel("Zoom out", keybind.Shortcut(usercfg.ActionZoomOut), func() {
 		u.Canvas.Zoom--
 	})
-	viewMenu.AddItemAccel("Reset zoom", "1", func() {
+	viewMenu.AddItemAccel("Reset zoom", keybind.Shortcut(usercfg.ActionZoomReset), func() {
 		u.Canvas.Zoom = 0
 	})
-	viewMenu.AddItemAccel("Scroll drawing to origin", "0", func() {
+	viewMenu.AddItemAccel("Scroll drawing to origin", keybind.Shortcut(usercfg.ActionOrigin), func() {
 		u.Canvas.ScrollTo(render.Origin)
 	})
+	if u.Scene.DrawingType == enum.LevelDrawing {
+		viewMenu.AddItem("Navigator", func() {
+			u.OpenNavigatorWindow()
+		})
+	}
+
+	viewMenu.AddSeparator()
+	for _, view := range level.Behaviors {
+		view := view
+		viewMenu.AddItem("Behaviors: "+view.String(), func() {
+			u.Canvas.Behavior = view
+			d.Flash("Behavior view: %s", view)
+		})
+	}
+
+	if u.Scene.DrawingType == enum.LevelDrawing {
+		viewMenu.AddSeparator()
+		for _, overlay := range analytics.Overlays {
+			overlay := overlay
+			viewMenu.AddItem("Heatmap: "+overlay.String(), func() {
+				u.ShowHeatmap(overlay)
+			})
+		}
+		viewMenu.AddItem("Heatmap: Clear data", func() {
+			u.ClearHeatmap()
+		})
+	}
 
 	viewMenu.AddSeparator()
 
@@ -223,13 +263,13 @@ func (u *EditorUI) SetupMenuBar(d *Doodle) *ui.MenuBar {
 	////////
 	// Tools menu
 	toolMenu := menu.AddMenu("Tools")
-	toolMenu.AddItemAccel("Debug overlay", "F3", func() {
+	toolMenu.AddItemAccel("Debug overlay", keybind.Shortcut(usercfg.ActionDebugOverlay), func() {
 		DebugOverlay = !DebugOverlay
 		if DebugOverlay {
 			d.Flash("Debug overlay enabled. Press F3 to turn it off.")
 		}
 	})
-	toolMenu.AddItemAccel("Command shell", "`", func() {
+	toolMenu.AddItemAccel("Command shell", keybind.Shortcut(usercfg.ActionShellKey), func() {
 		d.shell.Open = true
 	})
 	toolMenu.AddSeparator()
@@ -238,34 +278,110 @@ func (u *EditorUI) SetupMenuBar(d *Doodle) *ui.MenuBar {
 	})
 
 	// Draw Tools
-	toolMenu.AddItemAccel("Pencil Tool", "F", func() {
+	toolMenu.AddItemAccel("Pencil Tool", keybind.Shortcut(usercfg.ActionPencilTool), func() {
 		u.Canvas.Tool = drawtool.PencilTool
 		u.activeTool = u.Canvas.Tool.String()
 		d.Flash("Pencil Tool selected.")
 	})
-	toolMenu.AddItemAccel("Line Tool", "L", func() {
+	toolMenu.AddItemAccel("Line Tool", keybind.Shortcut(usercfg.ActionLineTool), func() {
 		u.Canvas.Tool = drawtool.LineTool
 		u.activeTool = u.Canvas.Tool.String()
 		d.Flash("Line Tool selected.")
 	})
-	toolMenu.AddItemAccel("Rectangle Tool", "R", func() {
+	toolMenu.AddItemAccel("Rectangle Tool", keybind.Shortcut(usercfg.ActionRectTool), func() {
 		u.Canvas.Tool = drawtool.RectTool
 		u.activeTool = u.Canvas.Tool.String()
 		d.Flash("Rectangle Tool selected.")
 	})
-	toolMenu.AddItemAccel("Ellipse Tool", "C", func() {
+	toolMenu.AddItemAccel("Ellipse Tool", keybind.Shortcut(usercfg.ActionEllipseTool), func() {
 		u.Canvas.Tool = drawtool.EllipseTool
 		u.activeTool = u.Canvas.Tool.String()
 		d.Flash("Ellipse Tool selected.")
 	})
-	toolMenu.AddItemAccel("Eraser Tool", "x", func() {
+
+	// Filled and vector shapes.
+	for _, tool := range []drawtool.Tool{
+		drawtool.FilledRectTool,
+		drawtool.FilledEllipseTool,
+		drawtool.CurveTool,
+		drawtool.PolylineTool,
+		drawtool.PolygonTool,
+		drawtool.FilledPolygonTool,
+	} {
+		tool := tool
+		toolMenu.AddItem(tool.String()+" Tool", func() {
+			u.Canvas.Tool = tool
+			u.activeTool = u.Canvas.Tool.String()
+			if tool.IsVector() {
+				d.Flash("%s Tool selected. Click to add points, drag them to adjust, and press Enter or right-click to finish.", tool)
+			} else {
+				d.Flash("%s Tool selected.", tool)
+			}
+		})
+	}
+	toolMenu.AddItemAccel("Eraser Tool", keybind.Shortcut(usercfg.ActionEraserTool), func() {
 		u.Canvas.Tool = drawtool.EraserTool
 		u.activeTool = u.Canvas.Tool.String()
 		d.Flash("Eraser Tool selected.")
 	})
 
+	// Brush shapes for the drawing tools.
+	toolMenu.AddSeparator()
+	toolMenu.AddItem("Square Brush", func() {
+		u.Canvas.Brush = nil
+		d.Flash("Square brush selected.")
+	})
+	toolMenu.AddItem("Round Brush", func() {
+		u.Canvas.Brush = drawtool.NewBrush(drawtool.RoundBrush)
+		d.Flash("Round brush selected.")
+	})
+	toolMenu.AddItem("Load Custom Brush...", func() {
+		filename, err := native.OpenFile("Choose a brush image:", "*.png")
+		if err != nil {
+			return
+		}
+
+		brush, err := drawtool.LoadBrushImage(filename)
+		if err != nil {
+			d.FlashError("Couldn't load the brush: %s", err)
+			return
+		}
+
+		u.Canvas.Brush = brush
+		d.Flash("Custom brush loaded: %s", brush.Name)
+	})
+	toolMenu.AddItem("Copy Brush Tool", func() {
+		u.Canvas.Tool = drawtool.BrushTool
+		u.activeTool = u.Canvas.Tool.String()
+		d.Flash("Copy Brush Tool selected. Drag a box around some pixels to use them as your brush.")
+	})
+
+	// Symmetry (mirror) drawing modes.
+	toolMenu.AddSeparator()
+	for _, mode := range []drawtool.Symmetry{
+		drawtool.NoSymmetry,
+		drawtool.HorizontalSymmetry,
+		drawtool.VerticalSymmetry,
+		drawtool.RadialSymmetry,
+	} {
+		mode := mode
+		toolMenu.AddItem("Symmetry: "+mode.String(), func() {
+			u.Canvas.SetSymmetry(mode)
+			d.Flash("Symmetry mode: %s", mode)
+		})
+	}
+	toolMenu.AddItem("Symmetry Axis Tool", func() {
+		u.Canvas.Tool = drawtool.SymmetryTool
+		u.activeTool = u.Canvas.Tool.String()
+		d.Flash("Symmetry Axis Tool selected. Click to move the mirror axis.")
+	})
+	toolMenu.AddItem("Center Symmetry Axis", func() {
+		u.Canvas.CenterSymmetryAxis()
+		d.Flash("Symmetry axis moved to the center.")
+	})
+
 	if u.Scene.DrawingType == enum.LevelDrawing {
-		toolMenu.AddItemAccel("Doodads", "q", func() {
+		toolMenu.AddItemAccel("Doodads", keybind.Shortcut(usercfg.ActionDoodadDropper), func() {
 			log.Info("Open the DoodadDropper")
 			u.OpenDoodadDropper()
 		})
@@ -274,6 +390,16 @@ func (u *EditorUI) SetupMenuBar(d *Doodle) *ui.MenuBar {
 			u.activeTool = u.Canvas.Tool.String()
 			d.Flash("Link Tool selected. Click a doodad in your level to link it to another.")
 		})
+		toolMenu.AddItem("Camera Region Tool", func() {
+			u.Canvas.Tool = drawtool.CameraRegionTool
+			u.activeTool = u.Canvas.Tool.String()
+			d.Flash("Camera Region Tool selected. Drag to add a region, click one to remove it.")
+		})
+		toolMenu.AddItem("Trigger Region Tool", func() {
+			u.Canvas.Tool = drawtool.TriggerRegionTool
+			u.activeTool = u.Canvas.Tool.String()
+			d.Flash("Trigger Region Tool selected. Drag to add a region, click one to rename or delete it.")
+		})
 	}
 
 	////////
@@ -42,6 +42,46 @@ func (u *EditorUI) OpenPaletteWindow() {
 	u.paletteEditor.Show()
 }
 
+// reloadPalette refreshes the drawing and the Palette toolbar after changes
+// to the palette's swatches.
+func (u *EditorUI) reloadPalette(pal *level.Palette) {
+	var scene = u.Scene
+
+	// Reload the level.
+	if scene.Level != nil {
+		log.Warn("RELOAD LEVEL")
+		u.Canvas.LoadLevel(scene.Level)
+		scene.Level.Chunker.Redraw()
+	} else if scene.Doodad != nil {
+		log.Warn("RELOAD DOODAD")
+		u.Canvas.LoadDoodadToLayer(scene.Doodad, scene.ActiveLayer)
+		scene.Doodad.Layers[scene.ActiveLayer].Chunker.Redraw()
+	}
+
+	// Flush the palette cache in case swatches got renamed,
+	// so it rebuilds the "color by name" map from scratch.
+	pal.FlushCaches()
+
+	// Reload the palette frame to reflect the changed data.
+	u.Palette.Hide()
+	u.Palette = u.SetupPalette(u.d)
+	u.Resized(u.d)
+}
+
+// OpenNavigatorWindow opens the Navigator (minimap) window.
+func (u *EditorUI) OpenNavigatorWindow() {
+	if u.navigatorWindow == nil {
+		u.navigatorWindow = windows.MakeNavigatorWindow(u.d.width, u.d.height, windows.Navigator{
+			Supervisor: u.Supervisor,
+			Engine:     u.d.Engine,
+			Canvas:     u.Canvas,
+			Event:      u.d.event,
+		})
+	}
+	u.navigatorWindow.Show()
+	u.Supervisor.FocusWindow(u.navigatorWindow)
+}
+
 // OpenDoodadDropper opens the Doodad Dropper window.
 func (u *EditorUI) OpenDoodadDropper() {
 	// NOTE: most places in the code call this directly, nice
@@ -176,6 +216,11 @@ func (u *EditorUI) SetupPopups(d *Doodle) {
 	if u.levelSettingsWindow == nil {
 		scene, _ := d.Scene.(*EditorScene)
 
+		// Undo history: changes are recorded relative to this snapshot.
+		if scene.Level != nil {
+			u.levelProperties = scene.Level.Properties()
+		}
+
 		u.levelSettingsWindow = windows.NewAddEditLevel(windows.AddEditLevel{
 			Supervisor: u.Supervisor,
 			Engine:     d.Engine,
@@ -187,6 +232,10 @@ func (u *EditorUI) SetupPopups(d *Doodle) {
 				scene.Level.Wallpaper = wallpaper
 				u.Canvas.Destroy() // clean up old textures
 				u.Canvas.LoadLevel(scene.Level)
+				u.recordLevelProperties()
+			},
+			OnChange: func() {
+				u.recordLevelProperties()
 			},
 			OnUpdateScreenshot: func() error {
 				return scene.UpdateLevelScreenshot(scene.Level)
@@ -284,6 +333,11 @@ func (u *EditorUI) SetupPopups(d *Doodle) {
 			pal = scene.Doodad.Palette
 		}
 
+		// Undo history: changes are recorded relative to this snapshot.
+		if pal != nil && u.paletteSnapshot.Palette() != pal {
+			u.paletteSnapshot = pal.Snapshot()
+		}
+
 		u.paletteEditor = windows.NewPaletteEditor(windows.PaletteEditor{
 			Supervisor:  u.Supervisor,
 			Engine:      d.Engine,
@@ -291,25 +345,8 @@ func (u *EditorUI) SetupPopups(d *Doodle) {
 			EditPalette: pal,
 
 			OnChange: func() {
-				// Reload the level.
-				if scene.Level != nil {
-					log.Warn("RELOAD LEVEL")
-					u.Canvas.LoadLevel(scene.Level)
-					scene.Level.Chunker.Redraw()
-				} else if scene.Doodad != nil {
-					log.Warn("RELOAD DOODAD")
-					u.Canvas.LoadDoodadToLayer(u.Scene.Doodad, u.Scene.ActiveLayer)
-					u.Scene.Doodad.Layers[u.Scene.ActiveLayer].Chunker.Redraw()
-				}
-
-				// Flush the palette cache in case swatches got renamed,
-				// so it rebuilds the "color by name" map from scratch.
-				pal.FlushCaches()
-
-				// Reload the palette frame to reflect the changed data.
-				u.Palette.Hide()
-				u.Palette = u.SetupPalette(d)
-				u.Resized(d)
+				u.reloadPalette(pal)
+				u.recordPalette(pal)
 			},
 			OnAddColor: func() {
 				// Adding a new color to the palette.
@@ -320,6 +357,7 @@ func (u *EditorUI) SetupPopups(d *Doodle) {
 				}
 
 				log.Info("Added new palette color: %+v", sw)
+				u.recordPalette(pal)
 
 				// Awkward but... reload this very same window.
 				u.paletteEditor.Close()
@@ -327,6 +365,28 @@ func (u *EditorUI) SetupPopups(d *Doodle) {
 				u.SetupPopups(d)
 				u.paletteEditor.Show()
 			},
+			OnReplaceSwatch: func(from, to *level.Swatch, scope string) {
+				var within render.Rect
+				switch {
+				case scope == "":
+					// The whole drawing.
+				case strings.EqualFold(scope, "selection"):
+					u.Canvas.RecolorFrom = from
+					u.Canvas.RecolorTo = to
+					u.Canvas.Tool = drawtool.RecolorTool
+					u.activeTool = u.Canvas.Tool.String()
+					d.Flash("Recolor Tool selected. Drag a box to replace '%s' with '%s' inside it.", from.Name, to.Name)
+					return
+				case scene.Level != nil && scene.Level.Regions.ByName(scope) != nil:
+					within = scene.Level.Regions.ByName(scope).Rect()
+				default:
+					d.FlashError("No region named '%s' in this level.", scope)
+					return
+				}
+
+				count := u.Canvas.ReplaceSwatch(from, to, within)
+				d.Flash("Replaced %d pixels of '%s' with '%s'.", count, from.Name, to.Name)
+			},
 			OnCancel: func() {
 				u.paletteEditor.Close()
 			},
@@ -0,0 +1,102 @@
+package doodle
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/autosave"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/doodads"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/enum"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/modal"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/windows"
+)
+
+// Max number of differences to show in the Diff alert of the Revisions window.
+const maxDiffLines = 12
+
+// OpenRevisionsWindow opens the Revisions window with the autosave snapshots
+// of the drawing being edited.
+func (u *EditorUI) OpenRevisionsWindow() {
+	var ext = enum.LevelExt
+	if u.Scene.DrawingType == enum.DoodadDrawing {
+		ext = enum.DoodadExt
+	}
+
+	snapshots, err := autosave.List(u.Scene.autosaveFilename(), ext)
+	if err != nil {
+		u.d.FlashError("Couldn't list the autosaves: %s", err)
+	}
+
+	if u.revisionsWindow != nil {
+		u.revisionsWindow.Close()
+		u.revisionsWindow = nil
+	}
+
+	u.revisionsWindow = windows.NewRevisionsWindow(windows.Revisions{
+		Supervisor: u.Supervisor,
+		Engine:     u.d.Engine,
+		Drawing:    autosave.DrawingName(u.Scene.autosaveFilename(), ext),
+		Snapshots:  snapshots,
+		OnOpen: func(snap autosave.Snapshot) {
+			modal.Confirm(
+				"Open the autosave from %s?\n\nUnsaved changes to the current drawing will be lost.",
+				snap.Time.Format("Jan 2 15:04:05"),
+			).WithTitle("Open Revision").Then(func() {
+				if err := u.d.EditDrawing(snap.Filename); err != nil {
+					u.d.FlashError("Couldn't open the autosave: %s", err)
+				}
+			})
+		},
+		OnDiff: func(snap autosave.Snapshot) {
+			diff, err := u.diffRevision(snap)
+			if err != nil {
+				u.d.FlashError("Couldn't compare with the autosave: %s", err)
+				return
+			}
+
+			var message = "The autosave is the same as the current drawing."
+			if len(diff) > maxDiffLines {
+				diff = append(diff[:maxDiffLines], fmt.Sprintf("...and %d more.", len(diff)-maxDiffLines))
+			}
+			if len(diff) > 0 {
+				message = "Changes since the autosave:\n\n" + strings.Join(diff, "\n")
+			}
+			modal.Alert("%s", message).WithTitle("Revision from " + snap.Time.Format("Jan 2 15:04:05"))
+		},
+		OnAutoSave: func() {
+			filename, err := u.Scene.writeSnapshot()
+			if err != nil {
+				u.d.FlashError("Error saving %s: %s", filename, err)
+				return
+			}
+			u.Scene.lastAutosaveAt = time.Now()
+			u.OpenRevisionsWindow()
+		},
+		OnCancel: func() {
+			u.revisionsWindow.Close()
+		},
+	})
+	u.ConfigureWindow(u.d, u.revisionsWindow)
+	u.revisionsWindow.Show()
+}
+
+// diffRevision compares an autosave snapshot with the drawing being edited.
+func (u *EditorUI) diffRevision(snap autosave.Snapshot) ([]string, error) {
+	switch u.Scene.DrawingType {
+	case enum.LevelDrawing:
+		old, err := level.LoadFile(snap.Filename)
+		if err != nil {
+			return nil, err
+		}
+		return level.Diff(old, u.Scene.Level), nil
+	case enum.DoodadDrawing:
+		old, err := doodads.LoadFile(snap.Filename)
+		if err != nil {
+			return nil, err
+		}
+		return doodads.Diff(old, u.Scene.Doodad), nil
+	}
+	return nil, fmt.Errorf("unknown drawing type")
+}
@@ -21,6 +21,9 @@ const (
 	EditorMode
 )
 
+// AnyMode is for the gamepad actions which are active in every mode.
+const AnyMode Mode = -1
+
 // Controller style options.
 const (
 	XStyle      Style = iota // Xbox 360 layout (A button on bottom)
@@ -3,25 +3,27 @@ Package gamepad provides game controller logic for the game.
 
 # Controls
 
-The gamepad controls are currently hard-coded for Xbox 360 style controllers, and the
+Each game controller has its own profile of button mappings and analog stick
+settings (usercfg.GamepadProfile), keyed by its GUID in the user's settings.
+The Gamepad window (from the Settings window) remaps its buttons. The buttons
+translate into the keys and mouse clicks of the game's actions, and the
 controller mappings vary depending on the "mode" of control.
 
-# N Style and X Style
+The default buttons of each action are listed below, see Actions in
+profile.go for them all.
 
-If the gamepad control is set to "NStyle" then the A/B and X/Y buttons will be swapped
-to match the labels of a Nintendo style controller. Since the game has relatively few
-inputs needed now, this module maps them to a PrimaryButton and a SecondaryButton,
-defined as:
+# N Style and X Style
 
-PrimaryButton: A or X button
-SecondaryButton: B or Y button
+If the gamepad control is set to "NStyle" then the default A/B and X/Y buttons
+will be swapped to match the labels of a Nintendo style controller. New
+controller profiles take the style from the Settings window.
 
 # Mouse Mode
 
 - Left stick moves the mouse cursor (a cursor sprite is drawn on screen)
 - Right stick scrolls the level (title screen or level editor)
-- PrimaryButton emulates a left click.
-- SecondaryButton emulates a right click.
+- A or X emulates a left click.
+- B or Y emulates a right click.
 - Left Shoulder emulates a middle click.
 - Left Trigger (L2) closes the top-most window in the Editor (Backspace key)
 - Right Shoulder toggles between Mouse Mode and other scene-specific mode.
@@ -30,18 +32,28 @@ SecondaryButton: B or Y button
 
 - Left stick moves the player character (left/right only).
 - D-Pad also moves the player character (left/right only).
-- PrimaryButton is to "Use"
-- SecondaryButton is to "Jump"
+- A or X is to "Use"
+- B or Y is to "Jump"
+- Select shows or hides the inventory.
 - If the player has antigravity, up/down controls on left stick or D-Pad work too.
 - Right Shoulder toggles between GameplayMode and MouseMode.
+
+# Multiple Controllers
+
+Any connected controller may play: whichever one last pressed a button becomes
+Player One, with its own profile.
 */
 package gamepad
 
 import (
+	"math"
+
 	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/keybind"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/sprites"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/usercfg"
 	"git.kirsle.net/go/render"
 	"git.kirsle.net/go/render/event"
 	"git.kirsle.net/go/ui"
@@ -53,8 +65,9 @@ var (
 	PlayModeAntigravity bool
 	SceneName           string // Set by doodle.Goto() so we know what scene name we're on.
 
-	playerOne *int // controller index for Player 1 (main).
-	p1style   Style
+	playerOne *int   // controller index for Player 1 (main).
+	p1id      string // controller GUID of Player 1, for their profile.
+	p1name    string
 	p1mode    Mode
 
 	// Mouse cursor
@@ -62,24 +75,22 @@ var (
 	cursorSprite  *ui.Image
 	cursorLast    render.Point // detect if mouse cursor took over from gamepad
 
-	// MouseMode button state history to emulate mouse-ups.
-	leftClickLast   bool
-	rightClickLast  bool
-	middleClickLast bool
-	leftTriggerLast bool
+	// Actions whose buttons are held down, to emulate key-ups and mouse-ups.
+	actionsHeld = map[string]bool{}
+
+	// After a button is remapped, it is still held down: don't trigger its
+	// action until it has been released.
+	settleButtons bool
 
 	// MouseMode right stick last position, for arrow key (level scroll) emulation.
 	rightStickLast event.Vector
 
 	// Gameplay mode last left-stick position.
 	leftStickLast event.Vector
-	dpLeftLast    bool // D-Pad last positions.
-	dpRightLast   bool
-	dpDownLast    bool
-	dpUpLast      bool
 
-	// Right Shoulder last state (mode switch)
-	rightShoulderLast bool
+	// Button capture for remapping an action in the Gamepad window.
+	captureCallback func(button string)
+	captureHeld     map[string]bool
 )
 
 // SetControllerIndex sets which gamepad will be "Player One"
@@ -92,9 +103,12 @@ func UnsetController() {
 	playerOne = nil
 }
 
-// SetStyle sets the controller button style.
-func SetStyle(s Style) {
-	p1style = s
+// PlayerOne returns the GUID and name of the Player One controller.
+func PlayerOne() (guid, name string, ok bool) {
+	if playerOne == nil {
+		return "", "", false
+	}
+	return p1id, p1name, true
 }
 
 // SetMode sets the controller mode.
@@ -102,48 +116,60 @@ func SetMode(m Mode) {
 	p1mode = m
 }
 
-// PrimaryButton returns whether the A or X button is pressed.
-func PrimaryButton(ctrl event.GameController) bool {
-	if p1style == NStyle {
-		return ctrl.ButtonB() || ctrl.ButtonY()
-	}
-	return ctrl.ButtonA() || ctrl.ButtonX()
+// Capture the next button that is pressed on the Player One controller. The
+// callback receives its name, like "A", or a blank string if the Escape key
+// was pressed to cancel.
+func Capture(callback func(button string)) {
+	captureCallback = callback
+	captureHeld = nil
 }
 
-// SecondaryButton returns whether the B or Y button is pressed.
-func SecondaryButton(ctrl event.GameController) bool {
-	if p1style == NStyle {
-		return ctrl.ButtonA() || ctrl.ButtonX()
-	}
-	return ctrl.ButtonB() || ctrl.ButtonY()
+// Capturing returns whether a button capture is in progress.
+func Capturing() bool {
+	return captureCallback != nil
 }
 
 // Loop hooks the render events on each game tick.
 func Loop(ev *event.State) {
-	// If we don't have a controller registered, watch out for one until we do.
-	if playerOne == nil {
-		if len(ev.Controllers) > 0 {
-			for idx, ctrl := range ev.Controllers {
-				SetControllerIndex(idx)
-				log.Info("Gamepad: using controller #%d (%s) as Player 1", idx, ctrl.Name())
-				break
-			}
-		} else {
-			return
+	// Watch for a controller to be Player One: the first one connected, or
+	// whichever other controller has a button pressed (except while one is
+	// being remapped).
+	for idx, ctrl := range ev.Controllers {
+		if playerOne != nil && (idx == *playerOne || captureCallback != nil || !anyButtonDown(ctrl, Buttons)) {
+			continue
 		}
+
+		releaseAll(ev)
+		SetControllerIndex(idx)
+		log.Info("Gamepad: using controller #%d (%s) as Player 1", idx, ctrl.Name())
+		break
+	}
+	if playerOne == nil {
+		return
 	}
 
 	// Get our SDL2 controller.
 	ctrl, ok := ev.GetController(*playerOne)
 	if !ok {
-		log.Error("gamepad: controller #%d has gone away! Detaching as Player 1", playerOne)
+		log.Error("gamepad: controller #%d has gone away! Detaching as Player 1", *playerOne)
+		releaseAll(ev)
 		playerOne = nil
 		return
 	}
+	p1id = ControllerID(*playerOne, ctrl)
+	p1name = ctrl.Name()
 
-	// Right Shoulder = toggle controller mode, handle this first.
-	if ctrl.ButtonR1() {
-		if !rightShoulderLast {
+	var profile = usercfg.Current.GetGamepad(p1id)
+
+	// Remapping a button in the Gamepad window?
+	if captureCallback != nil {
+		captureLoop(ev, ctrl)
+		return
+	}
+
+	// Toggle controller mode, handle this first.
+	if anyButtonDown(ctrl, GetButtons(profile, ActionToggleMode)) {
+		if !actionsHeld[ActionToggleMode] && !settleButtons {
 			if SceneName == "Play" {
 				// Toggle between GameplayMode and MouseMode.
 				if p1mode == GameplayMode {
@@ -155,115 +181,53 @@ func Loop(ev *event.State) {
 				}
 
 				// Reset all button states.
-				ev.Left = false
-				ev.Right = false
-				ev.Up = false
-				ev.Down = false
-				ev.Enter = false
-				ev.Space = false
+				releaseAll(ev)
 			}
 
-			rightShoulderLast = true
+			actionsHeld[ActionToggleMode] = true
 			return
 		}
-	} else if rightShoulderLast {
-		rightShoulderLast = false
+		actionsHeld[ActionToggleMode] = true
+	} else {
+		actionsHeld[ActionToggleMode] = false
 	}
 
-	// If we are in Play Mode, translate gamepad events into key events.
-	if p1mode == GameplayMode {
-		// D-Pad controls to move the player character.
-		if ctrl.ButtonLeft() {
-			ev.Left = true
-			dpLeftLast = true
-		} else if dpLeftLast {
-			ev.Left = false
-			dpLeftLast = false
+	// Translate the buttons of this mode into key and mouse events.
+	for _, action := range Actions {
+		if action.Mode != p1mode {
+			continue
 		}
 
-		if ctrl.ButtonRight() {
-			ev.Right = true
-			dpRightLast = true
-		} else if dpRightLast {
-			ev.Right = false
-			dpRightLast = false
-		}
-
-		// Antigravity on? Up/Down arrow emulation.
-		if PlayModeAntigravity {
-			if ctrl.ButtonUp() {
-				ev.Up = true
-				dpUpLast = true
-			} else if dpUpLast {
-				ev.Up = false
-				dpUpLast = false
-			}
+		var down = anyButtonDown(ctrl, GetButtons(profile, action.Name))
 
-			if ctrl.ButtonDown() {
-				ev.Down = true
-				dpDownLast = true
-			} else if dpDownLast {
-				ev.Down = false
-				dpDownLast = false
-			}
+		// Up/Down controls only with antigravity.
+		if (action.Name == ActionUp || action.Name == ActionDown) && !PlayModeAntigravity {
+			down = false
 		}
 
-		// "Use" button.
-		if PrimaryButton(ctrl) {
-			ev.Space = true
-			ev.Enter = true // to click thru modals
-			leftClickLast = true
-		} else if leftClickLast {
-			ev.Space = false
-			ev.Enter = false
-			leftClickLast = false
+		if down && !actionsHeld[action.Name] {
+			if !settleButtons {
+				action.apply(ev, true)
+			}
+			actionsHeld[action.Name] = true
+		} else if !down && actionsHeld[action.Name] {
+			action.apply(ev, false)
+			actionsHeld[action.Name] = false
 		}
+	}
+	settleButtons = false
 
-		// Jump button.
-		if SecondaryButton(ctrl) {
-			ev.Up = true
-			rightClickLast = true
-		} else if rightClickLast {
-			ev.Up = false
-			rightClickLast = false
-		}
+	var deadZone = DeadZone(profile)
 
-		// Left control stick to move the player character.
+	// If we are in Play Mode, the left control stick moves the player character.
+	if p1mode == GameplayMode {
 		// TODO: analog movements.
 		leftStick := ctrl.LeftStick()
-		if leftStick.X != 0 {
-			if leftStick.X < -balance.GameControllerScrollMin {
-				ev.Left = true
-				ev.Right = false
-			} else if leftStick.X > balance.GameControllerScrollMin {
-				ev.Right = true
-				ev.Left = false
-			} else {
-				ev.Right = false
-				ev.Left = false
-			}
-		} else if leftStickLast.X != 0 {
-			ev.Left = false
-			ev.Right = false
-		}
+		stickKeys(ev, leftStick.X, leftStickLast.X, deadZone, usercfg.ActionLeft, usercfg.ActionRight)
 
 		// Antigravity on?
 		if PlayModeAntigravity {
-			if leftStick.Y != 0 {
-				if leftStick.Y < -balance.GameControllerScrollMin {
-					ev.Up = true
-					ev.Down = false
-				} else if leftStick.Y > balance.GameControllerScrollMin {
-					ev.Down = true
-					ev.Up = false
-				} else {
-					ev.Down = false
-					ev.Up = false
-				}
-			} else if leftStickLast.Y != 0 {
-				ev.Up = false
-				ev.Down = false
-			}
+			stickKeys(ev, leftStick.Y, leftStickLast.Y, deadZone, usercfg.ActionUp, usercfg.ActionDown)
 		}
 
 		leftStickLast = leftStick
@@ -272,8 +236,13 @@ func Loop(ev *event.State) {
 	// If we are emulating a mouse, handle that now.
 	if p1mode == MouseMode {
 		// Move the cursor.
-		leftStick := ctrl.LeftStick()
-		if leftStick.X != 0 || leftStick.Y != 0 {
+		var (
+			leftStick = ctrl.LeftStick()
+			moveX     = applyDeadZone(leftStick.X, deadZone)
+			moveY     = applyDeadZone(leftStick.Y, deadZone)
+			speed     = balance.GameControllerMouseMoveMax * Sensitivity(profile)
+		)
+		if moveX != 0 || moveY != 0 {
 			cursorVisible = true
 		} else if cursorVisible {
 			// If the mouse cursor has moved behind our back (e.g., real mouse moved), turn off
@@ -283,8 +252,8 @@ func Loop(ev *event.State) {
 			}
 		}
 
-		ev.CursorX += int(leftStick.X * balance.GameControllerMouseMoveMax)
-		ev.CursorY += int(leftStick.Y * balance.GameControllerMouseMoveMax)
+		ev.CursorX += int(moveX * speed)
+		ev.CursorY += int(moveY * speed)
 
 		// Constrain the cursor inside window boundaries.
 		w, h := shmem.CurrentRenderEngine.WindowSize()
@@ -302,79 +271,95 @@ func Loop(ev *event.State) {
 		// Store last cursor point so we can detect mouse movement outside the gamepad.
 		cursorLast = render.NewPoint(ev.CursorX, ev.CursorY)
 
-		// Are we clicking?
-		if PrimaryButton(ctrl) {
-			ev.Button1 = true // left-click
-			leftClickLast = true
-		} else if leftClickLast {
-			ev.Button1 = false
-			leftClickLast = false
-		}
+		// Arrow Key emulation on the right control stick, e.g. for Level Editor.
+		rightStick := ctrl.RightStick()
+		stickKeys(ev, rightStick.X, rightStickLast.X, deadZone, usercfg.ActionLeft, usercfg.ActionRight)
+		stickKeys(ev, rightStick.Y, rightStickLast.Y, deadZone, usercfg.ActionUp, usercfg.ActionDown)
+		rightStickLast = rightStick
+	}
+}
 
-		// Right-click
-		if SecondaryButton(ctrl) {
-			ev.Button3 = true // right-click
-			rightClickLast = true
-		} else if rightClickLast {
-			ev.Button3 = false
-			rightClickLast = false
-		}
+// stickKeys emulates the keys of two opposite actions, e.g. Left and Right,
+// by one axis of an analog stick.
+func stickKeys(ev *event.State, value, last, deadZone float64, negative, positive string) {
+	if value != 0 {
+		keybind.Press(ev, negative, value < -deadZone)
+		keybind.Press(ev, positive, value > deadZone)
+	} else if last != 0 {
+		keybind.Press(ev, negative, false)
+		keybind.Press(ev, positive, false)
+	}
+}
 
-		// Middle-click
-		if ctrl.ButtonL1() {
-			ev.Button2 = true // middle click
-			middleClickLast = true
-		} else if middleClickLast {
-			ev.Button2 = false
-			middleClickLast = false
-		}
+// applyDeadZone ignores an analog stick axis inside the dead zone, and scales
+// the rest of its range so the stick still goes from 0 to full speed.
+func applyDeadZone(value, deadZone float64) float64 {
+	if math.Abs(value) <= deadZone || deadZone >= 1 {
+		return 0
+	}
 
-		// Left Trigger = Backspace (close active window)
-		if ctrl.ButtonL2() {
-			if !leftTriggerLast {
-				ev.SetKeyDown(`\b`, true)
-			}
-			leftTriggerLast = true
-		} else if leftTriggerLast {
-			ev.SetKeyDown(`\b`, false)
-			leftTriggerLast = false
-		}
+	var scaled = (math.Abs(value) - deadZone) / (1 - deadZone)
+	if value < 0 {
+		return -scaled
+	}
+	return scaled
+}
 
-		// Arrow Key emulation on the right control stick, e.g. for Level Editor.
-		rightStick := ctrl.RightStick()
-		if rightStick.X != 0 {
-			if rightStick.X < -balance.GameControllerScrollMin {
-				ev.Left = true
-				ev.Right = false
-			} else if rightStick.X > balance.GameControllerScrollMin {
-				ev.Right = true
-				ev.Left = false
-			} else {
-				ev.Right = false
-				ev.Left = false
-			}
-		} else if rightStickLast.X != 0 {
-			ev.Left = false
-			ev.Right = false
+// releaseAll releases the keys and mouse buttons of all held actions, e.g.
+// when switching modes or controllers.
+func releaseAll(ev *event.State) {
+	for _, action := range Actions {
+		if actionsHeld[action.Name] {
+			action.apply(ev, false)
 		}
+	}
+	actionsHeld = map[string]bool{}
+
+	ev.Left = false
+	ev.Right = false
+	ev.Up = false
+	ev.Down = false
+	ev.Enter = false
+	ev.Space = false
+	leftStickLast = event.Vector{}
+	rightStickLast = event.Vector{}
+}
 
-		if rightStick.Y != 0 {
-			if rightStick.Y < -balance.GameControllerScrollMin {
-				ev.Up = true
-				ev.Down = false
-			} else if rightStick.Y > balance.GameControllerScrollMin {
-				ev.Down = true
-				ev.Up = false
-			} else {
-				ev.Down = false
-				ev.Up = false
-			}
-		} else if rightStickLast.Y != 0 {
-			ev.Up = false
-			ev.Down = false
+// captureLoop takes the next button pressed while remapping.
+func captureLoop(ev *event.State, ctrl event.GameController) {
+	var finish = func(button string) {
+		var callback = captureCallback
+		captureCallback = nil
+		captureHeld = nil
+		settleButtons = true
+		callback(button)
+	}
+
+	// Escape key to cancel.
+	if ev.Escape {
+		ev.Escape = false
+		finish("")
+		return
+	}
+
+	// On the first tick, note the buttons that are already held down (e.g.
+	// the one that clicked the remap button) so they aren't captured.
+	if captureHeld == nil {
+		releaseAll(ev)
+		captureHeld = map[string]bool{}
+		for _, button := range Buttons {
+			captureHeld[button] = ButtonDown(ctrl, button)
 		}
+		return
+	}
 
-		rightStickLast = rightStick
+	for _, button := range Buttons {
+		down := ButtonDown(ctrl, button)
+		if down && !captureHeld[button] {
+			finish(button)
+			return
+		}
+		captureHeld[button] = down
 	}
 }
 
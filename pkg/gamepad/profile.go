@@ -0,0 +1,279 @@
+package gamepad
+
+import (
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/keybind"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/native"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/usercfg"
+	"git.kirsle.net/go/render/event"
+)
+
+// Names of the controller buttons, as stored in a usercfg.GamepadProfile.
+const (
+	ButtonA     = "A"
+	ButtonB     = "B"
+	ButtonX     = "X"
+	ButtonY     = "Y"
+	ButtonL1    = "L1"
+	ButtonL2    = "L2"
+	ButtonR1    = "R1"
+	ButtonUp    = "D-Up"
+	ButtonDown  = "D-Down"
+	ButtonLeft  = "D-Left"
+	ButtonRight = "D-Right"
+)
+
+// Buttons are all of the controller buttons which may be mapped: the ones
+// that every event.GameController has.
+var Buttons = []string{
+	ButtonA, ButtonB, ButtonX, ButtonY,
+	ButtonL1, ButtonL2, ButtonR1,
+	ButtonUp, ButtonDown, ButtonLeft, ButtonRight,
+}
+
+// Names of the gamepad actions.
+const (
+	// Gameplay Mode.
+	ActionUse       = "Use"
+	ActionJump      = "Jump"
+	ActionLeft      = "Left"
+	ActionRight     = "Right"
+	ActionUp        = "Up"
+	ActionDown      = "Down"
+	ActionInventory = "Inventory"
+	ActionEditLevel = "EditLevel"
+
+	// Mouse Mode.
+	ActionLeftClick   = "LeftClick"
+	ActionRightClick  = "RightClick"
+	ActionMiddleClick = "MiddleClick"
+	ActionCloseWindow = "CloseWindow"
+
+	// Any mode.
+	ActionToggleMode = "ToggleMode"
+)
+
+// Action describes a gamepad action which may be mapped to buttons.
+type Action struct {
+	Name     string
+	Label    string   // for the Gamepad window
+	Mode     Mode     // the mode where it's active, or AnyMode
+	Key      string   // the usercfg.Keymap action to press, if any
+	Defaults []string // default buttons for the X Style
+}
+
+// Actions are all of the gamepad actions, in the order they're shown in the
+// Gamepad window.
+var Actions = []Action{
+	{ActionUse, "Activate", GameplayMode, usercfg.ActionUse, []string{ButtonA, ButtonX}},
+	{ActionJump, "Jump", GameplayMode, usercfg.ActionUp, []string{ButtonB, ButtonY}},
+	{ActionLeft, "Move left", GameplayMode, usercfg.ActionLeft, []string{ButtonLeft}},
+	{ActionRight, "Move right", GameplayMode, usercfg.ActionRight, []string{ButtonRight}},
+	{ActionUp, "Up (antigravity)", GameplayMode, usercfg.ActionUp, []string{ButtonUp}},
+	{ActionDown, "Down (antigravity)", GameplayMode, usercfg.ActionDown, []string{ButtonDown}},
+	{ActionInventory, "Show/hide items", GameplayMode, usercfg.ActionInventory, []string{ButtonL2}},
+	{ActionEditLevel, "Edit level", GameplayMode, usercfg.ActionGotoEdit, []string{}},
+
+	{ActionLeftClick, "Left-click", MouseMode, "", []string{ButtonA, ButtonX}},
+	{ActionRightClick, "Right-click", MouseMode, "", []string{ButtonB, ButtonY}},
+	{ActionMiddleClick, "Middle-click", MouseMode, "", []string{ButtonL1}},
+	{ActionCloseWindow, "Close window", MouseMode, usercfg.ActionCloseTopmostWindow, []string{ButtonL2}},
+
+	{ActionToggleMode, "Mouse/Gameplay mode", AnyMode, "", []string{ButtonR1}},
+}
+
+// GetAction looks up a gamepad action by name.
+func GetAction(name string) (Action, bool) {
+	for _, action := range Actions {
+		if action.Name == name {
+			return action, true
+		}
+	}
+	return Action{}, false
+}
+
+// apply presses or releases the action's emulated mouse button or key.
+func (a Action) apply(ev *event.State, down bool) {
+	switch a.Name {
+	case ActionLeftClick:
+		ev.Button1 = down
+	case ActionRightClick:
+		ev.Button3 = down
+	case ActionMiddleClick:
+		ev.Button2 = down
+	case ActionUse:
+		ev.Enter = down // to click thru modals
+	}
+
+	if a.Key != "" {
+		keybind.Press(ev, a.Key, down)
+	}
+}
+
+// DefaultButtons returns the default buttons of an action for the button
+// style: the N Style swaps the A/B and X/Y buttons.
+func DefaultButtons(style Style, action string) []string {
+	a, ok := GetAction(action)
+	if !ok {
+		return nil
+	}
+
+	if style != NStyle {
+		return a.Defaults
+	}
+
+	var (
+		swap = map[string]string{
+			ButtonA: ButtonB,
+			ButtonB: ButtonA,
+			ButtonX: ButtonY,
+			ButtonY: ButtonX,
+		}
+		result = []string{}
+	)
+	for _, button := range a.Defaults {
+		if other, ok := swap[button]; ok {
+			button = other
+		}
+		result = append(result, button)
+	}
+	return result
+}
+
+// GetButtons returns the buttons mapped to an action: the profile's custom
+// buttons, or the defaults for its style.
+func GetButtons(profile *usercfg.GamepadProfile, action string) []string {
+	if buttons, ok := profile.Buttons[action]; ok {
+		return buttons
+	}
+	return DefaultButtons(Style(profile.Style), action)
+}
+
+// SetButtons maps buttons to an action. Setting an action back to its
+// default buttons removes it from the custom mapping.
+func SetButtons(profile *usercfg.GamepadProfile, action string, buttons []string) {
+	if profile.Buttons == nil {
+		profile.Buttons = map[string][]string{}
+	}
+
+	if sameButtons(DefaultButtons(Style(profile.Style), action), buttons) {
+		delete(profile.Buttons, action)
+		return
+	}
+	profile.Buttons[action] = buttons
+}
+
+// AddButton maps another button to an action, keeping its existing buttons.
+func AddButton(profile *usercfg.GamepadProfile, action, button string) {
+	var buttons = append([]string{}, GetButtons(profile, action)...)
+	for _, existing := range buttons {
+		if existing == button {
+			return
+		}
+	}
+	SetButtons(profile, action, append(buttons, button))
+}
+
+// Conflicts returns the names of the other actions that a button is
+// already mapped to, which are active in the same mode as this action.
+func Conflicts(profile *usercfg.GamepadProfile, action, button string) []string {
+	var (
+		result = []string{}
+		mode   = AnyMode
+	)
+	if a, ok := GetAction(action); ok {
+		mode = a.Mode
+	}
+
+	for _, other := range Actions {
+		if other.Name == action || (other.Mode != mode && other.Mode != AnyMode && mode != AnyMode) {
+			continue
+		}
+		for _, existing := range GetButtons(profile, other.Name) {
+			if existing == button {
+				result = append(result, other.Name)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// DeadZone returns the analog stick dead zone of a profile: stick movements
+// smaller than this are ignored.
+func DeadZone(profile *usercfg.GamepadProfile) float64 {
+	if profile.DeadZone > 0 {
+		return profile.DeadZone
+	}
+	return balance.GameControllerScrollMin
+}
+
+// Sensitivity returns the mouse cursor speed multiplier of a profile.
+func Sensitivity(profile *usercfg.GamepadProfile) float64 {
+	if profile.Sensitivity > 0 {
+		return profile.Sensitivity
+	}
+	return 1
+}
+
+// ControllerID returns the GUID of a game controller by its device index,
+// which keys its profile in the user settings. Where SDL2 can't tell the GUID
+// (e.g. on WASM) the controller is identified by its name instead.
+func ControllerID(index int, ctrl event.GameController) string {
+	if guid := native.ControllerGUID(index); guid != "" {
+		return guid
+	}
+	return ctrl.Name()
+}
+
+// ButtonDown checks whether a controller button, by name, is held down.
+func ButtonDown(ctrl event.GameController, button string) bool {
+	switch button {
+	case ButtonA:
+		return ctrl.ButtonA()
+	case ButtonB:
+		return ctrl.ButtonB()
+	case ButtonX:
+		return ctrl.ButtonX()
+	case ButtonY:
+		return ctrl.ButtonY()
+	case ButtonL1:
+		return ctrl.ButtonL1()
+	case ButtonL2:
+		return ctrl.ButtonL2()
+	case ButtonR1:
+		return ctrl.ButtonR1()
+	case ButtonUp:
+		return ctrl.ButtonUp()
+	case ButtonDown:
+		return ctrl.ButtonDown()
+	case ButtonLeft:
+		return ctrl.ButtonLeft()
+	case ButtonRight:
+		return ctrl.ButtonRight()
+	}
+	return false
+}
+
+// anyButtonDown checks whether any of the buttons are held down.
+func anyButtonDown(ctrl event.GameController, buttons []string) bool {
+	for _, button := range buttons {
+		if ButtonDown(ctrl, button) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameButtons compares two lists of buttons.
+func sameButtons(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package gamepad
+
+import (
+	"testing"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/usercfg"
+)
+
+func TestDefaultButtons(t *testing.T) {
+	tests := []struct {
+		style  Style
+		action string
+		expect []string
+	}{
+		{XStyle, ActionUse, []string{ButtonA, ButtonX}},
+		{NStyle, ActionUse, []string{ButtonB, ButtonY}},
+		{NStyle, ActionJump, []string{ButtonA, ButtonX}},
+		{NStyle, ActionMiddleClick, []string{ButtonL1}},
+		{XStyle, ActionEditLevel, []string{}},
+	}
+	for i, test := range tests {
+		if actual := DefaultButtons(test.style, test.action); !sameButtons(actual, test.expect) {
+			t.Errorf("Test %d: %s buttons: expected %+v but got %+v", i, test.action, test.expect, actual)
+		}
+	}
+}
+
+func TestProfileButtons(t *testing.T) {
+	var profile = &usercfg.GamepadProfile{
+		Style: int(NStyle),
+	}
+
+	// Remap Jump and add a second button.
+	SetButtons(profile, ActionJump, []string{ButtonUp})
+	AddButton(profile, ActionJump, ButtonL1)
+	if buttons := GetButtons(profile, ActionJump); !sameButtons(buttons, []string{ButtonUp, ButtonL1}) {
+		t.Errorf("unexpected custom Jump buttons: %+v", buttons)
+	}
+
+	// Conflicts: Gameplay and Mouse Mode may share a button, but the mode
+	// toggle conflicts with both.
+	if c := Conflicts(profile, ActionUse, ButtonL1); len(c) != 1 || c[0] != ActionJump {
+		t.Errorf("expected L1 to conflict with Jump, got %+v", c)
+	}
+	if c := Conflicts(profile, ActionLeftClick, ButtonUp); len(c) != 0 {
+		t.Errorf("Gameplay and Mouse Mode may share a button, got conflicts %+v", c)
+	}
+	if c := Conflicts(profile, ActionInventory, ButtonR1); len(c) != 1 || c[0] != ActionToggleMode {
+		t.Errorf("expected R1 to conflict with ToggleMode, got %+v", c)
+	}
+
+	// Setting the defaults again removes the custom mapping.
+	SetButtons(profile, ActionJump, []string{ButtonA, ButtonX})
+	if _, ok := profile.Buttons[ActionJump]; ok {
+		t.Errorf("Jump should have been reset to default")
+	}
+}
+
+func TestApplyDeadZone(t *testing.T) {
+	tests := []struct {
+		value    float64
+		deadZone float64
+		expect   float64
+	}{
+		{0.2, 0.3, 0},
+		{-0.3, 0.3, 0},
+		{1, 0.25, 1},
+		{-1, 0.5, -1},
+		{0.75, 0.5, 0.5},
+		{0.5, 0, 0.5},
+	}
+	for i, test := range tests {
+		if actual := applyDeadZone(test.value, test.deadZone); actual != test.expect {
+			t.Errorf("Test %d: applyDeadZone(%f, %f): expected %f but got %f",
+				i, test.value, test.deadZone, test.expect, actual,
+			)
+		}
+	}
+}
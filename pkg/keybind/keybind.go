@@ -1,13 +1,16 @@
 // Package keybind centralizes the global hotkey bindings.
 //
 // Whenever the app would need to query a hotkey like "F3" or "Ctrl-Z"
-// is held down, it should use a method in this file. It can be
-// expanded later to allow user customizable bindings or something.
-//
-// NOTE: arrow key and gameplay controls not yet ported to here.
+// is held down, it should use a method in this file. The keys for each
+// action come from the user's keymap (usercfg.Keymap) which may be
+// customized in the Settings window; the keys named in the comments
+// below are the defaults.
 package keybind
 
-import "git.kirsle.net/go/render/event"
+import (
+	"git.kirsle.net/SketchyMaze/doodle/pkg/usercfg"
+	"git.kirsle.net/go/render/event"
+)
 
 // State returns a version of event.State which is domain specific
 // to what the game actually cares about.
@@ -85,158 +88,133 @@ func FromEvent(ev *event.State) State {
 
 // Shutdown (Escape) signals the game to start closing down.
 func Shutdown(ev *event.State) bool {
-	result := ev.Escape
-	ev.Escape = false
-	return result
+	return once(ev, usercfg.ActionShutdown)
 }
 
 // Help (F1) can be checked one time.
 func Help(ev *event.State) bool {
-	result := ev.KeyDown("F1")
-	ev.SetKeyDown("F1", false)
-	return result
+	return once(ev, usercfg.ActionHelp)
 }
 
 // DebugOverlay (F3) can be checked one time.
 func DebugOverlay(ev *event.State) bool {
-	result := ev.KeyDown("F3")
-	ev.SetKeyDown("F3", false)
-	return result
+	return once(ev, usercfg.ActionDebugOverlay)
 }
 
 // DebugCollision (F4) can be checked one time.
 func DebugCollision(ev *event.State) bool {
-	result := ev.KeyDown("F4")
-	ev.SetKeyDown("F4", false)
-	return result
+	return once(ev, usercfg.ActionDebugCollision)
 }
 
 // CloseTopmostWindow (Backspace)
 func CloseTopmostWindow(ev *event.State) bool {
-	result := ev.KeyDown(`\b`)
-	ev.SetKeyDown(`\b`, false)
-	return result
+	return once(ev, usercfg.ActionCloseTopmostWindow)
 }
 
 // CloseAllWindows (Shift+Backspace)
 func CloseAllWindows(ev *event.State) bool {
-	result := ev.KeyDown(`\b`) && ev.Shift
-	if result {
-		ev.SetKeyDown(`\b`, false)
-	}
-	return result
+	return once(ev, usercfg.ActionCloseAllWindows)
 }
 
 // NewViewport (V)
 func NewViewport(ev *event.State) bool {
-	result := ev.KeyDown("v")
-	if result {
-		ev.SetKeyDown("v", false)
-	}
-	return result
+	return once(ev, usercfg.ActionNewViewport)
 }
 
 // Undo (Ctrl-Z)
 func Undo(ev *event.State) bool {
-	return ev.Ctrl && ev.KeyDown("z")
+	return pressed(ev, usercfg.ActionUndo)
 }
 
 // Redo (Ctrl-Y)
 func Redo(ev *event.State) bool {
-	return ev.Ctrl && ev.KeyDown("y")
+	return pressed(ev, usercfg.ActionRedo)
 }
 
 // New Level (Ctrl-N)
 func NewLevel(ev *event.State) bool {
-	return ev.Ctrl && ev.KeyDown("n")
+	return pressed(ev, usercfg.ActionNewLevel)
 }
 
 // Save (Ctrl-S)
 func Save(ev *event.State) bool {
-	var result = ev.Ctrl && ev.KeyDown("s")
-	if result {
-		ev.SetKeyDown("s", false)
-	}
-	return result
+	return once(ev, usercfg.ActionSave)
 }
 
 // SaveAs (Shift-Ctrl-S)
 func SaveAs(ev *event.State) bool {
-	return ev.Ctrl && ev.Shift && ev.KeyDown("s")
+	return pressed(ev, usercfg.ActionSaveAs)
 }
 
 // Open (Ctrl-O)
 func Open(ev *event.State) bool {
-	return ev.Ctrl && ev.KeyDown("o")
+	return pressed(ev, usercfg.ActionOpen)
 }
 
 // ZoomIn (+)
 func ZoomIn(ev *event.State) bool {
-	return ev.KeyDown("=") || ev.KeyDown("+")
+	return pressed(ev, usercfg.ActionZoomIn)
 }
 
 // ZoomOut (-)
 func ZoomOut(ev *event.State) bool {
-	return ev.KeyDown("-")
+	return pressed(ev, usercfg.ActionZoomOut)
 }
 
 // ZoomReset (1)
 func ZoomReset(ev *event.State) bool {
-	return ev.KeyDown("1")
+	return pressed(ev, usercfg.ActionZoomReset)
 }
 
 // Origin (0) -- scrolls the canvas back to 0,0 in Editor Mode.
 func Origin(ev *event.State) bool {
-	return ev.KeyDown("0")
+	return pressed(ev, usercfg.ActionOrigin)
 }
 
 // GotoPlay (P) play tests the current level in the editor.
 func GotoPlay(ev *event.State) bool {
-	return ev.KeyDown("p")
+	return pressed(ev, usercfg.ActionGotoPlay)
 }
 
 // GotoEdit (E) opens the current played level in Edit Mode, if the
 // player has come from the editor originally.
 func GotoEdit(ev *event.State) bool {
-	return ev.KeyDown("e")
+	return pressed(ev, usercfg.ActionGotoEdit)
 }
 
 // LineTool (L) selects the Line Tool in the editor.
 func LineTool(ev *event.State) bool {
-	return ev.KeyDown("l")
+	return pressed(ev, usercfg.ActionLineTool)
 }
 
 // PencilTool (F) selects the freehand pencil tool in the editor.
-// GotoPlay (P) play tests the current level in the editor.
 func PencilTool(ev *event.State) bool {
-	return ev.KeyDown("f")
+	return pressed(ev, usercfg.ActionPencilTool)
 }
 
 // RectTool (R) selects the rectangle in the editor.
 func RectTool(ev *event.State) bool {
-	return ev.KeyDown("r")
+	return pressed(ev, usercfg.ActionRectTool)
 }
 
 // EllipseTool (C) selects this tool in the editor.
 func EllipseTool(ev *event.State) bool {
-	return ev.KeyDown("c")
+	return pressed(ev, usercfg.ActionEllipseTool)
 }
 
 // EraserTool (X) selects this tool in the editor.
 func EraserTool(ev *event.State) bool {
-	return ev.KeyDown("x")
+	return pressed(ev, usercfg.ActionEraserTool)
 }
 
-// DoodadDropper (D) opens the doodad dropper in the editor.
+// DoodadDropper (Q) opens the doodad dropper in the editor.
 func DoodadDropper(ev *event.State) bool {
-	return ev.KeyDown("q")
+	return pressed(ev, usercfg.ActionDoodadDropper)
 }
 
 // ShellKey (`) opens the developer console.
 func ShellKey(ev *event.State) bool {
-	v := ev.KeyDown("`")
-	ev.SetKeyDown("`", false)
-	return v
+	return once(ev, usercfg.ActionShellKey)
 }
 
 // Enter key.
@@ -251,29 +229,44 @@ func Shift(ev *event.State) bool {
 	return ev.Shift
 }
 
-// Left arrow.
+// Left arrow (or A).
 func Left(ev *event.State) bool {
-	return ev.Left || ev.KeyDown("a")
+	return pressed(ev, usercfg.ActionLeft)
 }
 
-// Right arrow.
+// Right arrow (or D).
 func Right(ev *event.State) bool {
-	return ev.Right || ev.KeyDown("d")
+	return pressed(ev, usercfg.ActionRight)
 }
 
-// Up arrow.
+// Up arrow (or W).
 func Up(ev *event.State) bool {
-	return ev.Up || ev.KeyDown("w")
+	return pressed(ev, usercfg.ActionUp)
 }
 
-// Down arrow.
+// Down arrow (or S).
 func Down(ev *event.State) bool {
-	return ev.Down || ev.KeyDown("s")
+	return pressed(ev, usercfg.ActionDown)
 }
 
-// "Use" button.
+// "Use" button (Space or Q).
 func Use(ev *event.State) bool {
-	return ev.Space || ev.KeyDown("q")
+	return pressed(ev, usercfg.ActionUse)
+}
+
+// Inventory (I) shows or hides the items in Play Mode.
+func Inventory(ev *event.State) bool {
+	return once(ev, usercfg.ActionInventory)
+}
+
+// Quicksave (F5) saves the state of the level in Play Mode.
+func Quicksave(ev *event.State) bool {
+	return once(ev, usercfg.ActionQuicksave)
+}
+
+// Quickload (F9) loads the quicksave of the level in Play Mode.
+func Quickload(ev *event.State) bool {
+	return once(ev, usercfg.ActionQuickload)
 }
 
 // LeftClick of the primary mouse button.
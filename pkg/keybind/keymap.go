@@ -0,0 +1,188 @@
+package keybind
+
+import (
+	"git.kirsle.net/SketchyMaze/doodle/pkg/usercfg"
+	"git.kirsle.net/go/render/event"
+)
+
+// pressed checks whether any of the keys bound to an action are held down.
+func pressed(ev *event.State, action string) bool {
+	_, ok := match(ev, action)
+	return ok
+}
+
+// once checks an action that should only be handled one time per key
+// press: the key is released when it matches.
+func once(ev *event.State, action string) bool {
+	if binding, ok := match(ev, action); ok {
+		release(ev, binding)
+		return true
+	}
+	return false
+}
+
+// match returns the first key bound to the action which is held down.
+//
+// A binding matches when its modifier keys are held along with its key, and
+// other modifiers may be held too: so "S" matches while Ctrl-S is pressed.
+func match(ev *event.State, action string) (usercfg.Binding, bool) {
+	for _, key := range usercfg.Current.Keymap.Get(action) {
+		binding, err := usercfg.ParseBinding(key)
+		if err != nil {
+			continue
+		}
+
+		if (binding.Ctrl && !ev.Ctrl) || (binding.Shift && !ev.Shift) {
+			continue
+		}
+
+		if isDown(ev, binding.Key) {
+			return binding, true
+		}
+	}
+	return usercfg.Binding{}, false
+}
+
+// isDown checks whether a key, by its usercfg.Binding name, is held down.
+func isDown(ev *event.State, key string) bool {
+	switch key {
+	case "Escape":
+		return ev.Escape
+	case "Enter":
+		return ev.Enter
+	case "Space":
+		return ev.Space
+	case "Up":
+		return ev.Up
+	case "Down":
+		return ev.Down
+	case "Left":
+		return ev.Left
+	case "Right":
+		return ev.Right
+	case "Backspace":
+		return ev.KeyDown(`\b`)
+	}
+	return ev.KeyDown(key)
+}
+
+// release sets a key as no longer held down.
+func release(ev *event.State, binding usercfg.Binding) {
+	setDown(ev, binding.Key, false)
+}
+
+// setDown sets whether a key, by its usercfg.Binding name, is held down.
+func setDown(ev *event.State, key string, down bool) {
+	switch key {
+	case "Escape":
+		ev.Escape = down
+	case "Enter":
+		ev.Enter = down
+	case "Space":
+		ev.Space = down
+	case "Up":
+		ev.Up = down
+	case "Down":
+		ev.Down = down
+	case "Left":
+		ev.Left = down
+	case "Right":
+		ev.Right = down
+	case "Backspace":
+		ev.SetKeyDown(`\b`, down)
+	default:
+		ev.SetKeyDown(key, down)
+	}
+}
+
+// Press holds down (or releases) the first key bound to an action which
+// needs no modifier keys, so that other input devices like the game
+// controller (pkg/gamepad) can trigger the action.
+func Press(ev *event.State, action string, down bool) {
+	for _, key := range usercfg.Current.Keymap.Get(action) {
+		if binding, err := usercfg.ParseBinding(key); err == nil && !binding.Ctrl && !binding.Shift {
+			setDown(ev, binding.Key, down)
+			return
+		}
+	}
+}
+
+// Shortcut returns the first key bound to an action, for display in the
+// menus, e.g. "Ctrl-Z"
+func Shortcut(action string) string {
+	if keys := usercfg.Current.Keymap.Get(action); len(keys) > 0 {
+		return keys[0]
+	}
+	return ""
+}
+
+// Key capture for rebinding an action in the Settings window.
+var captureCallback func(key string)
+
+// Capture the next key that is pressed. The callback receives its binding,
+// like "Ctrl-Z", or a blank string if Escape was pressed to cancel.
+func Capture(callback func(key string)) {
+	captureCallback = callback
+}
+
+// Capturing returns whether a key capture is in progress.
+func Capturing() bool {
+	return captureCallback != nil
+}
+
+// CaptureLoop is called by the main loop each tick, before any hotkeys are
+// checked. While a key capture is in progress it takes the next key that
+// is pressed, so that it doesn't also trigger its current action.
+func CaptureLoop(ev *event.State) {
+	if captureCallback == nil {
+		return
+	}
+
+	var key string
+	switch {
+	case ev.Escape:
+		key = ""
+	case ev.Enter:
+		key = "Enter"
+	case ev.Space:
+		key = "Space"
+	case ev.Up:
+		key = "Up"
+	case ev.Down:
+		key = "Down"
+	case ev.Left:
+		key = "Left"
+	case ev.Right:
+		key = "Right"
+	default:
+		// Any other key, which isn't a modifier key on its own.
+		for _, name := range ev.KeysDown(false) {
+			if name == `\b` {
+				name = "Backspace"
+			}
+			if binding, err := usercfg.ParseBinding(name); err == nil {
+				binding.Ctrl = ev.Ctrl
+				binding.Shift = ev.Shift
+				key = binding.String()
+				break
+			}
+		}
+		if key == "" {
+			return
+		}
+	}
+
+	// Consume the key press.
+	ev.Escape = false
+	ev.Enter = false
+	ev.Space = false
+	ev.Up = false
+	ev.Down = false
+	ev.Left = false
+	ev.Right = false
+	ev.ResetKeyDown()
+
+	var callback = captureCallback
+	captureCallback = nil
+	callback(key)
+}
@@ -0,0 +1,72 @@
+package level
+
+import (
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/go/render"
+)
+
+// Behavior selects which swatch attributes are highlighted by the editor's
+// behavior view, which recolors the level by what its pixels do rather than
+// how they look.
+type Behavior int
+
+// Behavior view modes.
+const (
+	NoBehavior        Behavior = iota // normal rendering
+	AllBehaviors                      // every attribute in its own color
+	SolidBehavior                     // show all solid
+	SemiSolidBehavior                 // show all semi-solid
+	FireBehavior                      // show all fire
+	WaterBehavior                     // show all water
+	SlipperyBehavior                  // show all slippery
+)
+
+// Behaviors lists the view modes, e.g. for the editor's View menu.
+var Behaviors = []Behavior{
+	NoBehavior,
+	AllBehaviors,
+	SolidBehavior,
+	SemiSolidBehavior,
+	FireBehavior,
+	WaterBehavior,
+	SlipperyBehavior,
+}
+
+var behaviorNames = []string{
+	"Off",
+	"All",
+	"Solid",
+	"Semi-Solid",
+	"Fire",
+	"Water",
+	"Slippery",
+}
+
+func (b Behavior) String() string {
+	return behaviorNames[b]
+}
+
+// Color returns the color a swatch is drawn with in this behavior view.
+// Swatches without a matching attribute get the muted color.
+//
+// In the AllBehaviors view a swatch with several attributes is drawn by the
+// most specific one: fire, water, slippery, semi-solid and then solid.
+func (b Behavior) Color(sw *Swatch) render.Color {
+	var matches = func(view Behavior, attr bool) bool {
+		return attr && (b == view || b == AllBehaviors)
+	}
+
+	switch {
+	case matches(FireBehavior, sw.Fire):
+		return balance.BehaviorFireColor
+	case matches(WaterBehavior, sw.Water):
+		return balance.BehaviorWaterColor
+	case matches(SlipperyBehavior, sw.Slippery):
+		return balance.BehaviorSlipperyColor
+	case matches(SemiSolidBehavior, sw.SemiSolid):
+		return balance.BehaviorSemiSolidColor
+	case matches(SolidBehavior, sw.Solid):
+		return balance.BehaviorSolidColor
+	}
+	return balance.BehaviorMutedColor
+}
@@ -0,0 +1,107 @@
+package level_test
+
+import (
+	"image"
+	"testing"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
+	"git.kirsle.net/go/render"
+)
+
+func TestBehaviorColor(t *testing.T) {
+	var (
+		decoration = &level.Swatch{Name: "decoration", Color: render.Grey}
+		solid      = &level.Swatch{Name: "solid", Color: render.Black, Solid: true}
+		fire       = &level.Swatch{Name: "fire", Color: render.Red, Fire: true}
+		ice        = &level.Swatch{Name: "ice", Color: render.Cyan, Solid: true, Slippery: true}
+	)
+
+	type testCase struct {
+		view   level.Behavior
+		swatch *level.Swatch
+		expect render.Color
+	}
+	tests := []testCase{
+		{level.AllBehaviors, decoration, balance.BehaviorMutedColor},
+		{level.AllBehaviors, solid, balance.BehaviorSolidColor},
+		{level.AllBehaviors, fire, balance.BehaviorFireColor},
+		{level.AllBehaviors, ice, balance.BehaviorSlipperyColor},
+		{level.SolidBehavior, ice, balance.BehaviorSolidColor},
+		{level.SolidBehavior, fire, balance.BehaviorMutedColor},
+		{level.FireBehavior, fire, balance.BehaviorFireColor},
+		{level.WaterBehavior, solid, balance.BehaviorMutedColor},
+	}
+	for i, test := range tests {
+		if actual := test.view.Color(test.swatch); actual != test.expect {
+			t.Errorf("Test %d: %s view of %s: expected %+v but got %+v",
+				i, test.view, test.swatch.Name, test.expect, actual,
+			)
+		}
+	}
+
+	// The behavior bitmap of a chunk.
+	c := level.NewChunker(16)
+	c.Set(render.NewPoint(1, 2), fire)
+	c.Set(render.NewPoint(3, 4), decoration)
+	chunk, _ := c.GetChunk(render.Origin)
+
+	img := chunk.ToBehaviorBitmap(level.FireBehavior)
+	if actual := render.FromColor(img.At(1, 2)); actual != balance.BehaviorFireColor {
+		t.Errorf("expected a fire pixel at 1,2 but got %+v", actual)
+	}
+	if actual := render.FromColor(img.At(3, 4)); actual != balance.BehaviorMutedColor {
+		t.Errorf("expected a muted pixel at 3,4 but got %+v", actual)
+	}
+
+	// The behavior texture is cached until the chunk changes.
+	var engine = &fakeEngine{}
+	var orig = shmem.CurrentRenderEngine
+	shmem.CurrentRenderEngine = engine
+	t.Cleanup(func() { shmem.CurrentRenderEngine = orig })
+
+	first := chunk.TextureBehavior(engine, level.FireBehavior)
+	chunk.TextureBehavior(engine, level.FireBehavior)
+	if engine.stored != 1 {
+		t.Errorf("expected the behavior texture to be cached but it was stored %d times", engine.stored)
+	}
+
+	// A palette change redraws the chunks, which must invalidate the cached
+	// behavior texture too.
+	c.Redraw()
+	decoration.Fire = true
+	second := chunk.TextureBehavior(engine, level.FireBehavior)
+	if engine.stored != 2 || second == first || !first.(*fakeTexture).freed {
+		t.Errorf("expected the behavior texture to be regenerated after the redraw (stored %d times)", engine.stored)
+	}
+	if actual := render.FromColor(second.Image().At(3, 4)); actual != balance.BehaviorFireColor {
+		t.Errorf("expected a fire pixel at 3,4 after the palette change but got %+v", actual)
+	}
+}
+
+// fakeEngine stores textures for the behavior view test.
+type fakeEngine struct {
+	render.Engine
+	stored int
+}
+
+func (e *fakeEngine) StoreTexture(name string, img image.Image) (render.Texturer, error) {
+	e.stored++
+	return &fakeTexture{img: img}, nil
+}
+
+type fakeTexture struct {
+	render.Texturer
+	img   image.Image
+	freed bool
+}
+
+func (t *fakeTexture) Image() image.Image {
+	return t.img
+}
+
+func (t *fakeTexture) Free() error {
+	t.freed = true
+	return nil
+}
@@ -0,0 +1,65 @@
+package level
+
+import "git.kirsle.net/go/render"
+
+// CameraRegion is an area of the level that the camera is kept inside of
+// while the player is within it, Metroidvania style. Regions are placed in
+// the editor with the Camera Region tool.
+type CameraRegion struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// Rect returns the camera region as a render.Rect with width and height.
+func (r CameraRegion) Rect() render.Rect {
+	return render.Rect{X: r.X, Y: r.Y, W: r.W, H: r.H}
+}
+
+// Contains checks whether a world coordinate is inside the region.
+func (r CameraRegion) Contains(p render.Point) bool {
+	return p.X >= r.X && p.X < r.X+r.W && p.Y >= r.Y && p.Y < r.Y+r.H
+}
+
+// CameraRegionAt returns the camera region containing a world coordinate.
+// When regions overlap, the one placed last wins.
+func (l *Level) CameraRegionAt(p render.Point) (CameraRegion, bool) {
+	for i := len(l.CameraRegions) - 1; i >= 0; i-- {
+		if l.CameraRegions[i].Contains(p) {
+			return l.CameraRegions[i], true
+		}
+	}
+	return CameraRegion{}, false
+}
+
+// AddCameraRegion adds a camera region from two corner points dragged out in
+// the editor.
+func (l *Level) AddCameraRegion(a, b render.Point) CameraRegion {
+	var region = CameraRegion{
+		X: a.X,
+		Y: a.Y,
+		W: b.X - a.X,
+		H: b.Y - a.Y,
+	}
+	if region.W < 0 {
+		region.X, region.W = b.X, -region.W
+	}
+	if region.H < 0 {
+		region.Y, region.H = b.Y, -region.H
+	}
+
+	l.CameraRegions = append(l.CameraRegions, region)
+	return region
+}
+
+// RemoveCameraRegionAt removes the topmost camera region at a point.
+func (l *Level) RemoveCameraRegionAt(p render.Point) bool {
+	for i := len(l.CameraRegions) - 1; i >= 0; i-- {
+		if l.CameraRegions[i].Contains(p) {
+			l.CameraRegions = append(l.CameraRegions[:i], l.CameraRegions[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
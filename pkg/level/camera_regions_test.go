@@ -0,0 +1,56 @@
+package level
+
+import (
+	"testing"
+
+	"git.kirsle.net/go/render"
+)
+
+func TestCameraRegions(t *testing.T) {
+	var lvl = &Level{}
+
+	// Corners are normalized however the region was dragged out.
+	if r := lvl.AddCameraRegion(render.NewPoint(0, 0), render.NewPoint(100, 50)); r != (CameraRegion{0, 0, 100, 50}) {
+		t.Errorf("unexpected region: %+v", r)
+	}
+	if r := lvl.AddCameraRegion(render.NewPoint(150, 80), render.NewPoint(50, 20)); r != (CameraRegion{50, 20, 100, 60}) {
+		t.Errorf("unexpected backwards region: %+v", r)
+	}
+
+	tests := []struct {
+		point  render.Point
+		expect int // index of the region, -1 for none
+	}{
+		{render.NewPoint(10, 10), 0},
+		{render.NewPoint(0, 0), 0},
+		{render.NewPoint(99, 49), 1}, // overlap: the last placed wins
+		{render.NewPoint(100, 10), -1},
+		{render.NewPoint(120, 30), 1},
+		{render.NewPoint(149, 79), 1},
+		{render.NewPoint(150, 80), -1}, // the far edges are outside
+		{render.NewPoint(-1, 0), -1},
+	}
+	for i, test := range tests {
+		region, ok := lvl.CameraRegionAt(test.point)
+		if test.expect < 0 {
+			if ok {
+				t.Errorf("Test %d: expected no region at %s but got %+v", i, test.point, region)
+			}
+			continue
+		}
+		if !ok || region != lvl.CameraRegions[test.expect] {
+			t.Errorf("Test %d: expected region %d at %s but got %+v (%+v)", i, test.expect, test.point, region, ok)
+		}
+	}
+
+	// Remove the topmost region at a point.
+	if !lvl.RemoveCameraRegionAt(render.NewPoint(60, 30)) || len(lvl.CameraRegions) != 1 {
+		t.Errorf("expected to remove the overlapping region: %+v", lvl.CameraRegions)
+	}
+	if region, _ := lvl.CameraRegionAt(render.NewPoint(60, 30)); region != lvl.CameraRegions[0] {
+		t.Errorf("expected the first region to be left: %+v", region)
+	}
+	if lvl.RemoveCameraRegionAt(render.NewPoint(500, 500)) {
+		t.Errorf("expected no region to remove at 500,500")
+	}
+}
@@ -42,8 +42,14 @@ type Chunk struct {
 	textureMasked      render.Texturer
 	textureMaskedColor render.Color
 
-	dirty    bool // Chunk is changed and needs textures redrawn
-	modified bool // Chunk is changed and is held in memory til next Zipfile save
+	// Behavior view texture, see TextureBehavior.
+	textureBehavior        render.Texturer
+	textureBehaviorView    Behavior
+	textureBehaviorVersion uint64
+
+	dirty    bool   // Chunk is changed and needs textures redrawn
+	modified bool   // Chunk is changed and is held in memory til next Zipfile save
+	version  uint64 // Counts every change to the chunk, see Version()
 }
 
 // JSONChunk holds a lightweight (interface-free) copy of the Chunk for
@@ -112,10 +118,40 @@ func (c *Chunk) TextureMasked(e render.Engine, mask render.Color) render.Texture
 	return c.textureMasked
 }
 
+// TextureBehavior returns a cached texture of the chunk recolored by the
+// attributes of its swatches, for the editor's behavior view.
+func (c *Chunk) TextureBehavior(e render.Engine, view Behavior) render.Texturer {
+	if c.textureBehavior == nil || c.textureBehaviorView != view || c.textureBehaviorVersion != c.version {
+		if c.textureBehavior != nil {
+			c.textureBehavior.Free()
+			c.textureBehavior = nil
+		}
+
+		if c.uuid == uuid.Nil {
+			c.uuid = uuid.Must(uuid.NewUUID())
+		}
+		name := fmt.Sprintf("%s-behavior-%d", c.uuid, view)
+
+		tex, err := shmem.CurrentRenderEngine.StoreTexture(name, c.ToBehaviorBitmap(view))
+		if err != nil {
+			log.Error("TextureBehavior: %s", err)
+		}
+
+		c.textureBehavior = tex
+		c.textureBehaviorView = view
+		c.textureBehaviorVersion = c.version
+	}
+	return c.textureBehavior
+}
+
 // SetDirty sets the `dirty` flag to true and forces the texture to be
 // re-computed next frame.
+//
+// It also counts as a new Version, so the cached textures of other views
+// (like TextureBehavior) are re-computed, e.g. after a palette change.
 func (c *Chunk) SetDirty() {
 	c.dirty = true
+	c.version++
 }
 
 // CachedBitmap returns a cached render of the chunk as a bitmap image.
@@ -164,6 +200,42 @@ func (c *Chunk) generateTexture(mask render.Color) (render.Texturer, error) {
 // want a cached bitmap image that only generates itself once, and
 // again when marked dirty.
 func (c *Chunk) ToBitmap(mask render.Color) image.Image {
+	return c.paintBitmap(func(px Pixel) render.Color {
+		var color = px.Swatch.Color
+
+		// Don't draw perfectly white pixels, SDL2 will make them invisible!
+		if color == render.White {
+			color.Blue--
+		}
+
+		// If the swatch has a pattern, mesh it in.
+		if px.Swatch.Pattern != "" {
+			color = pattern.SampleColor(px.Swatch.Pattern, color, px.Point())
+		}
+
+		if mask != render.Invisible {
+			// A semi-transparent mask will overlay on top of the actual color.
+			if mask.Alpha < 255 {
+				color = color.AddColor(mask)
+			} else {
+				color = mask
+			}
+		}
+		return color
+	})
+}
+
+// ToBehaviorBitmap exports the chunk's pixels as a bitmap image, colored by
+// the attributes of their swatches for a behavior view. NOT CACHED!
+func (c *Chunk) ToBehaviorBitmap(view Behavior) image.Image {
+	return c.paintBitmap(func(px Pixel) render.Color {
+		return view.Color(px.Swatch)
+	})
+}
+
+// paintBitmap draws the chunk's pixels onto a new image, using a function to
+// pick the color of each pixel.
+func (c *Chunk) paintBitmap(colorOf func(Pixel) render.Color) image.Image {
 	var (
 		size    = int(c.Size)
 		canvas  = c.SizePositive()
@@ -202,26 +274,7 @@ func (c *Chunk) ToBitmap(mask render.Color) image.Image {
 
 	// Blot all the pixels onto it.
 	for px := range c.Iter() {
-		var color = px.Swatch.Color
-
-		// Don't draw perfectly white pixels, SDL2 will make them invisible!
-		if color == render.White {
-			color.Blue--
-		}
-
-		// If the swatch has a pattern, mesh it in.
-		if px.Swatch.Pattern != "" {
-			color = pattern.SampleColor(px.Swatch.Pattern, color, px.Point())
-		}
-
-		if mask != render.Invisible {
-			// A semi-transparent mask will overlay on top of the actual color.
-			if mask.Alpha < 255 {
-				color = color.AddColor(mask)
-			} else {
-				color = mask
-			}
-		}
+		var color = colorOf(px)
 		img.Set(
 			px.X-pointOffset.X,
 			px.Y-pointOffset.Y,
@@ -253,6 +306,12 @@ func (c *Chunk) Teardown() int {
 		freed++
 	}
 
+	if c.textureBehavior != nil {
+		c.textureBehavior.Free()
+		c.textureBehavior = nil
+		freed++
+	}
+
 	return freed
 }
 
@@ -263,6 +322,7 @@ func (c *Chunk) Teardown() int {
 func (c *Chunk) Set(p render.Point, sw *Swatch) error {
 	c.dirty = true
 	c.modified = true
+	c.version++
 	return c.Accessor.Set(p, sw)
 }
 
@@ -270,9 +330,18 @@ func (c *Chunk) Set(p render.Point, sw *Swatch) error {
 func (c *Chunk) Delete(p render.Point) error {
 	c.dirty = true
 	c.modified = true
+	c.version++
 	return c.Accessor.Delete(p)
 }
 
+// Version counts the changes made to the chunk since it was loaded, and the
+// times it was marked dirty. Unlike the dirty flag it is never reset, so that
+// e.g. the editor's Navigator can tell which chunks changed since it last
+// looked at them.
+func (c *Chunk) Version() uint64 {
+	return c.version
+}
+
 /*
 IsModified returns the chunk's Modified flag. This is most likely to occur in the Editor when
 the user is drawing onto the level. Modified chunks are not unloaded from memory ever, until
@@ -48,6 +48,9 @@ type Chunker struct {
 
 	// The palette reference from first call to Inflate()
 	pal *Palette
+
+	// Counts the calls to Redraw, see Redraws().
+	redraws uint64
 }
 
 // NewChunker creates a new chunk manager with a given chunk size.
@@ -482,6 +485,14 @@ func (c *Chunker) Redraw() {
 	for chunk := range c.IterChunksThemselves() {
 		chunk.SetDirty()
 	}
+	c.redraws++
+}
+
+// Redraws counts the calls to Redraw, e.g. after the palette was changed,
+// so that renders of the whole drawing (like the editor's Navigator) know
+// to start over, including for the chunks that aren't in memory.
+func (c *Chunker) Redraws() uint64 {
+	return c.redraws
 }
 
 // Prerender visits every chunk and fetches its texture, in order to pre-load
@@ -563,6 +574,43 @@ func (c *Chunker) SetRect(r render.Rect, sw *Swatch) error {
 	return nil
 }
 
+// ReplaceSwatch recolors every pixel of one swatch to another. Only the
+// pixels inside the rect are replaced, or the whole drawing if the rect is
+// zero.
+//
+// Returns the points that were changed, e.g. for the Undo history.
+func (c *Chunker) ReplaceSwatch(from, to *Swatch, within render.Rect) []render.Point {
+	var (
+		points = []render.Point{}
+		pixels <-chan Pixel
+	)
+	if within == (render.Rect{}) {
+		pixels = c.IterPixels()
+	} else {
+		pixels = c.IterViewport(within)
+	}
+
+	// Collect the points first: don't modify the chunks while iterating them.
+	for px := range pixels {
+		if px.Swatch != from {
+			continue
+		}
+
+		// Like SetRect, the rect doesn't include its right and bottom edge.
+		if within != (render.Rect{}) && (px.X >= within.X+within.W || px.Y >= within.Y+within.H) {
+			continue
+		}
+
+		points = append(points, px.Point())
+	}
+
+	for _, pt := range points {
+		c.Set(pt, to)
+	}
+
+	return points
+}
+
 // Delete a pixel at the given coordinate.
 func (c *Chunker) Delete(p render.Point) error {
 	coord := c.ChunkCoordinate(p)
@@ -323,3 +323,55 @@ func TestRelativeCoordinates(t *testing.T) {
 		}
 	}
 }
+
+func TestReplaceSwatch(t *testing.T) {
+	var (
+		grass = &level.Swatch{Name: "grass", Color: render.Green}
+		ice   = &level.Swatch{Name: "ice", Color: render.Cyan}
+		dirt  = &level.Swatch{Name: "dirt", Color: render.Black}
+	)
+
+	// A fresh 20x20 chunker: grass on the left half, dirt on the right.
+	setup := func() *level.Chunker {
+		c := level.NewChunker(8)
+		for x := 0; x < 20; x++ {
+			for y := 0; y < 20; y++ {
+				if x < 10 {
+					c.Set(render.NewPoint(x, y), grass)
+				} else {
+					c.Set(render.NewPoint(x, y), dirt)
+				}
+			}
+		}
+		return c
+	}
+
+	var tests = []struct {
+		Note   string
+		Within render.Rect
+		Expect int
+	}{
+		{"whole drawing", render.Rect{}, 200},
+		{"within a rect", render.NewRect(5, 5), 25},
+		{"rect over both swatches", render.Rect{X: 8, Y: 0, W: 4, H: 2}, 4},
+	}
+
+	for _, test := range tests {
+		c := setup()
+		points := c.ReplaceSwatch(grass, ice, test.Within)
+		if len(points) != test.Expect {
+			t.Errorf("%s: expected %d pixels replaced but got %d", test.Note, test.Expect, len(points))
+		}
+
+		for _, pt := range points {
+			if sw, err := c.Get(pt); err != nil || sw != ice {
+				t.Errorf("%s: pixel %s was not recolored to ice: %v %v", test.Note, pt, sw, err)
+				break
+			}
+		}
+
+		if sw, _ := c.Get(render.NewPoint(15, 15)); sw != dirt {
+			t.Errorf("%s: other swatches should not be replaced", test.Note)
+		}
+	}
+}
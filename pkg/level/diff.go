@@ -0,0 +1,175 @@
+package level
+
+import (
+	"fmt"
+	"sort"
+
+	"git.kirsle.net/go/render"
+)
+
+// Diff describes how a level changed from an older version of it (such as
+// an autosave snapshot), one line per difference. Returns an empty list if
+// the two are the same.
+func Diff(old, new *Level) []string {
+	var result = []string{}
+
+	// Level properties.
+	var (
+		a = old.Properties()
+		b = new.Properties()
+	)
+	if !a.Equal(b) {
+		var fields = []struct {
+			name     string
+			old, new interface{}
+		}{
+			{"Title", a.Title, b.Title},
+			{"Author", a.Author, b.Author},
+			{"Description", a.Description, b.Description},
+			{"Tags", a.Tags, b.Tags},
+			{"Music", a.Music, b.Music},
+			{"Game rule", a.GameRule, b.GameRule},
+			{"Page type", a.PageType, b.PageType},
+			{"Wallpaper", a.Wallpaper, b.Wallpaper},
+			{"Max width", a.MaxWidth, b.MaxWidth},
+			{"Max height", a.MaxHeight, b.MaxHeight},
+		}
+		for _, field := range fields {
+			if fmt.Sprintf("%v", field.old) != fmt.Sprintf("%v", field.new) {
+				result = append(result, fmt.Sprintf("%s changed: %v -> %v", field.name, field.old, field.new))
+			}
+		}
+	}
+
+	// Palette swatches, by name.
+	result = append(result, DiffPalettes(old.Palette, new.Palette)...)
+
+	// Actors, by ID.
+	var added, removed, moved, changed int
+	for id, actor := range new.Actors {
+		if orig, ok := old.Actors[id]; !ok {
+			added++
+		} else if orig.Point != actor.Point {
+			moved++
+		} else if orig.Filename != actor.Filename || len(orig.Links) != len(actor.Links) || len(orig.Options) != len(actor.Options) {
+			changed++
+		}
+	}
+	for id := range old.Actors {
+		if _, ok := new.Actors[id]; !ok {
+			removed++
+		}
+	}
+	for _, count := range []struct {
+		n    int
+		verb string
+	}{
+		{added, "added"},
+		{removed, "removed"},
+		{moved, "moved"},
+		{changed, "changed"},
+	} {
+		if count.n > 0 {
+			result = append(result, fmt.Sprintf("Doodads %s: %d", count.verb, count.n))
+		}
+	}
+
+	// Pixels.
+	result = append(result, DiffChunkers(old.Chunker, new.Chunker)...)
+
+	return result
+}
+
+// DiffPalettes compares the swatches of two palettes by their names.
+func DiffPalettes(old, new *Palette) []string {
+	var (
+		result = []string{}
+		before = map[string]*Swatch{}
+	)
+	if old == nil || new == nil {
+		return result
+	}
+
+	for _, sw := range old.Swatches {
+		before[sw.Name] = sw
+	}
+
+	for _, sw := range new.Swatches {
+		orig, ok := before[sw.Name]
+		if !ok {
+			result = append(result, fmt.Sprintf("Swatch added: %s", sw.Name))
+			continue
+		}
+		delete(before, sw.Name)
+
+		if orig.Color != sw.Color || orig.Attributes() != sw.Attributes() || orig.Pattern != sw.Pattern {
+			result = append(result, fmt.Sprintf("Swatch changed: %s", sw.Name))
+		}
+	}
+
+	var names = []string{}
+	for name := range before {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result = append(result, fmt.Sprintf("Swatch removed: %s", name))
+	}
+
+	return result
+}
+
+// DiffChunkers counts the pixels that were drawn, erased or recolored
+// between two versions of a drawing. Pixels are compared by the name of
+// their swatch.
+func DiffChunkers(old, new *Chunker) []string {
+	var (
+		result  = []string{}
+		before  = map[render.Point]string{}
+		added   int
+		removed int
+		changed int
+	)
+
+	for px := range old.IterPixels() {
+		before[render.NewPoint(px.X, px.Y)] = swatchName(px)
+	}
+
+	for px := range new.IterPixels() {
+		var point = render.NewPoint(px.X, px.Y)
+		name, ok := before[point]
+		if !ok {
+			added++
+			continue
+		}
+		delete(before, point)
+
+		if name != swatchName(px) {
+			changed++
+		}
+	}
+	removed = len(before)
+
+	for _, count := range []struct {
+		n    int
+		verb string
+	}{
+		{added, "drawn"},
+		{removed, "erased"},
+		{changed, "recolored"},
+	} {
+		if count.n > 0 {
+			result = append(result, fmt.Sprintf("Pixels %s: %d", count.verb, count.n))
+		}
+	}
+
+	return result
+}
+
+// swatchName of a pixel, which may not be inflated yet.
+func swatchName(px Pixel) string {
+	if px.Swatch == nil {
+		return fmt.Sprintf("#%d", px.PaletteIndex)
+	}
+	return px.Swatch.Name
+}
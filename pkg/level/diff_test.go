@@ -0,0 +1,59 @@
+package level_test
+
+import (
+	"testing"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
+	"git.kirsle.net/go/render"
+)
+
+func TestDiff(t *testing.T) {
+	var (
+		old   = level.New()
+		new   = level.New()
+		solid = &level.Swatch{Name: "solid", Color: render.Black, Solid: true}
+		fire  = &level.Swatch{Name: "fire", Color: render.Red, Fire: true}
+	)
+
+	// The same (empty) levels have no differences.
+	if diff := level.Diff(old, new); len(diff) != 0 {
+		t.Errorf("expected no differences between new levels, got %+v", diff)
+	}
+
+	old.Palette.AddSwatch(solid)
+	new.Palette.AddSwatch(&level.Swatch{Name: "solid", Color: render.Black, Solid: true})
+	new.Palette.AddSwatch(fire)
+	new.Title = "Renamed"
+
+	// Pixels: one unchanged, one recolored, one erased, two drawn.
+	old.Chunker.Set(render.NewPoint(1, 1), solid)
+	old.Chunker.Set(render.NewPoint(2, 2), solid)
+	old.Chunker.Set(render.NewPoint(3, 3), solid)
+	new.Chunker.Set(render.NewPoint(1, 1), new.Palette.Swatches[0])
+	new.Chunker.Set(render.NewPoint(2, 2), fire)
+	new.Chunker.Set(render.NewPoint(4, 4), fire)
+	new.Chunker.Set(render.NewPoint(5, 5), fire)
+
+	// Actors: one added.
+	new.Actors.Add(level.NewActor(level.Actor{
+		Filename: "button.doodad",
+	}))
+
+	expect := []string{
+		"Title changed: Untitled -> Renamed",
+		"Swatch added: fire",
+		"Doodads added: 1",
+		"Pixels drawn: 2",
+		"Pixels erased: 1",
+		"Pixels recolored: 1",
+	}
+	diff := level.Diff(old, new)
+	if len(diff) != len(expect) {
+		t.Fatalf("expected %d differences but got %d: %+v", len(expect), len(diff), diff)
+	}
+	for i, line := range diff {
+		if line != expect[i] {
+			t.Errorf("difference %d: expected %q but got %q", i, expect[i], line)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package level
 
-import "git.kirsle.net/SketchyMaze/doodle/pkg/log"
+import (
+	"sort"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+)
 
 // Maintenance functions for the file format on disk.
 
@@ -27,24 +31,74 @@ func (m *Level) Vacuum() error {
 //
 // This is called automatically in WriteFile.
 func (m *Level) PruneLinks() int {
-	var count int
-	for id, actor := range m.Actors {
-		var newLinks []string
+	var broken = m.BrokenLinks()
+	for _, link := range broken {
+		if link.Region {
+			log.Warn("Level.PruneLinks: region %s (%s) was linked to unresolved actor %s",
+				link.From,
+				link.Name,
+				link.LinkID,
+			)
+			if region, ok := m.Regions[link.From]; ok {
+				region.Unlink(link.LinkID)
+			}
+		} else {
+			log.Warn("Level.PruneLinks: actor %s (%s) was linked to unresolved actor %s",
+				link.From,
+				link.Name,
+				link.LinkID,
+			)
+			if actor, ok := m.Actors[link.From]; ok {
+				actor.Unlink(link.LinkID)
+			}
+		}
+	}
+	return len(broken)
+}
+
+// BrokenLink is an Actor Link that can not be resolved, see BrokenLinks.
+type BrokenLink struct {
+	From   string // ID of the actor or region which has the link
+	Region bool   // whether From is a region
+	Name   string // doodad filename of the actor, or the region name
+	LinkID string // ID of the missing actor
+}
 
+// BrokenLinks finds the links of actors and regions to actors which are
+// not in the level, sorted by the ID of the actor or region.
+func (m *Level) BrokenLinks() []BrokenLink {
+	var result = []BrokenLink{}
+
+	for id, actor := range m.Actors {
 		for _, linkID := range actor.Links {
 			if _, ok := m.Actors[linkID]; !ok {
-				log.Warn("Level.PruneLinks: actor %s (%s) was linked to unresolved actor %s",
-					id,
-					actor.Filename,
-					linkID,
-				)
-				count++
-				continue
+				result = append(result, BrokenLink{
+					From:   id,
+					Name:   actor.Filename,
+					LinkID: linkID,
+				})
 			}
-			newLinks = append(newLinks, linkID)
 		}
+	}
 
-		actor.Links = newLinks
+	for id, region := range m.Regions {
+		for _, linkID := range region.Links {
+			if _, ok := m.Actors[linkID]; !ok {
+				result = append(result, BrokenLink{
+					From:   id,
+					Region: true,
+					Name:   region.Name,
+					LinkID: linkID,
+				})
+			}
+		}
 	}
-	return count
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].From != result[j].From {
+			return result[i].From < result[j].From
+		}
+		return result[i].LinkID < result[j].LinkID
+	})
+	return result
 }
@@ -0,0 +1,196 @@
+package giant_screenshot
+
+import (
+	"image"
+	"math"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
+	"git.kirsle.net/go/render"
+	"golang.org/x/image/draw"
+)
+
+/*
+Overview is a scaled down render of a level's chunks, e.g. for the editor's
+Navigator window.
+
+It updates incrementally: only the chunks that changed since the last Update
+are drawn again, using their cached bitmaps like the Giant Screenshot does.
+Chunks that aren't in memory (at first, or after the chunker was redrawn for
+a palette change) are loaded and drawn a few at a time, LoadsPerUpdate per
+Update. When the level grows past its chunk bounds, what was drawn is scaled
+into the new layout.
+*/
+type Overview struct {
+	Image          *image.RGBA
+	Scale          float64 // overview pixels per world pixel, at most 1
+	LoadsPerUpdate int     // chunks to load per Update, 0 for no limit
+
+	chunker   *level.Chunker
+	maxSize   render.Rect
+	world     render.Rect // world coordinates that the Image covers
+	low, high render.Point
+	versions  map[render.Point]uint64 // chunk versions last drawn
+	pending   map[render.Point]bool   // chunks to draw again
+	redraws   uint64                  // chunker Redraws last queued
+}
+
+// NewOverview creates an overview of a chunker that fits within a maximum
+// size in pixels. Call Update to render it.
+func NewOverview(chunker *level.Chunker, maxSize render.Rect) *Overview {
+	return &Overview{
+		chunker:  chunker,
+		maxSize:  maxSize,
+		versions: map[render.Point]uint64{},
+		pending:  map[render.Point]bool{},
+	}
+}
+
+// Update redraws the chunks that changed since last time. Returns true if
+// the Image was changed.
+func (o *Overview) Update() bool {
+	var changed bool
+
+	// The first time, or after the palette changed, every chunk is drawn.
+	if o.Image == nil || o.chunker.Redraws() != o.redraws {
+		o.redraws = o.chunker.Redraws()
+		o.queueAll()
+	}
+
+	// Did the level grow? Make room for it.
+	low, high := o.chunker.Bounds()
+	if o.Image == nil || low != o.low || high != o.high {
+		o.layout(low, high)
+		changed = true
+	}
+
+	// The chunks in memory that changed: chunks are never freed while they
+	// have unsaved changes.
+	for chunk := range o.chunker.IterCachedChunks() {
+		if o.pending[chunk.Point] || chunk.Version() != o.versions[chunk.Point] {
+			delete(o.pending, chunk.Point)
+			o.drawChunk(chunk)
+			changed = true
+		}
+	}
+
+	// Load a few more of the chunks still waiting to be drawn.
+	var loads int
+	for point := range o.pending {
+		if o.LoadsPerUpdate > 0 && loads >= o.LoadsPerUpdate {
+			break
+		}
+		loads++
+
+		delete(o.pending, point)
+		if chunk, ok := o.chunker.GetChunk(point); ok {
+			o.drawChunk(chunk)
+		} else {
+			o.clearChunk(point)
+		}
+		changed = true
+	}
+
+	return changed
+}
+
+// Done returns whether every chunk has been drawn.
+func (o *Overview) Done() bool {
+	return len(o.pending) == 0
+}
+
+// queueAll queues every chunk of the level to be drawn, without loading them.
+func (o *Overview) queueAll() {
+	for point := range o.chunker.IterChunks() {
+		o.pending[point] = true
+	}
+}
+
+// layout sizes the Image for the chunk bounds of the level, keeping what was
+// drawn before.
+func (o *Overview) layout(low, high render.Point) {
+	var (
+		size     = int(o.chunker.Size)
+		previous = o.Image
+		world    = o.world
+		scale    = o.Scale
+	)
+	o.low, o.high = low, high
+	o.world = render.Rect{
+		X: low.X * size,
+		Y: low.Y * size,
+		W: (high.X - low.X + 1) * size,
+		H: (high.Y - low.Y + 1) * size,
+	}
+
+	o.Scale = math.Min(
+		float64(o.maxSize.W)/float64(o.world.W),
+		float64(o.maxSize.H)/float64(o.world.H),
+	)
+	if o.Scale > 1 {
+		o.Scale = 1
+	}
+
+	o.Image = image.NewRGBA(image.Rect(0, 0,
+		int(math.Ceil(float64(o.world.W)*o.Scale)),
+		int(math.Ceil(float64(o.world.H)*o.Scale)),
+	))
+
+	if previous == nil {
+		return
+	}
+
+	// Scale the old Image into its place. If the level got smaller, the
+	// scale went up and the chunks are drawn again to be sharp.
+	var (
+		a = o.FromWorld(render.NewPoint(world.X, world.Y))
+		b = o.FromWorld(render.NewPoint(world.X+world.W, world.Y+world.H))
+	)
+	draw.NearestNeighbor.Scale(o.Image, image.Rect(a.X, a.Y, b.X, b.Y), previous, previous.Bounds(), draw.Src, nil)
+	if o.Scale > scale {
+		o.queueAll()
+	}
+}
+
+// drawChunk draws a chunk's bitmap scaled down onto the Image.
+func (o *Overview) drawChunk(chunk *level.Chunk) {
+	var dst = o.clearChunk(chunk.Point)
+	o.versions[chunk.Point] = chunk.Version()
+	if chunk.Len() == 0 || dst.Empty() {
+		return
+	}
+
+	var bitmap = chunk.CachedBitmap(render.Invisible)
+	draw.NearestNeighbor.Scale(o.Image, dst, bitmap, bitmap.Bounds(), draw.Over, nil)
+}
+
+// clearChunk clears what was drawn for a chunk before, e.g. for erased
+// pixels, and returns its rect on the Image.
+func (o *Overview) clearChunk(point render.Point) image.Rectangle {
+	var (
+		size = int(o.chunker.Size)
+		a    = o.FromWorld(render.NewPoint(point.X*size, point.Y*size))
+		b    = o.FromWorld(render.NewPoint((point.X+1)*size, (point.Y+1)*size))
+		dst  = image.Rect(a.X, a.Y, b.X, b.Y)
+	)
+	draw.Draw(o.Image, dst, image.Transparent, image.Point{}, draw.Src)
+	return dst
+}
+
+// FromWorld converts a world coordinate into a pixel on the Image.
+func (o *Overview) FromWorld(p render.Point) render.Point {
+	return render.Point{
+		X: int(math.Floor(float64(p.X-o.world.X) * o.Scale)),
+		Y: int(math.Floor(float64(p.Y-o.world.Y) * o.Scale)),
+	}
+}
+
+// ToWorld converts a pixel on the Image into a world coordinate.
+func (o *Overview) ToWorld(p render.Point) render.Point {
+	if o.Scale == 0 {
+		return render.Origin
+	}
+	return render.Point{
+		X: o.world.X + int(float64(p.X)/o.Scale),
+		Y: o.world.Y + int(float64(p.Y)/o.Scale),
+	}
+}
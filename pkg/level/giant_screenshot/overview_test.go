@@ -0,0 +1,88 @@
+package giant_screenshot
+
+import (
+	"testing"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
+	"git.kirsle.net/go/render"
+)
+
+func TestOverview(t *testing.T) {
+	var (
+		chunker = level.NewChunker(16)
+		solid   = &level.Swatch{Name: "solid", Color: render.Black}
+	)
+
+	// Fill the chunk at 0,0.
+	chunker.SetRect(render.NewRect(16, 16), solid)
+
+	// Chunks 0,0 to 1,1 (32x32 pixels) scaled down to 8x8.
+	overview := NewOverview(chunker, render.NewRect(8, 8))
+	chunker.Set(render.NewPoint(31, 31), solid)
+
+	if !overview.Update() {
+		t.Errorf("the first Update should render the overview")
+	}
+	if overview.Scale != 0.25 {
+		t.Errorf("expected a scale of 0.25 but got %f", overview.Scale)
+	}
+	if size := overview.Image.Bounds().Size(); size.X != 8 || size.Y != 8 {
+		t.Errorf("expected an 8x8 image but got %s", size)
+	}
+
+	// The top left quarter is solid, the top right is empty.
+	if _, _, _, a := overview.Image.At(1, 1).RGBA(); a == 0 {
+		t.Errorf("expected a solid pixel at 1,1")
+	}
+	if _, _, _, a := overview.Image.At(6, 1).RGBA(); a != 0 {
+		t.Errorf("expected an empty pixel at 6,1")
+	}
+
+	// No changes, no update.
+	if overview.Update() {
+		t.Errorf("Update with no changes should return false")
+	}
+
+	// Draw into an existing chunk.
+	chunker.SetRect(render.Rect{X: 16, Y: 0, W: 16, H: 16}, solid)
+	if !overview.Update() {
+		t.Errorf("Update should redraw the changed chunk")
+	}
+	if _, _, _, a := overview.Image.At(6, 1).RGBA(); a == 0 {
+		t.Errorf("expected a solid pixel at 6,1 after the update")
+	}
+
+	// Change the swatch color, like the Palette Editor does.
+	solid.Color = render.Red
+	chunker.Redraw()
+	if !overview.Update() {
+		t.Errorf("Update should redraw the overview after the chunker was redrawn")
+	}
+	if r, _, _, a := overview.Image.At(1, 1).RGBA(); r == 0 || a == 0 {
+		t.Errorf("expected a red pixel at 1,1 after the palette change")
+	}
+
+	// World coordinates round trip.
+	if p := overview.ToWorld(overview.FromWorld(render.NewPoint(20, 8))); p != render.NewPoint(20, 8) {
+		t.Errorf("expected world point 20,8 to round trip but got %s", p)
+	}
+
+	// The level grows to twice the size: what was drawn is kept, scaled down
+	// into the new layout, and only the new chunk is drawn.
+	chunker.Set(render.NewPoint(63, 63), solid)
+	if !overview.Update() {
+		t.Errorf("Update should lay out the overview after the level grew")
+	}
+	if overview.Scale != 0.125 {
+		t.Errorf("expected a scale of 0.125 but got %f", overview.Scale)
+	}
+	if r, _, _, a := overview.Image.At(1, 1).RGBA(); r == 0 || a == 0 {
+		t.Errorf("expected the red pixel to be kept at 1,1 after the level grew")
+	}
+	if _, _, _, a := overview.Image.At(7, 7).RGBA(); a == 0 {
+		t.Errorf("expected a solid pixel at 7,7 for the new chunk")
+	}
+	if !overview.Done() {
+		t.Errorf("expected every chunk to be drawn")
+	}
+}
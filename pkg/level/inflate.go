@@ -11,13 +11,14 @@ This function calls the following:
 
   - Chunker.Inflate(Palette) to update references to the level's pixels to point
     to the Swatch entry.
-  - Actors.Inflate()
+  - Actors.Inflate() and Regions.Inflate()
   - Palette.Inflate() to load private instance values for the palette subsystem.
 */
 func (l *Level) Inflate() {
 	// Inflate the chunk metadata to map the pixels to their palette indexes.
 	l.Chunker.Inflate(l.Palette)
 	l.Actors.Inflate()
+	l.Regions.Inflate()
 
 	// Inflate the private instance values.
 	l.Palette.Inflate()
@@ -0,0 +1,263 @@
+/*
+Package lint checks a Level for common problems before the players find them.
+
+It is used by the `doodad lint` command and the "Check level" item of the
+Level Editor's menu. Each problem has a severity: errors will break the
+level for the player (e.g. there is no Start Flag), and warnings are likely
+mistakes or wasted space in the level file.
+*/
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/doodads"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/plus/dpp"
+	"git.kirsle.net/go/render"
+)
+
+// Severity of a problem.
+type Severity string
+
+// Severity values.
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+)
+
+// Names of the checks, for the JSON output.
+const (
+	CheckStartFlag     = "start-flag"
+	CheckExit          = "exit"
+	CheckBrokenLink    = "broken-link"
+	CheckOutOfBounds   = "out-of-bounds"
+	CheckMissingDoodad = "missing-doodad"
+	CheckUnusedFile    = "unused-file"
+	CheckUnusedSwatch  = "unused-swatch"
+	CheckUnreachable   = "unreachable-exit"
+	CheckLoad          = "load" // the level file couldn't be loaded
+)
+
+// StartFlag is the doodad where the player spawns.
+const StartFlag = "start-flag.doodad"
+
+// Problem found in a level.
+type Problem struct {
+	Severity Severity `json:"severity"`
+	Check    string   `json:"check"`
+	Message  string   `json:"message"`
+}
+
+// String formats the problem like "error: this level has no Start Flag"
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Severity, p.Message)
+}
+
+// Report of the problems in a level.
+type Report struct {
+	Filename string    `json:"filename,omitempty"`
+	Problems []Problem `json:"problems"`
+}
+
+// OK returns whether the level had no errors.
+func (r *Report) OK() bool {
+	return r.Count(Error) == 0
+}
+
+// Count the problems of a severity.
+func (r *Report) Count(severity Severity) int {
+	var n int
+	for _, p := range r.Problems {
+		if p.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *Report) errorf(check, tmpl string, v ...interface{}) {
+	r.Problems = append(r.Problems, Problem{Error, check, fmt.Sprintf(tmpl, v...)})
+}
+
+func (r *Report) warnf(check, tmpl string, v ...interface{}) {
+	r.Problems = append(r.Problems, Problem{Warning, check, fmt.Sprintf(tmpl, v...)})
+}
+
+// loadDoodad resolves the doodad of an actor: from the level's embedded
+// files or the game's doodads.
+var loadDoodad = func(filename string, lvl *level.Level) (*doodads.Doodad, error) {
+	return dpp.Driver.LoadFromEmbeddable(filename, lvl, false)
+}
+
+// placed is an actor with its resolved doodad (nil if not resolved).
+type placed struct {
+	id     string
+	actor  *level.Actor
+	doodad *doodads.Doodad
+}
+
+// rect returns the actor's box in the level.
+func (p placed) rect() render.Rect {
+	var rect = render.Rect{
+		X: p.actor.Point.X,
+		Y: p.actor.Point.Y,
+	}
+	if p.doodad != nil {
+		rect.W = p.doodad.Size.W
+		rect.H = p.doodad.Size.H
+	}
+	return rect
+}
+
+// describe names the actor for the messages, like "button.doodad (ID abc) at 10,20"
+func (p placed) describe() string {
+	return fmt.Sprintf("%s (ID %s) at %d,%d", p.actor.Filename, p.id, p.actor.Point.X, p.actor.Point.Y)
+}
+
+// Check a level for problems.
+func Check(lvl *level.Level) *Report {
+	var (
+		report = &Report{
+			Problems: []Problem{},
+		}
+		actors  = []placed{}
+		missing = map[string]error{}
+		starts  = []placed{}
+		exits   = []placed{}
+	)
+
+	// Resolve the doodads of all the actors, sorted by ID for stable output.
+	var ids = []string{}
+	for id := range lvl.Actors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var cache = map[string]*doodads.Doodad{}
+	for _, id := range ids {
+		var actor = lvl.Actors[id]
+
+		dd, ok := cache[actor.Filename]
+		if _, failed := missing[actor.Filename]; !ok && !failed {
+			if doodad, err := loadDoodad(actor.Filename, lvl); err != nil {
+				missing[actor.Filename] = err
+			} else {
+				dd = doodad
+				cache[actor.Filename] = dd
+			}
+		}
+
+		var p = placed{id, actor, dd}
+		actors = append(actors, p)
+
+		if actor.Filename == StartFlag {
+			starts = append(starts, p)
+		} else if dd != nil && strings.Contains(dd.Script, "EndLevel(") {
+			exits = append(exits, p)
+		}
+	}
+
+	// Doodads that can't be resolved.
+	var names = []string{}
+	for filename := range missing {
+		names = append(names, filename)
+	}
+	sort.Strings(names)
+	for _, filename := range names {
+		report.errorf(CheckMissingDoodad, "doodad %s can't be loaded: %s", filename, missing[filename])
+	}
+
+	// The player start and level exits.
+	if len(starts) == 0 {
+		report.errorf(CheckStartFlag, "this level has no Start Flag for the player to spawn at")
+	} else if len(starts) > 1 {
+		report.warnf(CheckStartFlag, "this level has %d Start Flags: the player spawn point is ambiguous", len(starts))
+	}
+	if len(exits) == 0 && !lvl.GameRule.Survival {
+		report.errorf(CheckExit, "this level has no exit doodad for the player to win by")
+	}
+
+	// Links to missing actors.
+	for _, link := range lvl.BrokenLinks() {
+		var from = "actor " + link.From
+		if link.Region {
+			from = "region " + link.From
+		}
+		report.warnf(CheckBrokenLink, "%s (%s) is linked to missing actor %s", from, link.Name, link.LinkID)
+	}
+
+	// Actors outside the page boundaries.
+	if lvl.PageType > level.Unbounded {
+		for _, p := range actors {
+			var rect = p.rect()
+			if rect.X < 0 || rect.Y < 0 {
+				report.warnf(CheckOutOfBounds, "actor %s is placed in negative space", p.describe())
+			} else if lvl.PageType >= level.Bounded &&
+				(int64(rect.X+rect.W) > lvl.MaxWidth || int64(rect.Y+rect.H) > lvl.MaxHeight) {
+				report.warnf(CheckOutOfBounds, "actor %s is outside the %dx%d level boundary",
+					p.describe(), lvl.MaxWidth, lvl.MaxHeight,
+				)
+			}
+		}
+	}
+
+	checkFiles(lvl, report)
+
+	// Scan the level's pixels, for the palette and reachability checks.
+	var (
+		used   = map[string]bool{}
+		solids = []render.Point{}
+	)
+	for px := range lvl.Chunker.IterPixels() {
+		if px.Swatch == nil {
+			continue
+		}
+		used[px.Swatch.Name] = true
+		if px.Swatch.Solid {
+			solids = append(solids, render.NewPoint(px.X, px.Y))
+		}
+	}
+
+	// Unused palette swatches.
+	for _, swatch := range lvl.Palette.Swatches {
+		if !used[swatch.Name] {
+			report.warnf(CheckUnusedSwatch, "palette color %s is not used in the level", swatch.Name)
+		}
+	}
+
+	// Exits which can't be reached from the start.
+	if len(starts) > 0 && len(exits) > 0 {
+		checkReachable(lvl, report, starts[0], exits, actors, solids)
+	}
+
+	return report
+}
+
+// checkFiles looks for embedded files which the level doesn't use.
+func checkFiles(lvl *level.Level, report *Report) {
+	var used = map[string]bool{
+		balance.EmbeddedWallpaperBasePath + lvl.Wallpaper: true,
+		balance.EmbeddedMusicBasePath + lvl.Music:         true,
+	}
+	for _, actor := range lvl.Actors {
+		used[balance.EmbeddedDoodadsBasePath+actor.Filename] = true
+	}
+
+	for _, prefix := range []string{
+		balance.EmbeddedDoodadsBasePath,
+		balance.EmbeddedWallpaperBasePath,
+		balance.EmbeddedMusicBasePath,
+	} {
+		var files = lvl.ListFilesAt(prefix)
+		sort.Strings(files)
+		for _, filename := range files {
+			if !used[filename] {
+				report.warnf(CheckUnusedFile, "embedded file %s is not used by the level", filename)
+			}
+		}
+	}
+}
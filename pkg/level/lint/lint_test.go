@@ -0,0 +1,108 @@
+package lint
+
+import (
+	"errors"
+	"testing"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/doodads"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
+	"git.kirsle.net/go/render"
+)
+
+func TestCheck(t *testing.T) {
+	// Fake doodads for the test level.
+	var orig = loadDoodad
+	t.Cleanup(func() { loadDoodad = orig })
+	loadDoodad = func(filename string, lvl *level.Level) (*doodads.Doodad, error) {
+		switch filename {
+		case StartFlag:
+			return doodads.New(86), nil
+		case "exit.doodad":
+			dd := doodads.New(32)
+			dd.Script = "function main() { Events.OnCollide(function(e) { EndLevel(); }); }"
+			return dd, nil
+		case "button.doodad":
+			return doodads.New(32), nil
+		}
+		return nil, errors.New("not found")
+	}
+
+	var (
+		lvl   = level.New()
+		solid = &level.Swatch{Name: "solid", Color: render.Black, Solid: true}
+		water = &level.Swatch{Name: "water", Color: render.Blue, Water: true}
+	)
+	lvl.PageType = level.Bounded
+	lvl.MaxWidth = 1000
+	lvl.MaxHeight = 1000
+	lvl.Palette.AddSwatch(solid)
+	lvl.Palette.AddSwatch(water)
+	lvl.SetFile("assets/doodads/unused.doodad", []byte("{}"))
+
+	// Wall off the exit with a solid box.
+	for i := 0; i <= 150; i++ {
+		lvl.Chunker.Set(render.NewPoint(450+i, 50), solid)
+		lvl.Chunker.Set(render.NewPoint(450+i, 200), solid)
+		lvl.Chunker.Set(render.NewPoint(450, 50+i), solid)
+		lvl.Chunker.Set(render.NewPoint(600, 50+i), solid)
+	}
+
+	button := level.NewActor(level.Actor{
+		Filename: "button.doodad",
+		Point:    render.NewPoint(990, 0),
+	})
+	button.AddLink("missing-actor")
+	for _, actor := range []*level.Actor{
+		level.NewActor(level.Actor{Filename: StartFlag, Point: render.NewPoint(100, 100)}),
+		level.NewActor(level.Actor{Filename: "exit.doodad", Point: render.NewPoint(500, 100)}),
+		level.NewActor(level.Actor{Filename: "ghost.doodad", Point: render.NewPoint(200, 100)}),
+		button,
+	} {
+		lvl.Actors.Add(actor)
+	}
+
+	// Count the problems found by each check.
+	var count = func(report *Report) map[string]int {
+		var result = map[string]int{}
+		for _, p := range report.Problems {
+			result[p.Check]++
+		}
+		return result
+	}
+
+	report := Check(lvl)
+	expect := map[string]int{
+		CheckMissingDoodad: 1,
+		CheckBrokenLink:    1,
+		CheckOutOfBounds:   1,
+		CheckUnusedFile:    1,
+		CheckUnusedSwatch:  1,
+		CheckUnreachable:   1,
+	}
+	actual := count(report)
+	for check, n := range expect {
+		if actual[check] != n {
+			t.Errorf("expected %d %s problems but got %d: %+v", n, check, actual[check], report.Problems)
+		}
+	}
+	if len(report.Problems) != len(expect) {
+		t.Errorf("expected %d problems but got: %+v", len(expect), report.Problems)
+	}
+	if report.OK() {
+		t.Errorf("the missing doodad should have been an error")
+	}
+
+	// Open a door in the wall: the exit becomes reachable.
+	for y := 150; y < 200; y++ {
+		lvl.Chunker.Delete(render.NewPoint(450, y))
+	}
+	if n := count(Check(lvl))[CheckUnreachable]; n != 0 {
+		t.Errorf("expected the exit to be reachable through the door")
+	}
+
+	// An empty level has no start or exit.
+	report = Check(level.New())
+	if actual := count(report); actual[CheckStartFlag] != 1 || actual[CheckExit] != 1 || report.Count(Error) != 2 {
+		t.Errorf("expected errors for no start and exit, got %+v", report.Problems)
+	}
+}
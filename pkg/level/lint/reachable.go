@@ -0,0 +1,140 @@
+package lint
+
+import (
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
+	"git.kirsle.net/go/render"
+)
+
+// Tuning for the reachability check.
+const (
+	// The level is divided into square cells of this many pixels, and a cell
+	// with any solid pixel in it is a wall.
+	cellSize = 8
+
+	// Room to walk around the drawing in unbounded levels, in pixels.
+	searchMargin = 128
+
+	// Levels bigger than this many cells are too big to search.
+	maxCells = 4000000
+)
+
+/*
+checkReachable warns about level exits that the player can't get to from the
+Start Flag.
+
+This is a rough check which ignores gravity and the player's size: it finds
+which parts of the level are open space connected to the Start Flag, and
+warns about exits that are walled off from it entirely. Doodads (like locked
+doors) don't block the way, since the player may be able to open them.
+*/
+func checkReachable(lvl *level.Level, report *Report, start placed, exits, actors []placed, solids []render.Point) {
+	// Find the area to search: the drawing and all actors, with a margin
+	// around them, clamped to the page boundaries.
+	var low, high = start.actor.Point, start.actor.Point
+	for _, p := range solids {
+		low.X, low.Y = min(low.X, p.X), min(low.Y, p.Y)
+		high.X, high.Y = max(high.X, p.X), max(high.Y, p.Y)
+	}
+	for _, p := range actors {
+		var rect = p.rect()
+		low.X, low.Y = min(low.X, rect.X), min(low.Y, rect.Y)
+		high.X, high.Y = max(high.X, rect.X+rect.W), max(high.Y, rect.Y+rect.H)
+	}
+	low.X -= searchMargin
+	low.Y -= searchMargin
+	high.X += searchMargin
+	high.Y += searchMargin
+
+	if lvl.PageType > level.Unbounded {
+		low.X, low.Y = max(low.X, 0), max(low.Y, 0)
+	}
+	if lvl.PageType >= level.Bounded {
+		high.X, high.Y = min(high.X, int(lvl.MaxWidth)), min(high.Y, int(lvl.MaxHeight))
+	}
+
+	var (
+		cols = (high.X-low.X)/cellSize + 1
+		rows = (high.Y-low.Y)/cellSize + 1
+	)
+	if cols <= 0 || rows <= 0 {
+		return
+	}
+	if cols*rows > maxCells {
+		report.warnf(CheckUnreachable, "the level is too big to check that its exits can be reached")
+		return
+	}
+
+	// The cell of a point in the level, and whether it is in the search area.
+	var cellOf = func(x, y int) (int, bool) {
+		if x < low.X || y < low.Y || x > high.X || y > high.Y {
+			return 0, false
+		}
+		return ((y-low.Y)/cellSize)*cols + (x-low.X)/cellSize, true
+	}
+
+	// Walls.
+	var wall = make([]bool, cols*rows)
+	for _, p := range solids {
+		if cell, ok := cellOf(p.X, p.Y); ok {
+			wall[cell] = true
+		}
+	}
+
+	// Flood fill the open space from the center of the Start Flag.
+	var (
+		rect       = start.rect()
+		visited    = make([]bool, cols*rows)
+		queue      = []int{}
+		cell, ok   = cellOf(rect.X+rect.W/2, rect.Y+rect.H/2)
+		neighbours = func(cell int) []int {
+			var result = []int{}
+			if cell%cols > 0 {
+				result = append(result, cell-1)
+			}
+			if cell%cols < cols-1 {
+				result = append(result, cell+1)
+			}
+			if cell >= cols {
+				result = append(result, cell-cols)
+			}
+			if cell+cols < len(visited) {
+				result = append(result, cell+cols)
+			}
+			return result
+		}
+	)
+	if !ok {
+		return
+	}
+	visited[cell] = true
+	queue = append(queue, cell)
+	for len(queue) > 0 {
+		cell, queue = queue[0], queue[1:]
+		for _, next := range neighbours(cell) {
+			if !visited[next] && !wall[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	// Check whether any part of each exit was reached.
+	for _, exit := range exits {
+		var (
+			box     = exit.rect()
+			reached bool
+		)
+		for y := box.Y; y <= box.Y+box.H && !reached; y += cellSize {
+			for x := box.X; x <= box.X+box.W; x += cellSize {
+				if cell, ok := cellOf(x, y); ok && visited[cell] {
+					reached = true
+					break
+				}
+			}
+		}
+
+		if !reached {
+			report.warnf(CheckUnreachable, "exit %s may not be reachable from the Start Flag", exit.describe())
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package level
+
+import (
+	"sort"
+
+	"git.kirsle.net/go/render"
+	"github.com/google/uuid"
+)
+
+// RegionMap holds the trigger regions by their ID in the level data.
+type RegionMap map[string]*Region
+
+// Inflate assigns each region its ID from the hash map for their self reference.
+func (m RegionMap) Inflate() {
+	for id, region := range m {
+		region.id = id
+	}
+}
+
+// Add a new Region to the map. If it doesn't already have an ID it will be
+// given a random UUIDv4 ID.
+func (m RegionMap) Add(r *Region) {
+	if r.id == "" {
+		r.id = uuid.New().String()
+	}
+	m[r.id] = r
+}
+
+// Remove a Region from the map.
+func (m RegionMap) Remove(r *Region) bool {
+	if _, ok := m[r.id]; ok {
+		delete(m, r.id)
+		return true
+	}
+	return false
+}
+
+// At returns the topmost (smallest) region at a world coordinate.
+func (m RegionMap) At(p render.Point) *Region {
+	var found *Region
+	for _, region := range m.Sorted() {
+		if region.Contains(p) {
+			if found == nil || region.W*region.H < found.W*found.H {
+				found = region
+			}
+		}
+	}
+	return found
+}
+
+// ByName finds a region by its name (or ID). If more regions share the name,
+// the first one in Sorted order is returned.
+func (m RegionMap) ByName(name string) *Region {
+	if region, ok := m[name]; ok {
+		return region
+	}
+	for _, region := range m.Sorted() {
+		if region.Name == name {
+			return region
+		}
+	}
+	return nil
+}
+
+// Sorted returns the regions sorted by name, then ID.
+func (m RegionMap) Sorted() []*Region {
+	var result = make([]*Region, 0, len(m))
+	for _, region := range m {
+		result = append(result, region)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].id < result[j].id
+	})
+	return result
+}
+
+// AddRegion adds a trigger region to the level.
+func (l *Level) AddRegion(r *Region) {
+	if l.Regions == nil {
+		l.Regions = RegionMap{}
+	}
+	l.Regions.Add(r)
+}
+
+/*
+Region is a named, invisible rectangle in the level that fires events when
+actors enter or leave it, without needing a hidden doodad.
+
+Like actors, regions can be linked to other actors; the linked actors are
+notified when something enters or leaves the region.
+*/
+type Region struct {
+	id    string   // NOTE: read only, use ID() to access.
+	Name  string   `json:"name"`
+	X     int      `json:"x"`
+	Y     int      `json:"y"`
+	W     int      `json:"w"`
+	H     int      `json:"h"`
+	Links []string `json:"links,omitempty"` // IDs of linked actors
+}
+
+// NewRegion initializes a Region from two corner points, e.g. dragged out in
+// the editor.
+func NewRegion(name string, a, b render.Point) *Region {
+	var r = &Region{
+		Name:  name,
+		Links: []string{},
+	}
+	r.Resize(a, b)
+	return r
+}
+
+// ID returns the region's ID.
+func (r *Region) ID() string {
+	return r.id
+}
+
+// Rect returns the region as a render.Rect with width and height.
+func (r *Region) Rect() render.Rect {
+	return render.Rect{X: r.X, Y: r.Y, W: r.W, H: r.H}
+}
+
+// Contains checks whether a world coordinate is inside the region.
+func (r *Region) Contains(p render.Point) bool {
+	return p.X >= r.X && p.X < r.X+r.W && p.Y >= r.Y && p.Y < r.Y+r.H
+}
+
+// Resize the region to fit two corner points.
+func (r *Region) Resize(a, b render.Point) {
+	r.X, r.Y = a.X, a.Y
+	r.W, r.H = b.X-a.X, b.Y-a.Y
+	if r.W < 0 {
+		r.X, r.W = b.X, -r.W
+	}
+	if r.H < 0 {
+		r.Y, r.H = b.Y, -r.H
+	}
+}
+
+// AddLink links an actor to the region by the actor's ID.
+func (r *Region) AddLink(id string) {
+	for _, exist := range r.Links {
+		if exist == id {
+			return
+		}
+	}
+	r.Links = append(r.Links, id)
+}
+
+// Unlink removes the linked actor's ID.
+func (r *Region) Unlink(id string) {
+	var newLinks []string
+	for _, exist := range r.Links {
+		if exist == id {
+			continue
+		}
+		newLinks = append(newLinks, exist)
+	}
+	r.Links = newLinks
+}
+
+// IsLinked checks if the region is linked to the actor's ID.
+func (r *Region) IsLinked(id string) bool {
+	for _, exist := range r.Links {
+		if exist == id {
+			return true
+		}
+	}
+	return false
+}
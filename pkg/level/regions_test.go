@@ -0,0 +1,89 @@
+package level
+
+import (
+	"testing"
+
+	"git.kirsle.net/go/render"
+)
+
+func TestRegions(t *testing.T) {
+	var (
+		lvl   = &Level{}
+		room  = NewRegion("Room", render.NewPoint(0, 0), render.NewPoint(200, 100))
+		door  = NewRegion("Door", render.NewPoint(150, 80), render.NewPoint(120, 40)) // dragged backwards
+		exit  = NewRegion("Exit", render.NewPoint(500, 500), render.NewPoint(550, 550))
+		exit2 = NewRegion("Exit", render.NewPoint(600, 500), render.NewPoint(650, 550))
+	)
+	for _, r := range []*Region{room, door, exit, exit2} {
+		lvl.AddRegion(r)
+		if r.ID() == "" {
+			t.Errorf("region %s was not given an ID", r.Name)
+		}
+	}
+
+	if door.Rect() != (render.Rect{X: 120, Y: 40, W: 30, H: 40}) {
+		t.Errorf("expected the door's corners to be normalized: %+v", door.Rect())
+	}
+
+	// At: the smallest region wins where they overlap.
+	tests := []struct {
+		point  render.Point
+		expect *Region
+	}{
+		{render.NewPoint(10, 10), room},
+		{render.NewPoint(130, 50), door},
+		{render.NewPoint(150, 50), room}, // the far edge of the door is outside
+		{render.NewPoint(520, 520), exit},
+		{render.NewPoint(300, 300), nil},
+	}
+	for i, test := range tests {
+		if actual := lvl.Regions.At(test.point); actual != test.expect {
+			t.Errorf("Test %d: At(%s): expected %+v but got %+v", i, test.point, test.expect, actual)
+		}
+	}
+
+	// Sorted by name, then ID.
+	var sorted = lvl.Regions.Sorted()
+	if len(sorted) != 4 || sorted[0] != door || sorted[3] != room ||
+		sorted[1].ID() > sorted[2].ID() {
+		t.Errorf("unexpected sort order: %+v", sorted)
+	}
+
+	// ByName: by ID or name, the first in sorted order for duplicate names.
+	if lvl.Regions.ByName("Room") != room || lvl.Regions.ByName(door.ID()) != door {
+		t.Errorf("expected to find regions by name and ID")
+	}
+	if lvl.Regions.ByName("Exit") != sorted[1] {
+		t.Errorf("expected the first Exit region in sorted order")
+	}
+	if lvl.Regions.ByName("Nowhere") != nil {
+		t.Errorf("expected no region named Nowhere")
+	}
+
+	// Remove.
+	if !lvl.Regions.Remove(door) || lvl.Regions.Remove(door) {
+		t.Errorf("expected to remove the door only once")
+	}
+	if lvl.Regions.At(render.NewPoint(130, 50)) != room {
+		t.Errorf("expected the room where the door was")
+	}
+}
+
+func TestRegionResize(t *testing.T) {
+	tests := []struct {
+		a, b   render.Point
+		expect render.Rect
+	}{
+		{render.NewPoint(10, 10), render.NewPoint(50, 30), render.Rect{X: 10, Y: 10, W: 40, H: 20}},
+		{render.NewPoint(50, 30), render.NewPoint(10, 10), render.Rect{X: 10, Y: 10, W: 40, H: 20}},
+		{render.NewPoint(10, 30), render.NewPoint(50, 10), render.Rect{X: 10, Y: 10, W: 40, H: 20}},
+		{render.NewPoint(10, 10), render.NewPoint(10, 10), render.Rect{X: 10, Y: 10}},
+	}
+	for i, test := range tests {
+		var r = &Region{}
+		r.Resize(test.a, test.b)
+		if r.Rect() != test.expect {
+			t.Errorf("Test %d: Resize(%s, %s): expected %+v but got %+v", i, test.a, test.b, test.expect, r.Rect())
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package level
+
+// Snapshots of level data, so the editor can undo changes that aren't made
+// of pixels: see drawtool.Command.
+
+// Copy returns a deep copy of the actors, keeping their IDs. Changes to the
+// copy don't affect the original actors and vice versa.
+func (m ActorMap) Copy() ActorMap {
+	var copied = ActorMap{}
+	for id, actor := range m {
+		var dup = &Actor{
+			id:       id,
+			Filename: actor.Filename,
+			Point:    actor.Point,
+			Links:    append([]string{}, actor.Links...),
+			Options:  map[string]*Option{},
+		}
+		for name, option := range actor.Options {
+			var opt = *option
+			dup.Options[name] = &opt
+		}
+		copied[id] = dup
+	}
+	return copied
+}
+
+// PaletteSnapshot holds the state of a Palette's swatches.
+type PaletteSnapshot struct {
+	palette  *Palette
+	swatches []*Swatch
+	values   []Swatch
+}
+
+// Snapshot takes a copy of the palette's swatches.
+func (p *Palette) Snapshot() PaletteSnapshot {
+	p.update()
+
+	var snap = PaletteSnapshot{
+		palette:  p,
+		swatches: append([]*Swatch{}, p.Swatches...),
+		values:   make([]Swatch, len(p.Swatches)),
+	}
+	for i, sw := range p.Swatches {
+		snap.values[i] = *sw
+	}
+	return snap
+}
+
+// Palette returns the palette that the snapshot was taken of.
+func (s PaletteSnapshot) Palette() *Palette {
+	return s.palette
+}
+
+// Equal checks whether two snapshots of a palette have the same swatches.
+func (s PaletteSnapshot) Equal(other PaletteSnapshot) bool {
+	if s.palette != other.palette || len(s.swatches) != len(other.swatches) {
+		return false
+	}
+	for i := range s.swatches {
+		if s.swatches[i] != other.swatches[i] || s.values[i] != other.values[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Restore the palette to a snapshot. Pixels of the drawing keep pointing to
+// the same Swatch objects, which are updated in place.
+func (p *Palette) Restore(s PaletteSnapshot) {
+	p.Swatches = append([]*Swatch{}, s.swatches...)
+	for i, sw := range p.Swatches {
+		*sw = s.values[i]
+	}
+	p.FlushCaches()
+}
+
+// Properties are the level settings from the editor's Page Settings window.
+type Properties struct {
+	Title       string
+	Author      string
+	Description string
+	Tags        []string
+	Music       string
+	GameRule    GameRule
+	PageType    PageType
+	Wallpaper   string
+	MaxWidth    int64
+	MaxHeight   int64
+}
+
+// Properties returns a copy of the level's settings.
+func (m *Level) Properties() Properties {
+	return Properties{
+		Title:       m.Title,
+		Author:      m.Author,
+		Description: m.Description,
+		Tags:        append([]string{}, m.Tags...),
+		Music:       m.Music,
+		GameRule:    m.GameRule,
+		PageType:    m.PageType,
+		Wallpaper:   m.Wallpaper,
+		MaxWidth:    m.MaxWidth,
+		MaxHeight:   m.MaxHeight,
+	}
+}
+
+// SetProperties restores the level's settings.
+func (m *Level) SetProperties(p Properties) {
+	m.Title = p.Title
+	m.Author = p.Author
+	m.Description = p.Description
+	m.Tags = append([]string{}, p.Tags...)
+	m.Music = p.Music
+	m.GameRule = p.GameRule
+	m.PageType = p.PageType
+	m.Wallpaper = p.Wallpaper
+	m.MaxWidth = p.MaxWidth
+	m.MaxHeight = p.MaxHeight
+}
+
+// Equal checks whether the properties are the same.
+func (p Properties) Equal(other Properties) bool {
+	if len(p.Tags) != len(other.Tags) {
+		return false
+	}
+	for i := range p.Tags {
+		if p.Tags[i] != other.Tags[i] {
+			return false
+		}
+	}
+
+	return p.Title == other.Title &&
+		p.Author == other.Author &&
+		p.Description == other.Description &&
+		p.Music == other.Music &&
+		p.GameRule == other.GameRule &&
+		p.PageType == other.PageType &&
+		p.Wallpaper == other.Wallpaper &&
+		p.MaxWidth == other.MaxWidth &&
+		p.MaxHeight == other.MaxHeight
+}
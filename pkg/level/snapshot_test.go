@@ -0,0 +1,83 @@
+package level_test
+
+import (
+	"testing"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
+	"git.kirsle.net/go/render"
+)
+
+func TestActorMapCopy(t *testing.T) {
+	var actors = level.ActorMap{}
+	actors.Add(level.NewActor(level.Actor{
+		Filename: "button.doodad",
+		Point:    render.NewPoint(10, 20),
+	}))
+
+	var actor *level.Actor
+	for _, a := range actors {
+		actor = a
+	}
+	actor.SetOption("color", "str", "red")
+
+	// Changes to the original don't affect the copy.
+	copied := actors.Copy()
+	actor.Point = render.NewPoint(30, 40)
+	actor.AddLink("other")
+	actor.SetOption("color", "str", "blue")
+
+	dup, ok := copied[actor.ID()]
+	if !ok || dup.ID() != actor.ID() {
+		t.Fatalf("the copy should keep the actor ID %s", actor.ID())
+	}
+	if dup.Point != render.NewPoint(10, 20) {
+		t.Errorf("copied actor moved to %s", dup.Point)
+	}
+	if len(dup.Links) != 0 {
+		t.Errorf("copied actor got links: %+v", dup.Links)
+	}
+	if v := dup.Options["color"].Value; v != "red" {
+		t.Errorf("copied actor option changed to %v", v)
+	}
+}
+
+func TestPaletteSnapshot(t *testing.T) {
+	var (
+		pal    = level.DefaultPalette()
+		solid  = pal.Swatches[0]
+		before = pal.Snapshot()
+	)
+
+	// Change a swatch and add a new one.
+	solid.Name = "ground"
+	solid.Slippery = true
+	if _, err := pal.NewSwatch(); err != nil {
+		t.Fatalf("NewSwatch: %s", err)
+	}
+	after := pal.Snapshot()
+
+	if before.Equal(after) {
+		t.Errorf("snapshots should differ after changing the palette")
+	}
+
+	// Undo.
+	pal.Restore(before)
+	if len(pal.Swatches) != 4 || pal.Swatches[0] != solid {
+		t.Errorf("expected the original 4 swatches, got %d", len(pal.Swatches))
+	}
+	if solid.Name != "solid" || solid.Slippery {
+		t.Errorf("the swatch was not restored in place: %s", solid)
+	}
+	if !pal.Snapshot().Equal(before) {
+		t.Errorf("palette should equal the snapshot after restoring it")
+	}
+
+	// Redo.
+	pal.Restore(after)
+	if len(pal.Swatches) != 5 || solid.Name != "ground" {
+		t.Errorf("expected the changes redone, got %d swatches and %s", len(pal.Swatches), solid)
+	}
+	if _, ok := pal.Get("ground"); !ok {
+		t.Errorf("the renamed swatch should be found by its name")
+	}
+}
@@ -0,0 +1,102 @@
+package stats
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Metric of the chunks to draw in a heatmap.
+type Metric int
+
+// Metric values.
+const (
+	Density Metric = iota // portion of the chunk that is drawn on
+	Size                  // compressed size (or raw size, if not in a zipfile)
+)
+
+// Colors of the heatmap scale, from the lowest to the highest values.
+var heatScale = []color.RGBA{
+	{0, 0, 128, 255},   // dark blue
+	{0, 192, 255, 255}, // cyan
+	{255, 255, 0, 255}, // yellow
+	{255, 0, 0, 255},   // red
+}
+
+/*
+Heatmap draws an image of the level's chunks colored by a metric, with each
+chunk as a square of cellSize pixels. Chunks are colored on a scale from blue
+for the lowest values to red for the highest; the transparent squares are
+where there are no chunks.
+*/
+func (s *Stats) Heatmap(metric Metric, cellSize int) *image.RGBA {
+	if len(s.Chunks) == 0 {
+		return image.NewRGBA(image.Rect(0, 0, cellSize, cellSize))
+	}
+
+	// Find the chunk bounds and the highest value.
+	var (
+		low, high = s.Chunks[0].Point, s.Chunks[0].Point
+		maxValue  float64
+	)
+	for _, chunk := range s.Chunks {
+		low.X, low.Y = min(low.X, chunk.Point.X), min(low.Y, chunk.Point.Y)
+		high.X, high.Y = max(high.X, chunk.Point.X), max(high.Y, chunk.Point.Y)
+		maxValue = max(maxValue, s.value(chunk, metric))
+	}
+
+	img := image.NewRGBA(image.Rect(
+		0, 0,
+		(high.X-low.X+1)*cellSize,
+		(high.Y-low.Y+1)*cellSize,
+	))
+	for _, chunk := range s.Chunks {
+		var t float64
+		if maxValue > 0 {
+			t = s.value(chunk, metric) / maxValue
+		}
+
+		var (
+			x = (chunk.Point.X - low.X) * cellSize
+			y = (chunk.Point.Y - low.Y) * cellSize
+		)
+		draw.Draw(
+			img,
+			image.Rect(x, y, x+cellSize, y+cellSize),
+			&image.Uniform{HeatColor(t)},
+			image.Point{},
+			draw.Src,
+		)
+	}
+
+	return img
+}
+
+// value of a chunk for the metric.
+func (s *Stats) value(chunk Chunk, metric Metric) float64 {
+	switch metric {
+	case Size:
+		if chunk.Compressed > 0 {
+			return float64(chunk.Compressed)
+		}
+		return float64(chunk.Bytes)
+	default:
+		return chunk.Density(s.ChunkSize)
+	}
+}
+
+// HeatColor returns the color of a value on the heatmap scale, from 0 to 1.
+func HeatColor(t float64) color.RGBA {
+	t = min(max(t, 0), 1)
+
+	var (
+		steps = float64(len(heatScale) - 1)
+		i     = min(int(t*steps), len(heatScale)-2)
+		frac  = t*steps - float64(i)
+		a, b  = heatScale[i], heatScale[i+1]
+		mix   = func(x, y uint8) uint8 {
+			return uint8(float64(x) + (float64(y)-float64(x))*frac)
+		}
+	)
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), 255}
+}
@@ -0,0 +1,224 @@
+/*
+Package stats measures the complexity and file size of a Level, for the
+`doodad stats` command.
+
+It counts the pixels of each palette swatch, the pixels and bytes of each
+chunk, the actors by their doodad and the size of their link graph, and the
+sizes of the level's embedded files. For levels in the zipfile format, the
+byte sizes come from the zipfile entries so both the raw and compressed
+sizes are known.
+*/
+package stats
+
+import (
+	"archive/zip"
+	"fmt"
+	"sort"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
+	"git.kirsle.net/go/render"
+)
+
+// Stats of a level.
+type Stats struct {
+	ChunkSize int
+	Pixels    int            // total pixels drawn
+	Swatches  map[string]int // pixels by swatch name
+	Chunks    []Chunk        // sorted by coordinate
+
+	// Actors and their links.
+	Actors      int
+	Doodads     map[string]int // actors by doodad filename
+	Links       Links
+	RegionLinks int // links from regions to actors
+
+	Files []File // embedded files, sorted by name
+}
+
+// Chunk statistics.
+type Chunk struct {
+	Point      render.Point
+	Pixels     int
+	Bytes      uint64 // size of the chunk's data
+	Compressed uint64 // compressed size in the zipfile, 0 if not in a zipfile
+}
+
+// Density returns the portion of the chunk's area that is drawn on, 0 to 1.
+func (c Chunk) Density(chunkSize int) float64 {
+	return float64(c.Pixels) / float64(chunkSize*chunkSize)
+}
+
+// Links is the size of the graph of linked actors.
+type Links struct {
+	Edges  int // unique pairs of linked actors
+	Actors int // actors which have any links
+	Groups int // groups of actors that are connected by links
+}
+
+// File is an embedded file of the level.
+type File struct {
+	Name       string
+	Bytes      uint64
+	Compressed uint64 // 0 if not in a zipfile
+}
+
+// Compute the statistics of a level.
+func Compute(lvl *level.Level) *Stats {
+	var (
+		stats = &Stats{
+			ChunkSize: int(lvl.Chunker.Size),
+			Swatches:  map[string]int{},
+			Chunks:    []Chunk{},
+			Actors:    len(lvl.Actors),
+			Doodads:   map[string]int{},
+			Files:     []File{},
+		}
+		entries = zipEntries(lvl.Zipfile)
+	)
+
+	// Chunks and their pixels.
+	for coord := range lvl.Chunker.IterChunks() {
+		chunk, ok := lvl.Chunker.GetChunk(coord)
+		if !ok {
+			continue
+		}
+
+		var cs = Chunk{
+			Point:  coord,
+			Pixels: chunk.Len(),
+		}
+		for px := range chunk.Iter() {
+			if px.Swatch != nil {
+				stats.Swatches[px.Swatch.Name]++
+			}
+		}
+		stats.Pixels += cs.Pixels
+
+		// The size of the chunk: from the zipfile, or by encoding it.
+		if file, ok := entries[fmt.Sprintf("chunks/%d/%s.bin", lvl.Chunker.Layer, coord)]; ok && !chunk.IsModified() {
+			cs.Bytes = file.UncompressedSize64
+			cs.Compressed = file.CompressedSize64
+		} else if bin, err := chunk.MarshalBinary(); err == nil {
+			cs.Bytes = uint64(len(bin))
+		}
+
+		stats.Chunks = append(stats.Chunks, cs)
+	}
+	sort.Slice(stats.Chunks, func(i, j int) bool {
+		a, b := stats.Chunks[i].Point, stats.Chunks[j].Point
+		if a.Y != b.Y {
+			return a.Y < b.Y
+		}
+		return a.X < b.X
+	})
+
+	// Actors and links.
+	for _, actor := range lvl.Actors {
+		stats.Doodads[actor.Filename]++
+	}
+	stats.Links = linkGraph(lvl.Actors)
+	for _, region := range lvl.Regions {
+		stats.RegionLinks += len(region.Links)
+	}
+
+	// Embedded files.
+	for _, filename := range lvl.ListFiles() {
+		var file = File{
+			Name: filename,
+		}
+		if entry, ok := entries[filename]; ok {
+			file.Bytes = entry.UncompressedSize64
+			file.Compressed = entry.CompressedSize64
+		} else if data, err := lvl.GetFile(filename); err == nil {
+			file.Bytes = uint64(len(data))
+		}
+		stats.Files = append(stats.Files, file)
+	}
+	sort.Slice(stats.Files, func(i, j int) bool {
+		return stats.Files[i].Name < stats.Files[j].Name
+	})
+
+	return stats
+}
+
+// ChunkBytes returns the total size of the chunks, and their compressed size.
+func (s *Stats) ChunkBytes() (bytes, compressed uint64) {
+	for _, chunk := range s.Chunks {
+		bytes += chunk.Bytes
+		compressed += chunk.Compressed
+	}
+	return
+}
+
+// FileBytes returns the total size of the embedded files, and their
+// compressed size.
+func (s *Stats) FileBytes() (bytes, compressed uint64) {
+	for _, file := range s.Files {
+		bytes += file.Bytes
+		compressed += file.Compressed
+	}
+	return
+}
+
+// zipEntries maps the files of a zipfile by name.
+func zipEntries(zf *zip.Reader) map[string]*zip.File {
+	var result = map[string]*zip.File{}
+	if zf != nil {
+		for _, file := range zf.File {
+			result[file.Name] = file
+		}
+	}
+	return result
+}
+
+// linkGraph measures the graph of actors linked to each other. Links are
+// stored on both actors, so each pair is only counted once; links to missing
+// actors are ignored.
+func linkGraph(actors level.ActorMap) Links {
+	var (
+		result = Links{}
+		pairs  = map[[2]string]bool{}
+		parent = map[string]string{} // union-find of the linked groups
+	)
+
+	var find func(id string) string
+	find = func(id string) string {
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+
+	for id, actor := range actors {
+		for _, linkID := range actor.Links {
+			if _, ok := actors[linkID]; !ok || linkID == id {
+				continue
+			}
+
+			var pair = [2]string{id, linkID}
+			if linkID < id {
+				pair = [2]string{linkID, id}
+			}
+			if pairs[pair] {
+				continue
+			}
+			pairs[pair] = true
+
+			for _, node := range pair {
+				if _, ok := parent[node]; !ok {
+					parent[node] = node
+				}
+			}
+			parent[find(pair[0])] = find(pair[1])
+		}
+	}
+
+	result.Edges = len(pairs)
+	result.Actors = len(parent)
+	for node := range parent {
+		if find(node) == node {
+			result.Groups++
+		}
+	}
+	return result
+}
@@ -0,0 +1,89 @@
+package stats
+
+import (
+	"image/color"
+	"testing"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
+	"git.kirsle.net/go/render"
+)
+
+func TestCompute(t *testing.T) {
+	var (
+		lvl   = level.New()
+		solid = &level.Swatch{Name: "solid", Color: render.Black, Solid: true}
+		fire  = &level.Swatch{Name: "fire", Color: render.Red, Fire: true}
+		size  = int(lvl.Chunker.Size)
+	)
+	lvl.Palette.AddSwatch(solid)
+	lvl.Palette.AddSwatch(fire)
+
+	// Three pixels in the first chunk and one in the chunk to its right.
+	lvl.Chunker.Set(render.NewPoint(1, 1), solid)
+	lvl.Chunker.Set(render.NewPoint(2, 1), solid)
+	lvl.Chunker.Set(render.NewPoint(3, 1), fire)
+	lvl.Chunker.Set(render.NewPoint(size+1, 1), fire)
+
+	// Actors: two linked buttons and a door, and one lonely actor.
+	var actors = []*level.Actor{}
+	for _, filename := range []string{"button.doodad", "button.doodad", "door.doodad", "key.doodad"} {
+		actor := level.NewActor(level.Actor{Filename: filename})
+		lvl.Actors.Add(actor)
+		actors = append(actors, actor)
+	}
+	for _, pair := range [][2]int{{0, 2}, {1, 2}} {
+		a, b := actors[pair[0]], actors[pair[1]]
+		a.AddLink(b.ID())
+		b.AddLink(a.ID())
+	}
+	actors[3].AddLink("missing")
+
+	lvl.SetFile("assets/music/song.ogg", make([]byte, 100))
+
+	stats := Compute(lvl)
+
+	if stats.Pixels != 4 || stats.Swatches["solid"] != 2 || stats.Swatches["fire"] != 2 {
+		t.Errorf("unexpected pixel counts: %d total, by swatch %+v", stats.Pixels, stats.Swatches)
+	}
+	if len(stats.Chunks) != 2 || stats.Chunks[0].Pixels != 3 || stats.Chunks[1].Pixels != 1 {
+		t.Errorf("unexpected chunks: %+v", stats.Chunks)
+	}
+	if bytes, _ := stats.ChunkBytes(); bytes == 0 {
+		t.Errorf("expected the chunks to have a size")
+	}
+	if stats.Doodads["button.doodad"] != 2 || stats.Actors != 4 {
+		t.Errorf("unexpected actor counts: %d total, by doodad %+v", stats.Actors, stats.Doodads)
+	}
+	if stats.Links != (Links{Edges: 2, Actors: 3, Groups: 1}) {
+		t.Errorf("unexpected link graph: %+v", stats.Links)
+	}
+	if len(stats.Files) != 1 || stats.Files[0].Bytes != 100 {
+		t.Errorf("unexpected embedded files: %+v", stats.Files)
+	}
+
+	// Heatmap: two chunks side by side, the densest one in red.
+	img := stats.Heatmap(Density, 4)
+	if b := img.Bounds(); b.Dx() != 8 || b.Dy() != 4 {
+		t.Errorf("unexpected heatmap size: %s", b)
+	}
+	if c := img.RGBAAt(0, 0); c != HeatColor(1) {
+		t.Errorf("expected the first chunk to be hottest, got %+v", c)
+	}
+}
+
+func TestHeatColor(t *testing.T) {
+	tests := []struct {
+		t      float64
+		expect color.RGBA
+	}{
+		{0, heatScale[0]},
+		{1, heatScale[len(heatScale)-1]},
+		{-1, heatScale[0]},
+		{2, heatScale[len(heatScale)-1]},
+	}
+	for i, test := range tests {
+		if actual := HeatColor(test.t); actual != test.expect {
+			t.Errorf("Test %d: HeatColor(%f): expected %+v but got %+v", i, test.t, test.expect, actual)
+		}
+	}
+}
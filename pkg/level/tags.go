@@ -0,0 +1,24 @@
+package level
+
+import "strings"
+
+// ParseTags splits a comma separated string into a list of level tags.
+// Tags are trimmed and lowercased, and empty or duplicate tags are removed.
+func ParseTags(value string) []string {
+	var (
+		tags = []string{}
+		seen = map[string]interface{}{}
+	)
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = nil
+		tags = append(tags, tag)
+	}
+	return tags
+}
@@ -46,6 +46,10 @@ type Level struct {
 	UUID     string   `json:"uuid"` // unique level IDs, especially for the savegame.json
 	GameRule GameRule `json:"rules"`
 
+	// Metadata shown in the level browser.
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+
 	// Chunked pixel data.
 	Chunker *Chunker `json:"chunks"`
 
@@ -59,12 +63,22 @@ type Level struct {
 	MaxHeight int64    `json:"boundedHeight"`
 	Wallpaper string   `json:"wallpaper"`
 
+	// Background music: a file in the game's music folder, or embedded in
+	// the level under assets/music/.
+	Music string `json:"music,omitempty"`
+
 	// The last scrolled position in the editor.
 	ScrollPosition render.Point `json:"scroll"`
 
 	// Actors keep a list of the doodad instances in this map.
 	Actors ActorMap `json:"actors"`
 
+	// Named trigger regions that fire events when actors enter or leave.
+	Regions RegionMap `json:"regions,omitempty"`
+
+	// Camera regions clamp scrolling in Play Mode.
+	CameraRegions []CameraRegion `json:"cameraRegions,omitempty"`
+
 	// Publishing: attach any custom doodads the map uses on save.
 	SaveDoodads  bool `json:"saveDoodads"`
 	SaveBuiltins bool `json:"saveBuiltins"`
@@ -83,6 +97,9 @@ type Level struct {
 type GameRule struct {
 	Difficulty enum.Difficulty `json:"difficulty"`
 	Survival   bool            `json:"survival,omitempty"`
+
+	// Disallow quicksaves: the player must beat the level from its checkpoints.
+	NoQuicksave bool `json:"noQuicksave,omitempty"`
 }
 
 // New creates a blank level object with all its members initialized.
@@ -97,13 +114,14 @@ func New() *Level {
 		Chunker: NewChunker(balance.ChunkSize),
 		Palette: &Palette{},
 		Actors:  ActorMap{},
+		Regions: RegionMap{},
 
 		PageType:  NoNegativeSpace,
 		Wallpaper: DefaultWallpaper,
 		MaxWidth:  2550,
 		MaxHeight: 3300,
 
-		UndoHistory: drawtool.NewHistory(balance.UndoHistory),
+		UndoHistory: drawtool.NewHistory(balance.UndoHistoryDepth()),
 	}
 }
 
@@ -0,0 +1,271 @@
+// Package levelindex caches the metadata of the user's levels for the
+// level browser.
+//
+// Loading every level file to read its title and screenshot is slow for
+// a big levels folder. The index is kept in a JSON file in the user's cache
+// folder along with each level's thumbnail PNG, and a scan only reloads the
+// levels whose files were changed since they were last indexed.
+package levelindex
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/enum"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/userdir"
+)
+
+// Entry holds the cached metadata of one level file.
+type Entry struct {
+	Filename    string          `json:"filename"` // base name in the user's levels folder
+	Title       string          `json:"title"`
+	Author      string          `json:"author"`
+	Description string          `json:"description,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Difficulty  enum.Difficulty `json:"difficulty"`
+	UUID        string          `json:"uuid,omitempty"`
+	ModTime     time.Time       `json:"modTime"`
+	Size        int64           `json:"size"`
+	HasThumb    bool            `json:"thumbnail"` // has a cached thumbnail PNG
+}
+
+// Package state.
+var (
+	entries  = map[string]*Entry{}
+	loaded   bool
+	scanning bool
+	mu       sync.RWMutex
+)
+
+// Entries returns the indexed levels, sorted by filename. The index is read
+// from the cache file on first use; call Scan to bring it up to date.
+func Entries() []*Entry {
+	mu.Lock()
+	if !loaded {
+		loaded = true
+		if err := loadCache(); err != nil && !os.IsNotExist(err) {
+			log.Error("levelindex: couldn't read cache: %s", err)
+		}
+	}
+	mu.Unlock()
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	var result = make([]*Entry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Filename < result[j].Filename
+	})
+	return result
+}
+
+// Scanning returns whether a background scan is in progress.
+func Scanning() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return scanning
+}
+
+// ScanAsync updates the index on a background goroutine and calls the
+// callback when done. Does nothing if a scan is already running.
+func ScanAsync(onDone func()) {
+	mu.Lock()
+	if scanning {
+		mu.Unlock()
+		return
+	}
+	scanning = true
+	mu.Unlock()
+
+	go func() {
+		if err := Scan(); err != nil {
+			log.Error("levelindex.Scan: %s", err)
+		}
+
+		mu.Lock()
+		scanning = false
+		mu.Unlock()
+
+		if onDone != nil {
+			onDone()
+		}
+	}()
+}
+
+// Scan updates the index with the user's levels folder: new and modified
+// levels are (re)loaded, deleted ones are dropped, and the cache is saved.
+func Scan() error {
+	Entries() // make sure the cache was loaded
+
+	filenames, err := userdir.ListLevels()
+	if err != nil {
+		return err
+	}
+
+	var (
+		found   = map[string]interface{}{}
+		changed bool
+	)
+	for _, filename := range filenames {
+		found[filename] = nil
+
+		// WASM: no file times to compare, index the names only.
+		if runtime.GOOS == "js" {
+			mu.Lock()
+			if _, ok := entries[filename]; !ok {
+				entries[filename] = &Entry{
+					Filename: filename,
+					Title:    filename,
+				}
+			}
+			mu.Unlock()
+			continue
+		}
+
+		stat, err := os.Stat(userdir.LevelPath(filename))
+		if err != nil {
+			log.Error("levelindex: %s", err)
+			continue
+		}
+
+		mu.RLock()
+		cached, ok := entries[filename]
+		mu.RUnlock()
+		if ok && cached.ModTime.Equal(stat.ModTime()) && cached.Size == stat.Size() {
+			continue
+		}
+
+		entry, err := indexLevel(filename, stat)
+		if err != nil {
+			log.Error("levelindex: couldn't index %s: %s", filename, err)
+			continue
+		}
+
+		mu.Lock()
+		entries[filename] = entry
+		mu.Unlock()
+		changed = true
+	}
+
+	// Forget deleted levels.
+	mu.Lock()
+	for filename := range entries {
+		if _, ok := found[filename]; !ok {
+			delete(entries, filename)
+			os.Remove(thumbnailFilename(filename))
+			changed = true
+		}
+	}
+	mu.Unlock()
+
+	if changed && runtime.GOOS != "js" {
+		return saveCache()
+	}
+	return nil
+}
+
+// Thumbnail returns the level's cached screenshot thumbnail.
+func (e *Entry) Thumbnail() (image.Image, error) {
+	data, err := ioutil.ReadFile(thumbnailFilename(e.Filename))
+	if err != nil {
+		return nil, err
+	}
+	return png.Decode(bytes.NewBuffer(data))
+}
+
+// HasTag returns whether the level is tagged with a given tag.
+func (e *Entry) HasTag(tag string) bool {
+	tag = strings.ToLower(tag)
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// indexLevel loads a level file and extracts its metadata and thumbnail.
+func indexLevel(filename string, stat os.FileInfo) (*Entry, error) {
+	lvl, err := level.LoadJSON(userdir.LevelPath(filename))
+	if err != nil {
+		return nil, err
+	}
+	defer lvl.Teardown()
+
+	var entry = &Entry{
+		Filename:    filename,
+		Title:       lvl.Title,
+		Author:      lvl.Author,
+		Description: lvl.Description,
+		Tags:        lvl.Tags,
+		Difficulty:  lvl.GameRule.Difficulty,
+		UUID:        lvl.UUID,
+		ModTime:     stat.ModTime(),
+		Size:        stat.Size(),
+	}
+
+	// Copy its embedded screenshot to the cache folder.
+	if data, err := lvl.Files.Get("assets/screenshots/" + balance.LevelScreenshotTinyFilename); err == nil {
+		if err := ioutil.WriteFile(thumbnailFilename(filename), data, 0644); err != nil {
+			log.Error("levelindex: couldn't cache thumbnail for %s: %s", filename, err)
+		} else {
+			entry.HasThumb = true
+		}
+	} else {
+		os.Remove(thumbnailFilename(filename))
+	}
+
+	return entry, nil
+}
+
+// Cache files.
+func cacheFilename() string {
+	return userdir.CacheFilename("levelindex.json")
+}
+
+func thumbnailFilename(filename string) string {
+	return userdir.CacheFilename("thumbnails", filename+".png")
+}
+
+// loadCache reads the cache file. Call with the lock held.
+func loadCache() error {
+	data, err := ioutil.ReadFile(cacheFilename())
+	if err != nil {
+		return err
+	}
+
+	var cached = map[string]*Entry{}
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return err
+	}
+
+	entries = cached
+	return nil
+}
+
+// saveCache writes the cache file.
+func saveCache() error {
+	mu.RLock()
+	data, err := json.MarshalIndent(entries, "", "\t")
+	mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(cacheFilename(), data, 0644)
+}
@@ -0,0 +1,116 @@
+package levelindex
+
+import (
+	"sort"
+	"strings"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/enum"
+)
+
+// SortBy options for a Query.
+type SortBy int
+
+const (
+	SortTitle SortBy = iota
+	SortAuthor
+	SortDifficulty
+	SortModified // most recently modified first
+)
+
+// Completion filters for a Query.
+type Completion int
+
+const (
+	AnyCompletion Completion = iota
+	Completed
+	NotCompleted
+)
+
+// Query filters and sorts the level index for the level browser.
+type Query struct {
+	Search     string           // matches the title, author, description or tags
+	Tag        string           // only levels with this tag
+	Difficulty *enum.Difficulty // only levels of this difficulty
+	Completion Completion
+	SortBy     SortBy
+
+	// Tells whether the player has completed a level, from their savegame.
+	// Required to filter by Completion.
+	IsCompleted func(*Entry) bool
+}
+
+// Filter returns the entries that match the query, in sorted order.
+func (q Query) Filter(entries []*Entry) []*Entry {
+	var (
+		result = []*Entry{}
+		search = strings.ToLower(strings.TrimSpace(q.Search))
+	)
+
+	for _, entry := range entries {
+		if search != "" && !entry.matches(search) {
+			continue
+		}
+		if q.Tag != "" && !entry.HasTag(q.Tag) {
+			continue
+		}
+		if q.Difficulty != nil && entry.Difficulty != *q.Difficulty {
+			continue
+		}
+		if q.Completion != AnyCompletion && q.IsCompleted != nil {
+			if q.IsCompleted(entry) != (q.Completion == Completed) {
+				continue
+			}
+		}
+		result = append(result, entry)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		var a, b = result[i], result[j]
+		switch q.SortBy {
+		case SortAuthor:
+			if !strings.EqualFold(a.Author, b.Author) {
+				return strings.ToLower(a.Author) < strings.ToLower(b.Author)
+			}
+		case SortDifficulty:
+			if a.Difficulty != b.Difficulty {
+				return a.Difficulty < b.Difficulty
+			}
+		case SortModified:
+			if !a.ModTime.Equal(b.ModTime) {
+				return a.ModTime.After(b.ModTime)
+			}
+		}
+		return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+	})
+
+	return result
+}
+
+// Tags returns all of the tags used by the entries, sorted.
+func Tags(entries []*Entry) []string {
+	var (
+		tags = []string{}
+		seen = map[string]interface{}{}
+	)
+	for _, entry := range entries {
+		for _, tag := range entry.Tags {
+			if _, ok := seen[tag]; !ok {
+				seen[tag] = nil
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// matches checks the entry's text fields for a lowercase search string.
+func (e *Entry) matches(search string) bool {
+	var fields = []string{e.Title, e.Author, e.Description, strings.Join(e.Tags, " ")}
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), search) {
+			return true
+		}
+	}
+	return false
+}
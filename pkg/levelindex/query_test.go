@@ -0,0 +1,113 @@
+package levelindex_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/enum"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/levelindex"
+)
+
+func TestQuery(t *testing.T) {
+	var (
+		now     = time.Now()
+		hard    = enum.Hard
+		entries = []*levelindex.Entry{
+			{
+				Filename:   "castle.level",
+				Title:      "Castle",
+				Author:     "zed",
+				Tags:       []string{"puzzle", "long"},
+				Difficulty: enum.Hard,
+				ModTime:    now.Add(-time.Hour),
+			},
+			{
+				Filename:    "tutorial.level",
+				Title:       "Tutorial",
+				Author:      "Alice",
+				Description: "Learn how to play",
+				Tags:        []string{"short"},
+				Difficulty:  enum.Peaceful,
+				ModTime:     now,
+			},
+			{
+				Filename:   "azulian.level",
+				Title:      "Azulian Tag",
+				Author:     "bob",
+				Tags:       []string{"puzzle"},
+				Difficulty: enum.Normal,
+				ModTime:    now.Add(-time.Minute),
+			},
+		}
+		completed = map[string]bool{
+			"tutorial.level": true,
+		}
+		isCompleted = func(e *levelindex.Entry) bool {
+			return completed[e.Filename]
+		}
+	)
+
+	var tests = []struct {
+		Query  levelindex.Query
+		Expect []string
+	}{
+		{
+			Query:  levelindex.Query{},
+			Expect: []string{"Azulian Tag", "Castle", "Tutorial"},
+		},
+		{
+			Query:  levelindex.Query{SortBy: levelindex.SortAuthor},
+			Expect: []string{"Tutorial", "Azulian Tag", "Castle"},
+		},
+		{
+			Query:  levelindex.Query{SortBy: levelindex.SortDifficulty},
+			Expect: []string{"Tutorial", "Azulian Tag", "Castle"},
+		},
+		{
+			Query:  levelindex.Query{SortBy: levelindex.SortModified},
+			Expect: []string{"Tutorial", "Azulian Tag", "Castle"},
+		},
+		{
+			Query:  levelindex.Query{Search: "LEARN"},
+			Expect: []string{"Tutorial"},
+		},
+		{
+			Query:  levelindex.Query{Search: "puzzle"},
+			Expect: []string{"Azulian Tag", "Castle"},
+		},
+		{
+			Query:  levelindex.Query{Tag: "Puzzle", Difficulty: &hard},
+			Expect: []string{"Castle"},
+		},
+		{
+			Query: levelindex.Query{
+				Completion:  levelindex.NotCompleted,
+				IsCompleted: isCompleted,
+			},
+			Expect: []string{"Azulian Tag", "Castle"},
+		},
+		{
+			Query: levelindex.Query{
+				Completion:  levelindex.Completed,
+				IsCompleted: isCompleted,
+			},
+			Expect: []string{"Tutorial"},
+		},
+	}
+
+	for i, test := range tests {
+		var titles = []string{}
+		for _, entry := range test.Query.Filter(entries) {
+			titles = append(titles, entry.Title)
+		}
+
+		if strings.Join(titles, ",") != strings.Join(test.Expect, ",") {
+			t.Errorf("Test %d: expected %v, got %v", i, test.Expect, titles)
+		}
+	}
+
+	if tags := strings.Join(levelindex.Tags(entries), ","); tags != "long,puzzle,short" {
+		t.Errorf("Unexpected Tags(): %s", tags)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"git.kirsle.net/SketchyMaze/doodle/assets"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/achievements"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/enum"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/filesystem"
@@ -38,6 +39,9 @@ type LevelPack struct {
 	// 0 = all levels unlocked
 	FreeLevels int `json:"freeLevels"`
 
+	// Achievements for the players of this levelpack.
+	Achievements []achievements.Definition `json:"achievements,omitempty"`
+
 	// The loaded zip file for reading an existing levelpack.
 	Zipfile *zip.Reader `json:"-"`
 
@@ -57,6 +61,26 @@ type Level struct {
 	Filename string `json:"filename"`
 }
 
+// AchievementBook returns the achievements of the levelpack, or nil if it
+// has none.
+func (l LevelPack) AchievementBook() *achievements.Book {
+	if len(l.Achievements) == 0 {
+		return nil
+	}
+
+	var book = &achievements.Book{
+		Pack:        l.Filename,
+		Title:       l.Title,
+		Definitions: l.Achievements,
+	}
+	for _, lvl := range l.Levels {
+		if lvl.UUID != "" {
+			book.Levels = append(book.Levels, lvl.UUID)
+		}
+	}
+	return book
+}
+
 // LoadFile reads a .levelpack zip file.
 func LoadFile(filename string) (*LevelPack, error) {
 	var (
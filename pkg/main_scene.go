@@ -16,8 +16,10 @@ import (
 	"git.kirsle.net/SketchyMaze/doodle/pkg/savegame"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/scripting"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/sound"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/uix"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/updater"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/usercfg"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/windows"
 	"git.kirsle.net/go/render"
 	"git.kirsle.net/go/render/event"
@@ -42,11 +44,16 @@ type MainScene struct {
 	frame          *ui.Frame // Main button frame
 	winRegister    *ui.Window
 	winSettings    *ui.Window
+	winProfiles    *ui.Window
+	winAchievement *ui.Window
 	winLevelPacks  *ui.Window
 	winPlayLevel   *ui.Window
 	winOpenDrawing *ui.Window
 	winAbout       *ui.Window
 
+	// The Play a Level browser, looped while its window is open.
+	levelBrowser *windows.LevelBrowser
+
 	// Update check variables.
 	updateButton *ui.Button
 	updateInfo   updater.VersionInfo
@@ -223,6 +230,27 @@ func (s *MainScene) Setup(d *Doodle) error {
 				s.winSettings.Show()
 			},
 		},
+		{
+			Name: "Profiles",
+			Func: func() {
+				if s.winProfiles == nil {
+					s.makeProfilesWindow(d)
+				}
+				s.winProfiles.Show()
+			},
+		},
+		{
+			Name: "Achievements",
+			Func: func() {
+				if s.winAchievement == nil {
+					s.winAchievement = windows.MakeAchievementsWindow(windows.Achievements{
+						Supervisor: s.Supervisor,
+						Engine:     d.Engine,
+					})
+				}
+				s.winAchievement.Show()
+			},
+		},
 		{
 			Name: "Register",
 			If: func() bool {
@@ -325,6 +353,31 @@ func (s *MainScene) Setup(d *Doodle) error {
 	return nil
 }
 
+// makeProfilesWindow (re)makes the Save Profiles window.
+func (s *MainScene) makeProfilesWindow(d *Doodle) {
+	s.winProfiles = windows.MakeProfilesWindow(windows.Profiles{
+		Supervisor: s.Supervisor,
+		Engine:     d.Engine,
+		OnSwitch: func() {
+			// Close the windows with the old profile's progress and settings.
+			for _, win := range []**ui.Window{&s.winSettings, &s.winLevelPacks, &s.winPlayLevel, &s.winAchievement} {
+				if *win != nil {
+					(*win).Hide()
+					(*win).Destroy()
+					*win = nil
+				}
+			}
+			s.levelBrowser = nil
+			sound.SetVolume(usercfg.Current.MusicVolume, usercfg.Current.SoundVolume, usercfg.Current.MuteAudio)
+		},
+		OnChange: func() {
+			s.winProfiles.Hide()
+			s.makeProfilesWindow(d)
+			s.winProfiles.Show()
+		},
+	})
+}
+
 // common function to show the "Open Drawing" window for the Play Level/Edit Drawing buttons.
 func (s *MainScene) showOpenDrawing(d *Doodle, forPlay bool) {
 	// Find or create the relevant window.
@@ -332,18 +385,19 @@ func (s *MainScene) showOpenDrawing(d *Doodle, forPlay bool) {
 	if forPlay {
 		window = s.winPlayLevel
 		if window == nil {
-			window = windows.NewOpenDrawingWindow(windows.OpenDrawing{
+			s.levelBrowser = &windows.LevelBrowser{
 				Supervisor: s.Supervisor,
 				Engine:     shmem.CurrentRenderEngine,
-				LevelsOnly: true,
-				OnOpenDrawing: func(filename string) {
+				OnOpenLevel: func(filename string) {
 					d.PlayLevel(filename)
 				},
 				OnCloseWindow: func() {
 					s.winPlayLevel.Destroy()
 					s.winPlayLevel = nil
+					s.levelBrowser = nil
 				},
-			})
+			}
+			window = windows.NewLevelBrowserWindow(s.levelBrowser)
 			s.winPlayLevel = window
 		}
 	} else {
@@ -506,6 +560,10 @@ func (s *MainScene) Loop(d *Doodle, ev *event.State) error {
 
 	s.canvas.Loop(ev)
 
+	if s.levelBrowser != nil {
+		s.levelBrowser.Loop()
+	}
+
 	if ev.WindowResized {
 		s.Resized(d.width, d.height)
 	}
@@ -0,0 +1,104 @@
+package modal
+
+import (
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/go/ui"
+)
+
+// Dialog pops up a speech or dialog box with a row of choices for the player,
+// e.g. for a doodad script to talk to the player.
+//
+// With no choices, a single "Ok" button is shown. The Enter key picks the
+// first choice. The dialog can not be cancelled with the Escape key: the
+// player must pick an answer.
+func Dialog(speaker, message string, choices ...string) *Modal {
+	if !ready {
+		panic("modal.Dialog(): not ready")
+	} else if current != nil {
+		current.Dismiss(false)
+	}
+
+	if len(choices) == 0 {
+		choices = []string{"Ok"}
+	}
+
+	// Reset the supervisor.
+	supervisor = ui.NewSupervisor()
+
+	m := &Modal{
+		title:   speaker,
+		message: message,
+		choice:  choices[0],
+	}
+	m.window = makeDialog(m, choices)
+
+	center(m.window)
+	current = m
+
+	return m
+}
+
+// ThenChoice calls a function with the player's answer to a Dialog.
+func (m *Modal) ThenChoice(f func(choice string)) *Modal {
+	m.callback = func() {
+		f(m.choice)
+	}
+	return m
+}
+
+// makeDialog creates the ui.Window for the Dialog modal.
+func makeDialog(m *Modal, choices []string) *ui.Window {
+	win := ui.NewWindow("Dialog")
+	_, title := win.TitleBar()
+	title.TextVariable = &m.title
+
+	msgFrame := ui.NewFrame("Dialog Message")
+	win.Pack(msgFrame, ui.Pack{
+		Side: ui.N,
+	})
+
+	msg := ui.NewLabel(ui.Label{
+		TextVariable: &m.message,
+		Font:         balance.UIFont,
+	})
+	msgFrame.Pack(msg, ui.Pack{
+		Side: ui.N,
+	})
+
+	// Choice button bar.
+	btnBar := ui.NewFrame("Button Bar")
+	msgFrame.Pack(btnBar, ui.Pack{
+		Side: ui.N,
+		PadY: 4,
+	})
+
+	for i, choice := range choices {
+		choice := choice
+		button := ui.NewButton(choice+"Button", ui.NewLabel(ui.Label{
+			Text: choice,
+			Font: balance.MenuFont,
+		}))
+		button.Handle(ui.Click, func(ev ui.EventData) error {
+			m.choice = choice
+			m.Dismiss(true)
+			return nil
+		})
+		button.Compute(engine)
+		supervisor.Add(button)
+
+		// The first choice is primary.
+		if i == 0 {
+			button.SetStyle(&balance.ButtonPrimary)
+		}
+
+		btnBar.Pack(button, ui.Pack{
+			Side: ui.W,
+			PadX: 2,
+		})
+	}
+
+	win.Compute(engine)
+	win.Supervise(supervisor)
+
+	return win
+}
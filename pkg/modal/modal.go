@@ -126,6 +126,7 @@ type Modal struct {
 	cancelable bool   // Escape key can cancel the modal
 	force      bool   // Enter key can not close the modal (e.g. Wait)
 	teardown   func() // Optional teardown logic a modal can attach.
+	choice     string // The answer picked in a Dialog.
 }
 
 // WithTitle sets the title of the modal.
@@ -27,6 +27,15 @@ func CopyToClipboard(text string) error {
 	return errors.New("not supported")
 }
 
+// ControllerGUID returns the GUID of the game controller at a device index,
+// or blank if there is no such controller.
+func ControllerGUID(index int) string {
+	if index < 0 || index >= sdl2.NumJoysticks() {
+		return ""
+	}
+	return sdl2.JoystickGetGUIDString(sdl2.JoystickGetDeviceGUID(index))
+}
+
 // CountTextures returns the count of loaded SDL2 textures, for the F3 debug overlay, or "n/a"
 func CountTextures(e render.Engine) string {
 	var texCount = "n/a"
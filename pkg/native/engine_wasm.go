@@ -18,6 +18,10 @@ func CopyToClipboard(text string) error {
 	return errors.New("not supported on WASM")
 }
 
+func ControllerGUID(index int) string {
+	return ""
+}
+
 func CountTextures(e render.Engine) string {
 	return "n/a"
 }
@@ -0,0 +1,179 @@
+package doodle
+
+import (
+	"time"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/achievements"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/savegame"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/uix"
+	"git.kirsle.net/go/ui"
+)
+
+// setupAchievements loads the achievements in play, the player's progress
+// towards them and the toast that announces their unlocks.
+func (s *PlayScene) setupAchievements() {
+	// The progress is kept in memory during play and written to the savegame
+	// when an achievement unlocks or the level ends.
+	save, err := savegame.GetOrCreate()
+	if err != nil {
+		log.Warn("Load savegame file: %s", err)
+	}
+	s.achievementProgress = save.Achievements
+
+	if book, err := achievements.Builtin(); err != nil {
+		log.Error("Couldn't load the built-in achievements: %s", err)
+	} else {
+		s.achievementBooks = append(s.achievementBooks, book)
+	}
+	if s.LevelPack != nil {
+		if book := s.LevelPack.AchievementBook(); book != nil {
+			s.achievementBooks = append(s.achievementBooks, book)
+		}
+	}
+
+	// Scripts unlock achievements, and the player collects items.
+	s.scripting.OnUnlockAchievement(s.UnlockAchievement)
+	s.drawing.OnAddItem = func(a *uix.Actor, itemName string, quantity int) {
+		if a.IsPlayer() {
+			s.achievementEvent(achievements.Event{
+				Name:     achievements.EventItemCollected,
+				Level:    s.Level.UUID,
+				Item:     itemName,
+				Quantity: quantity,
+			})
+		}
+	}
+
+	// The toast.
+	s.toastFrame = ui.NewFrame("Achievement Toast")
+	s.toastFrame.Configure(ui.Config{
+		BorderStyle: ui.BorderRaised,
+		BorderSize:  2,
+		Background:  balance.AchievementToastBackground,
+	})
+	header := ui.NewLabel(ui.Label{
+		Text: "Achievement Unlocked!",
+		Font: balance.LabelFont,
+	})
+	s.toastFrame.Pack(header, ui.Pack{
+		Side: ui.N,
+		PadX: 8,
+		PadY: 2,
+	})
+	label := ui.NewLabel(ui.Label{
+		TextVariable: &s.toastText,
+		Font:         balance.UIFont,
+	})
+	s.toastFrame.Pack(label, ui.Pack{
+		Side: ui.N,
+		PadX: 8,
+		PadY: 2,
+	})
+	s.screen.Place(s.toastFrame, ui.Place{
+		Top:    40,
+		Center: true,
+	})
+	s.toastFrame.Hide()
+}
+
+// achievementEvent records game events towards the player's achievements,
+// and shows the ones that were unlocked.
+func (s *PlayScene) achievementEvent(events ...achievements.Event) {
+	if !s.canUnlockAchievements() {
+		return
+	}
+
+	for _, ev := range events {
+		s.achievementProgress.Record(ev)
+	}
+	s.achievementsModified = true
+
+	unlocked := s.achievementProgress.Check(s.achievementBooks...)
+	if len(unlocked) > 0 {
+		s.saveAchievements()
+	}
+	for _, def := range unlocked {
+		s.toastAchievement(def)
+	}
+}
+
+// UnlockAchievement unlocks an achievement by ID: of the levelpack being
+// played, or else a built-in one. Called by doodad scripts.
+func (s *PlayScene) UnlockAchievement(id string) {
+	if !s.canUnlockAchievements() {
+		return
+	}
+
+	// Look in the levelpack's book first.
+	for i := len(s.achievementBooks) - 1; i >= 0; i-- {
+		book := s.achievementBooks[i]
+		def, ok := book.Get(id)
+		if !ok {
+			continue
+		}
+
+		if !s.achievementProgress.Unlock(book.Key(id)) {
+			return
+		}
+		s.achievementsModified = true
+		s.saveAchievements()
+		s.toastAchievement(def)
+		return
+	}
+
+	log.Error("Achievements.Unlock(%s): no achievement with that ID", id)
+}
+
+// saveAchievements writes the player's achievement progress to the savegame,
+// if it has changed since it was last saved.
+func (s *PlayScene) saveAchievements() {
+	if !s.achievementsModified {
+		return
+	}
+
+	save, err := savegame.GetOrCreate()
+	if err != nil {
+		log.Warn("Load savegame file: %s", err)
+	}
+	save.Achievements = s.achievementProgress
+	if err := save.Save(); err != nil {
+		log.Error("Couldn't save game: %s", err)
+		return
+	}
+	s.achievementsModified = false
+}
+
+// canUnlockAchievements checks the player is earning achievements: not if
+// they have cheated, or are playtesting a level from the editor.
+func (s *PlayScene) canUnlockAchievements() bool {
+	return !s.cheated && !s.CanEdit
+}
+
+// toastAchievement queues the toast of an unlocked achievement.
+func (s *PlayScene) toastAchievement(def achievements.Definition) {
+	log.Info("Achievement unlocked: %s", def.Title)
+	s.toastQueue = append(s.toastQueue, def)
+}
+
+// loopAchievementToast shows the queued toasts in turn.
+func (s *PlayScene) loopAchievementToast() {
+	if time.Now().Before(s.toastUntil) {
+		return
+	}
+
+	if len(s.toastQueue) == 0 {
+		if !s.toastFrame.Hidden() {
+			s.toastFrame.Hide()
+		}
+		return
+	}
+
+	var def = s.toastQueue[0]
+	s.toastQueue = s.toastQueue[1:]
+	s.toastText = def.Title + ": " + def.Description
+	s.toastUntil = time.Now().Add(balance.AchievementToastTime)
+	s.toastFrame.Show()
+	s.screen.Compute(s.d.Engine)
+}
@@ -5,7 +5,9 @@ import (
 
 	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/doodads"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/keybind"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/uix"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/usercfg"
 	"git.kirsle.net/go/render"
 	"git.kirsle.net/go/ui"
 )
@@ -115,8 +117,8 @@ func (s *PlayScene) computeInventory() {
 	})
 	s.invenFrame.Compute(s.d.Engine)
 
-	// If we removed all items, hide the frame.
-	if len(items) == 0 {
+	// If we removed all items (or the player hid them), hide the frame.
+	if len(items) == 0 || s.invenHidden {
 		s.invenFrame.Hide()
 	} else {
 		s.invenFrame.Show()
@@ -128,3 +130,11 @@ func (s *PlayScene) computeInventory() {
 	// Compute the inventory frame so it positions and wraps the items.
 	s.screen.Compute(s.d.Engine)
 }
+
+// toggleInventory shows or hides the inventory HUD by the Inventory key.
+func (s *PlayScene) toggleInventory() {
+	s.invenHidden = !s.invenHidden
+	if s.invenHidden {
+		s.d.Flash("Inventory hidden. Press %s to show it again.", keybind.Shortcut(usercfg.ActionInventory))
+	}
+}
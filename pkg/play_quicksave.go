@@ -0,0 +1,173 @@
+package doodle
+
+import (
+	"errors"
+	"time"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/keybind"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/quicksave"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/savegame"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/uix"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/usercfg"
+)
+
+// Quicksave saves the state of the level in progress to the active save
+// profile, replacing its last quicksave.
+func (s *PlayScene) Quicksave() {
+	if !s.canQuicksave() {
+		return
+	}
+
+	// A quickload starts the level fresh, which can't bring back the actors
+	// that the scripts created (Actors.New) along the way.
+	for _, actor := range s.drawing.Actors() {
+		if actor == s.Player || actor.IsDestroyed() {
+			continue
+		}
+		if _, ok := s.Level.Actors[actor.ID()]; !ok {
+			s.d.FlashError("Can't quicksave right now: doodads that were created during the level can't be saved.")
+			return
+		}
+	}
+
+	dir, err := quicksaveDirectory()
+	if err != nil {
+		s.d.FlashError("Couldn't quicksave: %s", err)
+		return
+	}
+
+	var snap = &quicksave.Snapshot{
+		UUID:              s.Level.UUID,
+		Title:             s.Level.Title,
+		Created:           time.Now(),
+		Elapsed:           time.Since(s.startTime),
+		Perfect:           s.perfectRun,
+		Cheated:           s.cheated,
+		Quickloaded:       s.quickloaded,
+		Player:            s.Player.Actor.Filename,
+		Checkpoint:        s.lastCheckpoint,
+		CheckpointStorage: s.checkpointStorage,
+		Actors:            []uix.ActorState{},
+		Storage:           s.scripting.SnapshotStorage(),
+		Timers:            s.scripting.SnapshotTimers(),
+		HUD:               s.hud.snapshot(),
+	}
+	for _, actor := range s.drawing.Actors() {
+		if !actor.IsDestroyed() {
+			snap.Actors = append(snap.Actors, actor.State())
+		}
+	}
+
+	if err := quicksave.Save(dir, snap); err != nil {
+		s.d.FlashError("Couldn't quicksave: %s", err)
+		return
+	}
+	s.d.Flash("Quicksaved! Press %s to quickload.", keybind.Shortcut(usercfg.ActionQuickload))
+}
+
+// Quickload restarts the level from its quicksave. A level that was
+// quickloaded is not a perfect run.
+func (s *PlayScene) Quickload() {
+	if !s.canQuicksave() {
+		return
+	}
+
+	dir, err := quicksaveDirectory()
+	if err != nil {
+		s.d.FlashError("Couldn't quickload: %s", err)
+		return
+	}
+
+	snap, err := quicksave.Load(dir, s.Level.UUID)
+	if err != nil {
+		if errors.Is(err, quicksave.ErrNoQuicksave) {
+			s.d.FlashError("There is no quicksave of this level. Press %s to quicksave.",
+				keybind.Shortcut(usercfg.ActionQuicksave),
+			)
+		} else {
+			s.d.FlashError("Couldn't quickload: %s", err)
+		}
+		return
+	}
+
+	log.Info("Quickload level %s from %s", snap.UUID, snap.Created)
+	s.d.Goto(&PlayScene{
+		LevelPack: s.LevelPack,
+		Filename:  s.Filename,
+		Level:     s.Level,
+		CanEdit:   s.CanEdit,
+		HasNext:   s.HasNext,
+		Quickload: snap,
+	})
+}
+
+// canQuicksave checks the level allows quicksaves, with a message if not.
+func (s *PlayScene) canQuicksave() bool {
+	if s.Level.GameRule.NoQuicksave {
+		s.d.FlashError("Quicksaves are not allowed in this level.")
+		return false
+	} else if s.Level.UUID == "" {
+		s.d.FlashError("This level can't be quicksaved until it is saved from the editor.")
+		return false
+	}
+	return true
+}
+
+/*
+restoreQuicksave puts the level back the way it was at a quicksave.
+
+It is called at the end of setupAsync, after the actors' main() functions
+have run and set up their event handlers and timers.
+*/
+func (s *PlayScene) restoreQuicksave(snap *quicksave.Snapshot) {
+	// The player may have become another character during the level.
+	if snap.Player != "" && snap.Player != s.Player.Actor.Filename {
+		s.SetPlayerCharacter(snap.Player)
+	}
+
+	// Actors that were destroyed by the quicksave are removed again.
+	for _, actor := range s.drawing.Actors() {
+		state, ok := snap.Actor(actor.ID())
+		if !ok {
+			actor.Destroy()
+			continue
+		}
+
+		if err := actor.RestoreState(state); err != nil {
+			log.Error("Quickload: restore actor %s: %s", actor.ID(), err)
+		}
+	}
+
+	// Scripts.
+	s.scripting.RestoreStorage(snap.Storage)
+	s.scripting.RestoreTimers(snap.Timers)
+	s.hud.restore(snap.HUD)
+
+	s.lastCheckpoint = snap.Checkpoint
+	if snap.CheckpointStorage != nil {
+		s.checkpointStorage = snap.CheckpointStorage
+	}
+
+	// Score variables: the level timer resumes, and the run isn't perfect.
+	s.startTime = time.Now().Add(-snap.Elapsed)
+	s.quickloaded = true
+	if snap.Cheated {
+		s.SetCheated()
+	}
+	s.SetImperfect()
+
+	s.drawing.ResetCamera()
+	s.drawing.FollowActor = s.Player.ID()
+	s.d.Flash("Quickloaded the level from %s.", snap.Created.Format("Jan 2 3:04 PM"))
+}
+
+// quicksaveDirectory returns the folder of the active save profile's
+// quicksaves.
+func quicksaveDirectory() (string, error) {
+	profiles, err := savegame.LoadProfiles()
+	if err != nil {
+		return "", err
+	}
+	return profiles.Current().QuicksaveDirectory(), nil
+}
@@ -5,6 +5,8 @@ import (
 	"strings"
 	"time"
 
+	"git.kirsle.net/SketchyMaze/doodle/pkg/achievements"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/analytics"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/collision"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/cursor"
@@ -19,9 +21,11 @@ import (
 	"git.kirsle.net/SketchyMaze/doodle/pkg/physics"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/plus"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/plus/dpp"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/quicksave"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/savegame"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/scripting"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/sound"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/sprites"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/uix"
 	"git.kirsle.net/go/render"
@@ -39,6 +43,9 @@ type PlayScene struct {
 	RememberScrollPosition render.Point // for the Editor quality of life
 	SpawnPoint             render.Point // if not zero, overrides Start Flag
 
+	// A quicksave to restore once the level has started.
+	Quickload *quicksave.Snapshot
+
 	// If this level was part of a levelpack. The Play Scene will read it
 	// from the levelpack ZIP file in priority over any other location.
 	LevelPack *levelpack.LevelPack
@@ -56,6 +63,24 @@ type PlayScene struct {
 	perfectRun bool      // set false on first respawn
 	cheated    bool      // user has entered a cheat code while playing
 
+	// The level was quickloaded, so it is not eligible for a perfect score.
+	// Impl. in play_quicksave.go
+	quickloaded bool
+
+	// Playtest analytics of this session: where the player goes and dies.
+	analytics *analytics.Session
+
+	// Achievements in play: the built-in ones and the levelpack's, the
+	// player's progress, and the toast of the unlocked ones.
+	// Impl. in play_achievements.go
+	achievementBooks     []*achievements.Book
+	achievementProgress  *achievements.Progress
+	achievementsModified bool
+	toastFrame           *ui.Frame
+	toastText            string
+	toastUntil           time.Time
+	toastQueue           []achievements.Definition
+
 	// UI widgets.
 	Supervisor    *ui.Supervisor
 	screen        *ui.Frame // A window sized invisible frame to position UI elements.
@@ -86,10 +111,17 @@ type PlayScene struct {
 	jumpCooldownUntil     uint64    // future game tick for jump cooldown (swimming esp.)
 	mustFollowPlayerUntil uint64    // first frames where anvils don't take focus from player
 
+	// Script Storage at the last checkpoint, restored on respawn.
+	checkpointStorage *scripting.StorageSnapshot
+
+	// Script-driven HUD. Impl. in play_scene_hud.go
+	hud *scriptHUD
+
 	// Inventory HUD. Impl. in play_inventory.go
 	invenFrame   *ui.Frame
 	invenItems   []string // item list
 	invenDoodads map[string]*uix.Canvas
+	invenHidden  bool // hidden by the Inventory key
 
 	// Cheats window
 	cheatsWindow *ui.Window
@@ -118,6 +150,14 @@ func (s *PlayScene) Setup(d *Doodle) error {
 	s.scripting = scripting.NewSupervisor()
 	s.Supervisor = ui.NewSupervisor()
 
+	// The script debugger halts the game at a breakpoint. The level timer
+	// doesn't count the time spent there.
+	s.scripting.Debugger.Hold = func() {
+		var since = time.Now()
+		d.holdAtBreakpoint(s.scripting.Debugger)
+		s.startTime = s.startTime.Add(time.Since(since))
+	}
+
 	// Show the loading screen.
 	loadscreen.ShowWithProgress()
 	go func() {
@@ -246,6 +286,9 @@ func (s *PlayScene) setupAsync(d *Doodle) error {
 	s.drawing.OnSetPlayerCharacter = s.SetPlayerCharacter
 	s.drawing.OnResetTimer = s.ResetTimer
 
+	// Set up the achievements and their toast.
+	s.setupAchievements()
+
 	// If this level game from a signed LevelPack, inform the canvas.
 	if s.LevelPack != nil && dpp.Driver.IsLevelPackSigned(s.LevelPack) {
 		s.drawing.IsSignedLevelPack = s.LevelPack
@@ -286,7 +329,17 @@ func (s *PlayScene) setupAsync(d *Doodle) error {
 		"by "+s.Level.Author,
 	)
 
+	// Load the levelpack's script storage from the savegame.
+	if s.LevelPack != nil {
+		if save, err := savegame.GetOrCreate(); err != nil {
+			log.Warn("Load savegame file: %s", err)
+		} else {
+			s.scripting.SetPackStorage(save.GetStorage(s.LevelPack.Filename))
+		}
+	}
+
 	// Load all actor scripts.
+	s.setupScriptHUD()
 	s.drawing.SetScriptSupervisor(s.scripting)
 	if err := s.scripting.InstallScripts(s.Level); err != nil {
 		log.Error("PlayScene.Setup: failed to InstallScripts: %s", err)
@@ -317,9 +370,22 @@ func (s *PlayScene) setupAsync(d *Doodle) error {
 		log.Error("PlayScene.Setup: failed to drawing.InstallScripts: %s", err)
 	}
 
+	// The Start Flag checkpoint remembers the script storage as the
+	// actors' main() functions have left it.
+	s.checkpointStorage = s.scripting.SnapshotStorage()
+
+	// Start the level's background music.
+	s.drawing.PlayLevelMusic()
+
 	s.startTime = time.Now()
 	s.perfectRun = true
 	s.running = true
+	s.analytics = analytics.NewSession(s.Level.UUID, s.Level.Title)
+
+	// Loading a quicksave?
+	if s.Quickload != nil {
+		s.restoreQuicksave(s.Quickload)
+	}
 
 	// // Cap the canvas size in case the user has an ultra HD monitor that's bigger
 	// // than a bounded level's limits.
@@ -413,9 +479,7 @@ func (s *PlayScene) SetPlayerCharacter(filename string) {
 	}
 
 	// Restore their inventory.
-	for item, qty := range inventory {
-		s.Player.AddItem(item, qty)
-	}
+	s.Player.RestoreInventory(inventory)
 }
 
 // ResetTimer sets the level elapsed timer back to zero.
@@ -584,6 +648,8 @@ func (s *PlayScene) RestartLevel() {
 // SetCheckpoint sets the player's checkpoint.
 func (s *PlayScene) SetCheckpoint(where render.Point) {
 	s.lastCheckpoint = where
+	s.checkpointStorage = s.scripting.SnapshotStorage()
+	s.analytics.Checkpoint(where)
 }
 
 // RetryCheckpoint moves the player back to their last checkpoint.
@@ -593,12 +659,34 @@ func (s *PlayScene) RetryCheckpoint() {
 
 	log.Info("Move player back to last checkpoint")
 	s.Player.MoveTo(s.lastCheckpoint)
+	s.scripting.RestoreStorage(s.checkpointStorage)
+	s.drawing.ResetCamera()
+	s.drawing.FollowActor = s.Player.ID()
 	s.running = true
 }
 
 // BeatLevel handles the level success condition.
 func (s *PlayScene) BeatLevel() {
+	s.analytics.Complete(time.Since(s.startTime), s.perfectRun, s.cheated)
 	s.d.Flash("Hurray!")
+
+	// Achievements for beating the level, and doing so without dying.
+	var events = []achievements.Event{
+		{Name: achievements.EventLevelCompleted, Level: s.Level.UUID},
+	}
+	if s.perfectRun {
+		events = append(events, achievements.Event{Name: achievements.EventNoDeath, Level: s.Level.UUID})
+	}
+	s.achievementEvent(events...)
+	s.saveAchievements()
+
+	// The level's quicksave is used up.
+	if dir, err := quicksaveDirectory(); err == nil {
+		if err := quicksave.Delete(dir, s.Level.UUID); err != nil {
+			log.Error("Couldn't delete the quicksave: %s", err)
+		}
+	}
+
 	s.ShowEndLevelModal(
 		true,
 		"Level Completed",
@@ -613,9 +701,15 @@ If the Survival GameRule is set, this ends the level with a note on how long the
 player had survived for and they get a silver rating.
 */
 func (s *PlayScene) FailLevel(message string) {
+	s.failLevel(message, message)
+}
+
+// failLevel is FailLevel with the cause of death for the analytics.
+func (s *PlayScene) failLevel(message, cause string) {
 	if s.Player.Invulnerable() || s.godMode || s.godModeUntil.After(time.Now()) {
 		return
 	}
+	s.analytics.Death(s.playerCenter(), cause, time.Since(s.startTime))
 	s.SetImperfect()
 	s.d.FlashError(message)
 
@@ -641,7 +735,16 @@ func (s *PlayScene) FailLevel(message string) {
 
 // DieByFire ends the level by "fire", or w/e the swatch is named.
 func (s *PlayScene) DieByFire(name string) {
-	s.FailLevel(fmt.Sprintf("Watch out for %s!", name))
+	s.failLevel(fmt.Sprintf("Watch out for %s!", name), name)
+}
+
+// playerCenter returns the world position of the center of the player.
+func (s *PlayScene) playerCenter() render.Point {
+	var (
+		pos  = s.Player.Position()
+		size = s.Player.Size()
+	)
+	return render.NewPoint(pos.X+size.W/2, pos.Y+size.H/2)
 }
 
 // SetImperfect sets the perfectRun flag to false and changes the icon for the timer.
@@ -743,6 +846,9 @@ func (s *PlayScene) ShowEndLevelModal(success bool, title, message string) {
 				save.MarkCompleted(s.LevelPack.Filename, s.Filename, s.Level.UUID)
 			}
 
+			// Carry the scripts' levelpack storage on to the next level.
+			save.SetStorage(s.LevelPack.Filename, s.scripting.PackStorage().Export())
+
 			// Save the player's scores file.
 			if err = save.Save(); err != nil {
 				log.Error("Couldn't save game: %s", err)
@@ -762,6 +868,17 @@ func (s *PlayScene) ShowEndLevelModal(success bool, title, message string) {
 					}
 				}
 			}
+		} else if s.Level.UUID != "" {
+			// A standalone level: remember it was completed, for the level browser.
+			save, err := savegame.GetOrCreate()
+			if err != nil {
+				log.Warn("Load savegame file: %s", err)
+			}
+
+			save.MarkLevelCompleted(s.Level.UUID)
+			if err = save.Save(); err != nil {
+				log.Error("Couldn't save game: %s", err)
+			}
 		}
 	}
 
@@ -773,6 +890,11 @@ func (s *PlayScene) ShowEndLevelModal(success bool, title, message string) {
 		config.OnNextLevel = nil
 	}
 
+	// Record the playtest analytics so far.
+	if err := s.analytics.Save(); err != nil {
+		log.Error("Couldn't save analytics: %s", err)
+	}
+
 	// Show the modal.
 	modal.EndLevel(config, title, message)
 
@@ -800,6 +922,7 @@ func (s *PlayScene) Loop(d *Doodle, ev *event.State) error {
 
 	// Update the timer.
 	s.timerLabel.Text = savegame.FormatDuration(time.Since(s.startTime))
+	s.loopAchievementToast()
 
 	s.Supervisor.Loop(ev)
 
@@ -822,10 +945,24 @@ func (s *PlayScene) Loop(d *Doodle, ev *event.State) error {
 		if err := s.scripting.Loop(); err != nil {
 			log.Error("PlayScene.Loop: scripting.Loop: %s", err)
 		}
+		s.hud.Loop()
 
 		// Touch regions.
 		s.LoopTouchable(ev)
 
+		// Show or hide the inventory HUD.
+		if keybind.Inventory(ev) {
+			s.toggleInventory()
+		}
+
+		// Quicksave and quickload.
+		if keybind.Quicksave(ev) {
+			s.Quicksave()
+		} else if keybind.Quickload(ev) {
+			s.Quickload()
+			return nil
+		}
+
 		// Hide the mouse cursor if a gameplay input was received.
 		if keybind.Right(ev) || keybind.Left(ev) || keybind.Up(ev) || keybind.Down(ev) ||
 			keybind.Use(ev) {
@@ -836,6 +973,9 @@ func (s *PlayScene) Loop(d *Doodle, ev *event.State) error {
 		s.lastCursor = shmem.Cursor
 
 		s.movePlayer(ev)
+		if shmem.Tick%balance.AnalyticsSampleTicks == 0 {
+			s.analytics.Position(s.playerCenter())
+		}
 		if err := s.drawing.Loop(ev); err != nil {
 			log.Error("Drawing loop error: %s", err.Error())
 		}
@@ -966,6 +1106,18 @@ func (s *PlayScene) Destroy() error {
 	// their bitmaps cached and will regen the textures as needed.
 	s.drawing.Destroy()
 
+	// Stop the music and any looping sound effects.
+	s.drawing.StopSounds()
+	sound.StopMusic()
+
+	// Record the rest of the playtest analytics.
+	if err := s.analytics.Save(); err != nil {
+		log.Error("Couldn't save analytics: %s", err)
+	}
+
+	// Keep the achievement progress made on the level.
+	s.saveAchievements()
+
 	// Free inventory doodad textures.
 	for _, can := range s.invenDoodads {
 		log.Info("Destroy inventory doodad: %s", can)
@@ -0,0 +1,241 @@
+package doodle
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/modal"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/quicksave"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/scripting"
+	"git.kirsle.net/go/render"
+	"git.kirsle.net/go/ui"
+)
+
+// scriptHUD implements scripting.HUD for the Play Scene, so doodad scripts can
+// show labels, counters, timers and dialog boxes.
+type scriptHUD struct {
+	scene   *PlayScene
+	frames  map[string]*ui.Frame // screen anchor -> frame
+	widgets map[string]*hudWidget
+}
+
+// hudWidget is one line of text on the HUD.
+type hudWidget struct {
+	label  *ui.Label
+	anchor string
+	text   string
+
+	// Countdown timers.
+	timer     bool
+	prefix    string
+	remaining uint64 // ticks left
+	onExpire  func()
+}
+
+// Placement of the HUD anchors on screen, clear of the elapsed timer
+// (top-left), the inventory (top-right) and the Edit button (bottom-right).
+var hudAnchors = map[string]ui.Place{
+	scripting.AnchorTopLeft:     {Top: 80, Left: 40},
+	scripting.AnchorTopRight:    {Top: 100, Right: 40},
+	scripting.AnchorBottomLeft:  {Bottom: 40, Left: 40},
+	scripting.AnchorBottomRight: {Bottom: 60, Right: 40},
+}
+
+// setupScriptHUD attaches the HUD to the script supervisor.
+func (s *PlayScene) setupScriptHUD() {
+	s.hud = &scriptHUD{
+		scene:   s,
+		frames:  map[string]*ui.Frame{},
+		widgets: map[string]*hudWidget{},
+	}
+	s.scripting.SetHUD(s.hud)
+}
+
+// SetLabel shows a line of text on the HUD.
+func (h *scriptHUD) SetLabel(id, text, anchor string) {
+	w := h.widget(id, anchor)
+	w.timer = false
+	w.text = text
+	w.label.Show()
+	h.compute()
+}
+
+// SetCounter shows a "label: value" counter on the HUD.
+func (h *scriptHUD) SetCounter(id, label string, value int, anchor string) {
+	h.SetLabel(id, fmt.Sprintf("%s: %d", label, value), anchor)
+}
+
+// SetTimer shows a countdown timer on the HUD.
+func (h *scriptHUD) SetTimer(id, label string, milliseconds int, anchor string, onExpire func()) {
+	w := h.widget(id, anchor)
+	w.timer = true
+	w.prefix = label
+	w.remaining = uint64(float64(milliseconds) * (float64(balance.TargetFPS) / 1000))
+	w.onExpire = onExpire
+	w.text = formatCountdown(w.prefix, w.remaining)
+	w.label.Show()
+	h.compute()
+}
+
+// Remove a widget from the HUD.
+func (h *scriptHUD) Remove(id string) {
+	if w, ok := h.widgets[id]; ok {
+		w.timer = false
+		w.label.Hide()
+		h.compute()
+	}
+}
+
+// Dialog pops up a speech box. Gameplay is paused while a modal is up; the
+// level timer does not count the time the dialog was open.
+func (h *scriptHUD) Dialog(speaker, message string, choices []string, callback func(choice string)) {
+	var opened = time.Now()
+	modal.Dialog(speaker, message, choices...).ThenChoice(func(choice string) {
+		h.scene.startTime = h.scene.startTime.Add(time.Since(opened))
+		callback(choice)
+	})
+}
+
+// Loop counts down the HUD timers, while gameplay is running.
+func (h *scriptHUD) Loop() {
+	for _, w := range h.widgets {
+		if !w.timer {
+			continue
+		}
+
+		if w.remaining > 0 {
+			w.remaining--
+		}
+		w.text = formatCountdown(w.prefix, w.remaining)
+
+		if w.remaining == 0 {
+			w.timer = false
+			if w.onExpire != nil {
+				w.onExpire()
+			}
+		}
+	}
+}
+
+// snapshot returns the HUD widgets for a quicksave.
+func (h *scriptHUD) snapshot() []quicksave.Widget {
+	var result = []quicksave.Widget{}
+	for id, w := range h.widgets {
+		result = append(result, quicksave.Widget{
+			ID:        id,
+			Anchor:    w.anchor,
+			Text:      w.text,
+			Hidden:    w.label.Hidden(),
+			Timer:     w.timer,
+			Prefix:    w.prefix,
+			Remaining: w.remaining,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// restore the HUD widgets from a quicksave. Timers keep the onExpire function
+// their script gave them as the level started, and widgets that weren't on
+// the HUD at the quicksave are removed.
+func (h *scriptHUD) restore(widgets []quicksave.Widget) {
+	var saved = map[string]bool{}
+	for _, ws := range widgets {
+		saved[ws.ID] = true
+
+		w := h.widget(ws.ID, ws.Anchor)
+		w.text = ws.Text
+		w.timer = ws.Timer
+		w.prefix = ws.Prefix
+		w.remaining = ws.Remaining
+		if ws.Hidden {
+			w.label.Hide()
+		} else {
+			w.label.Show()
+		}
+	}
+
+	for id := range h.widgets {
+		if !saved[id] {
+			h.Remove(id)
+		}
+	}
+	h.compute()
+}
+
+// widget gets or creates a HUD widget by ID, moving it to the anchor.
+func (h *scriptHUD) widget(id, anchor string) *hudWidget {
+	if _, ok := hudAnchors[anchor]; !ok {
+		anchor = scripting.AnchorTopLeft
+	}
+
+	if w, ok := h.widgets[id]; ok && w.anchor == anchor {
+		return w
+	} else if ok {
+		// Moving anchors: hide the old label and make a new one.
+		w.label.Hide()
+	}
+
+	w := &hudWidget{
+		anchor: anchor,
+	}
+	w.label = ui.NewLabel(ui.Label{
+		TextVariable: &w.text,
+		Font:         balance.LabelFont,
+	})
+
+	side := ui.N
+	if anchor == scripting.AnchorBottomLeft || anchor == scripting.AnchorBottomRight {
+		side = ui.S
+	}
+	h.frame(anchor).Pack(w.label, ui.Pack{
+		Side: side,
+		PadY: 2,
+	})
+
+	h.widgets[id] = w
+	return w
+}
+
+// frame gets or creates the frame for a screen anchor.
+func (h *scriptHUD) frame(anchor string) *ui.Frame {
+	if frame, ok := h.frames[anchor]; ok {
+		return frame
+	}
+
+	frame := ui.NewFrame("Script HUD " + anchor)
+	frame.SetBackground(render.Invisible)
+	h.scene.screen.Place(frame, hudAnchors[anchor])
+	h.frames[anchor] = frame
+	return frame
+}
+
+// compute resizes the HUD frames after their labels change.
+func (h *scriptHUD) compute() {
+	for _, frame := range h.frames {
+		// Work around ui.Frame not shrinking; see computeInventory.
+		frame.Configure(ui.Config{
+			AutoResize: true,
+			Width:      1,
+			Height:     1,
+		})
+		frame.Compute(h.scene.d.Engine)
+	}
+	h.scene.screen.Compute(h.scene.d.Engine)
+}
+
+// formatCountdown formats a HUD timer in M:SS format.
+func formatCountdown(label string, ticks uint64) string {
+	var (
+		seconds = int(ticks) / balance.TargetFPS
+		text    = fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+	)
+	if label != "" {
+		return label + ": " + text
+	}
+	return text
+}
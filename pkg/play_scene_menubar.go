@@ -1,6 +1,7 @@
 package doodle
 
 import (
+	"git.kirsle.net/SketchyMaze/doodle/pkg/keybind"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/levelpack"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/usercfg"
@@ -65,7 +66,7 @@ func (u *PlayScene) setupMenuBar(d *Doodle) *ui.MenuBar {
 	gameMenu.AddItem("Quit to menu", func() {
 		d.Goto(&MainScene{})
 	})
-	gameMenu.AddItemAccel("Quit", "Escape", func() {
+	gameMenu.AddItemAccel("Quit", keybind.Shortcut(usercfg.ActionShutdown), func() {
 		d.ConfirmExit()
 	})
 
@@ -77,8 +78,10 @@ func (u *PlayScene) setupMenuBar(d *Doodle) *ui.MenuBar {
 		u.SetImperfect()
 		u.RetryCheckpoint()
 	})
+	levelMenu.AddItemAccel("Quicksave", keybind.Shortcut(usercfg.ActionQuicksave), u.Quicksave)
+	levelMenu.AddItemAccel("Quickload", keybind.Shortcut(usercfg.ActionQuickload), u.Quickload)
 	levelMenu.AddSeparator()
-	levelMenu.AddItemAccel("Edit level", "E", u.EditLevel)
+	levelMenu.AddItemAccel("Edit level", keybind.Shortcut(usercfg.ActionGotoEdit), u.EditLevel)
 
 	// Hilariously broken, someday!
 	if usercfg.Current.EnableFeatures {
@@ -0,0 +1,152 @@
+/*
+Package quicksave saves a snapshot of a level in progress, so the player can
+load it again instead of starting over from their last checkpoint.
+
+A Snapshot holds the runtime state of every actor in the level, the pending
+script timers and HUD widgets, and all of the script storage. Each save
+profile keeps one quicksave per level, keyed by the level UUID (see
+savegame.Profile.QuicksaveDirectory).
+
+The JavaScript functions of the doodad scripts can't be saved: a quicksave
+is loaded by starting the level fresh, so the scripts set up their handlers
+and timers again, and then putting everything back the way it was. For the
+same reason, a level can't be quicksaved while it has actors that the scripts
+created along the way.
+*/
+package quicksave
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/scripting"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/uix"
+	"git.kirsle.net/go/render"
+	"github.com/google/uuid"
+)
+
+// Snapshot of a level in progress.
+type Snapshot struct {
+	UUID    string        `json:"uuid"` // of the level
+	Title   string        `json:"title"`
+	Created time.Time     `json:"created"`
+	Elapsed time.Duration `json:"elapsed"` // level timer
+
+	// Score variables. A level that was quickloaded is never a perfect run.
+	Perfect     bool `json:"perfect"`
+	Cheated     bool `json:"cheated,omitempty"`
+	Quickloaded bool `json:"quickloaded,omitempty"`
+
+	// The player character and their last checkpoint.
+	Player            string                     `json:"player"` // doodad filename
+	Checkpoint        render.Point               `json:"checkpoint"`
+	CheckpointStorage *scripting.StorageSnapshot `json:"checkpointStorage,omitempty"`
+
+	// All of the actors in the level, including the player. Actors of the
+	// level that are missing had been destroyed.
+	Actors  []uix.ActorState                  `json:"actors"`
+	Storage *scripting.StorageSnapshot        `json:"storage"`
+	Timers  map[string][]scripting.TimerState `json:"timers,omitempty"` // by actor ID
+	HUD     []Widget                          `json:"hud,omitempty"`
+}
+
+// Widget is a label or countdown timer on the scripted HUD.
+type Widget struct {
+	ID        string `json:"id"`
+	Anchor    string `json:"anchor"`
+	Text      string `json:"text"`
+	Hidden    bool   `json:"hidden,omitempty"`
+	Timer     bool   `json:"timer,omitempty"`
+	Prefix    string `json:"prefix,omitempty"`    // timer label
+	Remaining uint64 `json:"remaining,omitempty"` // timer ticks left
+}
+
+// ErrNoQuicksave is returned by Load when there is no quicksave of the level.
+var ErrNoQuicksave = errors.New("there is no quicksave of this level")
+
+// Filename returns the path to the quicksave of a level in a folder.
+//
+// The UUID comes from the level file, so it must parse as a UUID and the
+// filename uses its canonical form: it can't point outside of the folder.
+func Filename(dir, levelUUID string) (string, error) {
+	id, err := uuid.Parse(levelUUID)
+	if err != nil {
+		return "", fmt.Errorf("the level has an invalid UUID %q", levelUUID)
+	}
+	return filepath.Join(dir, id.String()+".json"), nil
+}
+
+// Save a snapshot to the folder, replacing the quicksave of its level.
+func Save(dir string, snap *Snapshot) error {
+	if snap.UUID == "" {
+		return errors.New("the level has no UUID: save it from the editor first")
+	}
+
+	filename, err := Filename(dir, snap.UUID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// Load the quicksave of a level from the folder.
+func Load(dir, levelUUID string) (*Snapshot, error) {
+	if levelUUID == "" {
+		return nil, ErrNoQuicksave
+	}
+
+	filename, err := Filename(dir, levelUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNoQuicksave
+		}
+		return nil, err
+	}
+
+	var snap = &Snapshot{}
+	if err := json.Unmarshal(data, snap); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// Delete the quicksave of a level, e.g. when the player beats it.
+func Delete(dir, levelUUID string) error {
+	filename, err := Filename(dir, levelUUID)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Actor returns the saved state of an actor by its ID.
+func (s *Snapshot) Actor(id string) (uix.ActorState, bool) {
+	for _, actor := range s.Actors {
+		if actor.ID == id {
+			return actor, true
+		}
+	}
+	return uix.ActorState{}, false
+}
@@ -0,0 +1,123 @@
+package quicksave
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/physics"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/scripting"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/uix"
+	"git.kirsle.net/go/render"
+)
+
+func TestQuicksave(t *testing.T) {
+	var (
+		dir       = t.TempDir()
+		levelUUID = "0b6a5a3e-1c2d-4e5f-8a9b-0c1d2e3f4a5b"
+	)
+
+	// No quicksave yet.
+	if _, err := Load(dir, levelUUID); err != ErrNoQuicksave {
+		t.Errorf("expected ErrNoQuicksave but got: %v", err)
+	}
+
+	// A level needs a UUID to be quicksaved.
+	if err := Save(dir, &Snapshot{}); err == nil {
+		t.Errorf("expected an error saving a level with no UUID")
+	}
+
+	var snap = &Snapshot{
+		UUID:    levelUUID,
+		Elapsed: 90 * time.Second,
+		Player:  "boy.doodad",
+		Actors: []uix.ActorState{
+			{
+				ID:          "PLAYER",
+				Position:    render.NewPoint(100, 200),
+				Velocity:    physics.NewVector(1.5, -4),
+				ActiveLayer: 2,
+				Inventory:   map[string]int{"key-blue.doodad": 0, "gem.doodad": 3},
+			},
+			{
+				ID:             "door",
+				Animation:      "open",
+				AnimationFrame: 1,
+			},
+		},
+		Storage: &scripting.StorageSnapshot{
+			Level: map[string]interface{}{"switches": 2.0},
+		},
+		Timers: map[string][]scripting.TimerState{
+			"door": {{ID: 1, Ticks: 60, Remaining: 30}},
+		},
+		HUD: []Widget{
+			{ID: "countdown", Timer: true, Remaining: 120},
+		},
+	}
+	if err := Save(dir, snap); err != nil {
+		t.Fatalf("Save: %s", err)
+	}
+
+	loaded, err := Load(dir, levelUUID)
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+	if loaded.Elapsed != snap.Elapsed || loaded.Player != snap.Player {
+		t.Errorf("expected %+v but got %+v", snap, loaded)
+	}
+
+	player, ok := loaded.Actor("PLAYER")
+	if !ok {
+		t.Fatalf("expected the player in the quicksave")
+	}
+	if player.Position != render.NewPoint(100, 200) || player.Velocity.Y != -4 ||
+		player.ActiveLayer != 2 || player.Inventory["gem.doodad"] != 3 {
+		t.Errorf("player state didn't round trip: %+v", player)
+	}
+	if door, _ := loaded.Actor("door"); door.Animation != "open" || door.AnimationFrame != 1 {
+		t.Errorf("door animation didn't round trip: %+v", door)
+	}
+	if _, ok := loaded.Actor("missing"); ok {
+		t.Errorf("expected no state for a missing actor")
+	}
+
+	if loaded.Storage.Level["switches"] != 2.0 || loaded.Timers["door"][0].Remaining != 30 ||
+		loaded.HUD[0].Remaining != 120 {
+		t.Errorf("scripts didn't round trip: %+v", loaded)
+	}
+
+	// Delete it.
+	if err := Delete(dir, levelUUID); err != nil {
+		t.Errorf("Delete: %s", err)
+	}
+	if _, err := Load(dir, levelUUID); err != ErrNoQuicksave {
+		t.Errorf("expected the quicksave to be deleted but got: %v", err)
+	}
+	if err := Delete(dir, levelUUID); err != nil {
+		t.Errorf("deleting a missing quicksave should not be an error: %s", err)
+	}
+}
+
+func TestFilename(t *testing.T) {
+	var dir = t.TempDir()
+
+	// A level UUID that isn't a UUID can't name a file outside the folder.
+	for _, bad := range []string{"", "level-1", "../../settings", "/etc/passwd"} {
+		if _, err := Filename(dir, bad); err == nil {
+			t.Errorf("Filename(%q): expected an error", bad)
+		}
+		if err := Save(dir, &Snapshot{UUID: bad}); err == nil {
+			t.Errorf("Save(%q): expected an error", bad)
+		}
+	}
+
+	// Other forms of a UUID are saved under the canonical one.
+	filename, err := Filename(dir, "{0B6A5A3E-1C2D-4E5F-8A9B-0C1D2E3F4A5B}")
+	if err != nil {
+		t.Fatalf("Filename: %s", err)
+	}
+	if expect := filepath.Join(dir, "0b6a5a3e-1c2d-4e5f-8a9b-0c1d2e3f4a5b.json"); filename != expect {
+		t.Errorf("expected %s but got %s", expect, filename)
+	}
+}
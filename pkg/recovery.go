@@ -0,0 +1,65 @@
+package doodle
+
+import (
+	"time"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/autosave"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/branding"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/modal"
+)
+
+/*
+Crash recovery for the level editor.
+
+The autosave package keeps a lock file while the game is running. If the
+game didn't shut down cleanly last time, offerRecovery asks the user
+whether to restore the newest autosave snapshot. When the game panics,
+rescueDrawing tries to write a snapshot of the drawing being edited before
+the program exits.
+*/
+
+// offerRecovery asks the user to restore the newest autosave snapshot taken
+// during a session that did not shut down cleanly.
+func (d *Doodle) offerRecovery(since time.Time) {
+	snap, ok := autosave.Latest()
+	if !ok || snap.Time.Before(since.Truncate(time.Second)) {
+		return
+	}
+
+	modal.Confirm(
+		"%s did not shut down cleanly last time.\n\n"+
+			"Do you want to restore the autosave of %s\n"+
+			"from %s?",
+		branding.AppName,
+		snap.Drawing,
+		snap.Time.Format("Jan 2 2006 at 15:04:05"),
+	).WithTitle("Restore Autosave").Then(func() {
+		if err := d.EditDrawing(snap.Filename); err != nil {
+			d.FlashError("Couldn't restore the autosave: %s", err)
+		}
+	})
+}
+
+// rescueDrawing writes an autosave snapshot of the drawing being edited, if
+// any, when the game is crashing.
+func (d *Doodle) rescueDrawing() {
+	scene, ok := d.Scene.(*EditorScene)
+	if !ok {
+		return
+	}
+
+	// Don't let a second panic hide the first one.
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error("rescueDrawing: couldn't save the drawing: %s", err)
+		}
+	}()
+
+	filename, err := scene.writeSnapshot()
+	if err != nil {
+		log.Error("rescueDrawing: couldn't save the drawing: %s", err)
+		return
+	}
+	log.Warn("rescueDrawing: saved the drawing to %s before exiting", filename)
+}
@@ -0,0 +1,293 @@
+package savegame
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/usercfg"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/userdir"
+	"github.com/google/uuid"
+)
+
+/*
+Profile is a named save profile, so that several players sharing a computer
+each have their own high scores and levelpack progress.
+
+Each profile has its own savegame file in the saves folder of the user's
+profile directory. A profile may also have its own values of some of the
+game settings (see usercfg.ProfileFields), otherwise it uses the global
+settings.
+*/
+type Profile struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Created  time.Time         `json:"created"`
+	Settings usercfg.Overrides `json:"settings,omitempty"` // nil = the global settings
+}
+
+// Profiles is the index of the save profiles, and which one is playing.
+type Profiles struct {
+	Active   string     `json:"active"`
+	Profiles []*Profile `json:"profiles"`
+}
+
+// DefaultProfileName is the name of the first save profile.
+const DefaultProfileName = "Player 1"
+
+// Filename of the profile index in the saves folder.
+const profilesFilename = "profiles.json"
+
+// SaveFile returns the path to the savegame file of the profile.
+func (p *Profile) SaveFile() string {
+	return filepath.Join(userdir.SaveProfileDirectory, p.ID+".json")
+}
+
+// QuicksaveDirectory returns the folder of the profile's quicksaves of
+// levels in progress.
+func (p *Profile) QuicksaveDirectory() string {
+	return filepath.Join(userdir.SaveProfileDirectory, p.ID)
+}
+
+// ActiveFile returns the path to the savegame file of the active profile.
+func ActiveFile() string {
+	profiles, err := LoadProfiles()
+	if err != nil {
+		log.Error("Couldn't load the save profiles: %s", err)
+		return userdir.SaveFile
+	}
+	return profiles.Current().SaveFile()
+}
+
+/*
+LoadProfiles loads the index of the save profiles.
+
+The first time, it creates the default profile and moves the savegame.json
+of older versions of the game into it, so the player keeps their progress.
+*/
+func LoadProfiles() (*Profiles, error) {
+	var filename = filepath.Join(userdir.SaveProfileDirectory, profilesFilename)
+
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return migrateProfiles()
+	} else if err != nil {
+		return nil, err
+	}
+
+	var profiles = &Profiles{}
+	if err := json.Unmarshal(data, profiles); err != nil {
+		return nil, err
+	}
+
+	// There must always be a profile to play as. It is saved right away so
+	// that it keeps the same ID (and savegame) the next time.
+	if len(profiles.Profiles) == 0 {
+		profiles.Active = profiles.add(DefaultProfileName).ID
+		if err := profiles.Save(); err != nil {
+			return nil, err
+		}
+	}
+
+	return profiles, nil
+}
+
+// migrateProfiles creates the default profile with the global savegame.json
+// of older versions of the game.
+//
+// The profile index is saved first: if the savegame can't be moved, the
+// index is removed again so that the move is tried again the next time.
+func migrateProfiles() (*Profiles, error) {
+	var (
+		profiles = &Profiles{}
+		profile  = profiles.add(DefaultProfileName)
+	)
+	profiles.Active = profile.ID
+
+	if err := profiles.Save(); err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(userdir.SaveFile); err == nil {
+		log.Info("Moving %s into the save profile '%s'", userdir.SaveFile, profile.Name)
+		if err := os.Rename(userdir.SaveFile, profile.SaveFile()); err != nil {
+			if err := os.Remove(filepath.Join(userdir.SaveProfileDirectory, profilesFilename)); err != nil {
+				log.Error("Couldn't remove the save profiles: %s", err)
+			}
+			return nil, err
+		}
+	}
+
+	return profiles, nil
+}
+
+// Save the index of the profiles.
+func (p *Profiles) Save() error {
+	data, err := json.MarshalIndent(p, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(userdir.SaveProfileDirectory, profilesFilename), data, 0644)
+}
+
+// Current returns the active profile.
+func (p *Profiles) Current() *Profile {
+	if profile := p.Get(p.Active); profile != nil {
+		return profile
+	}
+	return p.Profiles[0]
+}
+
+// Get a profile by its ID, or nil.
+func (p *Profiles) Get(id string) *Profile {
+	for _, profile := range p.Profiles {
+		if profile.ID == id {
+			return profile
+		}
+	}
+	return nil
+}
+
+// Create a new profile. The name must not be used by another profile.
+func (p *Profiles) Create(name string) (*Profile, error) {
+	name = strings.TrimSpace(name)
+	if err := p.checkName("", name); err != nil {
+		return nil, err
+	}
+	return p.add(name), nil
+}
+
+// Rename a profile.
+func (p *Profiles) Rename(id, name string) error {
+	var profile = p.Get(id)
+	if profile == nil {
+		return fmt.Errorf("no profile with ID %s", id)
+	}
+
+	name = strings.TrimSpace(name)
+	if err := p.checkName(id, name); err != nil {
+		return err
+	}
+	profile.Name = name
+	return nil
+}
+
+// Delete a profile, its savegame and quicksaves. The last profile can't be deleted, and
+// if the active profile is deleted the first remaining one becomes active.
+func (p *Profiles) Delete(id string) error {
+	if len(p.Profiles) <= 1 {
+		return errors.New("the last save profile can't be deleted")
+	}
+
+	for i, profile := range p.Profiles {
+		if profile.ID != id {
+			continue
+		}
+
+		if err := os.Remove(profile.SaveFile()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err := os.RemoveAll(profile.QuicksaveDirectory()); err != nil {
+			return err
+		}
+
+		p.Profiles = append(p.Profiles[:i], p.Profiles[i+1:]...)
+		if p.Active == id {
+			p.Active = p.Profiles[0].ID
+		}
+		return nil
+	}
+
+	return fmt.Errorf("no profile with ID %s", id)
+}
+
+// Switch the active profile.
+func (p *Profiles) Switch(id string) error {
+	if p.Get(id) == nil {
+		return fmt.Errorf("no profile with ID %s", id)
+	}
+	p.Active = id
+	return nil
+}
+
+/*
+SetOwnSettings gives a profile its own game settings, starting from the
+current ones, or makes it use the global settings again.
+
+Call ApplySettings after saving, if it is the active profile.
+*/
+func (p *Profiles) SetOwnSettings(id string, own bool) error {
+	var profile = p.Get(id)
+	if profile == nil {
+		return fmt.Errorf("no profile with ID %s", id)
+	}
+
+	if !own {
+		profile.Settings = nil
+		return nil
+	}
+
+	if profile.Settings == nil {
+		settings, err := usercfg.CurrentOverrides()
+		if err != nil {
+			return err
+		}
+		profile.Settings = settings
+	}
+	return nil
+}
+
+// ApplySettings applies the settings of the active profile over the user's
+// game settings, and keeps the profile's settings updated when they are
+// saved from the Settings window.
+func (p *Profiles) ApplySettings() error {
+	var profile = p.Current()
+	if profile.Settings == nil {
+		return usercfg.SetOverrides(nil, nil)
+	}
+
+	var id = profile.ID
+	return usercfg.SetOverrides(profile.Settings, func(settings usercfg.Overrides) {
+		profiles, err := LoadProfiles()
+		if err != nil {
+			log.Error("Couldn't save the profile settings: %s", err)
+			return
+		}
+
+		if profile := profiles.Get(id); profile != nil {
+			profile.Settings = settings
+			if err := profiles.Save(); err != nil {
+				log.Error("Couldn't save the profile settings: %s", err)
+			}
+		}
+	})
+}
+
+// add a new profile.
+func (p *Profiles) add(name string) *Profile {
+	var profile = &Profile{
+		ID:      uuid.New().String(),
+		Name:    name,
+		Created: time.Now(),
+	}
+	p.Profiles = append(p.Profiles, profile)
+	return profile
+}
+
+// checkName validates the name of a new or renamed profile.
+func (p *Profiles) checkName(id, name string) error {
+	if name == "" {
+		return errors.New("the profile name can't be blank")
+	}
+	for _, profile := range p.Profiles {
+		if profile.ID != id && strings.EqualFold(profile.Name, name) {
+			return fmt.Errorf("there is already a profile named '%s'", profile.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package savegame
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/userdir"
+)
+
+func TestProfiles(t *testing.T) {
+	var dir = t.TempDir()
+	userdir.SaveProfileDirectory = filepath.Join(dir, "saves")
+	userdir.SaveFile = filepath.Join(dir, "savegame.json")
+	if err := os.Mkdir(userdir.SaveProfileDirectory, 0755); err != nil {
+		t.Fatalf("Mkdir: %s", err)
+	}
+
+	// The savegame.json of an older version moves into the default profile.
+	if err := os.WriteFile(userdir.SaveFile, []byte("checksum\n{}"), 0644); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+	profiles, err := LoadProfiles()
+	if err != nil {
+		t.Fatalf("LoadProfiles: %s", err)
+	}
+	var first = profiles.Current()
+	if len(profiles.Profiles) != 1 || first.Name != DefaultProfileName {
+		t.Errorf("expected the default profile: %+v", profiles.Profiles)
+	}
+	if _, err := os.Stat(first.SaveFile()); err != nil {
+		t.Errorf("expected the old savegame in the default profile: %s", err)
+	}
+	if ActiveFile() != first.SaveFile() {
+		t.Errorf("expected the default profile's savegame to be active")
+	}
+
+	// Create, rename and switch.
+	second, err := profiles.Create("  Sister ")
+	if err != nil || second.Name != "Sister" {
+		t.Fatalf("Create: %+v %v", second, err)
+	}
+	if _, err := profiles.Create("sister"); err == nil {
+		t.Errorf("expected an error creating a duplicate profile name")
+	}
+	if err := profiles.Rename(second.ID, ""); err == nil {
+		t.Errorf("expected an error renaming to a blank name")
+	}
+	if err := profiles.Rename(second.ID, "Brother"); err != nil || second.Name != "Brother" {
+		t.Errorf("Rename: %v", err)
+	}
+	if err := profiles.Switch(second.ID); err != nil || profiles.Current() != second {
+		t.Errorf("Switch: %v", err)
+	}
+
+	// Deleting the active profile makes the other one active.
+	if err := profiles.Delete(second.ID); err != nil {
+		t.Errorf("Delete: %s", err)
+	}
+	if profiles.Current() != first {
+		t.Errorf("expected the first profile to become active")
+	}
+	if err := profiles.Delete(first.ID); err == nil {
+		t.Errorf("expected an error deleting the last profile")
+	}
+}
+
+func TestProfilesEmpty(t *testing.T) {
+	var dir = t.TempDir()
+	userdir.SaveProfileDirectory = dir
+	userdir.SaveFile = filepath.Join(dir, "savegame.json")
+
+	// An index with no profiles gets a default one, which is saved so that
+	// the active savegame stays the same.
+	if err := os.WriteFile(filepath.Join(dir, profilesFilename), []byte(`{"profiles": []}`), 0644); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+	var first = ActiveFile()
+	if second := ActiveFile(); first != second {
+		t.Errorf("expected the same savegame each time, got %s and %s", first, second)
+	}
+	if first == userdir.SaveFile {
+		t.Errorf("expected the savegame of the default profile")
+	}
+}
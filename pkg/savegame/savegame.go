@@ -12,12 +12,12 @@ import (
 	"strings"
 	"time"
 
+	"git.kirsle.net/SketchyMaze/doodle/pkg/achievements"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/filesystem"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/levelpack"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/usercfg"
-	"git.kirsle.net/SketchyMaze/doodle/pkg/userdir"
 )
 
 // SaveGame holds the user's progress thru level packs.
@@ -31,6 +31,13 @@ type SaveGame struct {
 	// move around between levelpacks, get renamed, etc. that
 	// the user should be able to keep their high score.
 	Levels map[string]*Level
+
+	// Script storage for the `Storage.Pack` scope of doodad scripts,
+	// by levelpack filename. Carries data between levels of a pack.
+	Storage map[string]map[string]interface{} `json:"storage,omitempty"`
+
+	// Unlocked achievements and the counters of their game events.
+	Achievements *achievements.Progress `json:"achievements,omitempty"`
 }
 
 // LevelPack holds savegame process for a level pack.
@@ -50,6 +57,9 @@ func New() *SaveGame {
 	return &SaveGame{
 		LevelPacks: map[string]*LevelPack{},
 		Levels:     map[string]*Level{},
+		Storage:    map[string]map[string]interface{}{},
+
+		Achievements: achievements.NewProgress(),
 	}
 }
 
@@ -71,9 +81,9 @@ func GetOrCreate() (*SaveGame, error) {
 	}
 }
 
-// Load the save game JSON from the user's profile directory.
+// Load the save game JSON of the active save profile.
 func Load() (*SaveGame, error) {
-	fh, err := os.Open(userdir.SaveFile)
+	fh, err := os.Open(ActiveFile())
 	if err != nil {
 		return nil, err
 	}
@@ -100,6 +110,9 @@ func Load() (*SaveGame, error) {
 	if err != nil {
 		return nil, err
 	}
+	if sg.Achievements == nil {
+		sg.Achievements = achievements.NewProgress()
+	}
 
 	return sg, nil
 }
@@ -191,7 +204,7 @@ func Migrate() error {
 	return nil
 }
 
-// Save the savegame.json to disk.
+// Save the savegame.json of the active save profile to disk.
 func (sg *SaveGame) Save() error {
 	// Encode to JSON.
 	text, err := json.Marshal(sg)
@@ -203,7 +216,7 @@ func (sg *SaveGame) Save() error {
 	checksum := makeChecksum(text)
 
 	// Write the file.
-	fh, err := os.Create(userdir.SaveFile)
+	fh, err := os.Create(ActiveFile())
 	if err != nil {
 		return err
 	}
@@ -296,6 +309,53 @@ func (sg *SaveGame) GetLevelScore(levelpack, filename, uuid string) *Level {
 	}
 }
 
+// MarkLevelCompleted marks a level outside of a levelpack as completed, by
+// its UUID.
+func (sg *SaveGame) MarkLevelCompleted(uuid string) {
+	if sg.Levels == nil {
+		sg.Levels = map[string]*Level{}
+	}
+	if _, ok := sg.Levels[uuid]; !ok {
+		sg.Levels[uuid] = &Level{}
+	}
+	sg.Levels[uuid].Completed = true
+}
+
+// IsCompleted checks whether a level was completed, by its UUID or else by
+// its filename in any levelpack. Unlike GetLevelScore, it doesn't create a
+// score for the level.
+func (sg *SaveGame) IsCompleted(uuid, filename string) bool {
+	if uuid != "" && sg.Levels != nil {
+		if row, ok := sg.Levels[uuid]; ok && row.Completed {
+			return true
+		}
+	}
+
+	filename = filepath.Base(filename)
+	for _, lp := range sg.LevelPacks {
+		if row, ok := lp.Levels[filename]; ok && row.Completed {
+			return true
+		}
+	}
+	return false
+}
+
+// GetStorage returns the script storage for a levelpack.
+func (sg *SaveGame) GetStorage(levelpack string) map[string]interface{} {
+	if sg.Storage == nil {
+		return nil
+	}
+	return sg.Storage[filepath.Base(levelpack)]
+}
+
+// SetStorage updates the script storage for a levelpack.
+func (sg *SaveGame) SetStorage(levelpack string, data map[string]interface{}) {
+	if sg.Storage == nil {
+		sg.Storage = map[string]map[string]interface{}{}
+	}
+	sg.Storage[filepath.Base(levelpack)] = data
+}
+
 // CountCompleted returns the number of completed levels in a levelpack.
 func (sg *SaveGame) CountCompleted(levelpack *levelpack.LevelPack) int {
 	var (
@@ -0,0 +1,237 @@
+package scripting
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dop251/goja"
+)
+
+// CheckReport holds the findings of CheckScript.
+type CheckReport struct {
+	Errors   []string
+	Warnings []string
+
+	seen map[string]bool // de-duplicate findings
+}
+
+// OK returns whether the script had no errors.
+func (r *CheckReport) OK() bool {
+	return len(r.Errors) == 0
+}
+
+func (r *CheckReport) errorf(tmpl string, v ...interface{}) {
+	r.add(&r.Errors, fmt.Sprintf(tmpl, v...))
+}
+
+func (r *CheckReport) warnf(tmpl string, v ...interface{}) {
+	r.add(&r.Warnings, fmt.Sprintf(tmpl, v...))
+}
+
+func (r *CheckReport) add(list *[]string, msg string) {
+	if r.seen[msg] {
+		return
+	}
+	r.seen[msg] = true
+	*list = append(*list, msg)
+}
+
+// Globals checked for member access by CheckScript.
+var checkGlobals = []string{
+	"Self", "Actors", "Level", "Camera", "Events", "Message", "Sound", "Storage", "UI", "Achievements",
+	"console", "time",
+}
+
+var (
+	// Matches e.g. `Self.SetHitbox` with the global name and member.
+	reMemberAccess = regexp.MustCompile(`\b(` + strings.Join(checkGlobals, "|") + `)\.([A-Za-z_$][A-Za-z0-9_$]*)`)
+
+	// Matches top-level function names that look like event handlers, e.g. `function onCollide(`
+	reHandlerFunc = regexp.MustCompile(`(?m)^function\s+([oO]n[A-Z][A-Za-z0-9_]*)\s*\(`)
+
+	// Comments to strip before scanning the source.
+	reBlockComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	reLineComment  = regexp.MustCompile(`(?m)//.*$`)
+)
+
+/*
+CheckScript validates a doodad script outside of the game.
+
+The script is loaded into a VM with the same globals as in Play Mode, except
+that the Canvas APIs (Self, Actors and Level) are replaced by mock objects. The
+mocks know the names of the real API, given by the `api` map of global name to
+member names, and record any access to a name that doesn't exist.
+
+The script's main() function is run against the mocks to register its event
+handlers. CheckScript reports:
+
+  - Syntax errors in the script.
+  - Errors thrown when running main().
+  - Access to API members that don't exist, found both while running main()
+    and by scanning the source (to catch code in handlers that didn't run).
+  - Functions named like event handlers (e.g. onCollide) which were never
+    registered with the Events API.
+*/
+func CheckScript(name, source string, api map[string][]string) *CheckReport {
+	var report = &CheckReport{
+		Errors:   []string{},
+		Warnings: []string{},
+		seen:     map[string]bool{},
+	}
+
+	// Syntax errors.
+	if _, err := goja.Compile(name, source, false); err != nil {
+		report.errorf("Syntax error: %s", err)
+		return report
+	}
+
+	var s = NewSupervisor()
+	defer s.Teardown()
+
+	if err := s.AddLevelScript("check", name); err != nil {
+		report.errorf("Couldn't set up the script VM: %s", err)
+		return report
+	}
+	vm, _ := s.GetVM("check")
+
+	// Install the mock Canvas APIs.
+	for _, global := range sortedKeys(api) {
+		vm.Set(global, vm.vm.NewDynamicObject(&mockObject{
+			name:    global,
+			known:   api[global],
+			report:  report,
+			runtime: vm.vm,
+		}))
+	}
+
+	// Run the script and its main().
+	if _, err := vm.Run(source); err != nil {
+		report.errorf("Error running script: %s", err)
+		return report
+	}
+	if fn, ok := goja.AssertFunction(vm.Get("main")); !ok {
+		report.warnf("The script has no main() function")
+	} else if err := catchMain(fn); err != nil {
+		report.errorf("Error in main(): %s", err)
+	}
+
+	// Scan the source for API members that don't exist.
+	var scan = reLineComment.ReplaceAllString(reBlockComment.ReplaceAllString(source, ""), "")
+	for _, match := range reMemberAccess.FindAllStringSubmatch(scan, -1) {
+		var global, member = match[1], match[2]
+		value := vm.Get(global)
+		if value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+			continue
+		}
+
+		if v := value.ToObject(vm.vm).Get(member); v == nil || goja.IsUndefined(v) {
+			report.errorf("%s.%s is not part of the doodad scripting API", global, member)
+		}
+	}
+
+	// Functions that look like event handlers but were never registered.
+	for _, match := range reHandlerFunc.FindAllStringSubmatch(scan, -1) {
+		var (
+			function = match[1]
+			event    = "O" + function[1:] // onCollide -> OnCollide
+		)
+		if len(vm.Events.registry[event]) == 0 {
+			report.warnf("Function %s() looks like an event handler but Events.%s was never registered", function, event)
+		}
+	}
+
+	return report
+}
+
+// catchMain runs a script's main() and converts panics into errors.
+func catchMain(fn goja.Callable) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("%s", e)
+		}
+	}()
+	_, err = fn(goja.Undefined())
+	return
+}
+
+// mockObject is a goja.DynamicObject standing in for a Canvas API global.
+//
+// Its known members are functions that accept anything and return a
+// permissive mock value, so scripts can chain calls like Self.Position().X
+// without errors. Unknown members are undefined and recorded in the report.
+type mockObject struct {
+	name    string
+	known   []string
+	report  *CheckReport
+	runtime *goja.Runtime
+}
+
+func (m *mockObject) Get(key string) goja.Value {
+	if !m.Has(key) {
+		m.report.errorf("%s.%s is not part of the doodad scripting API", m.name, key)
+		return goja.Undefined()
+	}
+	return mockValue(m.runtime)
+}
+
+func (m *mockObject) Set(key string, val goja.Value) bool {
+	return true
+}
+
+func (m *mockObject) Has(key string) bool {
+	for _, name := range m.known {
+		if name == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *mockObject) Delete(key string) bool {
+	return true
+}
+
+func (m *mockObject) Keys() []string {
+	return m.known
+}
+
+// mockValue returns a function which, when called, returns an object whose
+// every property is another mockValue.
+func mockValue(rt *goja.Runtime) goja.Value {
+	return rt.ToValue(func(call goja.FunctionCall) goja.Value {
+		return rt.NewDynamicObject(anyObject{rt})
+	})
+}
+
+// anyObject is a goja.DynamicObject that has every property, except the
+// ones used to convert it into a primitive value, so that the script can
+// do math or string concatenation with it.
+type anyObject struct {
+	runtime *goja.Runtime
+}
+
+func (a anyObject) Get(key string) goja.Value {
+	if !a.Has(key) {
+		return nil // fall back on Object.prototype
+	}
+	return mockValue(a.runtime)
+}
+
+func (a anyObject) Has(key string) bool {
+	return key != "valueOf" && key != "toString" && key != "toJSON"
+}
+
+func (a anyObject) Set(key string, val goja.Value) bool { return true }
+func (a anyObject) Delete(key string) bool              { return true }
+func (a anyObject) Keys() []string                      { return []string{} }
+
+func sortedKeys(m map[string][]string) []string {
+	var keys = make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,99 @@
+package scripting
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckScript(t *testing.T) {
+	var api = map[string][]string{
+		"Self": {"Position", "SetHitbox", "ShowLayer"},
+	}
+
+	tests := []struct {
+		name     string
+		source   string
+		errors   []string // substrings of the expected errors, in order
+		warnings []string
+	}{
+		{
+			name: "clean",
+			source: `function main() {
+				Events.OnCollide(function(e) {
+					Self.ShowLayer(1);
+				});
+				var pos = Self.Position();
+				console.log("at " + pos.X);
+			}`,
+		},
+		{
+			name:   "syntax error",
+			source: `function main( {`,
+			errors: []string{"Syntax error"},
+		},
+		{
+			name:   "error in main",
+			source: `function main() { throw new Error("boom"); }`,
+			errors: []string{"Error in main(): Error: boom"},
+		},
+		{
+			name:     "no main",
+			source:   `var x = 1;`,
+			warnings: []string{"no main() function"},
+		},
+		{
+			// The typo is in a handler that main() doesn't run, and the
+			// commented out code is not scanned.
+			name: "typo in an unregistered handler",
+			source: `function main() {
+				// Self.Nope();
+			}
+			function onUse(e) {
+				Self.SetHitBox(0, 0, 10, 10);
+			}`,
+			errors:   []string{"Self.SetHitBox is not part of the doodad scripting API"},
+			warnings: []string{"Function onUse() looks like an event handler"},
+		},
+		{
+			name: "typo in main",
+			source: `function main() {
+				Self.Positoin();
+			}`,
+			errors: []string{"Self.Positoin is not part of the doodad scripting API", "Error in main()"},
+		},
+		{
+			name: "typo in the achievements API",
+			source: `function main() {
+				Events.OnCollide(function(e) {
+					Achievements.Unlok("secret");
+				});
+			}`,
+			errors: []string{"Achievements.Unlok is not part of the doodad scripting API"},
+		},
+	}
+
+	var match = func(expect, actual []string) bool {
+		if len(actual) != len(expect) {
+			return false
+		}
+		for i, substr := range expect {
+			if !strings.Contains(actual[i], substr) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, test := range tests {
+		report := CheckScript(test.name+".js", test.source, api)
+		if !match(test.errors, report.Errors) {
+			t.Errorf("%s: expected errors %+v but got %+v", test.name, test.errors, report.Errors)
+		}
+		if !match(test.warnings, report.Warnings) {
+			t.Errorf("%s: expected warnings %+v but got %+v", test.name, test.warnings, report.Warnings)
+		}
+		if report.OK() != (len(test.errors) == 0) {
+			t.Errorf("%s: unexpected OK() result: %+v", test.name, report.OK())
+		}
+	}
+}
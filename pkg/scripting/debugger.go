@@ -0,0 +1,219 @@
+package scripting
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
+	"github.com/dop251/goja"
+)
+
+// DebuggerTraceSize is the number of recent PubSub messages kept by the Debugger.
+const DebuggerTraceSize = 50
+
+// Debugger lets the developer shell inspect and step through the doodad
+// script VMs managed by a Supervisor.
+//
+// Breakpoints are set on an actor ID and event name (such as OnCollide). When
+// an event with a breakpoint fires, the Debugger flashes its details and halts
+// right before the handler runs: it calls the Hold function, which the game
+// sets to keep drawing frames and the shell (but not run the scene) until the
+// developer resumes. From there, `step` runs the one handler and halts again
+// before the next handler of any event, and `continue` resumes normally.
+type Debugger struct {
+	breakpoints map[string]map[string]bool // actor ID -> event names; "*" matches all actors
+	paused      bool
+	stepping    bool // break before the next handler, of any event
+	lastHit     string
+
+	// Hold is called on a breakpoint, before the handler runs, and returns
+	// once the debugger is no longer paused. Without it, hitting a breakpoint
+	// only marks the debugger paused.
+	Hold func()
+
+	// PubSub message tracing.
+	trace    bool
+	messages []string
+
+	lock sync.RWMutex
+}
+
+// NewDebugger initializes a Debugger.
+func NewDebugger() *Debugger {
+	return &Debugger{
+		breakpoints: map[string]map[string]bool{},
+		messages:    []string{},
+	}
+}
+
+// SetBreakpoint adds a breakpoint on an actor ID for an event name.
+// Use the actor ID "*" to break on the event for all actors.
+func (d *Debugger) SetBreakpoint(id, event string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if _, ok := d.breakpoints[id]; !ok {
+		d.breakpoints[id] = map[string]bool{}
+	}
+	d.breakpoints[id][event] = true
+}
+
+// ClearBreakpoints removes the breakpoints on an actor ID, or all
+// breakpoints if the ID is blank.
+func (d *Debugger) ClearBreakpoints(id string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if id == "" {
+		d.breakpoints = map[string]map[string]bool{}
+		return
+	}
+	delete(d.breakpoints, id)
+}
+
+// Breakpoints returns a sorted, human readable list of the breakpoints.
+func (d *Debugger) Breakpoints() []string {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	var result = []string{}
+	for id, events := range d.breakpoints {
+		for event := range events {
+			result = append(result, fmt.Sprintf("%s %s", id, event))
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Paused returns whether the scripts are halted at a breakpoint.
+func (d *Debugger) Paused() bool {
+	if d == nil {
+		return false
+	}
+
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.paused
+}
+
+// LastHit returns a description of the most recent breakpoint hit.
+func (d *Debugger) LastHit() string {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.lastHit
+}
+
+// Step runs the handler the debugger is halted at, and halts again before
+// the next handler of any event.
+func (d *Debugger) Step() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if !d.paused {
+		return fmt.Errorf("the debugger is not paused at a breakpoint")
+	}
+
+	d.paused = false
+	d.stepping = true
+	return nil
+}
+
+// Continue resumes the game until the next breakpoint.
+func (d *Debugger) Continue() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.paused = false
+	d.stepping = false
+}
+
+// SetTrace toggles the logging of PubSub messages between actors.
+func (d *Debugger) SetTrace(v bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.trace = v
+}
+
+// Trace returns whether PubSub logging is enabled.
+func (d *Debugger) Trace() bool {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.trace
+}
+
+// Messages returns the recently logged PubSub messages, oldest first.
+func (d *Debugger) Messages() []string {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	var result = make([]string, len(d.messages))
+	copy(result, d.messages)
+	return result
+}
+
+// beforeHandler is called by the Events API before it runs each handler of an
+// event. If a breakpoint matches (or the debugger is stepping), it halts here
+// until the developer resumes.
+func (d *Debugger) beforeHandler(vm *VM, event string, index, count int, params []goja.Value) {
+	if d == nil {
+		return
+	}
+
+	d.lock.Lock()
+	if !d.stepping && !d.breakpoints[vm.id][event] && !d.breakpoints["*"][event] {
+		d.lock.Unlock()
+		return
+	}
+
+	var args = make([]string, len(params))
+	for i, v := range params {
+		args[i] = fmt.Sprintf("%+v", v.Export())
+	}
+
+	d.paused = true
+	d.stepping = false
+	d.lastHit = fmt.Sprintf("%s handler %d of %d of %s (%s)", event, index+1, count, vm.Name, strings.Join(args, ", "))
+
+	// Hold outside the lock: the shell steps or continues in the meantime.
+	var hold = d.Hold
+	d.lock.Unlock()
+
+	log.Info("Debugger: break at %s", d.LastHit())
+	shmem.Flash("Debugger: break at %s", d.LastHit())
+	if hold != nil {
+		hold()
+	}
+}
+
+// onMessage is called by a VM's PubSub goroutine when it receives a message.
+//
+// This is called from a goroutine, so messages are only written to the log
+// and kept in a buffer for the shell to read from the main loop.
+func (d *Debugger) onMessage(vm *VM, msg Message) {
+	if d == nil {
+		return
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if !d.trace {
+		return
+	}
+
+	var args = make([]string, len(msg.Args))
+	for i, v := range msg.Args {
+		args[i] = fmt.Sprintf("%+v", v.Export())
+	}
+
+	line := fmt.Sprintf("%s -> %s: %s(%s)", msg.SenderID, vm.Name, msg.Name, strings.Join(args, ", "))
+	log.Info("PubSub: %s", line)
+
+	d.messages = append(d.messages, line)
+	if len(d.messages) > DebuggerTraceSize {
+		d.messages = d.messages[len(d.messages)-DebuggerTraceSize:]
+	}
+}
@@ -0,0 +1,107 @@
+package scripting
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dop251/goja"
+)
+
+func TestDebugger(t *testing.T) {
+	var (
+		d     = NewDebugger()
+		vm    = NewVM("button.doodad#actor-1")
+		calls []string
+		holds []int // handler calls made when the debugger held
+	)
+	vm.id = "actor-1"
+	vm.debugger = d
+	for _, name := range []string{"first", "second"} {
+		name := name
+		vm.Events.register(CollideEvent, func(this goja.Value, args ...goja.Value) (goja.Value, error) {
+			calls = append(calls, name)
+			return goja.Undefined(), nil
+		})
+	}
+	vm.Events.register(LeaveEvent, func(this goja.Value, args ...goja.Value) (goja.Value, error) {
+		calls = append(calls, "leave")
+		return goja.Undefined(), nil
+	})
+
+	// The developer resumes from the shell while the game holds: the hold
+	// returns once the debugger is no longer paused.
+	var resume func()
+	d.Hold = func() {
+		holds = append(holds, len(calls))
+		if !d.Paused() {
+			t.Errorf("expected the debugger to be paused while holding")
+		}
+		resume()
+	}
+
+	// No breakpoints yet.
+	vm.Events.RunCollide("player")
+	if len(calls) != 2 || len(holds) != 0 || d.Paused() {
+		t.Errorf("expected the handlers to run without holding: calls=%+v holds=%+v", calls, holds)
+	}
+
+	d.SetBreakpoint("actor-1", CollideEvent)
+	d.SetBreakpoint("*", LeaveEvent)
+	if bp := d.Breakpoints(); len(bp) != 2 || bp[0] != "* OnLeave" || bp[1] != "actor-1 OnCollide" {
+		t.Errorf("unexpected breakpoints: %+v", bp)
+	}
+
+	// The breakpoint halts before each handler runs.
+	calls, holds = nil, nil
+	resume = d.Continue
+	vm.Events.RunCollide("player")
+	if len(holds) != 2 || holds[0] != 0 || holds[1] != 1 || len(calls) != 2 {
+		t.Errorf("expected to hold before each handler: calls=%+v holds=%+v", calls, holds)
+	}
+	if !strings.HasPrefix(d.LastHit(), CollideEvent+" handler 2 of 2") || d.Paused() {
+		t.Errorf("unexpected last hit: %s (paused=%+v)", d.LastHit(), d.Paused())
+	}
+
+	// Stepping halts before the next handler of any event, even with the
+	// breakpoints cleared.
+	d.ClearBreakpoints("")
+	d.SetBreakpoint("actor-1", CollideEvent)
+	calls, holds = nil, nil
+	var steps = 0
+	resume = func() {
+		if steps++; steps < 3 {
+			if err := d.Step(); err != nil {
+				t.Errorf("Step: %s", err)
+			}
+			d.ClearBreakpoints("")
+			return
+		}
+		d.Continue()
+	}
+	vm.Events.RunCollide("player")
+	vm.Events.RunLeave("player")
+	if len(holds) != 3 || holds[2] != 2 || strings.Join(calls, ",") != "first,second,leave" {
+		t.Errorf("expected to step through every handler: calls=%+v holds=%+v", calls, holds)
+	}
+	if !strings.HasPrefix(d.LastHit(), LeaveEvent) {
+		t.Errorf("expected the last step at OnLeave but got: %s", d.LastHit())
+	}
+
+	// Continue resumes until the next breakpoint: there are none.
+	calls, holds = nil, nil
+	vm.Events.RunCollide("player")
+	if d.Paused() || len(holds) != 0 || len(calls) != 2 {
+		t.Errorf("expected Continue to resume: calls=%+v holds=%+v", calls, holds)
+	}
+	if err := d.Step(); err == nil {
+		t.Errorf("expected an error stepping while not paused")
+	}
+
+	// Without a Hold function, a breakpoint only marks the debugger paused.
+	d.Hold = nil
+	d.SetBreakpoint("*", LeaveEvent)
+	vm.Events.RunLeave("player")
+	if !d.Paused() {
+		t.Errorf("expected the breakpoint to pause the debugger")
+	}
+}
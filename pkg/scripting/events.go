@@ -16,6 +16,11 @@ const (
 	LeaveEvent   = "OnLeave"   // a doodad no longer collides with us
 	UseEvent     = "OnUse"     // player pressed the Use key while touching us
 
+	// Level trigger regions: fired for the actor that entered or left the
+	// region, and for the actors linked to the region.
+	RegionEnterEvent = "OnRegionEnter"
+	RegionLeaveEvent = "OnRegionLeave"
+
 	// Controllable (player character) doodad events
 	KeypressEvent = "OnKeypress" // i.e. arrow keys
 )
@@ -72,6 +77,26 @@ func (e *Events) RunLeave(v interface{}) error {
 	return e.run(LeaveEvent, v)
 }
 
+// OnRegionEnter fires when an actor enters a level region.
+func (e *Events) OnRegionEnter(call goja.Callable) goja.Value {
+	return e.register(RegionEnterEvent, call)
+}
+
+// RunRegionEnter invokes the OnRegionEnter handler function.
+func (e *Events) RunRegionEnter(v interface{}) error {
+	return e.run(RegionEnterEvent, v)
+}
+
+// OnRegionLeave fires when an actor leaves a level region.
+func (e *Events) OnRegionLeave(call goja.Callable) goja.Value {
+	return e.register(RegionLeaveEvent, call)
+}
+
+// RunRegionLeave invokes the OnRegionLeave handler function.
+func (e *Events) RunRegionLeave(v interface{}) error {
+	return e.run(RegionLeaveEvent, v)
+}
+
 // OnKeypress fires when another actor collides with yours.
 func (e *Events) OnKeypress(call goja.Callable) goja.Value {
 	return e.register(KeypressEvent, call)
@@ -98,9 +123,6 @@ func (e *Events) register(name string, callback goja.Callable) goja.Value {
 // Run an event handler. Returns an error only if there was a JavaScript error
 // inside the function. If there are no event handlers, just returns nil.
 func (e *Events) run(name string, args ...interface{}) error {
-	e.lock.RLock()
-	defer e.lock.RUnlock()
-
 	defer func() {
 		if err := recover(); err != nil {
 			// TODO EXCEPTIONS: I once saw a "runtime error: index out of range [-1]"
@@ -110,7 +132,13 @@ func (e *Events) run(name string, args ...interface{}) error {
 		}
 	}()
 
-	if _, ok := e.registry[name]; !ok {
+	// Copy the handlers, so the lock isn't held while they run (or while the
+	// debugger is halted at one of them).
+	e.lock.RLock()
+	var handlers = append([]goja.Callable{}, e.registry[name]...)
+	e.lock.RUnlock()
+
+	if len(handlers) == 0 {
 		return nil
 	}
 
@@ -119,7 +147,10 @@ func (e *Events) run(name string, args ...interface{}) error {
 		params[i] = e.runtime.ToValue(v)
 	}
 
-	for _, function := range e.registry[name] {
+	for i, function := range handlers {
+		// Give the developer shell debugger a chance to break here.
+		e.vm.debugger.beforeHandler(e.vm, name, i, len(handlers), params)
+
 		value, err := function(goja.Undefined(), params...)
 		if err != nil {
 			// TODO EXCEPTIONS: this err is useful like
@@ -38,7 +38,7 @@ func NewJSProxy(vm *VM) JSProxy {
 			"error": ProxyLog(vm, log.Error),
 		},
 
-		// Audio API.
+		// Audio API. Actors get the full API from uix/canvas_sound.go
 		"Sound": map[string]interface{}{
 			"Play": sound.PlaySound,
 		},
@@ -72,6 +72,7 @@ func NewJSProxy(vm *VM) JSProxy {
 		"setInterval":   vm.SetInterval,
 		"clearTimeout":  vm.ClearTimer,
 		"clearInterval": vm.ClearTimer,
+		"Sleep":         vm.Sleep,
 
 		// Self for an actor to inspect themselves.
 		"Self": vm.Self,
@@ -0,0 +1,176 @@
+package scripting
+
+import (
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
+	"github.com/dop251/goja"
+)
+
+/*
+Promise support for the scripting engine.
+
+The goja runtime runs the Promise job queue (including the continuations of
+async functions) each time control returns from JavaScript to Go. The VM keeps
+a list of pending promises which are checked once per game tick by the
+Supervisor's Loop, so that a script can:
+
+	async function main() {
+		await Sleep(1000);
+		await Self.PlayAnimation("open", null);
+		var powered = await Message.Wait("power");
+	}
+
+Promises are only ever resolved from the main game loop, never from the
+PubSub goroutine, as the goja runtime is not goroutine-safe.
+*/
+
+// pendingPromise is a promise waiting to be resolved on a future game tick.
+type pendingPromise struct {
+	resolve func(interface{})
+
+	// Sleep: the tick when it resolves.
+	wakeTick uint64
+
+	// Message.Wait: the message name to wait for, and its arguments once received.
+	message  string
+	received bool
+	args     []goja.Value
+}
+
+// ready checks whether the pending promise can be resolved, and its value.
+func (p *pendingPromise) ready() (interface{}, bool) {
+	if p.message != "" {
+		if !p.received {
+			return nil, false
+		}
+
+		// Resolve with the first argument of the message, or an array if
+		// there were several.
+		switch len(p.args) {
+		case 0:
+			return goja.Undefined(), true
+		case 1:
+			return p.args[0], true
+		default:
+			var array = make([]interface{}, len(p.args))
+			for i, v := range p.args {
+				array[i] = v
+			}
+			return array, true
+		}
+	}
+
+	return nil, shmem.Tick >= p.wakeTick
+}
+
+// NewPromise creates a JavaScript Promise for a Go API function, with the
+// resolving functions passed to the executor.
+//
+// The resolving functions must only be called from the main game loop.
+func (vm *VM) NewPromise(executor func(resolve, reject func(interface{}))) *goja.Promise {
+	promise, resolve, reject := vm.vm.NewPromise()
+	executor(resolve, reject)
+	return promise
+}
+
+/*
+Sleep returns a Promise that resolves after a delay in milliseconds.
+
+In the JavaScript VM this is used like `await Sleep(1000)`.
+*/
+func (vm *VM) Sleep(interval int) *goja.Promise {
+	var ticks = float64(interval) * (float64(balance.TargetFPS) / 1000)
+	return vm.addPromise(&pendingPromise{
+		wakeTick: shmem.Tick + uint64(ticks),
+	})
+}
+
+/*
+WaitMessage returns a Promise that resolves the next time the VM receives a
+PubSub message by name.
+
+In the JavaScript VM this is bound to `Message.Wait(name)`.
+*/
+func (vm *VM) WaitMessage(name string) *goja.Promise {
+	return vm.addPromise(&pendingPromise{
+		message: name,
+	})
+}
+
+// addPromise creates a Promise to be resolved by TickPromises.
+func (vm *VM) addPromise(p *pendingPromise) *goja.Promise {
+	promise, resolve, _ := vm.vm.NewPromise()
+	p.resolve = resolve
+
+	vm.muPromises.Lock()
+	vm.promises = append(vm.promises, p)
+	vm.muPromises.Unlock()
+
+	return promise
+}
+
+// receiveMessage is called by the PubSub goroutine to wake up any promises
+// waiting on the message. They will be resolved on the next game tick.
+func (vm *VM) receiveMessage(msg Message) {
+	vm.muPromises.Lock()
+	defer vm.muPromises.Unlock()
+
+	for _, p := range vm.promises {
+		if p.message == msg.Name && !p.received {
+			p.received = true
+			p.args = msg.Args
+		}
+	}
+}
+
+// TickPromises resolves any pending promises that are ready.
+func (vm *VM) TickPromises() {
+	vm.muPromises.Lock()
+	if len(vm.promises) == 0 {
+		vm.muPromises.Unlock()
+		return
+	}
+
+	var (
+		pending = vm.promises[:0]
+		ready   []*pendingPromise
+		values  []interface{}
+	)
+	for _, p := range vm.promises {
+		if value, ok := p.ready(); ok {
+			ready = append(ready, p)
+			values = append(values, value)
+		} else {
+			pending = append(pending, p)
+		}
+	}
+	vm.promises = pending
+	vm.muPromises.Unlock()
+
+	// Resolve them outside the lock: the continuations run right away and may
+	// await new promises of their own.
+	for i, p := range ready {
+		p.resolve(values[i])
+	}
+}
+
+// ClearPromises drops all pending promises, on VM teardown. Their async
+// functions will never resume.
+func (vm *VM) ClearPromises() {
+	vm.muPromises.Lock()
+	defer vm.muPromises.Unlock()
+
+	if len(vm.promises) > 0 {
+		log.Debug("JavaScript VM %s: dropping %d pending promises", vm.Name, len(vm.promises))
+	}
+	vm.promises = nil
+}
+
+// trackRejections logs promise rejections that have no handler, which would
+// otherwise be silently swallowed (e.g. an error thrown in an async function).
+func (vm *VM) trackRejections(p *goja.Promise, operation goja.PromiseRejectionOperation) {
+	if operation == goja.PromiseRejectionReject {
+		log.Error("JavaScript VM %s: unhandled promise rejection: %s", vm.Name, p.Result())
+	}
+}
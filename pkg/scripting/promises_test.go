@@ -0,0 +1,80 @@
+package scripting
+
+import (
+	"testing"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
+	"github.com/dop251/goja"
+)
+
+func TestPromises(t *testing.T) {
+	var vm = NewVM("test")
+	vm.Set("Sleep", vm.Sleep)
+	vm.Set("Wait", vm.WaitMessage)
+	shmem.Tick = 0
+
+	if _, err := vm.Run(`
+		var steps = [];
+		async function main() {
+			steps.push("start");
+			await Sleep(1000);
+			steps.push("slept");
+			var power = await Wait("power");
+			steps.push("power=" + power);
+		}
+		main();
+	`); err != nil {
+		t.Fatalf("Run: %s", err)
+	}
+
+	// Test assertion helper.
+	shouldSteps := func(note, expect string) {
+		t.Helper()
+		v, err := vm.Run(`steps.join(",")`)
+		if err != nil {
+			t.Fatalf("%s: %s", note, err)
+		}
+		if v.String() != expect {
+			t.Errorf("Unexpected steps (%s)\nExpected: %s\n     Got: %s", note, expect, v.String())
+		}
+	}
+
+	shouldSteps("main runs until the first await", "start")
+	vm.TickPromises()
+	shouldSteps("still asleep", "start")
+
+	// Sleep resolves on the game tick, one second of ticks later.
+	shmem.Tick = balance.TargetFPS / 2
+	vm.TickPromises()
+	shouldSteps("half a second", "start")
+	shmem.Tick = balance.TargetFPS
+	vm.TickPromises()
+	shouldSteps("one second", "start,slept")
+
+	// Message.Wait resolves on the tick after the message is received.
+	vm.receiveMessage(Message{Name: "other"})
+	vm.TickPromises()
+	shouldSteps("a different message", "start,slept")
+	vm.receiveMessage(Message{Name: "power", Args: []goja.Value{vm.ToValue(true)}})
+	shouldSteps("received but not ticked", "start,slept")
+	vm.TickPromises()
+	shouldSteps("power message", "start,slept,power=true")
+
+	// Promises cleared on teardown never resume.
+	if _, err := vm.Run(`
+		(async function() {
+			await Sleep(10);
+			steps.push("never");
+		})();
+	`); err != nil {
+		t.Fatalf("Run: %s", err)
+	}
+	vm.ClearPromises()
+	shmem.Tick += balance.TargetFPS
+	vm.TickPromises()
+	shouldSteps("cleared promises", "start,slept,power=true")
+	if len(vm.promises) != 0 {
+		t.Errorf("expected no pending promises but got %d", len(vm.promises))
+	}
+}
@@ -17,8 +17,9 @@ type Message struct {
 /*
 RegisterPublishHooks adds the pub/sub hooks to a JavaScript VM.
 
-This adds the global methods `Message.Subscribe(name, func)` and
-`Message.Publish(name, args)` to the JavaScript VM's scope.
+This adds the global methods `Message.Subscribe(name, func)`,
+`Message.Publish(name, args)` and `Message.Wait(name)` to the JavaScript
+VM's scope.
 */
 func RegisterPublishHooks(s *Supervisor, vm *VM) {
 	// Goroutine to watch the VM's inbound channel and invoke Subscribe handlers
@@ -38,6 +39,8 @@ func RegisterPublishHooks(s *Supervisor, vm *VM) {
 				log.Debug("JavaScript VM %s stopping PubSub goroutine", vm.Name)
 				return
 			case msg := <-vm.Inbound:
+				vm.debugger.onMessage(vm, msg)
+				vm.receiveMessage(msg)
 				vm.muSubscribe.Lock()
 
 				if _, ok := vm.subscribe[msg.Name]; ok {
@@ -71,6 +74,9 @@ func RegisterPublishHooks(s *Supervisor, vm *VM) {
 			vm.subscribe[name] = append(vm.subscribe[name], callback)
 		},
 
+		// Message.Wait returns a Promise for the next message by name.
+		"Wait": vm.WaitMessage,
+
 		"Publish": func(name string, v ...goja.Value) {
 			vm.muPublish.Lock()
 			for _, channel := range vm.Outbound {
@@ -4,6 +4,8 @@ package scripting
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
@@ -16,16 +18,33 @@ import (
 type Supervisor struct {
 	scripts map[string]*VM
 
+	// Debugger for the developer shell.
+	Debugger *Debugger
+
+	// Script storage scopes.
+	actorStorage map[string]*Storage
+	levelStorage *Storage
+	packStorage  *Storage
+
 	// Global event handlers.
 	onLevelExit     func()
 	onLevelFail     func(message string)
 	onSetCheckpoint func(where render.Point)
+
+	onUnlockAchievement func(id string)
+
+	// Play Scene HUD for the UI scripting API.
+	hud HUD
 }
 
 // NewSupervisor creates a new JavaScript Supervior.
 func NewSupervisor() *Supervisor {
 	return &Supervisor{
-		scripts: map[string]*VM{},
+		scripts:      map[string]*VM{},
+		Debugger:     NewDebugger(),
+		actorStorage: map[string]*Storage{},
+		levelStorage: NewStorage(nil),
+		packStorage:  NewStorage(nil),
 	}
 }
 
@@ -34,14 +53,17 @@ func (s *Supervisor) Teardown() {
 	log.Info("scripting.Teardown(): stop all (%d) scripts", len(s.scripts))
 	for _, vm := range s.scripts {
 		vm.stop <- true
+		vm.ClearPromises()
 	}
 }
 
-// Loop the supervisor to invoke timer events in any running scripts.
+// Loop the supervisor to invoke timer events and resolve pending promises
+// in any running scripts.
 func (s *Supervisor) Loop() error {
 	now := time.Now()
 	for _, vm := range s.scripts {
 		vm.TickTimer(now)
+		vm.TickPromises()
 	}
 	return nil
 }
@@ -87,8 +109,12 @@ func (s *Supervisor) AddLevelScript(id string, name string) error {
 	}
 
 	s.scripts[id] = NewVM(fmt.Sprintf("%s#%s", name, id))
+	s.scripts[id].id = id
+	s.scripts[id].debugger = s.Debugger
 	RegisterPublishHooks(s, s.scripts[id])
 	RegisterEventHooks(s, s.scripts[id])
+	RegisterStorageHooks(s, s.scripts[id])
+	RegisterUIHooks(s, s.scripts[id])
 	if err := s.scripts[id].RegisterLevelHooks(); err != nil {
 		return err
 	}
@@ -118,6 +144,43 @@ func (s *Supervisor) GetVM(name string) (*VM, error) {
 	return nil, errors.New("not found")
 }
 
+// VMs returns the live script VMs sorted by name.
+func (s *Supervisor) VMs() []*VM {
+	var result = []*VM{}
+	for _, vm := range s.scripts {
+		result = append(result, vm)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// FindVM looks up a script VM for the developer shell. The query may be the
+// full actor ID, a unique prefix of the actor ID, or the doodad filename if
+// only one actor uses it.
+func (s *Supervisor) FindVM(query string) (*VM, error) {
+	if vm, ok := s.scripts[query]; ok {
+		return vm, nil
+	}
+
+	var found []*VM
+	for id, vm := range s.scripts {
+		if strings.HasPrefix(id, query) || strings.HasPrefix(vm.Name, query+"#") {
+			found = append(found, vm)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no script VM matches '%s'", query)
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("'%s' matches %d script VMs; be more specific", query, len(found))
+	}
+}
+
 // RemoveVM removes a script from the supervisor, stopping it.
 func (s *Supervisor) RemoveVM(name string) error {
 	if _, ok := s.scripts[name]; ok {
@@ -0,0 +1,252 @@
+package scripting
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"github.com/dop251/goja"
+)
+
+// Storage scope names.
+const (
+	ActorScope = "Actor" // private to one actor, for the current level run
+	LevelScope = "Level" // shared by all actors, for the current level run
+	PackScope  = "Pack"  // shared across the levels of a levelpack, persisted in the savegame
+)
+
+// Storage is a key/value store for doodad scripts.
+//
+// Values must be JSON serializable: the store is snapshotted at checkpoints
+// and the levelpack scope is written to the savegame file.
+type Storage struct {
+	data map[string]interface{}
+	lock sync.RWMutex
+}
+
+// NewStorage initializes a Storage, optionally from existing data.
+func NewStorage(data map[string]interface{}) *Storage {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	return &Storage{
+		data: data,
+	}
+}
+
+// Get a value from storage. Returns the default value if it is not set.
+//
+// Maps and slices are copied, so changing what the script gets back doesn't
+// change the storage without a Set.
+func (s *Storage) Get(key string, defaultValue interface{}) interface{} {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if v, ok := s.data[key]; ok {
+		return deepCopy(v)
+	}
+	return defaultValue
+}
+
+// Set a value in storage.
+func (s *Storage) Set(key string, value interface{}) error {
+	// Round trip the value through JSON, so it is a plain copy that can be
+	// persisted and what the script gets back is the same after a reload.
+	bin, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("Storage.Set(%s): value is not serializable: %s", key, err)
+	}
+
+	var copied interface{}
+	if err := json.Unmarshal(bin, &copied); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.data[key] = copied
+	return nil
+}
+
+// Has checks whether a key is set.
+func (s *Storage) Has(key string) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	_, ok := s.data[key]
+	return ok
+}
+
+// Delete a key from storage.
+func (s *Storage) Delete(key string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.data, key)
+}
+
+// Keys returns the sorted keys in storage.
+func (s *Storage) Keys() []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var keys = make([]string, 0, len(s.data))
+	for key := range s.data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Clear all keys from storage.
+func (s *Storage) Clear() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.data = map[string]interface{}{}
+}
+
+// Export a copy of the storage data.
+func (s *Storage) Export() map[string]interface{} {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	// All values were JSON round-tripped on Set, so only the maps and
+	// slices need copying.
+	return deepCopy(s.data).(map[string]interface{})
+}
+
+// Restore the storage data from an Export.
+func (s *Storage) Restore(data map[string]interface{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if data == nil {
+		s.data = map[string]interface{}{}
+		return
+	}
+	s.data = deepCopy(data).(map[string]interface{})
+}
+
+// deepCopy copies the JSON-like maps and slices of storage data.
+func deepCopy(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		var result = make(map[string]interface{}, len(value))
+		for k, v := range value {
+			result[k] = deepCopy(v)
+		}
+		return result
+	case []interface{}:
+		var result = make([]interface{}, len(value))
+		for i, v := range value {
+			result[i] = deepCopy(v)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// StorageSnapshot holds a copy of all script storage, taken at a checkpoint.
+type StorageSnapshot struct {
+	Actors map[string]map[string]interface{}
+	Level  map[string]interface{}
+	Pack   map[string]interface{}
+}
+
+// ActorStorage returns the storage for an actor ID.
+func (s *Supervisor) ActorStorage(id string) *Storage {
+	if _, ok := s.actorStorage[id]; !ok {
+		s.actorStorage[id] = NewStorage(nil)
+	}
+	return s.actorStorage[id]
+}
+
+// LevelStorage returns the storage shared by all actors in the level.
+func (s *Supervisor) LevelStorage() *Storage {
+	return s.levelStorage
+}
+
+// PackStorage returns the storage shared across the levels of a levelpack.
+func (s *Supervisor) PackStorage() *Storage {
+	return s.packStorage
+}
+
+// SetPackStorage loads the levelpack storage, e.g. from the savegame, at
+// the start of the level.
+func (s *Supervisor) SetPackStorage(data map[string]interface{}) {
+	s.packStorage.Restore(data)
+}
+
+// SnapshotStorage copies all of the script storage, e.g. when the player
+// reaches a checkpoint.
+func (s *Supervisor) SnapshotStorage() *StorageSnapshot {
+	var snap = &StorageSnapshot{
+		Actors: map[string]map[string]interface{}{},
+		Level:  s.levelStorage.Export(),
+		Pack:   s.packStorage.Export(),
+	}
+	for id, storage := range s.actorStorage {
+		snap.Actors[id] = storage.Export()
+	}
+	return snap
+}
+
+// RestoreStorage reverts all of the script storage to a snapshot, e.g. when
+// the player respawns at their last checkpoint.
+func (s *Supervisor) RestoreStorage(snap *StorageSnapshot) {
+	if snap == nil {
+		return
+	}
+
+	log.Debug("scripting.RestoreStorage: restore %d actors", len(snap.Actors))
+	s.levelStorage.Restore(snap.Level)
+	s.packStorage.Restore(snap.Pack)
+	for id, storage := range s.actorStorage {
+		if data, ok := snap.Actors[id]; ok {
+			storage.Restore(data)
+		} else {
+			storage.Clear()
+		}
+	}
+}
+
+/*
+RegisterStorageHooks adds the Storage API to a JavaScript VM.
+
+Each scope (Storage.Actor, Storage.Level and Storage.Pack) has the methods:
+
+  - Get(key, default): get a value, or the default if not set.
+  - Set(key, value): set a JSON serializable value.
+  - Has(key), Delete(key), Keys() and Clear().
+*/
+func RegisterStorageHooks(s *Supervisor, vm *VM) {
+	var scopes = map[string]*Storage{
+		ActorScope: s.ActorStorage(vm.id),
+		LevelScope: s.levelStorage,
+		PackScope:  s.packStorage,
+	}
+
+	var api = map[string]interface{}{}
+	for name, storage := range scopes {
+		storage := storage
+		api[name] = map[string]interface{}{
+			"Get": func(key string, defaultValue goja.Value) interface{} {
+				var def interface{}
+				if defaultValue != nil {
+					def = defaultValue.Export()
+				}
+				return storage.Get(key, def)
+			},
+			"Set": func(key string, value goja.Value) error {
+				return storage.Set(key, value.Export())
+			},
+			"Has":    storage.Has,
+			"Delete": storage.Delete,
+			"Keys":   storage.Keys,
+			"Clear":  storage.Clear,
+		}
+	}
+
+	vm.Set("Storage", api)
+}
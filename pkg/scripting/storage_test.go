@@ -0,0 +1,135 @@
+package scripting
+
+import "testing"
+
+func TestStorage(t *testing.T) {
+	var s = NewStorage(nil)
+
+	if v := s.Get("missing", "default"); v != "default" {
+		t.Errorf("expected the default value but got %+v", v)
+	}
+
+	// Values are copied through JSON: numbers become float64 and the caller's
+	// map can change without affecting storage.
+	var doors = map[string]interface{}{"red": true}
+	if err := s.Set("doors", doors); err != nil {
+		t.Errorf("Set: %s", err)
+	}
+	doors["blue"] = true
+	s.Set("switches", 2)
+
+	if v, ok := s.Get("doors", nil).(map[string]interface{}); !ok || len(v) != 1 {
+		t.Errorf("expected a copy of the doors map but got %+v", s.Get("doors", nil))
+	}
+	s.Get("doors", nil).(map[string]interface{})["green"] = true
+	if len(s.Get("doors", nil).(map[string]interface{})) != 1 {
+		t.Errorf("changing the value from Get should not change the storage")
+	}
+	if v := s.Get("switches", nil); v != 2.0 {
+		t.Errorf("expected switches to be float64 2 but got %T %+v", v, v)
+	}
+	if keys := s.Keys(); len(keys) != 2 || keys[0] != "doors" || keys[1] != "switches" {
+		t.Errorf("unexpected keys: %+v", keys)
+	}
+
+	// Values that aren't JSON serializable.
+	if err := s.Set("func", func() {}); err == nil {
+		t.Errorf("expected an error storing a function")
+	}
+
+	// Export and Restore make deep copies.
+	var data = s.Export()
+	data["doors"].(map[string]interface{})["green"] = true
+	if len(s.Get("doors", nil).(map[string]interface{})) != 1 {
+		t.Errorf("changing an export should not change the storage")
+	}
+
+	s.Delete("switches")
+	if s.Has("switches") {
+		t.Errorf("expected switches to be deleted")
+	}
+	s.Restore(data)
+	if !s.Has("switches") || len(s.Get("doors", nil).(map[string]interface{})) != 2 {
+		t.Errorf("unexpected storage after Restore: %+v", s.Export())
+	}
+
+	s.Clear()
+	if len(s.Keys()) != 0 {
+		t.Errorf("expected storage to be cleared")
+	}
+}
+
+func TestStorageScopes(t *testing.T) {
+	var s = NewSupervisor()
+	defer s.Teardown()
+
+	for _, id := range []string{"a", "b"} {
+		if err := s.AddLevelScript(id, id+".doodad"); err != nil {
+			t.Fatalf("AddLevelScript(%s): %s", id, err)
+		}
+	}
+	a, _ := s.GetVM("a")
+	b, _ := s.GetVM("b")
+
+	// Actor storage is private; level and pack storage is shared.
+	for _, vm := range []*VM{a, b} {
+		if _, err := vm.Run(`
+			Storage.Actor.Set("visits", Storage.Actor.Get("visits", 0) + 1);
+			Storage.Level.Set("visits", Storage.Level.Get("visits", 0) + 1);
+			Storage.Pack.Set("visits", Storage.Pack.Get("visits", 0) + 1);
+		`); err != nil {
+			t.Fatalf("%s: %s", vm.Name, err)
+		}
+	}
+	if v := s.ActorStorage("a").Get("visits", nil); v != 1.0 {
+		t.Errorf("expected actor a to have 1 visit but got %+v", v)
+	}
+	if v := s.LevelStorage().Get("visits", nil); v != 2.0 {
+		t.Errorf("expected the level to have 2 visits but got %+v", v)
+	}
+	if v := s.PackStorage().Get("visits", nil); v != 2.0 {
+		t.Errorf("expected the levelpack to have 2 visits but got %+v", v)
+	}
+
+	// Changing a value from Get doesn't change storage without a Set.
+	if _, err := a.Run(`
+		Storage.Level.Set("keys", ["red"]);
+		Storage.Level.Get("keys", []).push("blue");
+	`); err != nil {
+		t.Fatalf("%s: %s", a.Name, err)
+	}
+	if v, ok := s.LevelStorage().Get("keys", nil).([]interface{}); !ok || len(v) != 1 {
+		t.Errorf("expected one key in level storage but got %+v", s.LevelStorage().Get("keys", nil))
+	}
+	s.LevelStorage().Delete("keys")
+
+	// Reach a checkpoint, change everything, and respawn.
+	var checkpoint = s.SnapshotStorage()
+	if _, err := b.Run(`
+		Storage.Actor.Set("key", "blue");
+		Storage.Level.Clear();
+		Storage.Pack.Set("visits", 10);
+	`); err != nil {
+		t.Fatalf("%s: %s", b.Name, err)
+	}
+	s.RestoreStorage(checkpoint)
+
+	if s.ActorStorage("b").Has("key") {
+		t.Errorf("actor storage set after the checkpoint should be cleared")
+	}
+	if v := s.ActorStorage("b").Get("visits", nil); v != 1.0 {
+		t.Errorf("expected actor b to have 1 visit after the restore but got %+v", v)
+	}
+	if v := s.LevelStorage().Get("visits", nil); v != 2.0 {
+		t.Errorf("expected the level visits to be restored but got %+v", v)
+	}
+	if v := s.PackStorage().Get("visits", nil); v != 2.0 {
+		t.Errorf("expected the levelpack visits to be restored but got %+v", v)
+	}
+
+	// A nil snapshot changes nothing.
+	s.RestoreStorage(nil)
+	if v := s.LevelStorage().Get("visits", nil); v != 2.0 {
+		t.Errorf("RestoreStorage(nil) should not change storage but got %+v", v)
+	}
+}
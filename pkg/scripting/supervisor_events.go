@@ -1,6 +1,9 @@
 package scripting
 
-import "git.kirsle.net/go/render"
+import (
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/go/render"
+)
 
 /*
 RegisterEventHooks attaches the supervisor level event hooks into a JS VM.
@@ -11,6 +14,8 @@ Names registered:
     handler isn't defined.
   - FailLevel(): for a doodad to cause a level failure.
   - SetCheckpoint(): update the player's respawn location.
+  - Achievements.Unlock(id): unlock an achievement of the levelpack (or a
+    built-in one) for the player.
 */
 func RegisterEventHooks(s *Supervisor, vm *VM) {
 	vm.Set("EndLevel", func() {
@@ -31,6 +36,17 @@ func RegisterEventHooks(s *Supervisor, vm *VM) {
 		}
 		s.onSetCheckpoint(p)
 	})
+	vm.Set("Achievements", map[string]interface{}{
+		"Unlock": func(id string) {
+			// Not an error for scripts of levels outside of Play Mode, e.g.
+			// the title screen demo.
+			if s.onUnlockAchievement == nil {
+				log.Debug("JS Achievements.Unlock(%s): no OnUnlockAchievement handler attached", id)
+				return
+			}
+			s.onUnlockAchievement(id)
+		},
+	})
 }
 
 // OnLevelExit registers an event hook for when a Level Exit doodad is reached.
@@ -47,3 +63,8 @@ func (s *Supervisor) OnLevelFail(handler func(string)) {
 func (s *Supervisor) OnSetCheckpoint(handler func(render.Point)) {
 	s.onSetCheckpoint = handler
 }
+
+// OnUnlockAchievement registers an event hook for scripts unlocking achievements.
+func (s *Supervisor) OnUnlockAchievement(handler func(id string)) {
+	s.onUnlockAchievement = handler
+}
@@ -0,0 +1,110 @@
+package scripting
+
+import (
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"github.com/dop251/goja"
+)
+
+// HUD screen anchors for the UI scripting API.
+const (
+	AnchorTopLeft     = "top-left"
+	AnchorTopRight    = "top-right"
+	AnchorBottomLeft  = "bottom-left"
+	AnchorBottomRight = "bottom-right"
+)
+
+// HUD is implemented by the Play Scene so doodad scripts can show counters,
+// timers, labels and dialog boxes on screen.
+type HUD interface {
+	// Labels, counters and timers are identified by a unique ID. Setting an
+	// existing ID updates it in place.
+	SetLabel(id, text, anchor string)
+	SetCounter(id, label string, value int, anchor string)
+	SetTimer(id, label string, milliseconds int, anchor string, onExpire func())
+	Remove(id string)
+
+	// Dialog pops up a speech box that pauses gameplay until the player picks
+	// one of the choices.
+	Dialog(speaker, message string, choices []string, callback func(choice string))
+}
+
+// SetHUD attaches the Play Scene's HUD for the UI scripting API.
+func (s *Supervisor) SetHUD(hud HUD) {
+	s.hud = hud
+}
+
+/*
+RegisterUIHooks adds the `UI` global to a JavaScript VM.
+
+Names registered:
+
+  - UI.Label(id, text, anchor): show a line of text.
+  - UI.Counter(id, label, value, anchor): show a "label: value" counter.
+  - UI.Timer(id, label, milliseconds, anchor, onExpire): show a countdown
+    timer, calling the optional onExpire function when it runs out.
+  - UI.Remove(id): remove a label, counter or timer.
+  - UI.Dialog(speaker, message, choices, callback): pop up a dialog box and
+    return a Promise for the player's choice, also calling the optional
+    callback function with it.
+
+The anchor is one of "top-left", "top-right", "bottom-left" or "bottom-right",
+and defaults to top-left.
+*/
+func RegisterUIHooks(s *Supervisor, vm *VM) {
+	// Each call is a no-op with an error logged if the HUD isn't attached,
+	// e.g. on the title screen demo level.
+	ready := func(name string) bool {
+		if s.hud == nil {
+			log.Error("JS UI.%s(): no HUD attached to script supervisor", name)
+			return false
+		}
+		return true
+	}
+
+	// JS callbacks as Go functions.
+	callback := func(fn goja.Value) func() {
+		return func() {
+			if function, ok := goja.AssertFunction(fn); ok {
+				function(goja.Undefined())
+			}
+		}
+	}
+
+	vm.Set("UI", map[string]interface{}{
+		"Label": func(id, text, anchor string) {
+			if ready("Label") {
+				s.hud.SetLabel(id, text, anchor)
+			}
+		},
+		"Counter": func(id, label string, value int, anchor string) {
+			if ready("Counter") {
+				s.hud.SetCounter(id, label, value, anchor)
+			}
+		},
+		"Timer": func(id, label string, milliseconds int, anchor string, onExpire goja.Value) {
+			if ready("Timer") {
+				s.hud.SetTimer(id, label, milliseconds, anchor, callback(onExpire))
+			}
+		},
+		"Remove": func(id string) {
+			if ready("Remove") {
+				s.hud.Remove(id)
+			}
+		},
+		"Dialog": func(speaker, message string, choices []string, fn goja.Value) *goja.Promise {
+			return vm.NewPromise(func(resolve, reject func(interface{})) {
+				if !ready("Dialog") {
+					reject("no HUD attached")
+					return
+				}
+
+				s.hud.Dialog(speaker, message, choices, func(choice string) {
+					if function, ok := goja.AssertFunction(fn); ok {
+						function(goja.Undefined(), vm.ToValue(choice))
+					}
+					resolve(choice)
+				})
+			})
+		},
+	})
+}
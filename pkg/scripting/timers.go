@@ -1,9 +1,11 @@
 package scripting
 
 import (
+	"sort"
 	"time"
 
 	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
 	"github.com/dop251/goja"
 )
@@ -17,6 +19,14 @@ type Timer struct {
 	repeat   bool   // for setInterval
 }
 
+// TimerInfo describes a pending timer for the developer shell.
+type TimerInfo struct {
+	ID       int
+	Ticks    uint64 // interval in game ticks
+	NextTick uint64 // game tick when it next fires
+	Repeat   bool   // setInterval rather than setTimeout
+}
+
 /*
 SetTimeout registers a callback function to be run after a while.
 
@@ -108,6 +118,107 @@ func (vm *VM) ClearTimer(id int) {
 	delete(vm.timers, id)
 }
 
+// Timers returns information about the pending timers, sorted by ID.
+func (vm *VM) Timers() []TimerInfo {
+	var result = []TimerInfo{}
+	for _, timer := range vm.timers {
+		result = append(result, TimerInfo{
+			ID:       timer.id,
+			Ticks:    timer.ticks,
+			NextTick: timer.nextTick,
+			Repeat:   timer.repeat,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// TimerState is the saved countdown of a pending timer, for quicksaves.
+type TimerState struct {
+	ID        int    `json:"id"`
+	Ticks     uint64 `json:"ticks"`            // interval in game ticks
+	Repeat    bool   `json:"repeat,omitempty"` // setInterval rather than setTimeout
+	Remaining uint64 `json:"remaining"`        // game ticks until it fires
+}
+
+// TimerStates returns the countdowns of the pending timers, sorted by ID.
+func (vm *VM) TimerStates() []TimerState {
+	var result = []TimerState{}
+	for _, timer := range vm.Timers() {
+		var remaining uint64
+		if timer.NextTick > shmem.Tick {
+			remaining = timer.NextTick - shmem.Tick
+		}
+		result = append(result, TimerState{
+			ID:        timer.ID,
+			Ticks:     timer.Ticks,
+			Repeat:    timer.Repeat,
+			Remaining: remaining,
+		})
+	}
+	return result
+}
+
+/*
+RestoreTimers reschedules the pending timers from a quicksave.
+
+The callbacks are JavaScript functions which can't be saved, so this only
+works for timers with the same IDs in a freshly started level: a script sets
+its timers in the same order each time. A timer is only matched to a saved one
+with the same ID, interval and kind (timeout or interval). Timers which were
+not pending at the quicksave are cleared, and saved timers that no longer
+exist or don't match are skipped.
+*/
+func (vm *VM) RestoreTimers(states []TimerState) {
+	var pending = map[int]TimerState{}
+	for _, state := range states {
+		pending[state.ID] = state
+	}
+
+	var mismatched int
+	for id, timer := range vm.timers {
+		if state, ok := pending[id]; ok && state.Ticks == timer.ticks && state.Repeat == timer.repeat {
+			timer.nextTick = shmem.Tick + state.Remaining
+			delete(pending, id)
+		} else {
+			if ok {
+				mismatched++
+				delete(pending, id)
+			}
+			delete(vm.timers, id)
+		}
+	}
+
+	if mismatched > 0 {
+		log.Warn("%s: RestoreTimers: %d timers didn't match their saved timer and were cleared", vm.Name, mismatched)
+	}
+	if len(pending) > 0 {
+		log.Warn("%s: RestoreTimers: %d saved timers no longer exist", vm.Name, len(pending))
+	}
+}
+
+// SnapshotTimers returns the countdowns of the pending timers of all the
+// actors' scripts, by actor ID.
+func (s *Supervisor) SnapshotTimers() map[string][]TimerState {
+	var result = map[string][]TimerState{}
+	for id, vm := range s.scripts {
+		if states := vm.TimerStates(); len(states) > 0 {
+			result[id] = states
+		}
+	}
+	return result
+}
+
+// RestoreTimers reschedules the timers of all the actors' scripts from a
+// quicksave. See VM.RestoreTimers.
+func (s *Supervisor) RestoreTimers(timers map[string][]TimerState) {
+	for id, vm := range s.scripts {
+		vm.RestoreTimers(timers[id])
+	}
+}
+
 // Schedule the callback to be run in the future.
 func (t *Timer) Schedule() {
 	t.nextTick = shmem.Tick + t.ticks
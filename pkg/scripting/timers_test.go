@@ -0,0 +1,47 @@
+package scripting
+
+import (
+	"testing"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
+)
+
+func TestRestoreTimers(t *testing.T) {
+	shmem.Tick = 0
+
+	// A fresh level sets up its timers again.
+	setup := func() *VM {
+		var vm = NewVM("test")
+		vm.Set("setTimeout", vm.SetTimeout)
+		vm.Set("setInterval", vm.SetInterval)
+		if _, err := vm.Run(`
+			setTimeout(function() {}, 1000);
+			setInterval(function() {}, 500);
+		`); err != nil {
+			t.Fatalf("Run: %s", err)
+		}
+		return vm
+	}
+
+	var saved = setup().TimerStates()
+	if len(saved) != 2 {
+		t.Fatalf("expected 2 timers, got %+v", saved)
+	}
+	saved[0].Remaining = 5
+	saved[1].Remaining = 7
+
+	// The same timers are rescheduled.
+	var vm = setup()
+	vm.RestoreTimers(saved)
+	if timers := vm.Timers(); len(timers) != 2 || timers[0].NextTick != 5 || timers[1].NextTick != 7 {
+		t.Errorf("expected both timers to be restored, got %+v", timers)
+	}
+
+	// A timer with the same ID but a different interval is not the same timer.
+	saved[1].Ticks++
+	vm = setup()
+	vm.RestoreTimers(saved)
+	if timers := vm.Timers(); len(timers) != 1 || timers[0].ID != saved[0].ID {
+		t.Errorf("expected only the matching timer to be restored, got %+v", timers)
+	}
+}
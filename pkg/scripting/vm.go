@@ -12,6 +12,7 @@ import (
 // VM manages a single isolated JavaScript VM.
 type VM struct {
 	Name string
+	id   string // actor ID when managed by a Supervisor
 
 	// Globals available to the scripts.
 	Events *Events
@@ -35,6 +36,13 @@ type VM struct {
 	// setTimeout and setInterval variables.
 	timerLastID int // becomes 1 when first timer is set
 	timers      map[int]*Timer
+
+	// Pending promises resolved by the game tick.
+	promises   []*pendingPromise
+	muPromises sync.Mutex
+
+	// Developer shell debugger, shared by all VMs of the Supervisor.
+	debugger *Debugger
 }
 
 // NewVM creates a new JavaScript VM.
@@ -51,6 +59,7 @@ func NewVM(name string) *VM {
 		subscribe: map[string][]goja.Value{},
 	}
 	vm.Events = NewEvents(vm)
+	vm.vm.SetPromiseRejectionTracker(vm.trackRejections)
 	return vm
 }
 
@@ -60,11 +69,34 @@ func (vm *VM) Run(src string) (goja.Value, error) {
 	return v, err
 }
 
+// ID returns the actor ID of the VM, if it is managed by a Supervisor.
+func (vm *VM) ID() string {
+	return vm.id
+}
+
+// Subscriptions returns the PubSub message names the script subscribes to,
+// with the number of handlers for each.
+func (vm *VM) Subscriptions() map[string]int {
+	vm.muSubscribe.RLock()
+	defer vm.muSubscribe.RUnlock()
+
+	var result = map[string]int{}
+	for name, handlers := range vm.subscribe {
+		result[name] = len(handlers)
+	}
+	return result
+}
+
 // Set a value in the VM.
 func (vm *VM) Set(name string, v interface{}) error {
 	return vm.vm.Set(name, v)
 }
 
+// ToValue converts a Go value into a JavaScript value for the VM.
+func (vm *VM) ToValue(v interface{}) goja.Value {
+	return vm.vm.ToValue(v)
+}
+
 // Get a value from the VM.
 func (vm *VM) Get(name string) goja.Value {
 	return vm.vm.Get(name)
@@ -19,7 +19,7 @@ func PreloadAll() {
 				continue
 			}
 
-			LoadSound(file.Name())
+			loadEffect(file.Name())
 		}
 	}
 }
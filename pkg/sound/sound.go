@@ -1,7 +1,11 @@
 // Package sound manages music and sound effects.
 package sound
 
-import "path/filepath"
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
 
 // Package globals.
 var (
@@ -11,4 +15,44 @@ var (
 	// Root folder on disk where sound and music files should live.
 	SoundRoot = filepath.Join("rtp", "sfx")
 	MusicRoot = filepath.Join("rtp", "music")
+
+	// Volume settings (0.0 to 1.0) from the user's game settings.
+	musicVolume   = 1.0
+	effectsVolume = 1.0
 )
+
+// volumePercent converts a user volume setting to the 0.0-1.0 range.
+func volumePercent(percent int, mute bool) float64 {
+	if mute {
+		return 0
+	}
+	return clampVolume(float64(percent) / 100)
+}
+
+// clampVolume keeps a volume between 0.0 and 1.0.
+func clampVolume(volume float64) float64 {
+	if volume < 0 {
+		return 0
+	} else if volume > 1 {
+		return 1
+	}
+	return volume
+}
+
+// ListMusic returns the music files available in the MusicRoot.
+func ListMusic() []string {
+	var result []string
+	files, err := os.ReadDir(MusicRoot)
+	if err != nil {
+		return result
+	}
+
+	for _, file := range files {
+		switch filepath.Ext(file.Name()) {
+		case ".ogg", ".mp3", ".wav":
+			result = append(result, file.Name())
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -14,6 +14,7 @@ import (
 	"git.kirsle.net/go/audio"
 	"git.kirsle.net/go/audio/sdl"
 	"github.com/veandco/go-sdl2/mix"
+	sdl2 "github.com/veandco/go-sdl2/sdl"
 )
 
 // SDL engine globals.
@@ -25,6 +26,15 @@ var (
 	sounds = map[string]*sdl.Track{}
 	mu     sync.RWMutex
 
+	// Sound effects played on mixer channels, so their volume can be set
+	// (e.g. to fall off with distance from the camera).
+	effects = map[string]*mix.Chunk{}
+	playing = map[string]int{} // de-duplicate PlaySound by channel
+
+	// Music embedded in a level file, by name.
+	embeddedMusic = map[string]*embeddedTrack{}
+	currentMusic  string
+
 	// Supported file extensions, in preference order.
 	SupportedSoundExtensions = []string{
 		".wav",
@@ -119,11 +129,206 @@ func LoadSound(filename string) audio.Playable {
 
 // PlaySound plays the named sound. It will de-duplicate if the same sound is already playing.
 func PlaySound(filename string) {
+	PlaySoundVolume(filename, 1)
+}
+
+// PlaySoundVolume plays the named sound at a volume from 0.0 to 1.0. It will
+// de-duplicate if the same sound is already playing.
+func PlaySoundVolume(filename string, volume float64) {
 	log.Debug("Play sound: %s", filename)
-	sound := LoadSound(filename)
-	if sound != nil && !sound.Playing() {
-		sound.Play(1)
+	mu.RLock()
+	channel, ok := playing[filename]
+	mu.RUnlock()
+	if ok && EffectPlaying(channel) {
+		return
+	}
+
+	if channel = PlayEffect(filename, 0, volume); channel >= 0 {
+		mu.Lock()
+		playing[filename] = channel
+		mu.Unlock()
+	}
+}
+
+// loadEffect loads a sound effect from the SoundRoot into the effects cache.
+func loadEffect(filename string) *mix.Chunk {
+	if engine == nil || !Enabled {
+		return nil
+	}
+
+	mu.RLock()
+	chunk, ok := effects[filename]
+	mu.RUnlock()
+	if ok {
+		return chunk
+	}
+
+	fullpath, err := ResolveFilename(filename)
+	if err != nil {
+		log.Error("Loading sound: %s: %s", filename, err)
+		return nil
+	}
+
+	log.Info("Loading sound: %s", filename)
+	chunk, err = mix.LoadWAV(fullpath)
+	if err != nil {
+		log.Error("sound.loadEffect: failed to load file %s: %s", filename, err)
+		return nil
+	}
+
+	mu.Lock()
+	effects[filename] = chunk
+	mu.Unlock()
+
+	return chunk
+}
+
+/*
+PlayEffect plays a sound effect at a volume from 0.0 to 1.0, which is scaled
+by the user's sound volume setting.
+
+Loops is the number of times to repeat the sound, or -1 to loop forever.
+
+Returns the mixer channel the sound is playing on, to adjust its volume or
+stop it, or -1 if the sound could not be played.
+*/
+func PlayEffect(filename string, loops int, volume float64) int {
+	chunk := loadEffect(filename)
+	if chunk == nil {
+		return -1
+	}
+
+	channel, err := chunk.Play(-1, loops)
+	if err != nil {
+		log.Error("sound.PlayEffect(%s): %s", filename, err)
+		return -1
+	}
+
+	SetEffectVolume(channel, volume)
+	return channel
+}
+
+// SetEffectVolume adjusts the volume (0.0 to 1.0) of a playing sound effect.
+func SetEffectVolume(channel int, volume float64) {
+	if engine == nil || !Enabled || channel < 0 {
+		return
+	}
+	mix.Volume(channel, mixerVolume(volume, effectsVolume))
+}
+
+// EffectPlaying checks whether a sound effect channel is still playing.
+func EffectPlaying(channel int) bool {
+	if engine == nil || !Enabled || channel < 0 {
+		return false
+	}
+	return mix.Playing(channel) == 1
+}
+
+// StopEffect stops a sound effect channel.
+func StopEffect(channel int) {
+	if engine == nil || !Enabled || channel < 0 {
+		return
+	}
+	mix.HaltChannel(channel)
+}
+
+// embeddedTrack is music loaded from memory. The data must be kept alive
+// for as long as the mixer may read from it.
+type embeddedTrack struct {
+	data  []byte
+	music *mix.Music
+}
+
+// PlayMusic plays a music file from the MusicRoot, optionally looping it.
+// If the same music is already playing, it is not restarted.
+func PlayMusic(filename string, loop bool) {
+	if engine == nil || !Enabled || (filename == currentMusic && mix.PlayingMusic()) {
+		return
+	}
+
+	track := LoadMusic(filename)
+	if track == nil {
+		return
+	}
+
+	log.Info("Play music: %s", filename)
+	currentMusic = filename
+	mix.VolumeMusic(mixerVolume(1, musicVolume))
+	track.Play(musicLoops(loop))
+}
+
+// PlayMusicData plays music from memory, e.g. an audio file embedded in a
+// level. The name identifies the music for caching.
+func PlayMusicData(name string, data []byte, loop bool) {
+	if engine == nil || !Enabled || (name == currentMusic && mix.PlayingMusic()) {
+		return
+	}
+
+	mu.RLock()
+	track, ok := embeddedMusic[name]
+	mu.RUnlock()
+
+	if !ok {
+		rw, err := sdl2.RWFromMem(data)
+		if err != nil {
+			log.Error("sound.PlayMusicData(%s): %s", name, err)
+			return
+		}
+
+		mus, err := mix.LoadMUSRW(rw, 1)
+		if err != nil {
+			log.Error("sound.PlayMusicData(%s): %s", name, err)
+			return
+		}
+
+		track = &embeddedTrack{
+			data:  data,
+			music: mus,
+		}
+		mu.Lock()
+		embeddedMusic[name] = track
+		mu.Unlock()
+	}
+
+	log.Info("Play music: %s", name)
+	currentMusic = name
+	mix.VolumeMusic(mixerVolume(1, musicVolume))
+	if err := track.music.Play(musicLoops(loop)); err != nil {
+		log.Error("sound.PlayMusicData(%s): %s", name, err)
+	}
+}
+
+// StopMusic stops the current music.
+func StopMusic() {
+	if engine == nil || !Enabled {
+		return
+	}
+	currentMusic = ""
+	mix.HaltMusic()
+}
+
+// SetVolume applies the user's volume settings, in percent.
+func SetVolume(music, effects int, mute bool) {
+	musicVolume = volumePercent(music, mute)
+	effectsVolume = volumePercent(effects, mute)
+
+	if engine == nil || !Enabled {
+		return
+	}
+	mix.VolumeMusic(mixerVolume(1, musicVolume))
+}
+
+// mixerVolume scales a 0.0-1.0 volume by a user setting to the mixer range.
+func mixerVolume(volume, setting float64) int {
+	return int(clampVolume(volume) * setting * float64(mix.MAX_VOLUME))
+}
+
+// musicLoops gives the loop count to play music.
+func musicLoops(loop bool) int {
+	if loop {
+		return -1
 	}
+	return 1
 }
 
 // ResolveFilename resolves the filename to a sound file on disk.
@@ -31,3 +31,40 @@ func LoadSound(filename string) audio.Playable {
 
 // PlaySound plays the named sound.
 func PlaySound(filename string) {}
+
+// PlaySoundVolume plays the named sound at a volume.
+func PlaySoundVolume(filename string, volume float64) {}
+
+// loadEffect preloads a sound effect.
+func loadEffect(filename string) {}
+
+// PlayEffect plays a sound effect at a volume.
+func PlayEffect(filename string, loops int, volume float64) int {
+	return -1
+}
+
+// SetEffectVolume adjusts the volume of a playing sound effect.
+func SetEffectVolume(channel int, volume float64) {}
+
+// EffectPlaying checks whether a sound effect channel is still playing.
+func EffectPlaying(channel int) bool {
+	return false
+}
+
+// StopEffect stops a sound effect channel.
+func StopEffect(channel int) {}
+
+// PlayMusic plays a music file from the MusicRoot.
+func PlayMusic(filename string, loop bool) {}
+
+// PlayMusicData plays music from memory.
+func PlayMusicData(name string, data []byte, loop bool) {}
+
+// StopMusic stops the current music.
+func StopMusic() {}
+
+// SetVolume applies the user's volume settings, in percent.
+func SetVolume(music, effects int, mute bool) {
+	musicVolume = volumePercent(music, mute)
+	effectsVolume = volumePercent(effects, mute)
+}
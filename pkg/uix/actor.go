@@ -280,6 +280,10 @@ func (a *Actor) AddItem(itemName string, quantity int) {
 		a.inventory[itemName] = quantity
 	}
 	a.muInventory.Unlock()
+
+	if a.LevelCanvas != nil && a.LevelCanvas.OnAddItem != nil {
+		a.LevelCanvas.OnAddItem(a, itemName, quantity)
+	}
 }
 
 // RemoveItem removes a quantity of an item from the actor's inventory.
@@ -316,6 +320,18 @@ func (a *Actor) ClearInventory() {
 	a.muInventory.Unlock()
 }
 
+// RestoreInventory replaces the actor's inventory with a copy of the one
+// given, e.g. when the player character is changed. Unlike AddItem, the items
+// don't count as picked up.
+func (a *Actor) RestoreInventory(inventory map[string]int) {
+	a.muInventory.Lock()
+	a.inventory = map[string]int{}
+	for k, v := range inventory {
+		a.inventory[k] = v
+	}
+	a.muInventory.Unlock()
+}
+
 // HasItem checks the actor's inventory for the item and returns the quantity.
 //
 // A return value of -1 means the item was not found.
@@ -455,3 +471,9 @@ func (a *Actor) ShowLayerNamed(name string) error {
 func (a *Actor) Destroy() {
 	a.flagDestroy = true
 }
+
+// IsDestroyed returns whether the actor was destroyed, and will be removed
+// from the level at the next tick.
+func (a *Actor) IsDestroyed() bool {
+	return a.flagDestroy
+}
@@ -14,3 +14,11 @@ type CollideEvent struct {
 type UseEvent struct {
 	Actor *Actor
 }
+
+// RegionEvent holds data sent to an actor's OnRegionEnter and OnRegionLeave
+// handlers.
+type RegionEvent struct {
+	Region   string // the region's name
+	RegionID string
+	Actor    *Actor // the actor who entered or left the region
+}
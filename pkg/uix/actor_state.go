@@ -0,0 +1,92 @@
+package uix
+
+import (
+	"fmt"
+	"time"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/physics"
+	"git.kirsle.net/go/render"
+	"github.com/dop251/goja"
+)
+
+// ActorState is the runtime state of an actor, saved in a quicksave.
+type ActorState struct {
+	ID          string         `json:"id"`
+	Position    render.Point   `json:"position"`
+	Velocity    physics.Vector `json:"velocity"`
+	Grounded    bool           `json:"grounded,omitempty"`
+	Gravity     bool           `json:"gravity,omitempty"`
+	Hidden      bool           `json:"hidden,omitempty"`
+	Frozen      bool           `json:"frozen,omitempty"`
+	Immortal    bool           `json:"immortal,omitempty"`
+	ActiveLayer int            `json:"layer"`
+	Inventory   map[string]int `json:"inventory,omitempty"`
+
+	// The animation playing, and which of its frames is showing.
+	Animation      string `json:"animation,omitempty"`
+	AnimationFrame int    `json:"animationFrame,omitempty"`
+}
+
+// State returns the runtime state of the actor.
+func (a *Actor) State() ActorState {
+	var state = ActorState{
+		ID:          a.ID(),
+		Position:    a.Position(),
+		Velocity:    a.Velocity(),
+		Grounded:    a.Grounded(),
+		Gravity:     a.hasGravity,
+		Hidden:      a.hidden,
+		Frozen:      a.frozen,
+		Immortal:    a.immortal,
+		ActiveLayer: a.activeLayer,
+		Inventory:   a.Inventory(),
+	}
+
+	if a.activeAnimation != nil {
+		state.Animation = a.activeAnimation.Name
+		state.AnimationFrame = a.activeAnimation.activeLayer
+	}
+
+	return state
+}
+
+/*
+RestoreState sets the runtime state of the actor from a quicksave.
+
+An animation is resumed from the frame it was on, but the JavaScript function
+to call when it finishes can't be saved: the animation ends without calling it.
+*/
+func (a *Actor) RestoreState(state ActorState) error {
+	a.MoveTo(state.Position)
+	a.SetVelocity(state.Velocity)
+	a.SetGrounded(state.Grounded)
+	a.hasGravity = state.Gravity
+	a.hidden = state.Hidden
+	a.frozen = state.Frozen
+	a.immortal = state.Immortal
+
+	a.RestoreInventory(state.Inventory)
+
+	if state.ActiveLayer >= a.LayerCount() {
+		return fmt.Errorf("layer %d out of range for doodad's layers", state.ActiveLayer)
+	} else if err := a.ShowLayer(state.ActiveLayer); err != nil {
+		return err
+	}
+
+	a.StopAnimation()
+	if state.Animation != "" {
+		anim, ok := a.animations[state.Animation]
+		if !ok || state.AnimationFrame >= len(anim.Layers) {
+			log.Warn("Actor(%s) RestoreState: animation %s no longer exists", a.ID(), state.Animation)
+			return nil
+		}
+
+		a.activeAnimation = anim
+		a.animationCallback = goja.Null()
+		anim.activeLayer = state.AnimationFrame
+		anim.nextFrameAt = time.Now().Add(anim.Interval)
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"runtime"
 	"strings"
 
+	"git.kirsle.net/SketchyMaze/doodle/pkg/analytics"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/collision"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/cursor"
@@ -54,7 +55,19 @@ type Canvas struct {
 
 	// Selected draw tool/mode, default Pencil, for editable canvases.
 	Tool      drawtool.Tool
-	BrushSize int // thickness of selected brush
+	BrushSize int             // thickness of selected brush
+	Brush     *drawtool.Brush // shape of the brush, nil for square
+
+	// Symmetry mode mirrors the strokes drawn around an axis (world
+	// coordinates). See SetSymmetry.
+	Symmetry        drawtool.Symmetry
+	SymmetryAxis    render.Point
+	symmetryAxisSet bool // user moved the axis, don't snap it to the center
+
+	// Swatches for the Recolor Tool, which replaces one with the other
+	// inside a selection. See ReplaceSwatch.
+	RecolorFrom *level.Swatch
+	RecolorTo   *level.Swatch
 
 	// MaskColor will force every pixel to render as this color regardless of
 	// the palette index of that pixel. Otherwise pixels behave the same and
@@ -62,9 +75,21 @@ type Canvas struct {
 	// to remove the mask.
 	MaskColor render.Color
 
+	// Behavior view recolors the level by its swatch attributes (solid,
+	// fire, etc.) and outlines the actor hitboxes. Set to level.NoBehavior
+	// (zero value) for normal rendering.
+	Behavior level.Behavior
+
+	// Playtest analytics heatmap drawn over the level in the editor, or nil.
+	// Impl. in canvas_heatmap.go
+	Heatmap *analytics.Heatmap
+
 	// Actor ID to follow the camera on automatically, i.e. the main player.
 	FollowActor string
 
+	// Scripted camera control in Play Mode. Impl. in canvas_camera.go
+	camera camera
+
 	// Debug tools
 	// NoLimitScroll suppresses the scroll limit for bounded levels.
 	NoLimitScroll     bool
@@ -92,6 +117,15 @@ type Canvas struct {
 	// Collision memory for the actors.
 	collidingActors map[*Actor]*Actor // mapping their IDs to each other
 
+	// Level regions: the actors inside each region by ID, and the Trigger
+	// Region Tool state in the editor. Impl. in canvas_regions.go
+	regionOccupants map[string]map[*Actor]bool
+	regionDrag      regionDrag
+	regionLabels    map[*level.Region]*ui.Label
+
+	// Curve and Polygon tool state. Impl. in canvas_vector.go
+	vectorEdit vectorEdit
+
 	// Doodad scripting engine supervisor.
 	// NOTE: initialized and managed by the play_scene.
 	scripting *scripting.Supervisor
@@ -99,6 +133,9 @@ type Canvas struct {
 	// Wallpaper settings.
 	wallpaper *Wallpaper
 
+	// Looping sound effects played by actors. Impl. in canvas_sound.go
+	sounds []*actorSound
+
 	// When the Canvas wants to delete Actors, but ultimately it is upstream
 	// that controls the actors. Upstream should delete them and then reinstall
 	// the actor list from scratch.
@@ -108,8 +145,17 @@ type Canvas struct {
 	// -- WHEN Canvas.Tool is "Link" --
 	// When the Canvas wants to link two actors together. Arguments are the IDs
 	// of the two actors.
-	OnLinkActors func(a, b *Actor)
-	linkFirst    *Actor
+	OnLinkActors    func(a, b *Actor)
+	linkFirst       *Actor
+	linkFirstRegion *level.Region // or a Region clicked first
+
+	// -- WHEN Canvas.Tool is "Trigger Region" --
+	// When a region is clicked on, e.g. to rename or delete it.
+	OnRegionConfig func(*level.Region)
+
+	// -- WHEN Canvas.Tool is "Copy Brush" --
+	// When a custom brush was copied from the drawing.
+	OnBrushCopied func(*drawtool.Brush)
 
 	// Collision handlers for level geometry.
 	OnLevelCollision func(*Actor, *collision.Collide)
@@ -121,6 +167,9 @@ type Canvas struct {
 	// Handler for when a doodad script calls Level.ResetTimer().
 	OnResetTimer func()
 
+	// Handler when an actor picks up an item into its inventory.
+	OnAddItem func(a *Actor, itemName string, quantity int)
+
 	/********
 	 * Editable canvas private variables.
 	 ********/
@@ -291,10 +340,13 @@ func (w *Canvas) Loop(ev *event.State) error {
 	// Process the arrow keys scrolling the level in Edit Mode.
 	// canvas_scrolling.go
 	w.loopEditorScroll(ev)
+	w.unshakeCamera()
 	if err := w.loopFollowActor(ev); err != nil {
 		log.Error("Follow actor: %s", err) // not fatal but nice to know
 	}
+	w.loopCamera() // canvas_camera.go
 	_ = w.loopConstrainScroll()
+	w.shakeCamera()
 
 	// Every so often, eager-load/unload chunk bitmaps to save on memory.
 	if w.level != nil {
@@ -324,6 +376,8 @@ func (w *Canvas) Loop(ev *event.State) error {
 		if err := w.loopActorCollision(); err != nil {
 			log.Error("loopActorCollision: %s", err)
 		}
+		w.loopSounds()
+		w.loopRegions()
 	}
 
 	// If the canvas is editable, only care if it's over our space.
@@ -6,6 +6,8 @@ import (
 	"sort"
 	"strings"
 
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/collision"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/plus/dpp"
@@ -111,8 +113,9 @@ func (w *Canvas) InstallScripts() error {
 		}
 
 		// Security: expose a selective API to the actor to the JS engine.
-		vm.Self = w.MakeSelfAPI(actor)
+		vm.Self = w.MakeSelfAPI(actor, vm)
 		w.MakeScriptAPI(vm)
+		w.MakeSoundAPI(vm, actor)
 		vm.Set("Self", vm.Self)
 
 		// If there is no script attached, do not try and load or call the main() function.
@@ -265,5 +268,35 @@ func (w *Canvas) drawActors(e render.Engine, p render.Point) {
 		// Clean up the canvas size and offset.
 		can.Resize(actorSize) // restore original size in case cropped
 		can.ScrollTo(render.Origin)
+
+		// Outline the hitboxes in the behavior view.
+		if w.Behavior != level.NoBehavior {
+			w.drawActorHitbox(e, p, a)
+		}
 	}
 }
+
+// drawActorHitbox outlines an actor's hitbox, cropped to the Canvas.
+func (w *Canvas) drawActorHitbox(e render.Engine, p render.Point, a *Actor) {
+	var (
+		S      = w.Size()
+		hitbox = collision.GetBoundingRectHitbox(a, a.Hitbox())
+		x1     = p.X + w.Scroll.X + w.ZoomMultiply(hitbox.X) + w.BoxThickness(1)
+		y1     = p.Y + w.Scroll.Y + w.ZoomMultiply(hitbox.Y) + w.BoxThickness(1)
+		x2     = x1 + w.ZoomMultiply(hitbox.W)
+		y2     = y1 + w.ZoomMultiply(hitbox.H)
+	)
+
+	x1, y1 = max(x1, p.X), max(y1, p.Y)
+	x2, y2 = min(x2, p.X+S.W), min(y2, p.Y+S.H)
+	if x2 <= x1 || y2 <= y1 {
+		return // not on screen
+	}
+
+	e.DrawRect(balance.BehaviorHitboxColor, render.Rect{
+		X: x1,
+		Y: y1,
+		W: x2 - x1,
+		H: y2 - y1,
+	})
+}
@@ -0,0 +1,42 @@
+package uix
+
+import (
+	"fmt"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/drawtool"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
+	"git.kirsle.net/go/render"
+)
+
+// copyBrush makes a custom brush from the pixels of the drawing between two
+// world coordinates (Copy Brush tool).
+func (w *Canvas) copyBrush(a, b render.Point) {
+	var rect = render.Rect{
+		X: a.X,
+		Y: a.Y,
+		W: b.X - a.X,
+		H: b.Y - a.Y,
+	}
+	if rect.W < 0 {
+		rect.X, rect.W = b.X, -rect.W
+	}
+	if rect.H < 0 {
+		rect.Y, rect.H = b.Y, -rect.H
+	}
+
+	var points = []render.Point{}
+	for px := range w.chunks.IterViewport(rect) {
+		points = append(points, px.Point())
+	}
+
+	brush, err := drawtool.BrushFromPoints(fmt.Sprintf("%dx%d selection", rect.W, rect.H), points)
+	if err != nil {
+		shmem.FlashError("Couldn't copy the brush: %s", err)
+		return
+	}
+
+	w.Brush = brush
+	if w.OnBrushCopied != nil {
+		w.OnBrushCopied(brush)
+	}
+}
@@ -0,0 +1,330 @@
+package uix
+
+import (
+	"math/rand"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
+	"git.kirsle.net/go/render"
+	"git.kirsle.net/go/ui"
+)
+
+/*
+Scripted camera control for Play Mode.
+
+Normally the camera follows an actor (Canvas.FollowActor) and stays within the
+bounds of the page. Doodad scripts can take over via the Camera API to:
+
+  - Pan smoothly to a point and hold there, until released back to following.
+  - Zoom in or out temporarily.
+  - Shake the screen.
+  - Clamp scrolling to a rectangle of the level.
+
+Levels may also have designer-placed Camera Regions: while the followed actor
+is inside one, the camera is kept within its bounds, Metroidvania style.
+*/
+type camera struct {
+	// Pan to a point (world coordinates) and hold there.
+	panning  bool
+	holding  bool
+	panTo    render.Point
+	panSpeed int
+	panPrev  render.Point
+	onArrive func()
+
+	// Temporary zoom.
+	zoomed      bool
+	zoomRestore int
+	zoomUntil   uint64
+
+	// Screen shake.
+	shakeIntensity int
+	shakeStart     uint64
+	shakeUntil     uint64
+	shakeOffset    render.Point
+
+	// Scripted bounds, which override the level's camera regions.
+	bounds *render.Rect
+}
+
+// CameraPanTo smoothly scrolls the camera to center on a world coordinate
+// and holds it there until CameraRelease. Speed is in pixels per tick, or
+// 0 for the default. The callback is called when the camera arrives.
+func (w *Canvas) CameraPanTo(p render.Point, speed int, onArrive func()) {
+	if speed <= 0 {
+		speed = balance.CameraPanSpeed
+	}
+
+	// A previous pan that never arrived.
+	if w.camera.onArrive != nil {
+		w.camera.onArrive()
+	}
+
+	w.camera.panning = true
+	w.camera.holding = true
+	w.camera.panTo = p
+	w.camera.panSpeed = speed
+	w.camera.panPrev = render.Point{X: w.Scroll.X + 1} // not equal to Scroll
+	w.camera.onArrive = onArrive
+}
+
+// CameraRelease returns the camera to following its actor.
+func (w *Canvas) CameraRelease() {
+	if w.camera.onArrive != nil {
+		w.camera.onArrive()
+	}
+	w.camera.panning = false
+	w.camera.holding = false
+	w.camera.onArrive = nil
+}
+
+// CameraZoom zooms the camera for a number of ticks, or until CameraResetZoom
+// if the duration is zero. The zoom level is as in Canvas.Zoom.
+func (w *Canvas) CameraZoom(zoom int, ticks uint64) {
+	if zoom < balance.CameraMinZoom {
+		zoom = balance.CameraMinZoom
+	} else if zoom > balance.CameraMaxZoom {
+		zoom = balance.CameraMaxZoom
+	}
+
+	if !w.camera.zoomed {
+		w.camera.zoomed = true
+		w.camera.zoomRestore = w.Zoom
+	}
+
+	w.camera.zoomUntil = 0
+	if ticks > 0 {
+		w.camera.zoomUntil = shmem.Tick + ticks
+	}
+	w.setZoomCentered(zoom)
+}
+
+// CameraResetZoom undoes a CameraZoom.
+func (w *Canvas) CameraResetZoom() {
+	if w.camera.zoomed {
+		w.camera.zoomed = false
+		w.camera.zoomUntil = 0
+		w.setZoomCentered(w.camera.zoomRestore)
+	}
+}
+
+// CameraShake shakes the screen by up to intensity pixels, calming down over
+// a number of ticks.
+func (w *Canvas) CameraShake(intensity int, ticks uint64) {
+	w.camera.shakeIntensity = intensity
+	w.camera.shakeStart = shmem.Tick
+	w.camera.shakeUntil = shmem.Tick + ticks
+}
+
+// SetCameraBounds clamps the camera inside a rectangle of the level (world
+// coordinates with width and height), or nil to clear it.
+func (w *Canvas) SetCameraBounds(rect *render.Rect) {
+	w.camera.bounds = rect
+}
+
+// CameraCenter returns the world coordinate at the center of the screen.
+func (w *Canvas) CameraCenter() render.Point {
+	var (
+		S    = w.Size()
+		mult = w.GetZoomMultiplier()
+	)
+	return render.Point{
+		X: int((float64(-w.Scroll.X) + float64(S.W)/2) / mult),
+		Y: int((float64(-w.Scroll.Y) + float64(S.H)/2) / mult),
+	}
+}
+
+// ResetCamera releases all scripted camera control, e.g. when the player
+// respawns at a checkpoint.
+func (w *Canvas) ResetCamera() {
+	w.unshakeCamera()
+	w.camera.shakeUntil = 0
+	w.CameraRelease()
+	w.CameraResetZoom()
+	w.camera.bounds = nil
+}
+
+// scrollToCenter returns the Scroll value that centers on a world coordinate.
+func (w *Canvas) scrollToCenter(p render.Point) render.Point {
+	var (
+		S    = w.Size()
+		mult = w.GetZoomMultiplier()
+	)
+	return render.Point{
+		X: -(int(float64(p.X)*mult) - S.W/2),
+		Y: -(int(float64(p.Y)*mult) - S.H/2),
+	}
+}
+
+// setZoomCentered changes the zoom level, keeping the same point at the
+// center of the screen.
+func (w *Canvas) setZoomCentered(zoom int) {
+	var center = w.CameraCenter()
+	w.Zoom = zoom
+	w.Scroll = w.scrollToCenter(center)
+}
+
+// cameraBounds returns the rect the camera should be kept within: the
+// scripted bounds, or the camera region the followed actor is in.
+func (w *Canvas) cameraBounds() (render.Rect, bool) {
+	if w.camera.bounds != nil {
+		return *w.camera.bounds, true
+	}
+
+	if w.level == nil || len(w.level.CameraRegions) == 0 || w.FollowActor == "" {
+		return render.Rect{}, false
+	}
+
+	for _, actor := range w.actors {
+		if actor.ID() != w.FollowActor {
+			continue
+		}
+
+		var (
+			P = actor.Position()
+			S = actor.Size()
+		)
+		if region, ok := w.level.CameraRegionAt(render.NewPoint(P.X+S.W/2, P.Y+S.H/2)); ok {
+			return region.Rect(), true
+		}
+		break
+	}
+
+	return render.Rect{}, false
+}
+
+// clampScroll returns the Scroll value that keeps the view inside of a rect
+// of the level. If the rect is smaller than the screen, it is centered.
+func (w *Canvas) clampScroll(bounds render.Rect) render.Point {
+	var (
+		S    = w.Size()
+		mult = w.GetZoomMultiplier()
+	)
+
+	clamp := func(scroll, size, min, length int) int {
+		var (
+			view  = float64(size) / mult
+			start = float64(-scroll) / mult
+		)
+
+		if float64(length) <= view {
+			start = float64(min) + (float64(length)-view)/2
+		} else if start < float64(min) {
+			start = float64(min)
+		} else if start+view > float64(min+length) {
+			start = float64(min+length) - view
+		}
+
+		return -int(start * mult)
+	}
+
+	return render.Point{
+		X: clamp(w.Scroll.X, S.W, bounds.X, bounds.W),
+		Y: clamp(w.Scroll.Y, S.H, bounds.Y, bounds.H),
+	}
+}
+
+// approachScroll moves the Scroll toward a target by up to speed pixels.
+func (w *Canvas) approachScroll(target render.Point, speed int) {
+	step := func(from, to int) int {
+		if delta := to - from; delta > speed {
+			return from + speed
+		} else if delta < -speed {
+			return from - speed
+		}
+		return to
+	}
+
+	w.Scroll.X = step(w.Scroll.X, target.X)
+	w.Scroll.Y = step(w.Scroll.Y, target.Y)
+}
+
+/*
+Loop() subroutine for the scripted camera: panning, zoom timeout and bounds.
+
+Called after loopFollowActor and before loopConstrainScroll.
+*/
+func (w *Canvas) loopCamera() {
+	var cam = &w.camera
+
+	// Temporary zoom ran out.
+	if cam.zoomUntil > 0 && shmem.Tick >= cam.zoomUntil {
+		w.CameraResetZoom()
+	}
+
+	if cam.panning {
+		// Arrived, or the page bounds have stopped us from getting closer.
+		var target = w.scrollToCenter(cam.panTo)
+		if w.Scroll == target || w.Scroll == cam.panPrev {
+			cam.panning = false
+			if cam.onArrive != nil {
+				var onArrive = cam.onArrive
+				cam.onArrive = nil
+				onArrive()
+			}
+			return
+		}
+
+		cam.panPrev = w.Scroll
+		w.approachScroll(target, cam.panSpeed)
+		return
+	}
+
+	// Keep within the camera bounds, sliding over to a new region.
+	if !cam.holding && !w.Editable {
+		if bounds, ok := w.cameraBounds(); ok {
+			w.approachScroll(w.clampScroll(bounds), balance.FollowActorMaxScrollSpeed)
+		}
+	}
+}
+
+// shakeCamera offsets the Scroll for screen shake, after all the other
+// scrolling logic has run. The offset is taken back off by unshakeCamera at
+// the start of the next tick.
+func (w *Canvas) shakeCamera() {
+	var cam = &w.camera
+	if shmem.Tick >= cam.shakeUntil || cam.shakeIntensity <= 0 {
+		return
+	}
+
+	// Calm down over the duration of the shake.
+	var (
+		remaining = float64(cam.shakeUntil-shmem.Tick) / float64(cam.shakeUntil-cam.shakeStart)
+		amplitude = int(float64(cam.shakeIntensity) * remaining)
+	)
+	if amplitude < 1 {
+		return
+	}
+
+	cam.shakeOffset = render.Point{
+		X: rand.Intn(amplitude*2+1) - amplitude,
+		Y: rand.Intn(amplitude*2+1) - amplitude,
+	}
+	w.Scroll.Add(cam.shakeOffset)
+}
+
+// unshakeCamera removes the screen shake offset from the last tick.
+func (w *Canvas) unshakeCamera() {
+	if !w.camera.shakeOffset.IsZero() {
+		w.Scroll.Subtract(w.camera.shakeOffset)
+		w.camera.shakeOffset = render.Point{}
+	}
+}
+
+// presentCameraRegions outlines the level's camera regions in the editor.
+func (w *Canvas) presentCameraRegions(e render.Engine) {
+	if w.level == nil {
+		return
+	}
+
+	var P = ui.AbsolutePosition(w)
+	for _, region := range w.level.CameraRegions {
+		var rect = region.Rect()
+		e.DrawRect(balance.CameraRegionColor, render.Rect{
+			X: P.X + w.Scroll.X + w.BoxThickness(1) + w.ZoomMultiply(rect.X),
+			Y: P.Y + w.Scroll.Y + w.BoxThickness(1) + w.ZoomMultiply(rect.Y),
+			W: w.ZoomMultiply(rect.W),
+			H: w.ZoomMultiply(rect.H),
+		})
+	}
+}
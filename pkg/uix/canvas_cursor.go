@@ -26,8 +26,8 @@ func (w *Canvas) IsCursorOver() bool {
 // presentCursor draws something at the mouse cursor on the Canvas.
 //
 // This is currently used in Edit Mode when you're drawing a shape with a thick
-// brush size, and draws a "preview rect" under the cursor of how big a click
-// will be at that size.
+// brush size or a custom brush, and draws an outline under the cursor of the
+// shape a click will make.
 func (w *Canvas) presentCursor(e render.Engine) {
 	// Are we to show a custom mouse cursor?
 	if w.FancyCursors {
@@ -54,23 +54,17 @@ func (w *Canvas) presentCursor(e render.Engine) {
 	// Are we editing with a thick brush?
 	if w.Tool == drawtool.LineTool || w.Tool == drawtool.RectTool ||
 		w.Tool == drawtool.PencilTool || w.Tool == drawtool.EllipseTool ||
-		w.Tool == drawtool.EraserTool && w.Editable {
+		w.Tool == drawtool.FilledRectTool || w.Tool == drawtool.FilledEllipseTool ||
+		w.Tool.IsVector() || w.Tool == drawtool.EraserTool && w.Editable {
 
-		// Draw a box where the brush size is.
-		if w.BrushSize > 0 {
-			var r = w.BrushSize
-			rect := render.Rect{
-				X: shmem.Cursor.X - r,
-				Y: shmem.Cursor.Y - r,
-				W: r * 2,
-				H: r * 2,
+		// Outline the shape of the brush, scaled to the zoom level.
+		if w.Brush.IsThick(w.BrushSize) {
+			for _, pt := range w.Brush.Outline(w.BrushSize) {
+				e.DrawPoint(render.Black, render.Point{
+					X: shmem.Cursor.X + w.ZoomMultiply(pt.X),
+					Y: shmem.Cursor.Y + w.ZoomMultiply(pt.Y),
+				})
 			}
-			e.DrawRect(render.Black, rect)
-			rect.X++
-			rect.Y++
-			rect.W -= 2
-			rect.H -= 2
-			e.DrawRect(render.RGBA(153, 153, 153, 153), rect)
 		}
 	}
 
@@ -39,8 +39,33 @@ func (w *Canvas) commitStroke(tool drawtool.Tool, addHistory bool) {
 	// Mark the canvas as modified.
 	w.modified = true
 
+	// Symmetry mode: mirror the new stroke around the axis. The mirrors are
+	// kept on the stroke so they share its undo step (and are replayed by Redo).
+	if addHistory && w.Symmetry != drawtool.NoSymmetry {
+		w.currentStroke.Mirrors = w.Symmetry.Mirror(w.currentStroke, w.SymmetryAxis)
+	}
+
+	w.commitStrokePixels(w.currentStroke)
+	for _, mirror := range w.currentStroke.Mirrors {
+		w.commitStrokePixels(mirror)
+	}
+
+	// Add the stroke to level history.
+	if addHistory {
+		w.strokeToHistory(w.currentStroke)
+	}
+
+	w.RemoveStroke(w.currentStroke)
+	w.currentStroke = nil
+
+	w.lastPixel = nil
+}
+
+// commitStrokePixels writes the pixels of a (world coordinate) stroke to the
+// drawing and remembers the pixels it replaced for Undo.
+func (w *Canvas) commitStrokePixels(stroke *drawtool.Stroke) {
 	var (
-		deleting = w.currentStroke.Shape == drawtool.Eraser
+		deleting = stroke.Shape == drawtool.Eraser
 		dedupe   = map[render.Point]interface{}{} // don't revisit the same point twice
 
 		// Helper functions to set pixels on the level while storing the original
@@ -49,7 +74,7 @@ func (w *Canvas) commitStroke(tool drawtool.Tool, addHistory bool) {
 			// Take note of what pixel was originally here before we change it.
 			if swatch, err := w.chunks.Get(pt); err == nil {
 				if _, ok := dedupe[pt]; !ok {
-					w.currentStroke.OriginalPoints[pt] = swatch
+					stroke.OriginalPoints[pt] = swatch
 					dedupe[pt] = nil
 				}
 			}
@@ -68,7 +93,7 @@ func (w *Canvas) commitStroke(tool drawtool.Tool, addHistory bool) {
 			for pt := range w.chunks.IterViewport(rect) {
 				point := pt.Point()
 				if _, ok := dedupe[point]; !ok {
-					w.currentStroke.OriginalPoints[pt.Point()] = pt.Swatch
+					stroke.OriginalPoints[pt.Point()] = pt.Swatch
 					dedupe[point] = nil
 				}
 			}
@@ -85,49 +110,33 @@ func (w *Canvas) commitStroke(tool drawtool.Tool, addHistory bool) {
 	)
 
 	var swatch *level.Swatch
-	if v, ok := w.currentStroke.ExtraData.(*level.Swatch); ok {
+	if v, ok := stroke.ExtraData.(*level.Swatch); ok {
 		swatch = v
 	}
 
-	if w.currentStroke.Thickness > 0 {
+	if stroke.IsThick() {
 		// Eraser Tool only: record which pixels will be blown away by this.
 		// This is SLOW for thick (rect-based) lines, but eraser tool must have it.
 		if deleting {
-			for rect := range w.currentStroke.IterThickPoints() {
+			for rect := range stroke.IterThickPoints() {
 				readRect(rect)
 			}
 		}
-		for rect := range w.currentStroke.IterThickPoints() {
+		for rect := range stroke.IterThickPoints() {
 			setRect(rect, swatch)
 		}
 	} else {
-		for pt := range w.currentStroke.IterPoints() {
+		for pt := range stroke.IterPoints() {
 			// note: set already records the original pixel if changing it.
 			set(pt, swatch)
 		}
 	}
-
-	// Add the stroke to level history.
-	if addHistory {
-		w.strokeToHistory(w.currentStroke)
-	}
-
-	w.RemoveStroke(w.currentStroke)
-	w.currentStroke = nil
-
-	w.lastPixel = nil
 }
 
 // Add a recently drawn stroke to the UndoHistory.
 func (w *Canvas) strokeToHistory(stroke *drawtool.Stroke) {
-	if w.level != nil {
-		w.level.UndoHistory.AddStroke(stroke)
-	} else if w.doodad != nil {
-		if w.doodad.UndoHistory == nil {
-			// HACK: if UndoHistory was not initialized properly.
-			w.doodad.UndoHistory = drawtool.NewHistory(balance.UndoHistory)
-		}
-		w.doodad.UndoHistory.AddStroke(stroke)
+	if undoer := w.History(); undoer != nil {
+		undoer.AddStroke(stroke)
 	}
 }
 
@@ -143,6 +152,11 @@ func (w *Canvas) loopEditable(ev *event.State) error {
 		}
 	)
 
+	// Switched away from a vector tool? Commit the shape that was being edited.
+	if !w.Tool.IsVector() && w.currentStroke != nil && w.currentStroke.Shape.IsVector() {
+		w.commitVector()
+	}
+
 	// If the actual cursor is not over the actual Canvas UI element, don't
 	// pay any attention to clicks. I added this when I saw you were able to
 	// accidentally draw (with large brush size) when clicking on the Palette
@@ -249,6 +263,7 @@ func (w *Canvas) loopEditable(ev *event.State) error {
 				w.currentStroke = drawtool.NewStroke(drawtool.Freehand, w.Palette.ActiveSwatch.Color)
 				w.currentStroke.Pattern = w.Palette.ActiveSwatch.Pattern
 				w.currentStroke.Thickness = w.BrushSize
+				w.currentStroke.Brush = w.Brush
 				w.currentStroke.ExtraData = w.Palette.ActiveSwatch
 				w.AddStroke(w.currentStroke)
 			}
@@ -303,6 +318,7 @@ func (w *Canvas) loopEditable(ev *event.State) error {
 				w.currentStroke = drawtool.NewStroke(drawtool.Line, w.Palette.ActiveSwatch.Color)
 				w.currentStroke.Pattern = w.Palette.ActiveSwatch.Pattern
 				w.currentStroke.Thickness = w.BrushSize
+				w.currentStroke.Brush = w.Brush
 				w.currentStroke.ExtraData = w.Palette.ActiveSwatch
 				w.currentStroke.PointA = render.NewPoint(cursor.X, cursor.Y)
 				w.AddStroke(w.currentStroke)
@@ -313,7 +329,7 @@ func (w *Canvas) loopEditable(ev *event.State) error {
 			w.commitStroke(w.Tool, true)
 		}
 
-	case drawtool.RectTool:
+	case drawtool.RectTool, drawtool.FilledRectTool:
 		// If no swatch is active, do nothing with mouse clicks.
 		if w.Palette.ActiveSwatch == nil {
 			return nil
@@ -326,6 +342,8 @@ func (w *Canvas) loopEditable(ev *event.State) error {
 				w.currentStroke = drawtool.NewStroke(drawtool.Rectangle, w.Palette.ActiveSwatch.Color)
 				w.currentStroke.Pattern = w.Palette.ActiveSwatch.Pattern
 				w.currentStroke.Thickness = w.BrushSize
+				w.currentStroke.Brush = w.Brush
+				w.currentStroke.Fill = w.Tool == drawtool.FilledRectTool
 				w.currentStroke.ExtraData = w.Palette.ActiveSwatch
 				w.currentStroke.PointA = render.NewPoint(cursor.X, cursor.Y)
 				w.AddStroke(w.currentStroke)
@@ -336,7 +354,7 @@ func (w *Canvas) loopEditable(ev *event.State) error {
 			w.commitStroke(w.Tool, true)
 		}
 
-	case drawtool.EllipseTool:
+	case drawtool.EllipseTool, drawtool.FilledEllipseTool:
 		if w.Palette.ActiveSwatch == nil {
 			return nil
 		}
@@ -346,6 +364,8 @@ func (w *Canvas) loopEditable(ev *event.State) error {
 				w.currentStroke = drawtool.NewStroke(drawtool.Ellipse, w.Palette.ActiveSwatch.Color)
 				w.currentStroke.Pattern = w.Palette.ActiveSwatch.Pattern
 				w.currentStroke.Thickness = w.BrushSize
+				w.currentStroke.Brush = w.Brush
+				w.currentStroke.Fill = w.Tool == drawtool.FilledEllipseTool
 				w.currentStroke.ExtraData = w.Palette.ActiveSwatch
 				w.currentStroke.PointA = render.NewPoint(cursor.X, cursor.Y)
 				w.AddStroke(w.currentStroke)
@@ -356,6 +376,9 @@ func (w *Canvas) loopEditable(ev *event.State) error {
 			w.commitStroke(w.Tool, true)
 		}
 
+	case drawtool.CurveTool, drawtool.PolylineTool, drawtool.PolygonTool, drawtool.FilledPolygonTool:
+		return w.loopEditVector(ev, cursor)
+
 	case drawtool.TextTool:
 		// The Text Tool popup should initialize this for us, if somehow not
 		// initialized skip this tool processing.
@@ -477,6 +500,7 @@ func (w *Canvas) loopEditable(ev *event.State) error {
 				// wallpaper during the stroke.
 				w.currentStroke = drawtool.NewStroke(drawtool.Eraser, render.White)
 				w.currentStroke.Thickness = w.BrushSize
+				w.currentStroke.Brush = w.Brush
 				w.AddStroke(w.currentStroke)
 			}
 
@@ -586,6 +610,68 @@ func (w *Canvas) loopEditable(ev *event.State) error {
 			w.OnDeleteActors(deleteActors)
 		}
 
+	case drawtool.TriggerRegionTool:
+		return w.loopEditRegions(ev)
+
+	case drawtool.SymmetryTool:
+		// Click or drag to move the mirror axis.
+		if keybind.LeftClick(ev) {
+			w.SymmetryAxis = w.WorldIndexAt(shmem.Cursor)
+			w.symmetryAxisSet = true
+		}
+
+	case drawtool.BrushTool:
+		// Drag out a rectangle around the pixels to copy as a custom brush.
+		if keybind.LeftClick(ev) {
+			if w.currentStroke == nil {
+				w.currentStroke = drawtool.NewStroke(drawtool.Rectangle, balance.BrushSelectColor)
+				w.currentStroke.PointA = render.NewPoint(cursor.X, cursor.Y)
+				w.AddStroke(w.currentStroke)
+			}
+
+			w.currentStroke.PointB = render.NewPoint(cursor.X, cursor.Y)
+		} else if w.currentStroke != nil {
+			var stroke = w.ZoomStroke(w.currentStroke)
+			w.RemoveStroke(w.currentStroke)
+			w.currentStroke = nil
+			w.copyBrush(stroke.PointA, stroke.PointB)
+		}
+
+	case drawtool.RecolorTool:
+		return w.loopEditRecolor(ev, cursor)
+
+	case drawtool.CameraRegionTool:
+		// Drag out a rectangle to add a camera region, or click inside one
+		// to remove it.
+		if keybind.LeftClick(ev) {
+			if w.currentStroke == nil {
+				w.currentStroke = drawtool.NewStroke(drawtool.Rectangle, balance.CameraRegionColor)
+				w.currentStroke.PointA = render.NewPoint(cursor.X, cursor.Y)
+				w.AddStroke(w.currentStroke)
+			}
+
+			w.currentStroke.PointB = render.NewPoint(cursor.X, cursor.Y)
+		} else if w.currentStroke != nil {
+			var stroke = w.ZoomStroke(w.currentStroke)
+			w.RemoveStroke(w.currentStroke)
+			w.currentStroke = nil
+
+			if w.level == nil {
+				return nil
+			}
+
+			var delta = stroke.PointB.Compare(stroke.PointA)
+			if delta.X*delta.X+delta.Y*delta.Y < balance.CameraRegionMinDrag*balance.CameraRegionMinDrag {
+				if w.level.RemoveCameraRegionAt(stroke.PointA) {
+					shmem.Flash("Camera region removed.")
+				}
+			} else {
+				region := w.level.AddCameraRegion(stroke.PointA, stroke.PointB)
+				shmem.Flash("Camera region added: %dx%d", region.W, region.H)
+			}
+			w.modified = true
+		}
+
 	case drawtool.LinkTool:
 		// See if any of the actors are below the mouse cursor.
 		var WP = w.WorldIndexAt(cursor)
@@ -641,6 +727,11 @@ func (w *Canvas) loopEditable(ev *event.State) error {
 				})
 			}
 		}
+
+		// Clicked on a level region instead of a doodad?
+		if keybind.LeftClick(ev) && w.LinkRegionAt(WP) {
+			keybind.ClearLeftClick(ev)
+		}
 	}
 
 	return nil
@@ -0,0 +1,41 @@
+package uix
+
+import (
+	"git.kirsle.net/SketchyMaze/doodle/pkg/analytics"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/go/render"
+)
+
+// presentHeatmap draws the playtest analytics heatmap over the level, under
+// the actors. Only the cells which are visible on the canvas are drawn.
+func (w *Canvas) presentHeatmap(e render.Engine, p render.Point) {
+	if w.Heatmap == nil || !w.Editable {
+		return
+	}
+
+	var (
+		S        = w.Size()
+		bounds   = render.Rect{X: p.X, Y: p.Y, W: S.W, H: S.H}
+		toScreen = func(rect render.Rect) render.Rect {
+			return render.Rect{
+				X: p.X + w.Scroll.X + w.BoxThickness(1) + w.ZoomMultiply(rect.X),
+				Y: p.Y + w.Scroll.Y + w.BoxThickness(1) + w.ZoomMultiply(rect.Y),
+				W: w.ZoomMultiply(rect.W),
+				H: w.ZoomMultiply(rect.H),
+			}
+		}
+	)
+
+	for cell, color := range w.Heatmap.Cells {
+		if rect := toScreen(cell.Rect()); rect.Intersects(bounds) {
+			e.DrawBox(color, rect)
+		}
+	}
+
+	// Outline the cells of the checkpoints.
+	for _, point := range w.Heatmap.Checkpoints {
+		if rect := toScreen(analytics.CellAt(point).Rect()); rect.Intersects(bounds) {
+			e.DrawRect(balance.HeatmapCheckpoint, rect)
+		}
+	}
+}
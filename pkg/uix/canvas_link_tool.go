@@ -10,6 +10,13 @@ import (
 
 // LinkAdd adds an actor to be linked in the Link tool.
 func (w *Canvas) LinkAdd(a *Actor) error {
+	// A region was clicked first?
+	if w.linkFirstRegion != nil {
+		w.toggleRegionLink(w.linkFirstRegion, a)
+		w.linkFirstRegion = nil
+		return nil
+	}
+
 	if w.linkFirst == nil {
 		// First click, hold onto this actor.
 		w.linkFirst = a
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/sprites"
 	"git.kirsle.net/go/render"
@@ -89,7 +90,9 @@ func (w *Canvas) Present(e render.Engine, p render.Point) {
 	for coord := range w.chunks.IterViewportChunks(Viewport) {
 		if chunk, ok := w.chunks.GetChunk(coord); ok {
 			var tex render.Texturer
-			if w.MaskColor != render.Invisible {
+			if w.Behavior != level.NoBehavior {
+				tex = chunk.TextureBehavior(e, w.Behavior)
+			} else if w.MaskColor != render.Invisible {
 				tex = chunk.TextureMasked(e, w.MaskColor)
 			} else {
 				tex = chunk.Texture(e)
@@ -217,6 +220,7 @@ func (w *Canvas) Present(e render.Engine, p render.Point) {
 		}
 	}
 
+	w.presentHeatmap(e, p)
 	w.drawActors(e, p)
 	w.presentStrokes(e)
 	w.presentDoodadButtons(e)
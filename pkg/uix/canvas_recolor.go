@@ -0,0 +1,77 @@
+package uix
+
+import (
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/drawtool"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/keybind"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
+	"git.kirsle.net/go/render"
+	"git.kirsle.net/go/render/event"
+)
+
+/*
+ReplaceSwatch recolors every pixel of one swatch to another on the canvas,
+within a rect (world coordinates) or across the whole drawing if the rect
+is zero.
+
+The change is added to the Undo history as a single step. Returns the number
+of pixels that were changed.
+*/
+func (w *Canvas) ReplaceSwatch(from, to *level.Swatch, within render.Rect) int {
+	if from == nil || to == nil || from == to {
+		return 0
+	}
+
+	var points = w.chunks.ReplaceSwatch(from, to, within)
+	if len(points) == 0 {
+		return 0
+	}
+
+	// A freehand stroke over the changed pixels: Undo restores the original
+	// swatch from OriginalPoints and Redo sets them to the ExtraData swatch.
+	stroke := drawtool.NewStroke(drawtool.Freehand, to.Color)
+	stroke.ExtraData = to
+	for _, pt := range points {
+		stroke.AddPoint(pt)
+		stroke.OriginalPoints[pt] = from
+	}
+
+	w.strokeToHistory(stroke)
+	w.modified = true
+	return len(points)
+}
+
+// loopEditRecolor handles the Recolor Tool: drag out a rectangle to replace
+// the RecolorFrom swatch with RecolorTo inside of it.
+func (w *Canvas) loopEditRecolor(ev *event.State, cursor render.Point) error {
+	if keybind.LeftClick(ev) {
+		if w.currentStroke == nil {
+			w.currentStroke = drawtool.NewStroke(drawtool.Rectangle, balance.RecolorSelectColor)
+			w.currentStroke.PointA = render.NewPoint(cursor.X, cursor.Y)
+			w.AddStroke(w.currentStroke)
+		}
+
+		w.currentStroke.PointB = render.NewPoint(cursor.X, cursor.Y)
+	} else if w.currentStroke != nil {
+		var stroke = w.ZoomStroke(w.currentStroke)
+		w.RemoveStroke(w.currentStroke)
+		w.currentStroke = nil
+
+		if w.RecolorFrom == nil || w.RecolorTo == nil {
+			shmem.FlashError("Recolor Tool: choose the colors to replace in the Palette window first.")
+			return nil
+		}
+
+		var rect = render.Rect{
+			X: min(stroke.PointA.X, stroke.PointB.X),
+			Y: min(stroke.PointA.Y, stroke.PointB.Y),
+			W: render.AbsInt(stroke.PointB.X-stroke.PointA.X) + 1,
+			H: render.AbsInt(stroke.PointB.Y-stroke.PointA.Y) + 1,
+		}
+		var count = w.ReplaceSwatch(w.RecolorFrom, w.RecolorTo, rect)
+		shmem.Flash("Replaced %d pixels of %s with %s.", count, w.RecolorFrom.Name, w.RecolorTo.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,379 @@
+package uix
+
+import (
+	"fmt"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/drawtool"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/keybind"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/scripting"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
+	"git.kirsle.net/go/render"
+	"git.kirsle.net/go/render/event"
+	"git.kirsle.net/go/ui"
+)
+
+// Functions relating to the level's named trigger Regions.
+
+// Trigger Region Tool drag modes.
+const (
+	regionDragNone = iota
+	regionDragCreate
+	regionDragMove
+	regionDragResize
+)
+
+// regionDrag is the state of a mouse drag with the Trigger Region Tool.
+type regionDrag struct {
+	mode   int
+	region *level.Region
+	start  render.Point // world coordinate of the mouse down
+	orig   render.Rect  // the region before it was moved or resized
+}
+
+/*
+Loop() subroutine for Play Mode to fire the OnRegionEnter and OnRegionLeave
+events when actors enter or leave the level's regions.
+
+The events go to the actor that entered or left, and to every actor linked to
+the region.
+*/
+func (w *Canvas) loopRegions() {
+	if w.level == nil || len(w.level.Regions) == 0 {
+		return
+	}
+
+	if w.regionOccupants == nil {
+		w.regionOccupants = map[string]map[*Actor]bool{}
+	}
+
+	for id, region := range w.level.Regions {
+		var (
+			rect    = region.Rect()
+			current = []*Actor{}
+		)
+
+		for _, actor := range w.actors {
+			if actor.flagDestroy {
+				continue
+			}
+
+			var box = render.Rect{
+				X: actor.Position().X,
+				Y: actor.Position().Y,
+				W: actor.Size().W,
+				H: actor.Size().H,
+			}
+			if box.Intersects(rect) {
+				current = append(current, actor)
+			}
+		}
+
+		entered, left, occupants := regionChanges(w.regionOccupants[id], current)
+		for _, actor := range entered {
+			w.fireRegionEvent(scripting.RegionEnterEvent, region, actor)
+		}
+		for _, actor := range left {
+			w.fireRegionEvent(scripting.RegionLeaveEvent, region, actor)
+		}
+
+		w.regionOccupants[id] = occupants
+	}
+}
+
+// regionChanges compares the actors in a region on the previous tick with the
+// ones in it now, and returns those that entered and left it, and the new set
+// of occupants.
+func regionChanges(previous map[*Actor]bool, current []*Actor) (entered, left []*Actor, occupants map[*Actor]bool) {
+	occupants = map[*Actor]bool{}
+	for _, actor := range current {
+		occupants[actor] = true
+		if !previous[actor] {
+			entered = append(entered, actor)
+		}
+	}
+
+	for actor := range previous {
+		if !occupants[actor] {
+			left = append(left, actor)
+		}
+	}
+	return
+}
+
+// fireRegionEvent sends a region event to the actor and the region's links.
+func (w *Canvas) fireRegionEvent(name string, region *level.Region, actor *Actor) {
+	var (
+		ev = &RegionEvent{
+			Region:   region.Name,
+			RegionID: region.ID(),
+			Actor:    actor,
+		}
+		ids = append([]string{actor.ID()}, region.Links...)
+	)
+
+	for _, id := range ids {
+		vm, err := w.scripting.GetVM(id)
+		if err != nil {
+			continue
+		}
+
+		if name == scripting.RegionEnterEvent {
+			err = vm.Events.RunRegionEnter(ev)
+		} else {
+			err = vm.Events.RunRegionLeave(ev)
+		}
+		if err != nil && err != scripting.ErrReturnFalse {
+			log.Error("VM(%s).%s: %s", id, name, err)
+		}
+	}
+}
+
+// loopEditRegions handles the Trigger Region Tool in the editor: drag on
+// empty space to draw a new region, drag a region to move it, or drag its
+// bottom right corner to resize it. Clicking a region without dragging calls
+// the OnRegionConfig handler.
+func (w *Canvas) loopEditRegions(ev *event.State) error {
+	if w.level == nil {
+		return nil
+	}
+
+	var (
+		WP   = w.WorldIndexAt(render.NewPoint(ev.CursorX, ev.CursorY))
+		drag = &w.regionDrag
+	)
+
+	if keybind.LeftClick(ev) {
+		// Mouse down: start a drag.
+		if drag.mode == regionDragNone {
+			drag.start = WP
+			if region := w.level.Regions.At(WP); region != nil {
+				drag.region = region
+				drag.orig = region.Rect()
+				drag.mode = regionDragMove
+				if w.regionHandleAt(region, WP) {
+					drag.mode = regionDragResize
+				}
+			} else {
+				drag.mode = regionDragCreate
+				drag.region = level.NewRegion(
+					fmt.Sprintf("Region %d", len(w.level.Regions)+1),
+					WP, WP,
+				)
+			}
+		}
+
+		// Update the region being dragged.
+		var delta = WP.Compare(drag.start)
+		switch drag.mode {
+		case regionDragCreate:
+			drag.region.Resize(drag.start, WP)
+		case regionDragMove:
+			drag.region.X = drag.orig.X + delta.X
+			drag.region.Y = drag.orig.Y + delta.Y
+		case regionDragResize:
+			// Drag the bottom right corner; past the top left corner, the
+			// region flips around it.
+			drag.region.Resize(
+				render.NewPoint(drag.orig.X, drag.orig.Y),
+				render.NewPoint(drag.orig.X+drag.orig.W+delta.X, drag.orig.Y+drag.orig.H+delta.Y),
+			)
+		}
+		return nil
+	}
+
+	// Mouse up: finish the drag.
+	if drag.mode == regionDragNone {
+		return nil
+	}
+
+	var (
+		delta   = WP.Compare(drag.start)
+		clicked = render.AbsInt(delta.X) < balance.RegionMinDrag && render.AbsInt(delta.Y) < balance.RegionMinDrag
+	)
+	switch {
+	case drag.mode == regionDragCreate && !clicked:
+		w.level.AddRegion(drag.region)
+		w.modified = true
+		shmem.Flash("Added region '%s'. Click it to rename or delete it.", drag.region.Name)
+	case drag.mode == regionDragCreate:
+		// Too small to be a new region.
+	case clicked:
+		// Undo any nudge and open its settings.
+		drag.region.X, drag.region.Y = drag.orig.X, drag.orig.Y
+		drag.region.W, drag.region.H = drag.orig.W, drag.orig.H
+		if w.OnRegionConfig != nil {
+			w.OnRegionConfig(drag.region)
+		}
+	default:
+		w.modified = true
+	}
+
+	*drag = regionDrag{}
+	return nil
+}
+
+// regionHandleAt checks if a world coordinate is on the resize handle at the
+// bottom right corner of a region.
+func (w *Canvas) regionHandleAt(region *level.Region, p render.Point) bool {
+	var size = balance.RegionHandleSize
+	return p.X >= region.X+region.W-size && p.Y >= region.Y+region.H-size
+}
+
+// LinkRegionAt handles a Link Tool click on a region in the level. Returns
+// false if there was no region at the world coordinate.
+//
+// Clicking a region and then an actor (or an actor, then a region) links
+// them together, or unlinks them if they were already linked.
+func (w *Canvas) LinkRegionAt(p render.Point) bool {
+	if w.level == nil {
+		return false
+	}
+
+	region := w.level.Regions.At(p)
+	if region == nil {
+		return false
+	}
+
+	if w.linkFirst != nil {
+		w.toggleRegionLink(region, w.linkFirst)
+		w.linkFirst = nil
+	} else if w.linkFirstRegion == region {
+		shmem.Flash("De-selected the region for linking.")
+		w.linkFirstRegion = nil
+	} else {
+		w.linkFirstRegion = region
+		shmem.Flash("Region '%s' selected, click a Doodad to link it to", region.Name)
+	}
+	return true
+}
+
+// toggleRegionLink links or unlinks a region and an actor.
+func (w *Canvas) toggleRegionLink(region *level.Region, actor *Actor) {
+	if region.IsLinked(actor.ID()) {
+		region.Unlink(actor.ID())
+		shmem.Flash("Unlinked region '%s' from '%s'", region.Name, actor.Doodad().Title)
+	} else {
+		region.AddLink(actor.ID())
+		shmem.Flash("Linked region '%s' to '%s'", region.Name, actor.Doodad().Title)
+	}
+	w.modified = true
+}
+
+// regionLabel returns the cached name label of a region, made again only when
+// the region is new or renamed.
+func (w *Canvas) regionLabel(e render.Engine, region *level.Region) *ui.Label {
+	if w.regionLabels == nil {
+		w.regionLabels = map[*level.Region]*ui.Label{}
+	}
+
+	label, ok := w.regionLabels[region]
+	if !ok || label.Text != region.Name {
+		label = ui.NewLabel(ui.Label{
+			Text: region.Name,
+			Font: balance.RegionFont,
+		})
+		label.Compute(e)
+		w.regionLabels[region] = label
+	}
+	return label
+}
+
+// pruneRegionLabels forgets the labels of regions that were deleted.
+func (w *Canvas) pruneRegionLabels(regions []*level.Region) {
+	if len(w.regionLabels) <= len(regions) {
+		return
+	}
+
+	var keep = map[*level.Region]bool{}
+	for _, region := range regions {
+		keep[region] = true
+	}
+	for region := range w.regionLabels {
+		if !keep[region] {
+			delete(w.regionLabels, region)
+		}
+	}
+}
+
+// presentRegions outlines the level's regions in the editor, with their name
+// and resize handle, and their links in the Link Tool. Regions are invisible
+// in Play Mode.
+func (w *Canvas) presentRegions(e render.Engine) {
+	if w.level == nil || !w.Editable {
+		return
+	}
+
+	// Include a new region being dragged out.
+	var regions = w.level.Regions.Sorted()
+	if w.regionDrag.mode == regionDragCreate {
+		regions = append(regions, w.regionDrag.region)
+	}
+	w.pruneRegionLabels(regions)
+
+	var (
+		P        = ui.AbsolutePosition(w)
+		toScreen = func(p render.Point) render.Point {
+			return render.Point{
+				X: P.X + w.Scroll.X + w.BoxThickness(1) + w.ZoomMultiply(p.X),
+				Y: P.Y + w.Scroll.Y + w.BoxThickness(1) + w.ZoomMultiply(p.Y),
+			}
+		}
+		handle = w.ZoomMultiply(balance.RegionHandleSize)
+	)
+
+	// The actors by ID, for link lines.
+	var actors = map[string]*Actor{}
+	if w.Tool == drawtool.LinkTool {
+		for _, actor := range w.actors {
+			actors[actor.ID()] = actor
+		}
+	}
+
+	for _, region := range regions {
+		var (
+			color = balance.RegionColor
+			at    = toScreen(render.NewPoint(region.X, region.Y))
+			rect  = render.Rect{
+				X: at.X,
+				Y: at.Y,
+				W: w.ZoomMultiply(region.W),
+				H: w.ZoomMultiply(region.H),
+			}
+		)
+		if region == w.linkFirstRegion {
+			color = balance.LinkLineColor
+		}
+
+		e.DrawRect(color, rect)
+		e.DrawBox(color, render.Rect{
+			X: rect.X + rect.W - handle,
+			Y: rect.Y + rect.H - handle,
+			W: handle,
+			H: handle,
+		})
+
+		label := w.regionLabel(e, region)
+		label.Present(e, render.Point{
+			X: rect.X + 2,
+			Y: rect.Y + 2,
+		})
+
+		// Link lines from the center of the region to its actors.
+		for _, id := range region.Links {
+			if actor, ok := actors[id]; ok {
+				var (
+					pos    = actor.Position()
+					size   = actor.Size()
+					center = toScreen(render.NewPoint(pos.X+size.W/2, pos.Y+size.H/2))
+				)
+				e.DrawLine(balance.LinkLineColor, render.Point{
+					X: rect.X + rect.W/2,
+					Y: rect.Y + rect.H/2,
+				}, center)
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package uix
+
+import "testing"
+
+func TestRegionChanges(t *testing.T) {
+	var (
+		a, b, c  = &Actor{}, &Actor{}, &Actor{}
+		previous map[*Actor]bool // nothing was in the region yet
+	)
+
+	// Test assertion helper.
+	shouldActors := func(note string, expect, actual []*Actor) {
+		t.Helper()
+		if len(actual) != len(expect) {
+			t.Errorf("%s: expected %d actors but got %d", note, len(expect), len(actual))
+			return
+		}
+		for i := range expect {
+			if actual[i] != expect[i] {
+				t.Errorf("%s: unexpected actor at %d", note, i)
+			}
+		}
+	}
+
+	// a and b step in, in the order of the canvas actors.
+	entered, left, previous := regionChanges(previous, []*Actor{a, b})
+	shouldActors("first entered", []*Actor{a, b}, entered)
+	shouldActors("first left", nil, left)
+
+	// Standing still fires nothing.
+	entered, left, previous = regionChanges(previous, []*Actor{a, b})
+	shouldActors("still entered", nil, entered)
+	shouldActors("still left", nil, left)
+
+	// c enters as a leaves.
+	entered, left, previous = regionChanges(previous, []*Actor{b, c})
+	shouldActors("swap entered", []*Actor{c}, entered)
+	shouldActors("swap left", []*Actor{a}, left)
+	if len(previous) != 2 || !previous[b] || !previous[c] {
+		t.Errorf("unexpected occupants: %+v", previous)
+	}
+
+	// Everyone leaves.
+	entered, left, previous = regionChanges(previous, []*Actor{})
+	shouldActors("empty entered", nil, entered)
+	if len(left) != 2 || len(previous) != 0 {
+		t.Errorf("expected b and c to leave: left=%d occupants=%d", len(left), len(previous))
+	}
+}
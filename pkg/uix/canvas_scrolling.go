@@ -182,8 +182,8 @@ to follow. If the actor exists, the Canvas will scroll to keep it on the
 screen.
 */
 func (w *Canvas) loopFollowActor(ev *event.State) error {
-	// Are we following an actor?
-	if w.FollowActor == "" {
+	// Are we following an actor? (And not held by a scripted camera.)
+	if w.FollowActor == "" || w.camera.holding {
 		return nil
 	}
 
@@ -208,6 +208,14 @@ func (w *Canvas) loopFollowActor(ev *event.State) error {
 			scrollBy  render.Point
 		)
 
+		// Adapt to the zoom level, e.g. of a scripted camera.
+		if w.Zoom != 0 {
+			APosition.X = w.ZoomMultiply(APosition.X)
+			APosition.Y = w.ZoomMultiply(APosition.Y)
+			ASize.W = w.ZoomMultiply(ASize.W)
+			ASize.H = w.ZoomMultiply(ASize.H)
+		}
+
 		// Scroll left
 		if APosition.X <= VP.X+scrollboxHoz {
 			var delta = VP.X + scrollboxHoz - APosition.X
@@ -0,0 +1,163 @@
+package uix
+
+import (
+	"math"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/scripting"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/sound"
+)
+
+// actorSound is a looping sound effect emitted by an actor, whose volume
+// follows the actor's distance from the camera.
+type actorSound struct {
+	actor    *Actor
+	filename string
+	channel  int
+}
+
+// PlayMusic plays background music by name: an audio file embedded in the
+// level takes priority over the game's built-in music.
+func (w *Canvas) PlayMusic(filename string, loop bool) {
+	if filename == "" {
+		return
+	}
+
+	if w.level != nil {
+		var embedded = balance.EmbeddedMusicBasePath + filename
+		if w.level.Files.Exists(embedded) {
+			data, err := w.level.GetFile(embedded)
+			if err != nil {
+				log.Error("Canvas.PlayMusic(%s): %s", filename, err)
+				return
+			}
+			sound.PlayMusicData(w.level.UUID+"/"+filename, data, loop)
+			return
+		}
+	}
+
+	sound.PlayMusic(filename, loop)
+}
+
+// PlayLevelMusic starts the level's background music, if it has any.
+func (w *Canvas) PlayLevelMusic() {
+	if w.level != nil {
+		w.PlayMusic(w.level.Music, true)
+	}
+}
+
+// SoundVolume returns the volume (0.0 to 1.0) for a sound emitted by an
+// actor: full volume while the actor is on screen, fading out with its
+// distance from the edge of the screen.
+func (w *Canvas) SoundVolume(a *Actor) float64 {
+	var (
+		vp   = w.Viewport()
+		pos  = a.Position()
+		size = a.Size()
+		dx   float64
+		dy   float64
+	)
+
+	if right := pos.X + size.W; right < vp.X {
+		dx = float64(vp.X - right)
+	} else if pos.X > vp.W {
+		dx = float64(pos.X - vp.W)
+	}
+
+	if bottom := pos.Y + size.H; bottom < vp.Y {
+		dy = float64(vp.Y - bottom)
+	} else if pos.Y > vp.H {
+		dy = float64(pos.Y - vp.H)
+	}
+
+	var volume = 1 - math.Hypot(dx, dy)/balance.SoundFalloffDistance
+	if volume < 0 {
+		return 0
+	}
+	return volume
+}
+
+// loopSounds updates the volume of actors' looping sounds as they or the
+// camera move, and forgets sounds that have stopped.
+func (w *Canvas) loopSounds() {
+	if len(w.sounds) == 0 {
+		return
+	}
+
+	var playing = w.sounds[:0]
+	for _, s := range w.sounds {
+		if s.actor.flagDestroy {
+			sound.StopEffect(s.channel)
+			continue
+		}
+		if !sound.EffectPlaying(s.channel) {
+			continue
+		}
+
+		sound.SetEffectVolume(s.channel, w.SoundVolume(s.actor))
+		playing = append(playing, s)
+	}
+	w.sounds = playing
+}
+
+// StopSounds stops all of the actors' looping sounds, e.g. when leaving
+// Play Mode.
+func (w *Canvas) StopSounds() {
+	for _, s := range w.sounds {
+		sound.StopEffect(s.channel)
+	}
+	w.sounds = nil
+}
+
+/*
+MakeSoundAPI makes the `Sound` global available to an actor's script.
+
+Names registered:
+
+  - Sound.Play(filename): play a sound effect, louder the closer the actor
+    is to the camera.
+  - Sound.Loop(filename): loop a sound effect until stopped. Its volume
+    follows the actor as it moves.
+  - Sound.Stop(filename): stop the actor's looping sound.
+  - Sound.PlayMusic(filename, loop): change the background music, from the
+    game's music or an audio file embedded in the level.
+  - Sound.StopMusic(): stop the background music.
+*/
+func (w *Canvas) MakeSoundAPI(vm *scripting.VM, actor *Actor) {
+	vm.Set("Sound", map[string]interface{}{
+		"Play": func(filename string) {
+			sound.PlaySoundVolume(filename, w.SoundVolume(actor))
+		},
+		"Loop": func(filename string) {
+			for _, s := range w.sounds {
+				if s.actor == actor && s.filename == filename {
+					return
+				}
+			}
+
+			if channel := sound.PlayEffect(filename, -1, w.SoundVolume(actor)); channel >= 0 {
+				w.sounds = append(w.sounds, &actorSound{
+					actor:    actor,
+					filename: filename,
+					channel:  channel,
+				})
+			}
+		},
+		"Stop": func(filename string) {
+			var playing = w.sounds[:0]
+			for _, s := range w.sounds {
+				if s.actor == actor && s.filename == filename {
+					sound.StopEffect(s.channel)
+					continue
+				}
+				playing = append(playing, s)
+			}
+			w.sounds = playing
+		},
+		"PlayMusic": func(filename string, loop bool) {
+			w.PlayMusic(filename, loop)
+		},
+		"StopMusic": sound.StopMusic,
+	})
+}
@@ -38,83 +38,107 @@ func (w *Canvas) RemoveStroke(stroke *drawtool.Stroke) bool {
 	return false
 }
 
-// UndoStroke rolls back the level's UndoHistory and deletes the pixels last
-// added to the level. Returns false and emits a warning to the log if the
-// canvas has no level loaded properly.
-func (w *Canvas) UndoStroke() bool {
-	var undoer *drawtool.History
+// History returns the undo history of the level or doodad being edited, or
+// nil if the canvas has neither loaded.
+func (w *Canvas) History() *drawtool.History {
 	if w.level != nil {
-		undoer = w.level.UndoHistory
+		return w.level.UndoHistory
 	} else if w.doodad != nil {
 		if w.doodad.UndoHistory == nil {
 			// HACK: if UndoHistory was not initialized properly.
-			w.doodad.UndoHistory = drawtool.NewHistory(balance.UndoHistory)
+			w.doodad.UndoHistory = drawtool.NewHistory(balance.UndoHistoryDepth())
 		}
-		undoer = w.doodad.UndoHistory
-	} else {
+		return w.doodad.UndoHistory
+	}
+	return nil
+}
+
+// AddCommand records an undoable change to the drawing (other than its
+// pixels) in the undo history. The change should already have been made.
+func (w *Canvas) AddCommand(cmd *drawtool.Command) {
+	if undoer := w.History(); undoer != nil {
+		undoer.AddCommand(cmd)
+	}
+}
+
+// UndoStroke rolls back the level's UndoHistory and deletes the pixels last
+// added to the level, or undoes the Command that was last recorded. Returns
+// false and emits a warning to the log if the canvas has no level loaded
+// properly.
+func (w *Canvas) UndoStroke() bool {
+	var undoer = w.History()
+	if undoer == nil {
 		log.Error("Canvas.UndoStroke: no Level or Doodad currently available to the canvas")
 		return false
 	}
 
-	latest := undoer.Latest()
-	if latest != nil {
-		// TODO: only single-thickness lines will restore the original color;
-		// thick lines just delete their pixels from the world due to performance.
-		// But the Eraser Tool is always thick, which always should restore its
-		// pixels. Can't do anything about that, so the inefficient thick rect
-		// restore is used only for Eraser at least.
-		if latest.Thickness > 0 {
-			if latest.Shape == drawtool.Eraser {
-				for rect := range latest.IterThickPoints() {
-					var (
-						xMin = rect.X
-						xMax = rect.X + rect.W
-						yMin = rect.Y
-						yMax = rect.Y + rect.H
-					)
-					for x := xMin; x < xMax; x++ {
-						for y := yMin; y < yMax; y++ {
-							if v, ok := latest.OriginalPoints[render.NewPoint(x, y)]; ok {
-								if swatch, ok := v.(*level.Swatch); ok {
-									w.chunks.Set(render.NewPoint(x, y), swatch)
-								}
+	if cmd := undoer.LatestCommand(); cmd != nil {
+		cmd.Undo()
+	} else if latest := undoer.Latest(); latest != nil {
+		// Roll back any mirrored copies (Symmetry mode) before the stroke itself,
+		// in the reverse order they were committed.
+		for i := len(latest.Mirrors) - 1; i >= 0; i-- {
+			w.undoStrokePixels(latest.Mirrors[i])
+		}
+		w.undoStrokePixels(latest)
+	}
+	return undoer.Undo()
+}
+
+// undoStrokePixels removes the pixels of a stroke from the drawing, restoring
+// the original pixels it had replaced where it can.
+func (w *Canvas) undoStrokePixels(stroke *drawtool.Stroke) {
+	// TODO: only single-thickness lines will restore the original color;
+	// thick lines just delete their pixels from the world due to performance.
+	// But the Eraser Tool is always thick, which always should restore its
+	// pixels. Can't do anything about that, so the inefficient thick rect
+	// restore is used only for Eraser at least.
+	if stroke.IsThick() {
+		if stroke.Shape == drawtool.Eraser {
+			for rect := range stroke.IterThickPoints() {
+				var (
+					xMin = rect.X
+					xMax = rect.X + rect.W
+					yMin = rect.Y
+					yMax = rect.Y + rect.H
+				)
+				for x := xMin; x < xMax; x++ {
+					for y := yMin; y < yMax; y++ {
+						if v, ok := stroke.OriginalPoints[render.NewPoint(x, y)]; ok {
+							if swatch, ok := v.(*level.Swatch); ok {
+								w.chunks.Set(render.NewPoint(x, y), swatch)
 							}
 						}
 					}
 				}
-			} else {
-				for rect := range latest.IterThickPoints() {
-					w.chunks.DeleteRect(rect)
-				}
 			}
 		} else {
-			for point := range latest.IterPoints() {
-				// Was there a previous swatch at this point to restore?
-				if v, ok := latest.OriginalPoints[point]; ok {
-					if swatch, ok := v.(*level.Swatch); ok {
-						w.chunks.Set(point, swatch)
-						continue
-					}
+			for rect := range stroke.IterThickPoints() {
+				w.chunks.DeleteRect(rect)
+			}
+		}
+	} else {
+		for point := range stroke.IterPoints() {
+			// Was there a previous swatch at this point to restore?
+			if v, ok := stroke.OriginalPoints[point]; ok {
+				if swatch, ok := v.(*level.Swatch); ok {
+					w.chunks.Set(point, swatch)
+					continue
 				}
-
-				w.chunks.Delete(point)
 			}
 
+			w.chunks.Delete(point)
 		}
+
 	}
-	return undoer.Undo()
 }
 
 // RedoStroke rolls the level's UndoHistory forwards again and replays the
 // recently undone changes.
 func (w *Canvas) RedoStroke() bool {
-	var undoer *drawtool.History
-	if w.level != nil {
-		undoer = w.level.UndoHistory
-	} else if w.doodad != nil {
-		undoer = w.doodad.UndoHistory
-	} else {
-		log.Error("Canvas.UndoStroke: no Level or Doodad currently available to the canvas")
+	var undoer = w.History()
+	if undoer == nil {
+		log.Error("Canvas.RedoStroke: no Level or Doodad currently available to the canvas")
 		return false
 	}
 
@@ -123,6 +147,11 @@ func (w *Canvas) RedoStroke() bool {
 		return false
 	}
 
+	if cmd := undoer.LatestCommand(); cmd != nil {
+		cmd.Redo()
+		return ok
+	}
+
 	latest := undoer.Latest()
 
 	// We stored the ActiveSwatch on this stroke as we drew it. Recover it
@@ -133,6 +162,28 @@ func (w *Canvas) RedoStroke() bool {
 	return ok
 }
 
+// JumpHistory undoes or redoes the history until the given number of its
+// steps are applied, e.g. for the History window. Returns false if it could
+// not get all the way there.
+func (w *Canvas) JumpHistory(position int) bool {
+	var undoer = w.History()
+	if undoer == nil {
+		return false
+	}
+
+	for undoer.Position() > position {
+		if !w.UndoStroke() {
+			return false
+		}
+	}
+	for undoer.Position() < position {
+		if !w.RedoStroke() {
+			return false
+		}
+	}
+	return true
+}
+
 // presentStrokes is called as part of Present() and draws the strokes whose
 // pixels are currently visible within the viewport.
 func (w *Canvas) presentStrokes(e render.Engine) {
@@ -143,11 +194,25 @@ func (w *Canvas) presentStrokes(e render.Engine) {
 	}
 	w.DrawStrokes(e, strokes)
 
+	// Symmetry mode: preview the mirrors of the stroke being drawn.
+	w.presentSymmetry(e)
+
+	// Control points of the Curve and Polygon tools.
+	w.presentVector(e)
+
 	// Dynamic actor links visible in the ActorTool and LinkTool.
 	if w.Tool == drawtool.ActorTool || w.Tool == drawtool.LinkTool {
 		w.presentActorLinks(e)
 	}
 
+	// Level regions are visible in the editor.
+	w.presentRegions(e)
+
+	// Camera regions visible in the CameraRegionTool.
+	if w.Tool == drawtool.CameraRegionTool {
+		w.presentCameraRegions(e)
+	}
+
 	// Text Tool preview.
 	if w.Tool == drawtool.TextTool && drawtool.TT.Label != nil {
 		drawtool.TT.Label.Present(e, shmem.Cursor)
@@ -240,7 +305,7 @@ func (w *Canvas) DrawStrokes(e render.Engine, strokes []*drawtool.Stroke) {
 	for _, stroke := range strokes {
 		// If none of this stroke is in our viewport, don't waste time
 		// looping through it.
-		if stroke.Shape == drawtool.Freehand || stroke.Shape == drawtool.Eraser {
+		if stroke.Shape == drawtool.Freehand || stroke.Shape == drawtool.Eraser || stroke.Shape.IsVector() {
 			if len(stroke.Points) >= 2 {
 				if !stroke.Points[0].Inside(VP) && !stroke.Points[len(stroke.Points)-1].Inside(VP) {
 					continue
@@ -255,48 +320,19 @@ func (w *Canvas) DrawStrokes(e render.Engine, strokes []*drawtool.Stroke) {
 			}
 		}
 
-		// Iter the points and draw what's visible.
-		if stroke.Thickness > 0 {
-			for rect := range stroke.IterThickPoints() {
-				if !rect.Intersects(VP) {
-					continue
-				}
-
-				// Does the swatch have a pattern to sample?
-				color := stroke.Color
-				if stroke.Pattern != "" {
-					color = pattern.SampleColor(stroke.Pattern, color, rect.Point())
-				}
-
-				// Destination rectangle to draw to screen, taking into account
-				// the position of the Canvas itself.
-				dest := render.Rect{
-					X: rect.X + P.X + w.Scroll.X + w.BoxThickness(1),
-					Y: rect.Y + P.Y + w.Scroll.Y + w.BoxThickness(1),
-					W: rect.W,
-					H: rect.H,
-				}
-
-				// Cap the render square so it doesn't leave the Canvas and
-				// overlap other UI elements!
-				if dest.X < P.X {
-					// Left edge. TODO: right edge
-					delta := P.X - dest.X
-					dest.X = P.X
-					dest.W -= delta
-				}
-				if dest.Y < P.Y {
-					// Top edge. TODO: bottom edge
-					delta := P.Y - dest.Y
-					dest.Y = P.Y
-					dest.H -= delta
-				}
+		// The inside of a filled shape is drawn by its spans, scaled to the
+		// zoom level like the brush.
+		if stroke.Fill {
+			for _, span := range w.zoomFillSpans(stroke) {
+				w.drawStrokeRect(e, stroke, span, P, VP)
+			}
+		}
 
-				if balance.DebugCanvasStrokeColor != render.Invisible {
-					e.DrawBox(balance.DebugCanvasStrokeColor, dest)
-				} else {
-					e.DrawBox(color, dest)
-				}
+		// Iter the points and draw what's visible.
+		if stroke.IsThick() {
+			// Stamp the brush scaled to the zoom level.
+			for rect := range stroke.IterBrushRects(w.ZoomMultiply) {
+				w.drawStrokeRect(e, stroke, rect, P, VP)
 			}
 		} else {
 			var color = stroke.Color
@@ -323,7 +359,7 @@ func (w *Canvas) DrawStrokes(e render.Engine, strokes []*drawtool.Stroke) {
 				continue
 			}
 
-			for point := range stroke.IterPoints() {
+			for point := range stroke.IterOutline() {
 				if !point.Inside(VP) {
 					continue
 				}
@@ -343,3 +379,48 @@ func (w *Canvas) DrawStrokes(e render.Engine, strokes []*drawtool.Stroke) {
 		}
 	}
 }
+
+// drawStrokeRect draws one Rect of a stroke (a stamp of its brush or a span of
+// its fill) in canvas coordinates, if it is inside the viewport VP. P is the
+// position of the Canvas in the UI.
+func (w *Canvas) drawStrokeRect(e render.Engine, stroke *drawtool.Stroke, rect render.Rect, P render.Point, VP render.Rect) {
+	if !rect.Intersects(VP) {
+		return
+	}
+
+	// Does the swatch have a pattern to sample?
+	color := stroke.Color
+	if stroke.Pattern != "" {
+		color = pattern.SampleColor(stroke.Pattern, color, rect.Point())
+	}
+
+	// Destination rectangle to draw to screen, taking into account
+	// the position of the Canvas itself.
+	dest := render.Rect{
+		X: rect.X + P.X + w.Scroll.X + w.BoxThickness(1),
+		Y: rect.Y + P.Y + w.Scroll.Y + w.BoxThickness(1),
+		W: rect.W,
+		H: rect.H,
+	}
+
+	// Cap the render square so it doesn't leave the Canvas and
+	// overlap other UI elements!
+	if dest.X < P.X {
+		// Left edge. TODO: right edge
+		delta := P.X - dest.X
+		dest.X = P.X
+		dest.W -= delta
+	}
+	if dest.Y < P.Y {
+		// Top edge. TODO: bottom edge
+		delta := P.Y - dest.Y
+		dest.Y = P.Y
+		dest.H -= delta
+	}
+
+	if balance.DebugCanvasStrokeColor != render.Invisible {
+		e.DrawBox(balance.DebugCanvasStrokeColor, dest)
+	} else {
+		e.DrawBox(color, dest)
+	}
+}
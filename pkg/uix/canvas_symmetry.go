@@ -0,0 +1,86 @@
+package uix
+
+import (
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/drawtool"
+	"git.kirsle.net/go/render"
+	"git.kirsle.net/go/ui"
+)
+
+// SetSymmetry changes the Symmetry drawing mode. Unless the user has moved
+// the axis with the Symmetry Axis tool, it snaps to the center of the doodad
+// (or of the viewport, for levels).
+func (w *Canvas) SetSymmetry(mode drawtool.Symmetry) {
+	w.Symmetry = mode
+	if !w.symmetryAxisSet {
+		w.CenterSymmetryAxis()
+	}
+}
+
+// CenterSymmetryAxis moves the mirror axis to the center of the doodad being
+// edited, or the center of the viewport for levels.
+func (w *Canvas) CenterSymmetryAxis() {
+	w.symmetryAxisSet = false
+
+	if w.doodad != nil {
+		w.SymmetryAxis = render.NewPoint(w.doodad.Size.W/2, w.doodad.Size.H/2)
+		return
+	}
+
+	var S = w.Size()
+	w.SymmetryAxis = render.Point{
+		X: w.ZoomDivide(S.W/2 - w.Scroll.X),
+		Y: w.ZoomDivide(S.H/2 - w.Scroll.Y),
+	}
+}
+
+// presentSymmetry draws the mirror axis and a preview of the mirrored copies
+// of the stroke currently being drawn.
+func (w *Canvas) presentSymmetry(e render.Engine) {
+	if w.Symmetry == drawtool.NoSymmetry || !w.Editable {
+		return
+	}
+
+	var (
+		P = ui.AbsolutePosition(w)
+		S = w.Size()
+
+		// The axis relative to the canvas, like the points of a stroke being
+		// drawn (which are not yet divided by the zoom level).
+		axis = render.Point{
+			X: w.ZoomMultiply(w.SymmetryAxis.X),
+			Y: w.ZoomMultiply(w.SymmetryAxis.Y),
+		}
+		screen = render.Point{
+			X: P.X + w.Scroll.X + w.BoxThickness(1) + axis.X,
+			Y: P.Y + w.Scroll.Y + w.BoxThickness(1) + axis.Y,
+		}
+	)
+
+	// Mirror previews for the drawing tools.
+	switch w.Tool {
+	case drawtool.PencilTool, drawtool.LineTool, drawtool.RectTool,
+		drawtool.EllipseTool, drawtool.EraserTool, drawtool.CurveTool,
+		drawtool.PolylineTool, drawtool.PolygonTool, drawtool.FilledRectTool,
+		drawtool.FilledEllipseTool, drawtool.FilledPolygonTool:
+		if w.currentStroke != nil {
+			w.DrawStrokes(e, w.Symmetry.Mirror(w.currentStroke, axis))
+		}
+	}
+
+	// The axis lines, clipped to the canvas.
+	if w.Symmetry != drawtool.VerticalSymmetry && screen.X >= P.X && screen.X < P.X+S.W {
+		e.DrawLine(
+			balance.SymmetryAxisColor,
+			render.NewPoint(screen.X, P.Y),
+			render.NewPoint(screen.X, P.Y+S.H),
+		)
+	}
+	if w.Symmetry != drawtool.HorizontalSymmetry && screen.Y >= P.Y && screen.Y < P.Y+S.H {
+		e.DrawLine(
+			balance.SymmetryAxisColor,
+			render.NewPoint(P.X, screen.Y),
+			render.NewPoint(P.X+S.W, screen.Y),
+		)
+	}
+}
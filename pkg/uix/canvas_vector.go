@@ -0,0 +1,151 @@
+package uix
+
+import (
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/drawtool"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/keybind"
+	"git.kirsle.net/go/render"
+	"git.kirsle.net/go/render/event"
+	"git.kirsle.net/go/ui"
+)
+
+// Functions relating to the vector shape tools (Curve, Polyline and Polygon).
+
+// vectorEdit is the state of the vector tools while the user is placing and
+// dragging the control points of the shape, before it is committed.
+type vectorEdit struct {
+	mouseDown bool
+	dragging  int // index of the control point being dragged, -1 for none
+}
+
+/*
+loopEditVector handles the Curve, Polyline and Polygon tools.
+
+Each click on empty space adds a control point to the shape, and clicking
+on an existing control point lets the user drag it somewhere else. Press
+Enter or right-click to commit the shape to the drawing.
+*/
+func (w *Canvas) loopEditVector(ev *event.State, cursor render.Point) error {
+	// If no swatch is active, do nothing with mouse clicks.
+	if w.Palette.ActiveSwatch == nil {
+		return nil
+	}
+
+	var edit = &w.vectorEdit
+
+	// Enter or right-click to finish the shape.
+	if w.currentStroke != nil && (keybind.Enter(ev) || ev.Button3) {
+		ev.Button3 = false
+		w.commitVector()
+		return nil
+	}
+
+	if keybind.LeftClick(ev) {
+		// Mouse down: grab a control point, or add a new one.
+		if !edit.mouseDown {
+			edit.mouseDown = true
+
+			// Initialize a new Stroke for the shape?
+			if w.currentStroke == nil {
+				var shape = drawtool.Polygon
+				switch w.Tool {
+				case drawtool.CurveTool:
+					shape = drawtool.Curve
+				case drawtool.PolylineTool:
+					shape = drawtool.Polyline
+				}
+
+				w.currentStroke = drawtool.NewStroke(shape, w.Palette.ActiveSwatch.Color)
+				w.currentStroke.Pattern = w.Palette.ActiveSwatch.Pattern
+				w.currentStroke.Thickness = w.BrushSize
+				w.currentStroke.Brush = w.Brush
+				w.currentStroke.Fill = w.Tool == drawtool.FilledPolygonTool
+				w.currentStroke.ExtraData = w.Palette.ActiveSwatch
+				w.AddStroke(w.currentStroke)
+			}
+
+			edit.dragging = w.vectorHandleAt(cursor)
+			if edit.dragging < 0 {
+				w.currentStroke.Points = append(w.currentStroke.Points, cursor)
+				edit.dragging = len(w.currentStroke.Points) - 1
+			}
+		}
+
+		// Drag the control point along.
+		if w.currentStroke != nil && edit.dragging >= 0 {
+			w.currentStroke.Points[edit.dragging] = cursor
+		}
+	} else {
+		edit.mouseDown = false
+		edit.dragging = -1
+	}
+
+	return nil
+}
+
+// commitVector commits the vector shape being edited to the drawing. A shape
+// of only a single point is discarded.
+func (w *Canvas) commitVector() {
+	if w.currentStroke == nil {
+		return
+	}
+
+	if len(w.currentStroke.Points) < 2 {
+		w.RemoveStroke(w.currentStroke)
+		w.currentStroke = nil
+	} else {
+		w.commitStroke(w.Tool, true)
+	}
+
+	w.vectorEdit = vectorEdit{dragging: -1}
+}
+
+// vectorHandleAt returns the index of the control point of the shape being
+// edited under the cursor, or -1.
+func (w *Canvas) vectorHandleAt(cursor render.Point) int {
+	if w.currentStroke == nil {
+		return -1
+	}
+
+	var size = balance.VectorHandleSize
+	for i, pt := range w.currentStroke.Points {
+		if render.AbsInt(pt.X-cursor.X) <= size && render.AbsInt(pt.Y-cursor.Y) <= size {
+			return i
+		}
+	}
+	return -1
+}
+
+// presentVector draws the control points of the vector shape being edited,
+// and the lines between them for a Curve.
+func (w *Canvas) presentVector(e render.Engine) {
+	if !w.Tool.IsVector() || w.currentStroke == nil || !w.currentStroke.Shape.IsVector() {
+		return
+	}
+
+	var (
+		P        = ui.AbsolutePosition(w)
+		toScreen = func(p render.Point) render.Point {
+			return render.Point{
+				X: P.X + w.Scroll.X + w.BoxThickness(1) + p.X,
+				Y: P.Y + w.Scroll.Y + w.BoxThickness(1) + p.Y,
+			}
+		}
+		points = w.currentStroke.Points
+		size   = balance.VectorHandleSize
+	)
+
+	for i, pt := range points {
+		var at = toScreen(pt)
+		if i > 0 && w.currentStroke.Shape == drawtool.Curve {
+			e.DrawLine(balance.VectorHandleColor, toScreen(points[i-1]), at)
+		}
+
+		e.DrawRect(balance.VectorHandleColor, render.Rect{
+			X: at.X - size/2,
+			Y: at.Y - size/2,
+			W: size,
+			H: size,
+		})
+	}
+}
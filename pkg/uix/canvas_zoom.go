@@ -111,11 +111,14 @@ func (w *Canvas) ZoomStroke(stroke *drawtool.Stroke) *drawtool.Stroke {
 		Shape:          stroke.Shape,
 		Color:          stroke.Color,
 		Thickness:      stroke.Thickness,
+		Brush:          stroke.Brush,
+		Fill:           stroke.Fill,
 		ExtraData:      stroke.ExtraData,
 		PointA:         stroke.PointA,
 		PointB:         stroke.PointB,
 		Points:         stroke.Points,
 		OriginalPoints: stroke.OriginalPoints,
+		Mirrors:        stroke.Mirrors,
 	}
 
 	// Multiply all coordinates in this stroke, which should be World
@@ -134,3 +137,45 @@ func (w *Canvas) ZoomStroke(stroke *drawtool.Stroke) *drawtool.Stroke {
 
 	return copy
 }
+
+/*
+zoomFillSpans returns the inside of a filled stroke that is being drawn, whose
+points are still mouse cursor coordinates on the canvas.
+
+The spans are found in World Coordinates, like the pixels the stroke will
+commit, and multiplied back by the zoom level so the preview matches them.
+*/
+func (w *Canvas) zoomFillSpans(stroke *drawtool.Stroke) []render.Rect {
+	// Mirrored copies have their spans worked out already.
+	if stroke.Spans != nil {
+		return stroke.Spans
+	}
+
+	adjust := func(p render.Point) render.Point {
+		p.X = w.ZoomDivide(p.X)
+		p.Y = w.ZoomDivide(p.Y)
+		return p
+	}
+
+	world := &drawtool.Stroke{
+		Shape:  stroke.Shape,
+		Fill:   stroke.Fill,
+		PointA: adjust(stroke.PointA),
+		PointB: adjust(stroke.PointB),
+		Points: make([]render.Point, len(stroke.Points)),
+	}
+	for i, point := range stroke.Points {
+		world.Points[i] = adjust(point)
+	}
+
+	var spans = world.FillSpans()
+	for i, span := range spans {
+		spans[i] = render.Rect{
+			X: w.ZoomMultiply(span.X),
+			Y: w.ZoomMultiply(span.Y),
+			W: w.ZoomMultiply(span.X+span.W) - w.ZoomMultiply(span.X),
+			H: w.ZoomMultiply(span.Y+span.H) - w.ZoomMultiply(span.Y),
+		}
+	}
+	return spans
+}
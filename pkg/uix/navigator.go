@@ -0,0 +1,180 @@
+package uix
+
+import (
+	"fmt"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/keybind"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/level/giant_screenshot"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
+	"git.kirsle.net/go/render"
+	"git.kirsle.net/go/render/event"
+	"git.kirsle.net/go/ui"
+)
+
+/*
+Navigator is a widget that shows a scaled down overview of a level Canvas,
+with markers for its actors and the rectangle of the Canvas's viewport.
+
+Clicking or dragging on the Navigator scrolls the Canvas there. The overview
+is kept up to date as the chunks of the level are changed.
+*/
+type Navigator struct {
+	ui.Frame
+	Canvas *Canvas
+
+	chunker    *level.Chunker
+	overview   *giant_screenshot.Overview
+	texture    render.Texturer
+	name       string // texture name
+	lastUpdate uint64 // shmem.Tick of the last overview update
+}
+
+// NewNavigator creates a Navigator for a Canvas at a size.
+func NewNavigator(canvas *Canvas, size render.Rect) *Navigator {
+	w := &Navigator{
+		Canvas: canvas,
+	}
+	w.name = fmt.Sprintf("navigator-%p", w)
+	w.Resize(size)
+	w.Configure(ui.Config{
+		Background:  balance.NavigatorBackground,
+		BorderSize:  1,
+		BorderStyle: ui.BorderSunken,
+	})
+	w.IDFunc(func() string {
+		return "Navigator"
+	})
+	return w
+}
+
+// Loop handles clicking and dragging on the Navigator to scroll the Canvas.
+func (w *Navigator) Loop(ev *event.State) {
+	if w.overview == nil || !keybind.LeftClick(ev) {
+		return
+	}
+
+	var (
+		P      = ui.AbsolutePosition(w)
+		S      = w.Size()
+		cursor = render.NewPoint(ev.CursorX, ev.CursorY)
+	)
+	if !cursor.Inside(render.Rect{X: P.X, Y: P.Y, W: S.W, H: S.H}) {
+		return
+	}
+
+	var world = w.overview.ToWorld(render.Point{
+		X: cursor.X - P.X - w.BoxThickness(1),
+		Y: cursor.Y - P.Y - w.BoxThickness(1),
+	})
+	w.Canvas.Scroll = w.Canvas.scrollToCenter(world)
+	_ = w.Canvas.loopConstrainScroll()
+}
+
+// Present the Navigator.
+func (w *Navigator) Present(e render.Engine, p render.Point) {
+	w.MoveTo(p)
+	w.DrawBox(e, p)
+
+	var (
+		S     = w.Size()
+		inner = render.Rect{
+			X: p.X + w.BoxThickness(1),
+			Y: p.Y + w.BoxThickness(1),
+			W: S.W - w.BoxThickness(2),
+			H: S.H - w.BoxThickness(2),
+		}
+	)
+	e.DrawBox(w.Background(), inner)
+
+	var chunker = w.Canvas.Chunker()
+
+	// Keep the overview up to date, every few ticks, or every tick while it
+	// is still loading chunks. Start over if the Canvas loaded a different
+	// drawing.
+	if chunker != w.chunker {
+		w.chunker = chunker
+		w.overview = nil
+		w.Teardown()
+	}
+	if w.overview == nil {
+		w.overview = giant_screenshot.NewOverview(chunker, render.NewRect(inner.W, inner.H))
+		w.overview.LoadsPerUpdate = balance.NavigatorChunksPerUpdate
+	}
+	if w.texture == nil || !w.overview.Done() || shmem.Tick-w.lastUpdate >= balance.NavigatorUpdateInterval {
+		w.lastUpdate = shmem.Tick
+		if w.overview.Update() || w.texture == nil {
+			w.Teardown()
+
+			tex, err := e.StoreTexture(w.name, w.overview.Image)
+			if err != nil {
+				log.Error("Navigator: %s", err)
+				return
+			}
+			w.texture = tex
+		}
+	}
+
+	// The overview of the level.
+	var size = w.texture.Size()
+	e.Copy(w.texture, size, render.Rect{
+		X: inner.X,
+		Y: inner.Y,
+		W: min(size.W, inner.W),
+		H: min(size.H, inner.H),
+	})
+
+	// Converts a world rect to the screen, clipped to the Navigator.
+	toScreen := func(r render.Rect) render.Rect {
+		var (
+			a = w.overview.FromWorld(render.NewPoint(r.X, r.Y))
+			b = w.overview.FromWorld(render.NewPoint(r.X+r.W, r.Y+r.H))
+		)
+		a.X, a.Y = max(a.X, 0), max(a.Y, 0)
+		b.X, b.Y = min(b.X, inner.W-1), min(b.Y, inner.H-1)
+		return render.Rect{
+			X: inner.X + a.X,
+			Y: inner.Y + a.Y,
+			W: max(b.X-a.X, 1),
+			H: max(b.Y-a.Y, 1),
+		}
+	}
+
+	// Actor markers.
+	for _, actor := range w.Canvas.Actors() {
+		var (
+			pos  = actor.Position()
+			size = actor.Size()
+		)
+		e.DrawBox(balance.NavigatorActorColor, toScreen(render.Rect{
+			X: pos.X,
+			Y: pos.Y,
+			W: size.W,
+			H: size.H,
+		}))
+	}
+
+	// The Canvas's viewport.
+	var (
+		CS     = w.Canvas.Size()
+		center = w.Canvas.CameraCenter()
+		vw     = w.Canvas.ZoomDivide(CS.W)
+		vh     = w.Canvas.ZoomDivide(CS.H)
+	)
+	e.DrawRect(balance.NavigatorViewportColor, toScreen(render.Rect{
+		X: center.X - vw/2,
+		Y: center.Y - vh/2,
+		W: vw,
+		H: vh,
+	}))
+}
+
+// Teardown frees the Navigator's texture.
+func (w *Navigator) Teardown() {
+	if w.texture != nil {
+		w.texture.Free()
+		w.texture = nil
+	}
+}
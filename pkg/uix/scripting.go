@@ -1,11 +1,15 @@
 package uix
 
 import (
+	"sort"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/doodads"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/level"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/scripting"
 	"git.kirsle.net/go/render"
+	"github.com/dop251/goja"
 )
 
 // Functions relating to the Doodad JavaScript API for Canvas Actors.
@@ -45,6 +49,7 @@ func (w *Canvas) MakeScriptAPI(vm *scripting.VM) {
 					w.FollowActor = actor.ID()
 				}
 			}
+			w.CameraRelease()
 		},
 
 		// Actors.New: create a new actor.
@@ -84,11 +89,67 @@ func (w *Canvas) MakeScriptAPI(vm *scripting.VM) {
 			}
 		},
 	})
+
+	w.MakeCameraAPI(vm)
+}
+
+/*
+MakeCameraAPI makes the `Camera` global available to doodad scripts.
+
+Names registered:
+
+  - Camera.PanTo(x, y, speed): smoothly pan the camera to center on a point,
+    and hold it there until Camera.Release(). Returns a Promise that resolves
+    when the camera arrives. Speed (pixels per tick) is optional.
+  - Camera.Release(): return the camera to following the player.
+  - Camera.Zoom(level, milliseconds): zoom in (1, 2) or out (-1) for a while,
+    or until Camera.ResetZoom() if milliseconds is zero.
+  - Camera.ResetZoom()
+  - Camera.Shake(intensity, milliseconds): shake the screen.
+  - Camera.SetBounds(x, y, width, height): keep the camera inside a rect of
+    the level, overriding the level's camera regions.
+  - Camera.ClearBounds()
+  - Camera.Center(): the Point at the center of the screen.
+*/
+func (w *Canvas) MakeCameraAPI(vm *scripting.VM) {
+	ticks := func(ms int) uint64 {
+		if ms <= 0 {
+			return 0
+		}
+		return uint64(float64(ms) * (float64(balance.TargetFPS) / 1000))
+	}
+
+	vm.Set("Camera", map[string]interface{}{
+		"PanTo": func(x, y, speed int) *goja.Promise {
+			return vm.NewPromise(func(resolve, reject func(interface{})) {
+				w.CameraPanTo(render.NewPoint(x, y), speed, func() {
+					resolve(nil)
+				})
+			})
+		},
+		"Release": w.CameraRelease,
+		"Zoom": func(zoom, ms int) {
+			w.CameraZoom(zoom, ticks(ms))
+		},
+		"ResetZoom": w.CameraResetZoom,
+		"Shake": func(intensity, ms int) {
+			w.CameraShake(intensity, ticks(ms))
+		},
+		"SetBounds": func(x, y, width, height int) {
+			w.SetCameraBounds(&render.Rect{X: x, Y: y, W: width, H: height})
+		},
+		"ClearBounds": func() {
+			w.SetCameraBounds(nil)
+		},
+		"Center": w.CameraCenter,
+	})
 }
 
 // MakeSelfAPI generates the `Self` object for the scripting API in
-// reference to a live Canvas actor in the level.
-func (w *Canvas) MakeSelfAPI(actor *Actor) map[string]interface{} {
+// reference to a live Canvas actor in the level. The vm is the script that
+// will call the API: the actor's own, or the script it is linked to by
+// GetLinks.
+func (w *Canvas) MakeSelfAPI(actor *Actor, vm *scripting.VM) map[string]interface{} {
 	return map[string]interface{}{
 		"Filename": actor.Doodad().Filename,
 		"Title":    actor.Doodad().Title,
@@ -100,7 +161,7 @@ func (w *Canvas) MakeSelfAPI(actor *Actor) map[string]interface{} {
 		"GetLinks": func() []map[string]interface{} {
 			var result = []map[string]interface{}{}
 			for _, linked := range w.GetLinkedActors(actor) {
-				result = append(result, w.MakeSelfAPI(linked))
+				result = append(result, w.MakeSelfAPI(linked, vm))
 			}
 			return result
 		},
@@ -109,6 +170,7 @@ func (w *Canvas) MakeSelfAPI(actor *Actor) map[string]interface{} {
 		"CameraFollowMe": func() {
 			// Update the doodad that the camera should focus on.
 			w.FollowActor = actor.ID()
+			w.CameraRelease()
 		},
 
 		// functions
@@ -181,9 +243,57 @@ func (w *Canvas) MakeSelfAPI(actor *Actor) map[string]interface{} {
 		"Destroy":        actor.Destroy,
 
 		// actor_animation.go
-		"AddAnimation":  actor.AddAnimation,
-		"PlayAnimation": actor.PlayAnimation,
+		"AddAnimation": actor.AddAnimation,
+		"PlayAnimation": func(name string, callback goja.Value) *goja.Promise {
+			// Return a Promise that resolves when the animation finishes, so
+			// scripts may `await Self.PlayAnimation(name)`, while still calling
+			// the callback function if one was given. The promise belongs to
+			// the calling script, which may be playing a linked actor's
+			// animation, and is rejected if the animation can't play.
+			return vm.NewPromise(func(resolve, reject func(interface{})) {
+				done := func() {
+					if function, ok := goja.AssertFunction(callback); ok {
+						function(goja.Undefined())
+					}
+					resolve(nil)
+				}
+				if err := actor.PlayAnimation(name, vm.ToValue(done)); err != nil {
+					reject(vm.ToValue(err.Error()))
+				}
+			})
+		},
 		"IsAnimating":   actor.IsAnimating,
 		"StopAnimation": actor.StopAnimation,
 	}
 }
+
+// ScriptAPINames returns the member names of the `Self`, `Actors` and `Level`
+// globals available to doodad scripts. The `doodad check` command uses these
+// to mock the Canvas APIs when validating a script outside of the game.
+func ScriptAPINames() map[string][]string {
+	var (
+		w      = &Canvas{level: level.New()}
+		actor  = &Actor{Drawing: doodads.NewDrawing("", doodads.New(0))}
+		vm     = scripting.NewVM("ScriptAPINames")
+		result = map[string][]string{}
+	)
+
+	for name := range w.MakeSelfAPI(actor, vm) {
+		result["Self"] = append(result["Self"], name)
+	}
+
+	w.MakeScriptAPI(vm)
+	w.MakeSoundAPI(vm, actor)
+	for _, global := range []string{"Actors", "Level", "Camera", "Sound"} {
+		if api, ok := vm.Get(global).Export().(map[string]interface{}); ok {
+			for name := range api {
+				result[global] = append(result[global], name)
+			}
+		}
+	}
+
+	for _, names := range result {
+		sort.Strings(names)
+	}
+	return result
+}
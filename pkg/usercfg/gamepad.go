@@ -0,0 +1,37 @@
+package usercfg
+
+/*
+GamepadProfile holds the button mapping and analog stick settings for one
+game controller. Profiles are stored in Settings.Gamepads by the GUID of
+their controller, so each controller the user plays with keeps its own.
+
+The gamepad actions and their default buttons are defined in pkg/gamepad,
+and only the actions the user has remapped are stored in Buttons.
+*/
+type GamepadProfile struct {
+	Name        string              // name of the controller, for display
+	Style       int                 // gamepad.Style for the default buttons
+	Buttons     map[string][]string `json:",omitempty"` // custom buttons by gamepad action
+	DeadZone    float64             `json:",omitempty"` // analog stick dead zone, 0 = default
+	Sensitivity float64             `json:",omitempty"` // mouse cursor speed multiplier, 0 = default
+}
+
+// GetGamepad returns the profile of a controller by its GUID. If the user
+// has no profile for it yet, a new one is returned which uses the default
+// ControllerStyle; see SetGamepad to keep it.
+func (s *Settings) GetGamepad(guid string) *GamepadProfile {
+	if profile, ok := s.Gamepads[guid]; ok {
+		return profile
+	}
+	return &GamepadProfile{
+		Style: s.ControllerStyle,
+	}
+}
+
+// SetGamepad stores the profile of a controller by its GUID.
+func (s *Settings) SetGamepad(guid string, profile *GamepadProfile) {
+	if s.Gamepads == nil {
+		s.Gamepads = map[string]*GamepadProfile{}
+	}
+	s.Gamepads[guid] = profile
+}
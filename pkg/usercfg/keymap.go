@@ -0,0 +1,341 @@
+package usercfg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+/*
+Keymap holds the user's custom keyboard bindings.
+
+Each action (such as "Undo") may be bound to any number of keys, like
+"Ctrl-Z". Only the actions the user has customized are stored in their
+settings.json, all others use their default keys from KeyActions.
+
+The pkg/keybind functions check these bindings against the keyboard state,
+and the Controls tab of the Settings window (pkg/windows/settings.go) lets
+the user change them.
+*/
+type Keymap map[string][]string
+
+// Names of the rebindable actions.
+const (
+	// Universal shortcut keys.
+	ActionShutdown           = "Shutdown"
+	ActionHelp               = "Help"
+	ActionDebugOverlay       = "DebugOverlay"
+	ActionDebugCollision     = "DebugCollision"
+	ActionShellKey           = "ShellKey"
+	ActionCloseTopmostWindow = "CloseTopmostWindow"
+	ActionCloseAllWindows    = "CloseAllWindows"
+
+	// Movement: moves the player in Play Mode and scrolls the Editor.
+	ActionUp    = "Up"
+	ActionDown  = "Down"
+	ActionLeft  = "Left"
+	ActionRight = "Right"
+
+	// Play Mode.
+	ActionUse       = "Use"
+	ActionInventory = "Inventory"
+	ActionGotoEdit  = "GotoEdit"
+	ActionQuicksave = "Quicksave"
+	ActionQuickload = "Quickload"
+
+	// Level Editor.
+	ActionNewLevel      = "NewLevel"
+	ActionOpen          = "Open"
+	ActionSave          = "Save"
+	ActionSaveAs        = "SaveAs"
+	ActionUndo          = "Undo"
+	ActionRedo          = "Redo"
+	ActionZoomIn        = "ZoomIn"
+	ActionZoomOut       = "ZoomOut"
+	ActionZoomReset     = "ZoomReset"
+	ActionOrigin        = "Origin"
+	ActionGotoPlay      = "GotoPlay"
+	ActionNewViewport   = "NewViewport"
+	ActionDoodadDropper = "DoodadDropper"
+	ActionPencilTool    = "PencilTool"
+	ActionLineTool      = "LineTool"
+	ActionRectTool      = "RectTool"
+	ActionEllipseTool   = "EllipseTool"
+	ActionEraserTool    = "EraserTool"
+)
+
+// KeyContext is where in the game an action's keys are active. Actions in
+// different contexts may share a key without conflict.
+type KeyContext int
+
+// KeyContext values.
+const (
+	KeyGlobal   KeyContext = iota // anywhere in the game
+	KeyMovement                   // both in Play Mode and the Editor
+	KeyPlay                       // only in Play Mode
+	KeyEditor                     // only in the Level Editor
+)
+
+// String name of the context, for the headers of the Settings window.
+func (c KeyContext) String() string {
+	switch c {
+	case KeyGlobal:
+		return "Universal Shortcut Keys"
+	case KeyMovement:
+		return "Movement"
+	case KeyPlay:
+		return "Gameplay Controls (Play Mode)"
+	case KeyEditor:
+		return "Level Editor Shortcuts"
+	}
+	return "Other"
+}
+
+// Overlaps checks whether the keys of two contexts may be active at the
+// same time.
+func (c KeyContext) Overlaps(other KeyContext) bool {
+	if c == other || c == KeyGlobal || other == KeyGlobal {
+		return true
+	}
+	return c == KeyMovement || other == KeyMovement
+}
+
+// KeyAction describes a rebindable action.
+type KeyAction struct {
+	Name     string
+	Label    string // for the Settings window
+	Context  KeyContext
+	Defaults []string
+}
+
+// KeyActions are all of the rebindable actions, in the order they're shown
+// in the Settings window.
+var KeyActions = []KeyAction{
+	{ActionShutdown, "Exit game", KeyGlobal, []string{"Escape"}},
+	{ActionHelp, "Guidebook", KeyGlobal, []string{"F1"}},
+	{ActionDebugOverlay, "Debug overlay", KeyGlobal, []string{"F3"}},
+	{ActionDebugCollision, "Debug collision", KeyGlobal, []string{"F4"}},
+	{ActionShellKey, "Dev console", KeyGlobal, []string{"`"}},
+	{ActionCloseTopmostWindow, "Close window", KeyGlobal, []string{"Backspace"}},
+	{ActionCloseAllWindows, "Close all windows", KeyGlobal, []string{"Shift-Backspace"}},
+
+	{ActionUp, "Jump / Up", KeyMovement, []string{"Up", "W"}},
+	{ActionDown, "Down", KeyMovement, []string{"Down", "S"}},
+	{ActionLeft, "Move left", KeyMovement, []string{"Left", "A"}},
+	{ActionRight, "Move right", KeyMovement, []string{"Right", "D"}},
+
+	{ActionUse, "Activate", KeyPlay, []string{"Space", "Q"}},
+	{ActionInventory, "Show/hide items", KeyPlay, []string{"I"}},
+	{ActionGotoEdit, "Edit level", KeyPlay, []string{"E"}},
+	{ActionQuicksave, "Quicksave", KeyPlay, []string{"F5"}},
+	{ActionQuickload, "Quickload", KeyPlay, []string{"F9"}},
+
+	{ActionNewLevel, "New level", KeyEditor, []string{"Ctrl-N"}},
+	{ActionOpen, "Open drawing", KeyEditor, []string{"Ctrl-O"}},
+	{ActionSave, "Save drawing", KeyEditor, []string{"Ctrl-S"}},
+	{ActionSaveAs, "Save a copy", KeyEditor, []string{"Shift-Ctrl-S"}},
+	{ActionUndo, "Undo", KeyEditor, []string{"Ctrl-Z"}},
+	{ActionRedo, "Redo", KeyEditor, []string{"Ctrl-Y"}},
+	{ActionZoomIn, "Zoom in", KeyEditor, []string{"=", "+"}},
+	{ActionZoomOut, "Zoom out", KeyEditor, []string{"-"}},
+	{ActionZoomReset, "Reset zoom", KeyEditor, []string{"1"}},
+	{ActionOrigin, "Scroll to origin", KeyEditor, []string{"0"}},
+	{ActionGotoPlay, "Playtest", KeyEditor, []string{"P"}},
+	{ActionNewViewport, "New viewport", KeyEditor, []string{"V"}},
+	{ActionDoodadDropper, "Doodads", KeyEditor, []string{"Q"}},
+	{ActionPencilTool, "Pencil Tool", KeyEditor, []string{"F"}},
+	{ActionLineTool, "Line Tool", KeyEditor, []string{"L"}},
+	{ActionRectTool, "Rectangle Tool", KeyEditor, []string{"R"}},
+	{ActionEllipseTool, "Ellipse Tool", KeyEditor, []string{"C"}},
+	{ActionEraserTool, "Eraser Tool", KeyEditor, []string{"X"}},
+}
+
+// GetKeyAction looks up a rebindable action by name.
+func GetKeyAction(name string) (KeyAction, bool) {
+	for _, action := range KeyActions {
+		if action.Name == name {
+			return action, true
+		}
+	}
+	return KeyAction{}, false
+}
+
+// Get the keys bound to an action: the user's custom keys or the defaults.
+func (k Keymap) Get(action string) []string {
+	if keys, ok := k[action]; ok {
+		return keys
+	}
+	if a, ok := GetKeyAction(action); ok {
+		return a.Defaults
+	}
+	return nil
+}
+
+// Set the keys bound to an action. Setting an action back to its default
+// keys removes it from the custom keymap.
+func (k *Keymap) Set(action string, keys []string) {
+	if *k == nil {
+		*k = Keymap{}
+	}
+
+	var normalized = []string{}
+	for _, key := range keys {
+		if binding, err := ParseBinding(key); err == nil {
+			normalized = append(normalized, binding.String())
+		}
+	}
+
+	if a, ok := GetKeyAction(action); ok && sameKeys(a.Defaults, normalized) {
+		delete(*k, action)
+		return
+	}
+	(*k)[action] = normalized
+}
+
+// Add another key to an action, keeping its existing keys.
+func (k *Keymap) Add(action, key string) {
+	binding, err := ParseBinding(key)
+	if err != nil {
+		return
+	}
+
+	var keys = append([]string{}, k.Get(action)...)
+	for _, existing := range keys {
+		if existing == binding.String() {
+			return
+		}
+	}
+	k.Set(action, append(keys, key))
+}
+
+// Reset an action to its default keys.
+func (k Keymap) Reset(action string) {
+	delete(k, action)
+}
+
+// ResetAll sets every action back to its default keys.
+func (k Keymap) ResetAll() {
+	for action := range k {
+		delete(k, action)
+	}
+}
+
+// Conflicts returns the names of the other actions that a key is already
+// bound to, which may be active at the same time as this action.
+func (k Keymap) Conflicts(action, key string) []string {
+	var (
+		result       = []string{}
+		context      = KeyGlobal
+		binding, err = ParseBinding(key)
+	)
+	if err != nil {
+		return result
+	}
+
+	if a, ok := GetKeyAction(action); ok {
+		context = a.Context
+	}
+
+	for _, other := range KeyActions {
+		if other.Name == action || !other.Context.Overlaps(context) {
+			continue
+		}
+		for _, otherKey := range k.Get(other.Name) {
+			if b, err := ParseBinding(otherKey); err == nil && b == binding {
+				result = append(result, other.Name)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// Binding is a parsed key binding like "Shift-Ctrl-S"
+type Binding struct {
+	Ctrl  bool
+	Shift bool
+	Key   string // a key name like "s" or "F1", or a special key like "Escape"
+}
+
+// Special key names which aren't single characters or function keys.
+var specialKeys = []string{
+	"Escape", "Enter", "Space", "Backspace", "Up", "Down", "Left", "Right",
+}
+
+// ParseBinding parses a key binding like "Ctrl-Z", "Shift-Backspace" or "F1".
+// Letter keys are not case sensitive.
+func ParseBinding(key string) (Binding, error) {
+	var b Binding
+
+	for {
+		if len(key) > len("Ctrl-") && strings.EqualFold(key[:len("Ctrl-")], "Ctrl-") {
+			b.Ctrl = true
+			key = key[len("Ctrl-"):]
+		} else if len(key) > len("Shift-") && strings.EqualFold(key[:len("Shift-")], "Shift-") {
+			b.Shift = true
+			key = key[len("Shift-"):]
+		} else {
+			break
+		}
+	}
+
+	if key == "" {
+		return b, errors.New("no key given")
+	}
+
+	// Special keys by name.
+	for _, name := range specialKeys {
+		if strings.EqualFold(key, name) {
+			b.Key = name
+			return b, nil
+		}
+	}
+
+	// Function keys.
+	var (
+		n     int
+		upper = strings.ToUpper(key)
+	)
+	if _, err := fmt.Sscanf(upper, "F%d", &n); err == nil && n >= 1 && n <= 12 && fmt.Sprintf("F%d", n) == upper {
+		b.Key = upper
+		return b, nil
+	}
+
+	// Single characters.
+	if len([]rune(key)) == 1 {
+		b.Key = strings.ToLower(key)
+		return b, nil
+	}
+
+	return b, fmt.Errorf("unknown key: %s", key)
+}
+
+// String formats the binding for display and the settings file, like
+// "Shift-Ctrl-S"
+func (b Binding) String() string {
+	var key = b.Key
+	if len([]rune(key)) == 1 {
+		key = strings.ToUpper(key)
+	}
+	if b.Ctrl {
+		key = "Ctrl-" + key
+	}
+	if b.Shift {
+		key = "Shift-" + key
+	}
+	return key
+}
+
+// sameKeys compares two lists of keys.
+func sameKeys(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package usercfg
+
+import "testing"
+
+func TestParseBinding(t *testing.T) {
+	tests := []struct {
+		key    string
+		expect Binding
+		string string
+		err    bool
+	}{
+		{"Ctrl-Z", Binding{Ctrl: true, Key: "z"}, "Ctrl-Z", false},
+		{"shift-ctrl-s", Binding{Ctrl: true, Shift: true, Key: "s"}, "Shift-Ctrl-S", false},
+		{"Shift-Backspace", Binding{Shift: true, Key: "Backspace"}, "Shift-Backspace", false},
+		{"F1", Binding{Key: "F1"}, "F1", false},
+		{"f", Binding{Key: "f"}, "F", false},
+		{"Ctrl--", Binding{Ctrl: true, Key: "-"}, "Ctrl--", false},
+		{"escape", Binding{Key: "Escape"}, "Escape", false},
+		{"Ctrl-", Binding{}, "", true},
+		{"PageUp", Binding{}, "", true},
+	}
+	for i, test := range tests {
+		actual, err := ParseBinding(test.key)
+		if test.err {
+			if err == nil {
+				t.Errorf("Test %d: expected an error parsing %s", i, test.key)
+			}
+			continue
+		}
+		if err != nil || actual != test.expect || actual.String() != test.string {
+			t.Errorf("Test %d: ParseBinding(%s): expected %+v (%s) but got %+v (%s), err=%v",
+				i, test.key, test.expect, test.string, actual, actual.String(), err,
+			)
+		}
+	}
+}
+
+func TestKeymap(t *testing.T) {
+	var k Keymap
+
+	// Defaults.
+	if keys := k.Get(ActionUndo); len(keys) != 1 || keys[0] != "Ctrl-Z" {
+		t.Errorf("expected the default Undo key, got %+v", keys)
+	}
+
+	// Rebind Undo and add a second key.
+	k.Set(ActionUndo, []string{"ctrl-u"})
+	k.Add(ActionUndo, "F5")
+	if keys := k.Get(ActionUndo); len(keys) != 2 || keys[0] != "Ctrl-U" || keys[1] != "F5" {
+		t.Errorf("unexpected custom Undo keys: %+v", keys)
+	}
+
+	// Conflicts: Editor and Play Mode actions may share keys, but
+	// universal and movement keys conflict with both.
+	if c := k.Conflicts(ActionGotoPlay, "E"); len(c) != 0 {
+		t.Errorf("Play Mode and the Editor may share a key, got conflicts %+v", c)
+	}
+	if c := k.Conflicts(ActionPencilTool, "W"); len(c) != 1 || c[0] != ActionUp {
+		t.Errorf("expected W to conflict with Up, got %+v", c)
+	}
+	if c := k.Conflicts(ActionUse, "f3"); len(c) != 1 || c[0] != ActionDebugOverlay {
+		t.Errorf("expected F3 to conflict with DebugOverlay, got %+v", c)
+	}
+	if c := k.Conflicts(ActionRedo, "Ctrl-U"); len(c) != 1 || c[0] != ActionUndo {
+		t.Errorf("expected Ctrl-U to conflict with the rebound Undo, got %+v", c)
+	}
+
+	// Setting the defaults again removes the custom binding.
+	k.Set(ActionUndo, []string{"Ctrl-Z"})
+	if _, ok := k[ActionUndo]; ok {
+		t.Errorf("Undo should have been reset to default")
+	}
+
+	// Reset everything.
+	k.Set(ActionSave, []string{"F2"})
+	k.ResetAll()
+	if len(k) != 0 {
+		t.Errorf("expected an empty keymap after ResetAll, got %+v", k)
+	}
+}
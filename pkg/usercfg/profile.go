@@ -0,0 +1,132 @@
+package usercfg
+
+import (
+	"encoding/json"
+)
+
+/*
+ProfileFields are the settings which a save profile may override: the ones
+about how a player likes to play, rather than about the level editor or the
+computer. They are named as in the JSON of the settings file.
+
+A profile with its own settings has them stored with the profile (see
+pkg/savegame), and the settings file keeps the global values of them.
+*/
+var ProfileFields = []string{
+	"Keymap",
+	"Gamepads",
+	"ControllerStyle",
+	"CrosshairSize",
+	"CrosshairColor",
+	"HideTouchHints",
+	"MusicVolume",
+	"SoundVolume",
+	"MuteAudio",
+}
+
+// Overrides of the settings by a save profile, by field name.
+type Overrides map[string]json.RawMessage
+
+var (
+	overrides       Overrides                  // of the active save profile, or nil
+	onSaveOverrides func(Overrides)            // to store them with the profile
+	globalFields    map[string]json.RawMessage // the settings file as loaded
+)
+
+/*
+SetOverrides applies the settings of a save profile over the user's
+settings, or removes them if nil. The settings are reloaded from disk, and
+later calls to Save will call onSave with the profile's updated settings
+rather than writing them to the settings file.
+*/
+func SetOverrides(o Overrides, onSave func(Overrides)) error {
+	overrides = o
+	onSaveOverrides = onSave
+	return Load()
+}
+
+// CurrentOverrides returns the profile's own values of the ProfileFields
+// from the current settings, e.g. to start a profile with its own settings.
+func CurrentOverrides() (Overrides, error) {
+	fields, err := toFields(Current)
+	if err != nil {
+		return nil, err
+	}
+
+	var result = Overrides{}
+	for _, name := range ProfileFields {
+		if value, ok := fields[name]; ok {
+			result[name] = value
+		}
+	}
+	return result, nil
+}
+
+// applyOverrides returns the settings with the profile's settings over
+// them. A profile setting which is missing or null is the zero value.
+func applyOverrides(settings *Settings) (*Settings, error) {
+	if overrides == nil {
+		return settings, nil
+	}
+
+	fields, err := toFields(settings)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range ProfileFields {
+		if value, ok := overrides[name]; ok {
+			fields[name] = value
+		}
+	}
+
+	bin, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var result = &Settings{}
+	err = json.Unmarshal(bin, result)
+	return result, err
+}
+
+// splitOverrides takes the profile's settings out of the settings to save,
+// putting back their global values, and stores them with the profile.
+func splitOverrides(fields map[string]json.RawMessage) {
+	if overrides == nil {
+		return
+	}
+
+	for _, name := range ProfileFields {
+		if _, ok := overrides[name]; !ok {
+			continue
+		}
+
+		if value, ok := fields[name]; ok {
+			overrides[name] = value
+		} else {
+			overrides[name] = json.RawMessage("null") // omitted zero value
+		}
+
+		if value, ok := globalFields[name]; ok {
+			fields[name] = value
+		} else {
+			delete(fields, name)
+		}
+	}
+
+	if onSaveOverrides != nil {
+		onSaveOverrides(overrides)
+	}
+}
+
+// toFields encodes the settings to their JSON fields.
+func toFields(settings *Settings) (map[string]json.RawMessage, error) {
+	bin, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields = map[string]json.RawMessage{}
+	err = json.Unmarshal(bin, &fields)
+	return fields, err
+}
@@ -0,0 +1,49 @@
+package usercfg
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOverrides(t *testing.T) {
+	defer func() {
+		overrides, onSaveOverrides, globalFields = nil, nil, nil
+	}()
+
+	var global = Defaults()
+	global.MusicVolume = 80
+	global.MuteAudio = true
+	global.UndoHistory = 42
+
+	// The profile plays unmuted at full volume.
+	overrides = Overrides{
+		"MusicVolume": json.RawMessage("100"),
+		"MuteAudio":   json.RawMessage("null"),
+	}
+	settings, err := applyOverrides(global)
+	if err != nil {
+		t.Fatalf("applyOverrides: %s", err)
+	}
+	if settings.MusicVolume != 100 || settings.MuteAudio || settings.UndoHistory != 42 {
+		t.Errorf("unexpected settings with the overrides: %+v", settings)
+	}
+
+	// Saving keeps the profile's settings out of the global ones.
+	globalFields, _ = toFields(global)
+	settings.MusicVolume = 50
+	settings.UndoHistory = 10
+
+	var saved Overrides
+	onSaveOverrides = func(o Overrides) {
+		saved = o
+	}
+	fields, _ := toFields(settings)
+	splitOverrides(fields)
+
+	if string(saved["MusicVolume"]) != "50" || string(saved["MuteAudio"]) != "null" {
+		t.Errorf("unexpected profile settings: %s", saved)
+	}
+	if string(fields["MusicVolume"]) != "80" || string(fields["MuteAudio"]) != "true" || string(fields["UndoHistory"]) != "10" {
+		t.Errorf("unexpected global settings: %s", fields)
+	}
+}
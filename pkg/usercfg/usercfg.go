@@ -6,6 +6,9 @@ Other places in the codebase to look for its related functionality:
   - pkg/windows/settings.go: the Settings Window is the UI owner of
     this feature, it adjusts the usercfg.Current struct and Saves the
     changes to disk.
+  - pkg/keybind: checks the keyboard state against the user's Keymap.
+  - pkg/gamepad: maps game controller buttons by the GamepadProfiles.
+  - pkg/savegame: save profiles may override some settings (profile.go).
 */
 package usercfg
 
@@ -39,6 +42,18 @@ type Settings struct {
 	HideTouchHints     bool `json:",omitempty"`
 	DisableAutosave    bool `json:",omitempty"`
 	ControllerStyle    int
+	UndoHistory        int `json:",omitempty"` // 0 = balance.UndoHistory
+
+	// Custom keyboard bindings (keymap.go)
+	Keymap Keymap `json:",omitempty"`
+
+	// Game controller profiles by their GUID (gamepad.go)
+	Gamepads map[string]*GamepadProfile `json:",omitempty"`
+
+	// Audio settings: volumes are in percent.
+	MusicVolume int
+	SoundVolume int
+	MuteAudio   bool `json:",omitempty"`
 
 	// Secret boolprops from balance/boolprops.go
 	ShowHiddenDoodads bool `json:",omitempty"`
@@ -55,7 +70,10 @@ var Current = Defaults()
 
 // Defaults returns sensible default user settings.
 func Defaults() *Settings {
-	settings := &Settings{}
+	settings := &Settings{
+		MusicVolume: 100,
+		SoundVolume: 100,
+	}
 	return settings
 }
 
@@ -79,11 +97,22 @@ func Save() error {
 			Current.Entropy = key
 		}
 	}
-	if err := enc.Encode(Current); err != nil {
+
+	// A save profile with its own settings keeps them out of the file.
+	fields, err := toFields(Current)
+	if err != nil {
+		return err
+	}
+	splitOverrides(fields)
+
+	if err := enc.Encode(fields); err != nil {
 		return err
 	}
-	err := ioutil.WriteFile(filename, bin.Bytes(), 0644)
-	return err
+	if err := ioutil.WriteFile(filename, bin.Bytes(), 0644); err != nil {
+		return err
+	}
+	globalFields = fields
+	return nil
 }
 
 // Load the settings from disk. The loaded settings will be available
@@ -98,21 +127,29 @@ func Load() error {
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
 		Current = settings
 	} else {
-		fh, err := os.Open(filename)
+		bin, err := ioutil.ReadFile(filename)
 		if err != nil {
 			return err
 		}
 
 		// Decode JSON from file.
-		dec := json.NewDecoder(fh)
-		err = dec.Decode(settings)
-		if err != nil {
+		if err := json.Unmarshal(bin, settings); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(bin, &globalFields); err != nil {
 			return err
 		}
 
 		Current = settings
 	}
 
+	// Apply the settings of the save profile.
+	if settings, err := applyOverrides(Current); err != nil {
+		log.Error("Couldn't apply the save profile's settings: %s", err)
+	} else {
+		Current = settings
+	}
+
 	// If we don't have an entropy key saved, make one and save it.
 	if Current.Entropy == nil || len(Current.Entropy) == 0 {
 		log.Info("Initialized entropy field in settings.json")
@@ -15,14 +15,17 @@ import (
 var (
 	ConfigDirectoryName = "doodle"
 
-	ProfileDirectory    string
-	LevelDirectory      string
-	LevelPackDirectory  string
-	DoodadDirectory     string
-	CampaignDirectory   string
-	ScreenshotDirectory string
-	SaveFile            string
-	LogFile             string
+	ProfileDirectory     string
+	LevelDirectory       string
+	LevelPackDirectory   string
+	DoodadDirectory      string
+	CampaignDirectory    string
+	ScreenshotDirectory  string
+	AutosaveDirectory    string
+	SaveProfileDirectory string
+	SaveFile             string
+	AnalyticsFile        string
+	LogFile              string
 
 	CacheDirectory string
 	FontDirectory  string
@@ -43,7 +46,10 @@ func init() {
 	DoodadDirectory = configdir.LocalConfig(ConfigDirectoryName, "doodads")
 	CampaignDirectory = configdir.LocalConfig(ConfigDirectoryName, "campaigns")
 	ScreenshotDirectory = configdir.LocalConfig(ConfigDirectoryName, "screenshots")
+	AutosaveDirectory = configdir.LocalConfig(ConfigDirectoryName, "autosave")
+	SaveProfileDirectory = configdir.LocalConfig(ConfigDirectoryName, "saves")
 	SaveFile = configdir.LocalConfig(ConfigDirectoryName, "savegame.json")
+	AnalyticsFile = configdir.LocalConfig(ConfigDirectoryName, "analytics.json")
 	LogFile = configdir.LocalConfig(ConfigDirectoryName, "logfile.txt")
 
 	// Cache directory to extract font files to.
@@ -59,6 +65,8 @@ func init() {
 		configdir.MakePath(CampaignDirectory)
 		configdir.MakePath(FontDirectory)
 		configdir.MakePath(ScreenshotDirectory)
+		configdir.MakePath(AutosaveDirectory)
+		configdir.MakePath(SaveProfileDirectory)
 	}
 }
 
@@ -0,0 +1,218 @@
+package windows
+
+import (
+	"fmt"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/achievements"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/levelpack"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/savegame"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
+	"git.kirsle.net/go/render"
+	"git.kirsle.net/go/ui"
+)
+
+// Achievements window lists the built-in and levelpack achievements, and
+// which ones the player has unlocked.
+type Achievements struct {
+	// Settings passed in by doodle
+	Supervisor *ui.Supervisor
+	Engine     render.Engine
+}
+
+// MakeAchievementsWindow initializes the window and centers it on screen.
+func MakeAchievementsWindow(cfg Achievements) *ui.Window {
+	win := NewAchievementsWindow(cfg)
+	win.Compute(cfg.Engine)
+	win.Supervise(cfg.Supervisor)
+
+	// Center the window.
+	var (
+		w, h = shmem.CurrentRenderEngine.WindowSize()
+		size = win.Size()
+	)
+	win.MoveTo(render.Point{
+		X: (w / 2) - (size.W / 2),
+		Y: (h / 2) - (size.H / 2),
+	})
+
+	return win
+}
+
+// NewAchievementsWindow initializes the window.
+func NewAchievementsWindow(cfg Achievements) *ui.Window {
+	var (
+		Width    = 440
+		Height   = 400
+		rows     = []*ui.Frame{}
+		perPage  = 6
+		page     = 1
+		rowsPage = func(i int) bool {
+			return i >= (page-1)*perPage && i < page*perPage
+		}
+	)
+
+	// The built-in achievements, then those of each levelpack.
+	var books = []*achievements.Book{}
+	if book, err := achievements.Builtin(); err != nil {
+		log.Error("NewAchievementsWindow: %s", err)
+	} else {
+		books = append(books, book)
+	}
+
+	lpFiles, packmap, err := levelpack.LoadAllAvailable()
+	if err != nil {
+		log.Error("Couldn't list levelpack files: %s", err)
+	}
+	for _, filename := range lpFiles {
+		if lp, ok := packmap[filename]; ok {
+			if book := lp.AchievementBook(); book != nil {
+				books = append(books, book)
+			}
+		}
+	}
+
+	// Load the user's savegame.json
+	sg, err := savegame.GetOrCreate()
+	if err != nil {
+		log.Warn("NewAchievementsWindow: didn't load savegame json (fresh struct created): %s", err)
+	}
+
+	window := ui.NewWindow("Achievements")
+	window.SetButtons(ui.CloseButton)
+	window.Configure(ui.Config{
+		Width:      Width,
+		Height:     Height,
+		Background: render.Grey,
+	})
+
+	frame := ui.NewFrame("Window Body Frame")
+	window.Pack(frame, ui.Pack{
+		Side:   ui.N,
+		Fill:   true,
+		Expand: true,
+	})
+
+	/******************
+	 * One row per achievement.
+	 ******************/
+
+	var unlocked, total int
+	for _, book := range books {
+		for _, def := range book.Definitions {
+			total++
+
+			var (
+				when, ok    = sg.Achievements.Unlocked[book.Key(def.ID)]
+				title       = def.Title
+				description = def.Description
+				status      = "Locked"
+				font        = balance.UIFont
+			)
+			if ok {
+				unlocked++
+				status = "Unlocked " + when.Format("Jan 2, 2006")
+				font = balance.LabelFont
+			} else if def.Hidden {
+				title = "???"
+				description = "This achievement is a secret."
+			}
+			if book.Pack != "" {
+				title = fmt.Sprintf("%s (%s)", title, book.Title)
+			}
+
+			row := ui.NewFrame("Achievement " + book.Key(def.ID))
+			row.Configure(ui.Config{
+				BorderStyle: ui.BorderRaised,
+				BorderSize:  1,
+			})
+			rows = append(rows, row)
+
+			titleLabel := ui.NewLabel(ui.Label{
+				Text: title,
+				Font: font,
+			})
+			row.Pack(titleLabel, ui.Pack{
+				Side: ui.NW,
+				PadX: 4,
+			})
+
+			descLabel := ui.NewLabel(ui.Label{
+				Text: description,
+				Font: balance.UIFont,
+			})
+			row.Pack(descLabel, ui.Pack{
+				Side: ui.NW,
+				PadX: 4,
+			})
+
+			statusLabel := ui.NewLabel(ui.Label{
+				Text: status,
+				Font: balance.SmallFont,
+			})
+			row.Pack(statusLabel, ui.Pack{
+				Side: ui.NW,
+				PadX: 4,
+			})
+		}
+	}
+
+	intro := ui.NewLabel(ui.Label{
+		Text: fmt.Sprintf("You have unlocked %d of %d achievements.", unlocked, total),
+		Font: balance.UIFont,
+	})
+	frame.Pack(intro, ui.Pack{
+		Side: ui.N,
+		PadY: 4,
+	})
+
+	for i, row := range rows {
+		if !rowsPage(i) {
+			row.Hide()
+		}
+		frame.Pack(row, ui.Pack{
+			Side:  ui.N,
+			FillX: true,
+			PadY:  1,
+		})
+	}
+
+	/******************
+	 * Pager.
+	 ******************/
+
+	bottomFrame := ui.NewFrame("Button Frame")
+	frame.Pack(bottomFrame, ui.Pack{
+		Side:  ui.S,
+		FillX: true,
+	})
+
+	pager := ui.NewPager(ui.Pager{
+		Name:           "Achievements Pager",
+		Page:           page,
+		Pages:          (len(rows) + perPage - 1) / perPage,
+		PerPage:        perPage,
+		MaxPageButtons: 6,
+		Font:           balance.MenuFont,
+		OnChange: func(newPage, perPage int) {
+			page = newPage
+			for i, row := range rows {
+				if rowsPage(i) {
+					row.Show()
+				} else {
+					row.Hide()
+				}
+			}
+		},
+	})
+	pager.Compute(cfg.Engine)
+	pager.Supervise(cfg.Supervisor)
+	bottomFrame.Pack(pager, ui.Pack{
+		Side: ui.W,
+		PadX: 4,
+	})
+
+	window.Hide()
+	return window
+}
@@ -2,8 +2,11 @@ package windows
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/enum"
@@ -12,6 +15,7 @@ import (
 	"git.kirsle.net/SketchyMaze/doodle/pkg/modal"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/native"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/sound"
 	magicform "git.kirsle.net/SketchyMaze/doodle/pkg/uix/magic-form"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/wallpaper"
 	"git.kirsle.net/go/render"
@@ -32,6 +36,7 @@ type AddEditLevel struct {
 
 	// Callback functions.
 	OnChangePageTypeAndWallpaper func(pageType level.PageType, wallpaper string)
+	OnChange                     func() // a property of the EditLevel was changed
 	OnCreateNewLevel             func(*level.Level)
 	OnCreateNewDoodad            func(width, height int)
 	OnUpdateScreenshot           func() error
@@ -272,6 +277,7 @@ func (config AddEditLevel) setupLevelFrame(tf *ui.TabFrame) {
 		var (
 			levelSizeStr    = fmt.Sprintf("%dx%d", config.EditLevel.MaxWidth, config.EditLevel.MaxHeight)
 			levelSizeRegexp = regexp.MustCompile(`^(\d+)x(\d+)$`)
+			tagsStr         = strings.Join(config.EditLevel.Tags, ", ")
 		)
 		fields = append(fields, []magicform.Field{
 			{
@@ -295,9 +301,11 @@ func (config AddEditLevel) setupLevelFrame(tf *ui.TabFrame) {
 
 						config.EditLevel.MaxWidth = int64(width)
 						config.EditLevel.MaxHeight = int64(height)
+						config.onChange()
 					})
 				},
 			},
+			config.musicField(),
 			{
 				Label: "Metadata",
 				Font:  balance.LabelFont,
@@ -308,6 +316,7 @@ func (config AddEditLevel) setupLevelFrame(tf *ui.TabFrame) {
 				TextVariable: &config.EditLevel.Title,
 				PromptUser: func(answer string) {
 					config.EditLevel.Title = answer
+					config.onChange()
 				},
 			},
 			{
@@ -316,6 +325,30 @@ func (config AddEditLevel) setupLevelFrame(tf *ui.TabFrame) {
 				TextVariable: &config.EditLevel.Author,
 				PromptUser: func(answer string) {
 					config.EditLevel.Author = answer
+					config.onChange()
+				},
+			},
+			{
+				Label:        "Description:",
+				Font:         balance.UIFont,
+				TextVariable: &config.EditLevel.Description,
+				PromptUser: func(answer string) {
+					config.EditLevel.Description = answer
+					config.onChange()
+				},
+			},
+			{
+				Label:        "Tags:",
+				Font:         balance.UIFont,
+				TextVariable: &tagsStr,
+				Tooltip: ui.Tooltip{
+					Text: "Comma separated, e.g.: puzzle, short",
+					Edge: ui.Top,
+				},
+				PromptUser: func(answer string) {
+					config.EditLevel.Tags = level.ParseTags(answer)
+					tagsStr = strings.Join(config.EditLevel.Tags, ", ")
+					config.onChange()
 				},
 			},
 		}...)
@@ -499,6 +532,73 @@ func (config AddEditLevel) setupDoodadFrame(tf *ui.TabFrame) {
 
 }
 
+// Background music picker for an existing level: built-in music, or an
+// audio file embedded in the level.
+func (config AddEditLevel) musicField() magicform.Field {
+	const embedNew = "\x00embed"
+
+	var options = []magicform.Option{
+		{
+			Label: "None",
+			Value: "",
+		},
+	}
+	for _, filename := range sound.ListMusic() {
+		options = append(options, magicform.Option{
+			Label: filename,
+			Value: filename,
+		})
+	}
+	for _, filename := range config.EditLevel.ListFilesAt(balance.EmbeddedMusicBasePath) {
+		filename = filepath.Base(filename)
+		options = append(options, magicform.Option{
+			Label: filename + " (embedded)",
+			Value: filename,
+		})
+	}
+	options = append(options, []magicform.Option{
+		{
+			Separator: true,
+		},
+		{
+			Label: "Embed a music file...",
+			Value: embedNew,
+		},
+	}...)
+
+	return magicform.Field{
+		Label:       "Music:",
+		Font:        balance.UIFont,
+		Options:     options,
+		SelectValue: config.EditLevel.Music,
+		OnSelect: func(v interface{}) {
+			value, _ := v.(string)
+			if value != embedNew {
+				config.EditLevel.Music = value
+				config.onChange()
+				return
+			}
+
+			filename, err := native.OpenFile("Choose a music file:", "*.ogg *.mp3 *.wav")
+			if err != nil {
+				return
+			}
+
+			data, err := os.ReadFile(filename)
+			if err != nil {
+				shmem.Flash("Error loading music: %s", err)
+				return
+			}
+
+			var name = filepath.Base(filename)
+			config.EditLevel.SetFile(balance.EmbeddedMusicBasePath+name, data)
+			config.EditLevel.Music = name
+			config.onChange()
+			shmem.Flash("Embedded music %s into the level", name)
+		},
+	}
+}
+
 // Creates the Game Rules frame for existing level (set difficulty, etc.)
 func (config AddEditLevel) setupGameRuleFrame(tf *ui.TabFrame) {
 	frame := tf.AddTab("GameRules", ui.NewLabel(ui.Label{
@@ -547,12 +647,14 @@ func (config AddEditLevel) setupGameRuleFrame(tf *ui.TabFrame) {
 				value, _ := v.(enum.Difficulty)
 				config.EditLevel.GameRule.Difficulty = value
 				log.Info("Set level difficulty to: %d (%s)", value, value)
+				config.onChange()
 			},
 		},
 		{
 			Label:        "Survival Mode (silver high score)",
 			Font:         balance.UIFont,
 			BoolVariable: &config.EditLevel.GameRule.Survival,
+			OnClick:      config.onChange,
 			Tooltip: ui.Tooltip{
 				Text: "Use for levels where dying at least once is very likely\n" +
 					"(e.g. Azulian Tag). The silver high score will be for\n" +
@@ -561,6 +663,17 @@ func (config AddEditLevel) setupGameRuleFrame(tf *ui.TabFrame) {
 				Edge: ui.Top,
 			},
 		},
+		{
+			Label:        "No quicksaves",
+			Font:         balance.UIFont,
+			BoolVariable: &config.EditLevel.GameRule.NoQuicksave,
+			OnClick:      config.onChange,
+			Tooltip: ui.Tooltip{
+				Text: "Don't let the player quicksave and quickload in this\n" +
+					"level: they must beat it from its checkpoints.",
+				Edge: ui.Top,
+			},
+		},
 	}
 
 	form.Create(frame, fields)
@@ -702,3 +815,10 @@ func (config AddEditLevel) setupAdvancedFrame(tf *ui.TabFrame) {
 
 	form.Create(frame, fields)
 }
+
+// onChange calls the OnChange handler, if there is one.
+func (config AddEditLevel) onChange() {
+	if config.OnChange != nil {
+		config.OnChange()
+	}
+}
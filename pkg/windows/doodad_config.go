@@ -26,6 +26,10 @@ type DoodadConfig struct {
 	ActiveTab string // specify the tab to open
 	OnRefresh func() // caller should rebuild the window
 
+	// Optional: every change to the actor's options is passed through here,
+	// e.g. to record it in the undo history. The handler must call apply.
+	OnChange func(apply func())
+
 	// Widgets.
 	TabFrame *ui.TabFrame
 }
@@ -243,7 +247,9 @@ func (c DoodadConfig) makeOptionsTab(tabFrame *ui.TabFrame, Width, Height int) *
 					} else {
 						label = "false"
 					}
-					c.EditActor.Actor.SetOption(name, value.Type, label)
+					c.change(func() {
+						c.EditActor.Actor.SetOption(name, value.Type, label)
+					})
 					checkbox.SetText(label)
 					return nil
 				})
@@ -260,7 +266,9 @@ func (c DoodadConfig) makeOptionsTab(tabFrame *ui.TabFrame, Width, Height int) *
 						if answer == "" {
 							return
 						}
-						answer = c.EditActor.Actor.SetOption(name, value.Type, answer)
+						c.change(func() {
+							answer = c.EditActor.Actor.SetOption(name, value.Type, answer)
+						})
 						button.SetText(answer)
 					})
 					return nil
@@ -279,7 +287,9 @@ func (c DoodadConfig) makeOptionsTab(tabFrame *ui.TabFrame, Width, Height int) *
 			btnDelete.SetStyle(&balance.ButtonDanger)
 			btnDelete.Handle(ui.Click, func(ed ui.EventData) error {
 				log.Info("Delete option: %s", name)
-				delete(c.EditActor.Actor.Options, name)
+				c.change(func() {
+					delete(c.EditActor.Actor.Options, name)
+				})
 
 				// Update the value button's text label.
 				if stt, ok := btnValue.(SetTextable); ok {
@@ -317,3 +327,13 @@ func (c DoodadConfig) makeOptionsTab(tabFrame *ui.TabFrame, Width, Height int) *
 
 	return tab
 }
+
+// change applies a change to the actor's options, by way of the OnChange
+// handler if there is one.
+func (c DoodadConfig) change(apply func()) {
+	if c.OnChange != nil {
+		c.OnChange(apply)
+	} else {
+		apply()
+	}
+}
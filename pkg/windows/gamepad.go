@@ -0,0 +1,376 @@
+package windows
+
+import (
+	"fmt"
+	"strings"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/gamepad"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
+	magicform "git.kirsle.net/SketchyMaze/doodle/pkg/uix/magic-form"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/usercfg"
+	"git.kirsle.net/go/render"
+	"git.kirsle.net/go/ui"
+)
+
+// Gamepad window remaps the buttons and analog stick settings of the Player
+// One game controller, in its profile of the user settings.
+type Gamepad struct {
+	// Settings passed in by doodle
+	Supervisor *ui.Supervisor
+	Engine     render.Engine
+}
+
+// MakeGamepadWindow initializes the window and centers it on screen.
+func MakeGamepadWindow(cfg Gamepad) *ui.Window {
+	win := NewGamepadWindow(cfg)
+	win.Compute(cfg.Engine)
+	win.Supervise(cfg.Supervisor)
+
+	// Center the window.
+	var (
+		w, h = shmem.CurrentRenderEngine.WindowSize()
+		size = win.Size()
+	)
+	win.MoveTo(render.Point{
+		X: (w / 2) - (size.W / 2),
+		Y: (h / 2) - (size.H / 2),
+	})
+
+	return win
+}
+
+// NewGamepadWindow initializes the window.
+func NewGamepadWindow(cfg Gamepad) *ui.Window {
+	var (
+		Width  = 400
+		Height = 400
+
+		guid, name, connected = gamepad.PlayerOne()
+	)
+
+	window := ui.NewWindow("Gamepad: " + name)
+	window.SetButtons(ui.CloseButton)
+	window.Configure(ui.Config{
+		Width:      Width,
+		Height:     Height,
+		Background: render.Grey,
+	})
+
+	frame := ui.NewFrame("Window Body Frame")
+	window.Pack(frame, ui.Pack{
+		Side:   ui.N,
+		Fill:   true,
+		Expand: true,
+	})
+
+	if !connected {
+		window.Configure(ui.Config{
+			Height: 100,
+		})
+		label := ui.NewLabel(ui.Label{
+			Text: "No game controller is connected.\n\n" +
+				"Connect one and press a button on it, then try again.",
+			Font: balance.UIFont,
+		})
+		frame.Pack(label, ui.Pack{
+			Side: ui.N,
+			PadY: 8,
+		})
+		window.Hide()
+		return window
+	}
+
+	var (
+		profile   = usercfg.Current.GetGamepad(guid)
+		rowHeight = 20
+		labelSize = render.NewRect(140, rowHeight)
+		btnSize   = render.NewRect(180, rowHeight)
+
+		rows     = []*ui.Frame{}
+		refresh  = []func(){} // update the button labels after a reset
+		mode     = gamepad.Mode(-2)
+		perPage  = 8
+		page     = 1
+		rowsPage = func(i int) bool {
+			return i >= (page-1)*perPage && i < page*perPage
+		}
+	)
+
+	// Save the profile of this controller.
+	save := func() {
+		profile.Name = name
+		usercfg.Current.SetGamepad(guid, profile)
+		saveGameSettings()
+	}
+
+	// Text for the buttons mapped to an action.
+	mapping := func(action string) string {
+		if buttons := gamepad.GetButtons(profile, action); len(buttons) > 0 {
+			return strings.Join(buttons, " or ")
+		}
+		return "(none)"
+	}
+
+	/******************
+	 * Button style and analog sticks.
+	 ******************/
+
+	var deadZones, sensitivities []magicform.Option
+	for _, v := range []float64{0.1, 0.2, 0.3, 0.4, 0.5} {
+		deadZones = append(deadZones, magicform.Option{
+			Label: fmt.Sprintf("%d%%", int(v*100)),
+			Value: v,
+		})
+	}
+	for _, v := range []float64{0.5, 0.75, 1, 1.5, 2} {
+		sensitivities = append(sensitivities, magicform.Option{
+			Label: fmt.Sprintf("%gx", v),
+			Value: v,
+		})
+	}
+
+	form := magicform.Form{
+		Supervisor: cfg.Supervisor,
+		Engine:     cfg.Engine,
+		Vertical:   true,
+		LabelWidth: 150,
+	}
+	form.Create(frame, []magicform.Field{
+		{
+			Label: "Button Style:",
+			Font:  balance.UIFont,
+			Type:  magicform.Selectbox,
+			Options: []magicform.Option{
+				{
+					Label: "X Style",
+					Value: int(gamepad.XStyle),
+				},
+				{
+					Label: "N Style",
+					Value: int(gamepad.NStyle),
+				},
+			},
+			SelectValue: profile.Style,
+			OnSelect: func(v interface{}) {
+				style, _ := v.(int)
+				profile.Style = style
+				save()
+				for _, f := range refresh {
+					f()
+				}
+			},
+		},
+		{
+			Label:       "Stick dead zone:",
+			Font:        balance.UIFont,
+			Type:        magicform.Selectbox,
+			Options:     deadZones,
+			SelectValue: gamepad.DeadZone(profile),
+			OnSelect: func(v interface{}) {
+				profile.DeadZone, _ = v.(float64)
+				save()
+			},
+		},
+		{
+			Label:       "Cursor sensitivity:",
+			Font:        balance.UIFont,
+			Type:        magicform.Selectbox,
+			Options:     sensitivities,
+			SelectValue: gamepad.Sensitivity(profile),
+			OnSelect: func(v interface{}) {
+				profile.Sensitivity, _ = v.(float64)
+				save()
+			},
+		},
+	})
+
+	/******************
+	 * Button mappings.
+	 ******************/
+
+	// Capture a button to map to an action: replacing its buttons, or
+	// adding another button to them.
+	var cancelPending func() // restores the button of a pending capture
+	remap := func(action gamepad.Action, text *string, add bool) {
+		if cancelPending != nil {
+			cancelPending()
+		}
+		cancelPending = func() {
+			*text = mapping(action.Name)
+		}
+
+		*text = "Press a button..."
+		gamepad.Capture(func(button string) {
+			cancelPending = nil
+			defer func() {
+				*text = mapping(action.Name)
+			}()
+			if button == "" {
+				return
+			}
+
+			if conflicts := gamepad.Conflicts(profile, action.Name, button); len(conflicts) > 0 {
+				var labels = []string{}
+				for _, name := range conflicts {
+					if other, ok := gamepad.GetAction(name); ok {
+						labels = append(labels, other.Label)
+					}
+				}
+				shmem.FlashError("%s is already mapped to: %s", button, strings.Join(labels, ", "))
+				return
+			}
+
+			if add {
+				gamepad.AddButton(profile, action.Name, button)
+			} else {
+				gamepad.SetButtons(profile, action.Name, []string{button})
+			}
+			save()
+		})
+	}
+
+	for _, action := range gamepad.Actions {
+		var action = action // rescope
+
+		// Header row for the next mode of actions.
+		if action.Mode != mode {
+			mode = action.Mode
+
+			var header = "Any Mode"
+			switch mode {
+			case gamepad.GameplayMode:
+				header = "Gameplay Mode"
+			case gamepad.MouseMode:
+				header = "Mouse Mode"
+			}
+
+			row := ui.NewFrame("Header Row")
+			label := ui.NewLabel(ui.Label{
+				Text: header,
+				Font: balance.LabelFont,
+			})
+			row.Pack(label, ui.Pack{
+				Side: ui.W,
+			})
+			rows = append(rows, row)
+		}
+
+		row := ui.NewFrame("Button Row " + action.Name)
+		rows = append(rows, row)
+
+		helpLabel := ui.NewLabel(ui.Label{
+			Text: action.Label,
+			Font: balance.UIFont,
+		})
+		helpLabel.Resize(labelSize)
+		row.Pack(helpLabel, ui.Pack{
+			Side: ui.W,
+			PadX: 1,
+		})
+
+		// Button showing the mapped buttons: click to remap.
+		var text = mapping(action.Name)
+		refresh = append(refresh, func() {
+			text = mapping(action.Name)
+		})
+
+		mapButton := ui.NewButton("Map "+action.Name, ui.NewLabel(ui.Label{
+			TextVariable: &text,
+			Font:         balance.CodeLiteralFont,
+		}))
+		mapButton.Resize(btnSize)
+		mapButton.Handle(ui.Click, func(ed ui.EventData) error {
+			remap(action, &text, false)
+			return nil
+		})
+		cfg.Supervisor.Add(mapButton)
+		row.Pack(mapButton, ui.Pack{
+			Side: ui.W,
+			PadX: 1,
+		})
+
+		// Button to add another button.
+		addButton := ui.NewButton("Add "+action.Name, ui.NewLabel(ui.Label{
+			Text: "+",
+			Font: balance.UIFont,
+		}))
+		addButton.Handle(ui.Click, func(ed ui.EventData) error {
+			remap(action, &text, true)
+			return nil
+		})
+		cfg.Supervisor.Add(addButton)
+		row.Pack(addButton, ui.Pack{
+			Side: ui.W,
+			PadX: 1,
+		})
+	}
+
+	for i, row := range rows {
+		if !rowsPage(i) {
+			row.Hide()
+		}
+		frame.Pack(row, ui.Pack{
+			Side:  ui.N,
+			FillX: true,
+			PadY:  1,
+		})
+	}
+
+	/******************
+	 * Pager and Reset button.
+	 ******************/
+
+	bottomFrame := ui.NewFrame("Button Frame")
+	frame.Pack(bottomFrame, ui.Pack{
+		Side:  ui.S,
+		FillX: true,
+	})
+
+	pager := ui.NewPager(ui.Pager{
+		Name:           "Gamepad Pager",
+		Page:           page,
+		Pages:          (len(rows) + perPage - 1) / perPage,
+		PerPage:        perPage,
+		MaxPageButtons: 6,
+		Font:           balance.MenuFont,
+		OnChange: func(newPage, perPage int) {
+			page = newPage
+			for i, row := range rows {
+				if rowsPage(i) {
+					row.Show()
+				} else {
+					row.Hide()
+				}
+			}
+		},
+	})
+	pager.Compute(cfg.Engine)
+	pager.Supervise(cfg.Supervisor)
+	bottomFrame.Pack(pager, ui.Pack{
+		Side: ui.W,
+		PadX: 4,
+	})
+
+	btnReset := ui.NewButton("Reset Buttons", ui.NewLabel(ui.Label{
+		Text: "Reset to Defaults",
+		Font: balance.MenuFont,
+	}))
+	btnReset.Handle(ui.Click, func(ed ui.EventData) error {
+		profile.Buttons = nil
+		save()
+		for _, f := range refresh {
+			f()
+		}
+		shmem.Flash("All buttons have been reset to their defaults.")
+		return nil
+	})
+	cfg.Supervisor.Add(btnReset)
+	bottomFrame.Pack(btnReset, ui.Pack{
+		Side: ui.E,
+		PadX: 4,
+	})
+
+	window.Hide()
+	return window
+}
@@ -0,0 +1,255 @@
+package windows
+
+import (
+	"fmt"
+	"math"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/drawtool"
+	"git.kirsle.net/go/render"
+	"git.kirsle.net/go/ui"
+)
+
+// History window lists the steps of the editor's undo history and lets you
+// jump to any of them.
+type History struct {
+	Supervisor *ui.Supervisor
+	Engine     render.Engine
+
+	// The undo history of the drawing being edited.
+	History *drawtool.History
+
+	// Callback functions.
+	OnJump   func(position int) // undo or redo until this many steps are applied
+	OnCancel func()             // Close button was clicked.
+}
+
+// NewHistoryWindow initializes the window.
+func NewHistoryWindow(config History) *ui.Window {
+	// Default options.
+	var (
+		title = "History"
+		rows  = []*ui.Frame{}
+		steps = config.History.Steps()
+
+		// The current step (the newest one applied), 1-indexed.
+		position = config.History.Position()
+
+		// size of the popup window
+		width  = 320
+		height = 360
+
+		// Column sizes of the history table.
+		col1 = 40  // Index
+		col2 = 180 // Step name
+		col3 = 60  // Status
+
+		// pagination values: start on the page with the current step
+		perPage = 8
+		page    = 1
+	)
+
+	if position > 0 {
+		page = (position-1)/perPage + 1
+	}
+
+	window := ui.NewWindow(title)
+	window.SetButtons(ui.CloseButton)
+	window.Configure(ui.Config{
+		Width:      width,
+		Height:     height,
+		Background: render.Grey,
+	})
+
+	frame := ui.NewFrame("Window Body Frame")
+	window.Pack(frame, ui.Pack{
+		Side:   ui.N,
+		Fill:   true,
+		Expand: true,
+	})
+
+	if len(steps) == 0 {
+		label := ui.NewLabel(ui.Label{
+			Text: "Nothing to undo yet.",
+			Font: balance.UIFont,
+		})
+		frame.Pack(label, ui.Pack{
+			Side: ui.N,
+			PadY: 8,
+		})
+	}
+
+	// Draw the rows for each step of the history.
+	for i, step := range steps {
+		var (
+			i      = i // rescope
+			number = i + 1
+			font   = balance.MenuFont
+			status = "Undone"
+		)
+		if step.Applied {
+			status = ""
+		}
+		if number == position {
+			font = balance.MenuFontBold
+			status = "Current"
+		}
+
+		row := ui.NewFrame(fmt.Sprintf("Step %d", number))
+		rows = append(rows, row)
+
+		// Not on the current page?
+		if i < (page-1)*perPage || i >= page*perPage {
+			row.Hide()
+		}
+
+		// Index label.
+		idLabel := ui.NewLabel(ui.Label{
+			Text: fmt.Sprintf("%d.", number),
+			Font: font,
+		})
+		idLabel.Configure(ui.Config{
+			Width:  col1,
+			Height: 24,
+		})
+
+		// Step button: click to undo or redo up to this step.
+		btnStep := ui.NewButton("Step", ui.NewLabel(ui.Label{
+			Text: step.Name,
+			Font: font,
+		}))
+		btnStep.Configure(ui.Config{
+			Width:  col2,
+			Height: 24,
+		})
+		btnStep.Handle(ui.Click, func(ed ui.EventData) error {
+			if config.OnJump != nil {
+				config.OnJump(number)
+			}
+			return nil
+		})
+		config.Supervisor.Add(btnStep)
+
+		// Status label.
+		statusLabel := ui.NewLabel(ui.Label{
+			Text: status,
+			Font: font,
+		})
+		statusLabel.Configure(ui.Config{
+			Width:  col3,
+			Height: 24,
+		})
+
+		// Pack all the widgets.
+		row.Pack(idLabel, ui.Pack{
+			Side: ui.W,
+			PadX: 2,
+		})
+		row.Pack(btnStep, ui.Pack{
+			Side: ui.W,
+			PadX: 2,
+		})
+		row.Pack(statusLabel, ui.Pack{
+			Side: ui.W,
+			PadX: 2,
+		})
+
+		row.Compute(config.Engine)
+		frame.Pack(row, ui.Pack{
+			Side: ui.N,
+			PadY: 2,
+		})
+	}
+
+	{
+		/******************
+		 * Confirm/cancel buttons.
+		 ******************/
+
+		bottomFrame := ui.NewFrame("Button Frame")
+		frame.Pack(bottomFrame, ui.Pack{
+			Side:  ui.S,
+			FillX: true,
+		})
+
+		// Pager for the steps.
+		pager := ui.NewPager(ui.Pager{
+			Name: "History Window Pager",
+			Page: page,
+			Pages: int(math.Ceil(
+				float64(len(rows)) / float64(perPage),
+			)),
+			PerPage:        perPage,
+			MaxPageButtons: 6,
+			Font:           balance.MenuFont,
+			OnChange: func(newPage, perPage int) {
+				page = newPage
+
+				// Re-evaluate which rows are shown/hidden for this page.
+				var (
+					minRow  = (page - 1) * perPage
+					visible = 0
+				)
+				for i, row := range rows {
+					if visible >= perPage {
+						row.Hide()
+						continue
+					}
+
+					if i < minRow {
+						row.Hide()
+					} else {
+						row.Show()
+						visible++
+					}
+				}
+			},
+		})
+		pager.Compute(config.Engine)
+		pager.Supervise(config.Supervisor)
+		bottomFrame.Place(pager, ui.Place{
+			Top:  20,
+			Left: 20,
+		})
+
+		btnFrame := ui.NewFrame("Window Buttons")
+		var buttons = []struct {
+			Label string
+			F     func(ui.EventData) error
+		}{
+			{"Undo All", func(ed ui.EventData) error {
+				if config.OnJump != nil {
+					config.OnJump(0)
+				}
+				return nil
+			}},
+			{"Close", func(ed ui.EventData) error {
+				if config.OnCancel != nil {
+					config.OnCancel()
+				}
+				return nil
+			}},
+		}
+		for _, t := range buttons {
+			btn := ui.NewButton(t.Label, ui.NewLabel(ui.Label{
+				Text: t.Label,
+				Font: balance.MenuFont,
+			}))
+			btn.Handle(ui.Click, t.F)
+			btn.Compute(config.Engine)
+			config.Supervisor.Add(btn)
+
+			btnFrame.Pack(btn, ui.Pack{
+				Side: ui.W,
+				PadX: 4,
+			})
+		}
+		bottomFrame.Place(btnFrame, ui.Place{
+			Top:    60,
+			Center: true,
+		})
+	}
+
+	window.Hide()
+	return window
+}
@@ -0,0 +1,532 @@
+package windows
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/enum"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/levelindex"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/savegame"
+	magicform "git.kirsle.net/SketchyMaze/doodle/pkg/uix/magic-form"
+	"git.kirsle.net/go/render"
+	"git.kirsle.net/go/ui"
+)
+
+// LevelBrowser window lists the user's levels with their thumbnails, and
+// can search, filter and sort them by their metadata.
+type LevelBrowser struct {
+	Supervisor *ui.Supervisor
+	Engine     render.Engine
+
+	// Callback functions.
+	OnOpenLevel   func(filename string)
+	OnCloseWindow func()
+
+	// Internal variables
+	window   *ui.Window
+	savegame *savegame.SaveGame
+	query    levelindex.Query
+	search   string
+	results  []*levelindex.Entry
+	page     int
+	pageText string
+	status   string
+	slots    []*levelBrowserSlot
+
+	// Set by the background scan of the level index when it finishes, for
+	// Loop to refresh the results on the main thread.
+	rescanned atomic.Bool
+
+	// Tag filter, which gains the new tags found by the background scan.
+	tagSelect *ui.SelectBox
+	tags      map[string]bool
+}
+
+// levelBrowserSlot is one of the result buttons on the current page,
+// reused to show a different level when the page or filters change.
+type levelBrowserSlot struct {
+	button *ui.Button
+	entry  *levelindex.Entry
+	title  string
+	byline string
+	detail string
+
+	// Thumbnails are loaded as they're first shown.
+	thumbFrame *ui.Frame
+	thumbs     map[string]*ui.Image
+	shown      *ui.Image
+}
+
+// Level browser sort, difficulty and completion filter choices.
+const anyDifficulty = -99
+
+var (
+	levelBrowserSortOptions = []magicform.Option{
+		{Label: "Title", Value: int(levelindex.SortTitle)},
+		{Label: "Author", Value: int(levelindex.SortAuthor)},
+		{Label: "Difficulty", Value: int(levelindex.SortDifficulty)},
+		{Label: "Last modified", Value: int(levelindex.SortModified)},
+	}
+	levelBrowserDifficultyOptions = []magicform.Option{
+		{Label: "Any", Value: anyDifficulty},
+		{Label: enum.Peaceful.String(), Value: int(enum.Peaceful)},
+		{Label: enum.Normal.String(), Value: int(enum.Normal)},
+		{Label: enum.Hard.String(), Value: int(enum.Hard)},
+	}
+	levelBrowserCompletionOptions = []magicform.Option{
+		{Label: "All levels", Value: int(levelindex.AnyCompletion)},
+		{Label: "Completed", Value: int(levelindex.Completed)},
+		{Label: "Not completed", Value: int(levelindex.NotCompleted)},
+	}
+)
+
+// NewLevelBrowserWindow initializes the window. The caller must run the
+// LevelBrowser's Loop on each tick while the window is open.
+func NewLevelBrowserWindow(c *LevelBrowser) *ui.Window {
+	var (
+		title = "Browse Levels"
+
+		// size of the popup window
+		width       = 640
+		height      = 460
+		filterWidth = 190
+
+		// grid of result buttons
+		columns      = 3
+		rows         = 2
+		buttonWidth  = (width-filterWidth)/columns - 8
+		buttonHeight = 150
+	)
+
+	// Load the user's savegame.json for the completion status.
+	sg, err := savegame.GetOrCreate()
+	if err != nil {
+		log.Warn("NewLevelBrowserWindow: didn't load savegame json (fresh struct created): %s", err)
+	}
+	c.savegame = sg
+	c.query.IsCompleted = func(e *levelindex.Entry) bool {
+		return c.savegame.IsCompleted(e.UUID, e.Filename)
+	}
+
+	window := ui.NewWindow(title)
+	window.SetButtons(ui.CloseButton)
+	window.Configure(ui.Config{
+		Width:      width,
+		Height:     height,
+		Background: render.Grey,
+	})
+	window.Handle(ui.CloseWindow, func(ed ui.EventData) error {
+		if c.OnCloseWindow != nil {
+			c.OnCloseWindow()
+		}
+		return nil
+	})
+	c.window = window
+
+	/////////////
+	// Filters panel on the left.
+	filterFrame := ui.NewFrame("Filters")
+	filterFrame.Resize(render.NewRect(filterWidth, height-60))
+	window.Pack(filterFrame, ui.Pack{
+		Side: ui.W,
+		PadX: 4,
+	})
+	c.setupFilters(filterFrame)
+
+	/////////////
+	// Results grid on the right.
+	resultsFrame := ui.NewFrame("Results")
+	window.Pack(resultsFrame, ui.Pack{
+		Side:   ui.W,
+		Fill:   true,
+		Expand: true,
+	})
+
+	statusLabel := ui.NewLabel(ui.Label{
+		TextVariable: &c.status,
+		Font:         balance.MenuFont,
+	})
+	resultsFrame.Pack(statusLabel, ui.Pack{
+		Side: ui.N,
+		PadY: 2,
+	})
+
+	for row := 0; row < rows; row++ {
+		rowFrame := ui.NewFrame(fmt.Sprintf("Row %d", row))
+		resultsFrame.Pack(rowFrame, ui.Pack{
+			Side: ui.N,
+			PadY: 2,
+		})
+
+		for col := 0; col < columns; col++ {
+			slot := c.makeSlot(buttonWidth, buttonHeight)
+			rowFrame.Pack(slot.button, ui.Pack{
+				Side: ui.W,
+				PadX: 2,
+			})
+			c.slots = append(c.slots, slot)
+		}
+	}
+
+	// Page buttons.
+	pageFrame := ui.NewFrame("Pages")
+	resultsFrame.Pack(pageFrame, ui.Pack{
+		Side: ui.N,
+		PadY: 4,
+	})
+	for _, btn := range []struct {
+		label string
+		delta int
+	}{
+		{"« Previous", -1},
+		{"Next »", 1},
+	} {
+		btn := btn
+		button := ui.NewButton(btn.label, ui.NewLabel(ui.Label{
+			Text: btn.label,
+			Font: balance.MenuFont,
+		}))
+		button.Handle(ui.Click, func(ed ui.EventData) error {
+			c.page += btn.delta
+			c.refresh()
+			return nil
+		})
+		c.Supervisor.Add(button)
+		pageFrame.Pack(button, ui.Pack{
+			Side: ui.W,
+			PadX: 4,
+		})
+
+		// The page number between the buttons.
+		if btn.delta < 0 {
+			pageFrame.Pack(ui.NewLabel(ui.Label{
+				TextVariable: &c.pageText,
+				Font:         balance.MenuFont,
+			}), ui.Pack{
+				Side: ui.W,
+				PadX: 4,
+			})
+		}
+	}
+
+	// Close button.
+	if c.OnCloseWindow != nil {
+		closeBtn := ui.NewButton("Close Window", ui.NewLabel(ui.Label{
+			Text: "Close",
+			Font: balance.MenuFont,
+		}))
+		closeBtn.Handle(ui.Click, func(ed ui.EventData) error {
+			c.OnCloseWindow()
+			return nil
+		})
+		c.Supervisor.Add(closeBtn)
+		window.Place(closeBtn, ui.Place{
+			Bottom: 15,
+			Center: true,
+		})
+	}
+
+	// Show the cached index now, and refresh it in the background.
+	c.refresh()
+	levelindex.ScanAsync(func() {
+		c.rescanned.Store(true)
+	})
+
+	window.Supervise(c.Supervisor)
+	window.Hide()
+	return window
+}
+
+// setupFilters creates the search and filter form.
+func (c *LevelBrowser) setupFilters(frame *ui.Frame) {
+	form := magicform.Form{
+		Supervisor: c.Supervisor,
+		Engine:     c.Engine,
+		Vertical:   true,
+		LabelWidth: 70,
+		PadY:       2,
+	}
+	form.Create(frame, []magicform.Field{
+		{
+			Label: "Find a Level",
+			Font:  balance.LabelFont,
+		},
+		{
+			Label:        "Search:",
+			Font:         balance.UIFont,
+			TextVariable: &c.search,
+			PromptUser: func(answer string) {
+				c.search = answer
+				c.query.Search = answer
+				c.page = 0
+				c.refresh()
+			},
+		},
+		{
+			Label:       "Sort by:",
+			Font:        balance.UIFont,
+			Type:        magicform.Selectbox,
+			Options:     levelBrowserSortOptions,
+			SelectValue: int(c.query.SortBy),
+			OnSelect: func(value interface{}) {
+				if v, ok := value.(int); ok {
+					c.query.SortBy = levelindex.SortBy(v)
+					c.refresh()
+				}
+			},
+		},
+		{
+			Label:       "Difficulty:",
+			Font:        balance.UIFont,
+			Type:        magicform.Selectbox,
+			Options:     levelBrowserDifficultyOptions,
+			SelectValue: anyDifficulty,
+			OnSelect: func(value interface{}) {
+				if v, ok := value.(int); ok {
+					if v == anyDifficulty {
+						c.query.Difficulty = nil
+					} else {
+						difficulty := enum.Difficulty(v)
+						c.query.Difficulty = &difficulty
+					}
+					c.page = 0
+					c.refresh()
+				}
+			},
+		},
+		{
+			Label:       "Status:",
+			Font:        balance.UIFont,
+			Type:        magicform.Selectbox,
+			Options:     levelBrowserCompletionOptions,
+			SelectValue: int(levelindex.AnyCompletion),
+			OnSelect: func(value interface{}) {
+				if v, ok := value.(int); ok {
+					c.query.Completion = levelindex.Completion(v)
+					c.page = 0
+					c.refresh()
+				}
+			},
+		},
+		{
+			Frame: c.makeTagFilter(),
+		},
+		{
+			Buttons: []magicform.Field{
+				{
+					Label: "Clear search",
+					Font:  balance.UIFont,
+					OnClick: func() {
+						c.search = ""
+						c.query.Search = ""
+						c.page = 0
+						c.refresh()
+					},
+				},
+			},
+		},
+	})
+}
+
+// makeTagFilter creates the Tag filter row of the form. Its select box isn't
+// made by the magicform so that mergeTags can add to it later.
+func (c *LevelBrowser) makeTagFilter() *ui.Frame {
+	frame := ui.NewFrame("Tag Filter")
+
+	labFrame := ui.NewFrame("Label Frame")
+	labFrame.Configure(ui.Config{
+		Width: 70,
+	})
+	frame.Pack(labFrame, ui.Pack{
+		Side: ui.W,
+	})
+	labFrame.Pack(ui.NewLabel(ui.Label{
+		Text: "Tag:",
+		Font: balance.UIFont,
+	}), ui.Pack{
+		Side: ui.W,
+	})
+
+	c.tagSelect = ui.NewSelectBox("Select", ui.Label{
+		Font: balance.UIFont,
+	})
+	frame.Pack(c.tagSelect, ui.Pack{
+		Side:   ui.W,
+		FillX:  true,
+		Expand: true,
+	})
+
+	c.tags = map[string]bool{}
+	c.tagSelect.AddItem("Any", "", func() {})
+	c.mergeTags()
+	c.tagSelect.SetValue("")
+
+	c.tagSelect.Handle(ui.Change, func(ed ui.EventData) error {
+		if selection, ok := c.tagSelect.GetValue(); ok {
+			if v, ok := selection.Value.(string); ok {
+				c.query.Tag = v
+				c.page = 0
+				c.refresh()
+			}
+		}
+		return nil
+	})
+
+	c.tagSelect.Supervise(c.Supervisor)
+	c.Supervisor.Add(c.tagSelect)
+	return frame
+}
+
+// mergeTags adds the tags of the level index that aren't in the Tag filter
+// yet, e.g. the ones found by the background scan.
+func (c *LevelBrowser) mergeTags() {
+	for _, tag := range levelindex.Tags(levelindex.Entries()) {
+		if c.tags[tag] {
+			continue
+		}
+		c.tags[tag] = true
+		c.tagSelect.AddItem(tag, tag, func() {})
+	}
+}
+
+// makeSlot creates a result button.
+func (c *LevelBrowser) makeSlot(width, height int) *levelBrowserSlot {
+	var slot = &levelBrowserSlot{
+		thumbs: map[string]*ui.Image{},
+	}
+
+	btnFrame := ui.NewFrame("Level")
+	btnFrame.Resize(render.NewRect(width, height))
+
+	for _, line := range []struct {
+		text *string
+		font render.Text
+	}{
+		{&slot.title, balance.LabelFont},
+		{&slot.byline, balance.MenuFont},
+		{&slot.detail, balance.MenuFont},
+	} {
+		btnFrame.Pack(ui.NewLabel(ui.Label{
+			TextVariable: line.text,
+			Font:         line.font,
+		}), ui.Pack{
+			Side: ui.NW,
+		})
+	}
+
+	slot.thumbFrame = ui.NewFrame("Thumbnail")
+	btnFrame.Pack(slot.thumbFrame, ui.Pack{
+		Side: ui.N,
+		PadY: 50, // below the labels
+	})
+
+	slot.button = ui.NewButton("Level", btnFrame)
+	slot.button.Handle(ui.Click, func(ed ui.EventData) error {
+		if slot.entry != nil && c.OnOpenLevel != nil {
+			c.OnOpenLevel(slot.entry.Filename)
+		}
+		return nil
+	})
+	c.Supervisor.Add(slot.button)
+
+	return slot
+}
+
+// Loop refreshes the results when the background scan of the level index has
+// finished. The scan runs on its own goroutine, but the widgets and their
+// textures must only be updated from the main loop.
+func (c *LevelBrowser) Loop() {
+	if c.rescanned.Swap(false) {
+		c.mergeTags()
+		c.refresh()
+	}
+}
+
+// refresh runs the query against the level index and shows the current page
+// of results.
+func (c *LevelBrowser) refresh() {
+	var all = levelindex.Entries()
+	c.results = c.query.Filter(all)
+
+	// Keep the page in range.
+	var (
+		perPage = len(c.slots)
+		pages   = (len(c.results) + perPage - 1) / perPage
+	)
+	if c.page >= pages {
+		c.page = pages - 1
+	}
+	if c.page < 0 {
+		c.page = 0
+	}
+	c.pageText = fmt.Sprintf("Page %d of %d", c.page+1, pages)
+	if pages == 0 {
+		c.pageText = "Page 0 of 0"
+	}
+
+	c.status = fmt.Sprintf("Showing %d of %d levels", len(c.results), len(all))
+	if levelindex.Scanning() {
+		c.status += " (updating...)"
+	}
+
+	for i, slot := range c.slots {
+		var index = c.page*perPage + i
+		if index < len(c.results) {
+			c.showSlot(slot, c.results[index])
+			slot.button.Show()
+		} else {
+			slot.entry = nil
+			slot.button.Hide()
+		}
+	}
+}
+
+// showSlot fills a result button with a level's details.
+func (c *LevelBrowser) showSlot(slot *levelBrowserSlot, entry *levelindex.Entry) {
+	slot.entry = entry
+	slot.title = entry.Title
+	slot.byline = "by " + entry.Author
+
+	slot.detail = entry.Difficulty.String()
+	if c.query.IsCompleted(entry) {
+		slot.detail += " - ✓ Completed"
+	}
+	if !entry.ModTime.IsZero() {
+		slot.detail += "\n" + entry.ModTime.Format("Jan 2, 2006")
+	}
+
+	// Swap the thumbnail.
+	if slot.shown != nil {
+		slot.shown.Hide()
+		slot.shown = nil
+	}
+	if !entry.HasThumb {
+		return
+	}
+
+	var key = entry.Filename + entry.ModTime.String()
+	img, ok := slot.thumbs[key]
+	if !ok {
+		thumb, err := entry.Thumbnail()
+		if err != nil {
+			log.Error("LevelBrowser: thumbnail for %s: %s", entry.Filename, err)
+			return
+		}
+
+		img, err = ui.ImageFromImage(thumb)
+		if err != nil {
+			log.Error("LevelBrowser: thumbnail for %s: %s", entry.Filename, err)
+			return
+		}
+
+		slot.thumbs[key] = img
+		slot.thumbFrame.Pack(img, ui.Pack{
+			Side: ui.N,
+		})
+	}
+
+	img.Show()
+	slot.shown = img
+}
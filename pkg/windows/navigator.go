@@ -0,0 +1,80 @@
+package windows
+
+import (
+	"git.kirsle.net/SketchyMaze/doodle/pkg/uix"
+	"git.kirsle.net/go/render"
+	"git.kirsle.net/go/render/event"
+	"git.kirsle.net/go/ui"
+)
+
+// Navigator window shows an overview of the level being edited.
+type Navigator struct {
+	// Settings passed in by doodle
+	Supervisor *ui.Supervisor
+	Engine     render.Engine
+	Canvas     *uix.Canvas // the editor canvas
+	Event      *event.State
+
+	// Or sensible defaults:
+	Width  int
+	Height int
+}
+
+// MakeNavigatorWindow initializes the Navigator window in the top right
+// corner of the screen. The window width/height are the actual SDL2 window
+// dimensions.
+func MakeNavigatorWindow(windowWidth, windowHeight int, cfg Navigator) *ui.Window {
+	win := NewNavigatorWindow(cfg)
+	win.Compute(cfg.Engine)
+	win.Supervise(cfg.Supervisor)
+
+	size := win.Size()
+	win.MoveTo(render.Point{
+		X: windowWidth - size.W - 16,
+		Y: 64,
+	})
+
+	return win
+}
+
+// NewNavigatorWindow initializes the window.
+func NewNavigatorWindow(cfg Navigator) *ui.Window {
+	var (
+		windowWidth  = 240
+		windowHeight = 200
+	)
+
+	if cfg.Width+cfg.Height > 0 {
+		windowWidth = cfg.Width
+		windowHeight = cfg.Height
+	}
+
+	window := ui.NewWindow("Navigator")
+	window.SetButtons(ui.CloseButton)
+	window.Configure(ui.Config{
+		Width:  windowWidth,
+		Height: windowHeight,
+	})
+
+	nav := uix.NewNavigator(cfg.Canvas, render.NewRect(
+		windowWidth-8,
+		windowHeight-4-24, // for the titlebar
+	))
+
+	// NOTE: my UI toolkit calls this every tick while the mouse is over the
+	// window, like the PiP window does for its canvas.
+	window.Handle(ui.MouseMove, func(ed ui.EventData) error {
+		nav.Loop(cfg.Event)
+		return nil
+	})
+	window.Handle(ui.CloseWindow, func(ed ui.EventData) error {
+		nav.Teardown()
+		return nil
+	})
+
+	window.Pack(nav, ui.Pack{
+		Side: ui.N,
+	})
+
+	return window
+}
@@ -31,6 +31,11 @@ type PaletteEditor struct {
 	OnChange   func()
 	OnAddColor func()
 	OnCancel   func()
+
+	// Replace the pixels of one swatch with another. The scope is blank for
+	// the whole drawing, or the name of a level region, or "selection" to
+	// drag out the area with the Recolor Tool.
+	OnReplaceSwatch func(from, to *level.Swatch, scope string)
 }
 
 // NewPaletteEditor initializes the window.
@@ -440,6 +445,13 @@ func NewPaletteEditor(config PaletteEditor) *ui.Window {
 				}
 				return nil
 			}},
+			{"Replace Color", func(ed ui.EventData) error {
+				if config.OnReplaceSwatch == nil || config.EditPalette == nil {
+					return nil
+				}
+				promptReplaceSwatch(config.EditPalette, config.IsDoodad, config.OnReplaceSwatch)
+				return nil
+			}},
 			{"Close", func(ed ui.EventData) error {
 				if config.OnCancel != nil {
 					config.OnCancel()
@@ -471,6 +483,35 @@ func NewPaletteEditor(config PaletteEditor) *ui.Window {
 	return window
 }
 
+// Helper function for the Replace Color button: prompt for the names of the
+// swatches to replace and where, then call the handler.
+func promptReplaceSwatch(pal *level.Palette, isDoodad bool, handler func(from, to *level.Swatch, scope string)) {
+	shmem.Prompt("Replace which color? (name): ", func(fromName string) {
+		from, ok := pal.Get(fromName)
+		if !ok {
+			shmem.FlashError("No color named '%s' in the palette.", fromName)
+			return
+		}
+
+		shmem.Prompt("Replace '"+from.Name+"' with which color? (name): ", func(toName string) {
+			to, ok := pal.Get(toName)
+			if !ok {
+				shmem.FlashError("No color named '%s' in the palette.", toName)
+				return
+			}
+
+			// Doodads have no regions: the whole layer or a selection.
+			var question = "Where? (blank = whole level, a region name, or 'selection'): "
+			if isDoodad {
+				question = "Where? (blank = whole layer, or 'selection'): "
+			}
+			shmem.Prompt(question, func(scope string) {
+				handler(from, to, scope)
+			})
+		})
+	})
+}
+
 // Helper function to get the Tex (pattern) select box to
 // show the image by its filename... for both onChange and
 // initial render needs.
@@ -0,0 +1,267 @@
+package windows
+
+import (
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/modal"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/savegame"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
+	"git.kirsle.net/go/render"
+	"git.kirsle.net/go/ui"
+)
+
+// Profiles window to create, rename, delete and switch the save profiles.
+type Profiles struct {
+	// Settings passed in by doodle
+	Supervisor *ui.Supervisor
+	Engine     render.Engine
+
+	// OnSwitch is called when the active profile or its settings have
+	// changed, so the caller can refresh what shows the player's progress.
+	OnSwitch func()
+
+	// OnChange is called when the window needs to be made again to show the
+	// changed profiles.
+	OnChange func()
+}
+
+// MakeProfilesWindow initializes the window and centers it on screen.
+func MakeProfilesWindow(cfg Profiles) *ui.Window {
+	win := NewProfilesWindow(cfg)
+	win.Compute(cfg.Engine)
+	win.Supervise(cfg.Supervisor)
+
+	// Center the window.
+	var (
+		w, h = shmem.CurrentRenderEngine.WindowSize()
+		size = win.Size()
+	)
+	win.MoveTo(render.Point{
+		X: (w / 2) - (size.W / 2),
+		Y: (h / 2) - (size.H / 2),
+	})
+
+	return win
+}
+
+// NewProfilesWindow initializes the window.
+func NewProfilesWindow(cfg Profiles) *ui.Window {
+	var (
+		Width     = 400
+		rowHeight = 24
+		nameSize  = render.NewRect(200, rowHeight)
+	)
+
+	profiles, err := savegame.LoadProfiles()
+	if err != nil {
+		log.Error("NewProfilesWindow: %s", err)
+		profiles = &savegame.Profiles{}
+	}
+
+	window := ui.NewWindow("Save Profiles")
+	window.SetButtons(ui.CloseButton)
+	window.Configure(ui.Config{
+		Width:      Width,
+		Height:     150 + len(profiles.Profiles)*(rowHeight+4),
+		Background: render.Grey,
+	})
+
+	frame := ui.NewFrame("Window Body Frame")
+	window.Pack(frame, ui.Pack{
+		Side:   ui.N,
+		Fill:   true,
+		Expand: true,
+	})
+
+	intro := ui.NewLabel(ui.Label{
+		Text: "Each player has their own high scores and Story Mode progress.",
+		Font: balance.UIFont,
+	})
+	frame.Pack(intro, ui.Pack{
+		Side: ui.N,
+		PadY: 4,
+	})
+
+	if len(profiles.Profiles) == 0 {
+		window.Hide()
+		return window
+	}
+
+	// Save the changed profiles, and re-apply the settings of the active one.
+	var commit = func(switched bool) {
+		if err := profiles.Save(); err != nil {
+			modal.Alert("Couldn't save the profiles: %s", err).WithTitle("Save Profiles")
+			return
+		}
+		if switched {
+			if err := profiles.ApplySettings(); err != nil {
+				log.Error("Couldn't apply the profile settings: %s", err)
+			}
+			if cfg.OnSwitch != nil {
+				cfg.OnSwitch()
+			}
+		}
+		if cfg.OnChange != nil {
+			cfg.OnChange()
+		}
+	}
+
+	/******************
+	 * One row per profile.
+	 ******************/
+
+	var (
+		current = profiles.Current()
+		active  = current.ID // radio button variable
+	)
+	for _, profile := range profiles.Profiles {
+		var profile = profile // rescope
+
+		row := ui.NewFrame("Profile " + profile.ID)
+		frame.Pack(row, ui.Pack{
+			Side:  ui.N,
+			FillX: true,
+			PadY:  2,
+		})
+
+		// Radio button to play as this profile.
+		btnSwitch := ui.NewRadioButton("Play As", &active, profile.ID, ui.NewLabel(ui.Label{
+			Text: profile.Name,
+			Font: balance.UIFont,
+		}))
+		btnSwitch.Resize(nameSize)
+		btnSwitch.Handle(ui.Click, func(ed ui.EventData) error {
+			if profile.ID == current.ID {
+				return nil
+			}
+			if err := profiles.Switch(profile.ID); err != nil {
+				shmem.FlashError(err.Error())
+				return nil
+			}
+			shmem.Flash("Now playing as %s.", profile.Name)
+			commit(true)
+			return nil
+		})
+		cfg.Supervisor.Add(btnSwitch)
+		row.Pack(btnSwitch, ui.Pack{
+			Side: ui.W,
+			PadX: 4,
+		})
+
+		// Delete button.
+		btnDelete := ui.NewButton("Delete", ui.NewLabel(ui.Label{
+			Text: "Delete",
+			Font: balance.MenuFont,
+		}))
+		btnDelete.Handle(ui.Click, func(ed ui.EventData) error {
+			modal.Confirm(
+				"Delete the profile '%s' with all of its high scores\nand Story Mode progress?",
+				profile.Name,
+			).WithTitle("Delete Profile").Then(func() {
+				if err := profiles.Delete(profile.ID); err != nil {
+					modal.Alert("%s", err).WithTitle("Delete Profile")
+					return
+				}
+				commit(profile.ID == current.ID)
+			})
+			return nil
+		})
+		cfg.Supervisor.Add(btnDelete)
+		row.Pack(btnDelete, ui.Pack{
+			Side: ui.E,
+			PadX: 4,
+		})
+
+		// Rename button.
+		btnRename := ui.NewButton("Rename", ui.NewLabel(ui.Label{
+			Text: "Rename",
+			Font: balance.MenuFont,
+		}))
+		btnRename.Handle(ui.Click, func(ed ui.EventData) error {
+			shmem.Prompt("New name for "+profile.Name+": ", func(answer string) {
+				if answer == "" {
+					return
+				}
+				if err := profiles.Rename(profile.ID, answer); err != nil {
+					shmem.FlashError("Couldn't rename the profile: %s", err)
+					return
+				}
+				commit(false)
+			})
+			return nil
+		})
+		cfg.Supervisor.Add(btnRename)
+		row.Pack(btnRename, ui.Pack{
+			Side: ui.E,
+			PadX: 4,
+		})
+	}
+
+	/******************
+	 * Settings of the active profile.
+	 ******************/
+
+	var ownSettings = current.Settings != nil
+	cbSettings := ui.NewCheckbox("Own Settings", &ownSettings, ui.NewLabel(ui.Label{
+		Text: "Keep its own controls, crosshair and audio settings",
+		Font: balance.UIFont,
+	}))
+	cbSettings.Handle(ui.Click, func(ed ui.EventData) error {
+		if err := profiles.SetOwnSettings(current.ID, ownSettings); err != nil {
+			shmem.FlashError("Couldn't change the profile settings: %s", err)
+			return nil
+		}
+		if ownSettings {
+			shmem.Flash("%s now has their own settings.", current.Name)
+		} else {
+			shmem.Flash("%s now uses the global settings.", current.Name)
+		}
+		commit(true)
+		return nil
+	})
+	cbSettings.Supervise(cfg.Supervisor)
+	frame.Pack(cbSettings, ui.Pack{
+		Side: ui.N,
+		PadY: 8,
+	})
+
+	/******************
+	 * New profile button.
+	 ******************/
+
+	bottomFrame := ui.NewFrame("Button Frame")
+	frame.Pack(bottomFrame, ui.Pack{
+		Side:  ui.S,
+		FillX: true,
+		PadY:  4,
+	})
+
+	btnNew := ui.NewButton("New Profile", ui.NewLabel(ui.Label{
+		Text: "New Profile",
+		Font: balance.MenuFont,
+	}))
+	btnNew.SetStyle(&balance.ButtonPrimary)
+	btnNew.Handle(ui.Click, func(ed ui.EventData) error {
+		shmem.Prompt("Name of the new profile: ", func(answer string) {
+			if answer == "" {
+				return
+			}
+			profile, err := profiles.Create(answer)
+			if err != nil {
+				shmem.FlashError("Couldn't create the profile: %s", err)
+				return
+			}
+			shmem.Flash("Created the profile %s.", profile.Name)
+			commit(false)
+		})
+		return nil
+	})
+	cfg.Supervisor.Add(btnNew)
+	bottomFrame.Pack(btnNew, ui.Pack{
+		Side: ui.W,
+		PadX: 4,
+	})
+
+	window.Hide()
+	return window
+}
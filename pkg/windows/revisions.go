@@ -0,0 +1,251 @@
+package windows
+
+import (
+	"fmt"
+	"math"
+
+	"git.kirsle.net/SketchyMaze/doodle/pkg/autosave"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
+	"git.kirsle.net/go/render"
+	"git.kirsle.net/go/ui"
+)
+
+// Revisions window lists the autosave snapshots of the drawing being edited,
+// to open or compare them with the current drawing.
+type Revisions struct {
+	Supervisor *ui.Supervisor
+	Engine     render.Engine
+
+	// The drawing and its snapshots, newest first.
+	Drawing   string
+	Snapshots []autosave.Snapshot
+
+	// Callback functions.
+	OnOpen     func(autosave.Snapshot) // Open button of a snapshot
+	OnDiff     func(autosave.Snapshot) // Diff button of a snapshot
+	OnAutoSave func()                  // Take a snapshot now
+	OnCancel   func()                  // Close button was clicked.
+}
+
+// NewRevisionsWindow initializes the window.
+func NewRevisionsWindow(config Revisions) *ui.Window {
+	// Default options.
+	var (
+		title = "Revisions: " + config.Drawing
+		rows  = []*ui.Frame{}
+
+		// size of the popup window
+		width  = 360
+		height = 360
+
+		// Column sizes of the revisions table.
+		col1 = 40  // Index
+		col2 = 170 // Time
+		col3 = 50  // Open button
+		col4 = 50  // Diff button
+
+		// pagination values
+		perPage = 8
+		page    = 1
+	)
+
+	window := ui.NewWindow(title)
+	window.SetButtons(ui.CloseButton)
+	window.Configure(ui.Config{
+		Width:      width,
+		Height:     height,
+		Background: render.Grey,
+	})
+
+	frame := ui.NewFrame("Window Body Frame")
+	window.Pack(frame, ui.Pack{
+		Side:   ui.N,
+		Fill:   true,
+		Expand: true,
+	})
+
+	if len(config.Snapshots) == 0 {
+		label := ui.NewLabel(ui.Label{
+			Text: "No autosaves of this drawing yet.",
+			Font: balance.UIFont,
+		})
+		frame.Pack(label, ui.Pack{
+			Side: ui.N,
+			PadY: 8,
+		})
+	}
+
+	// Draw the rows for each snapshot.
+	for i, snap := range config.Snapshots {
+		var (
+			snap   = snap // rescope
+			number = i + 1
+		)
+
+		row := ui.NewFrame(fmt.Sprintf("Revision %d", number))
+		rows = append(rows, row)
+
+		// Not on the first page?
+		if i >= perPage {
+			row.Hide()
+		}
+
+		// Index label.
+		idLabel := ui.NewLabel(ui.Label{
+			Text: fmt.Sprintf("%d.", number),
+			Font: balance.MenuFont,
+		})
+		idLabel.Configure(ui.Config{
+			Width:  col1,
+			Height: 24,
+		})
+
+		// Time label.
+		timeLabel := ui.NewLabel(ui.Label{
+			Text: snap.Time.Format("Jan 2 15:04:05"),
+			Font: balance.MenuFont,
+		})
+		timeLabel.Configure(ui.Config{
+			Width:  col2,
+			Height: 24,
+		})
+
+		row.Pack(idLabel, ui.Pack{
+			Side: ui.W,
+			PadX: 2,
+		})
+		row.Pack(timeLabel, ui.Pack{
+			Side: ui.W,
+			PadX: 2,
+		})
+
+		// Open and Diff buttons.
+		var buttons = []struct {
+			Label string
+			Width int
+			F     func(autosave.Snapshot)
+		}{
+			{"Open", col3, config.OnOpen},
+			{"Diff", col4, config.OnDiff},
+		}
+		for _, t := range buttons {
+			t := t
+			btn := ui.NewButton(t.Label, ui.NewLabel(ui.Label{
+				Text: t.Label,
+				Font: balance.MenuFont,
+			}))
+			btn.Configure(ui.Config{
+				Width: t.Width,
+			})
+			btn.Handle(ui.Click, func(ed ui.EventData) error {
+				if t.F != nil {
+					t.F(snap)
+				}
+				return nil
+			})
+			config.Supervisor.Add(btn)
+			row.Pack(btn, ui.Pack{
+				Side: ui.W,
+				PadX: 2,
+			})
+		}
+
+		row.Compute(config.Engine)
+		frame.Pack(row, ui.Pack{
+			Side: ui.N,
+			PadY: 2,
+		})
+	}
+
+	{
+		/******************
+		 * Confirm/cancel buttons.
+		 ******************/
+
+		bottomFrame := ui.NewFrame("Button Frame")
+		frame.Pack(bottomFrame, ui.Pack{
+			Side:  ui.S,
+			FillX: true,
+		})
+
+		// Pager for the snapshots.
+		pager := ui.NewPager(ui.Pager{
+			Name: "Revisions Window Pager",
+			Page: page,
+			Pages: int(math.Ceil(
+				float64(len(rows)) / float64(perPage),
+			)),
+			PerPage:        perPage,
+			MaxPageButtons: 6,
+			Font:           balance.MenuFont,
+			OnChange: func(newPage, perPage int) {
+				page = newPage
+
+				// Re-evaluate which rows are shown/hidden for this page.
+				var (
+					minRow  = (page - 1) * perPage
+					visible = 0
+				)
+				for i, row := range rows {
+					if visible >= perPage {
+						row.Hide()
+						continue
+					}
+
+					if i < minRow {
+						row.Hide()
+					} else {
+						row.Show()
+						visible++
+					}
+				}
+			},
+		})
+		pager.Compute(config.Engine)
+		pager.Supervise(config.Supervisor)
+		bottomFrame.Place(pager, ui.Place{
+			Top:  20,
+			Left: 20,
+		})
+
+		btnFrame := ui.NewFrame("Window Buttons")
+		var buttons = []struct {
+			Label string
+			F     func(ui.EventData) error
+		}{
+			{"Autosave Now", func(ed ui.EventData) error {
+				if config.OnAutoSave != nil {
+					config.OnAutoSave()
+				}
+				return nil
+			}},
+			{"Close", func(ed ui.EventData) error {
+				if config.OnCancel != nil {
+					config.OnCancel()
+				}
+				return nil
+			}},
+		}
+		for _, t := range buttons {
+			btn := ui.NewButton(t.Label, ui.NewLabel(ui.Label{
+				Text: t.Label,
+				Font: balance.MenuFont,
+			}))
+			btn.Handle(ui.Click, t.F)
+			btn.Compute(config.Engine)
+			config.Supervisor.Add(btn)
+
+			btnFrame.Pack(btn, ui.Pack{
+				Side: ui.W,
+				PadX: 4,
+			})
+		}
+		bottomFrame.Place(btnFrame, ui.Place{
+			Top:    60,
+			Center: true,
+		})
+	}
+
+	window.Hide()
+	return window
+}
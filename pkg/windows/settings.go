@@ -1,13 +1,16 @@
 package windows
 
 import (
+	"fmt"
 	"strings"
 
 	"git.kirsle.net/SketchyMaze/doodle/pkg/balance"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/gamepad"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/keybind"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/log"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/native"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/shmem"
+	"git.kirsle.net/SketchyMaze/doodle/pkg/sound"
 	magicform "git.kirsle.net/SketchyMaze/doodle/pkg/uix/magic-form"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/usercfg"
 	"git.kirsle.net/SketchyMaze/doodle/pkg/userdir"
@@ -30,7 +33,12 @@ type Settings struct {
 	CrosshairColor     *render.Color
 	HideTouchHints     *bool
 	DisableAutosave    *bool
+	UndoHistory        *int
+	Keymap             *usercfg.Keymap
 	ControllerStyle    *int
+	MusicVolume        *int
+	SoundVolume        *int
+	MuteAudio          *bool
 
 	// Configuration options.
 	SceneName          string // name of scene which called this window
@@ -38,8 +46,10 @@ type Settings struct {
 	OnApply            func()
 	OnOpenCheatsWindow func() *ui.Window // user opens the Cheats Menu
 
-	// The Settings window owns the Cheats window to ensure only one opens at a time.
-	cheatsWindow *ui.Window
+	// The Settings window owns the Cheats and Gamepad windows to ensure only
+	// one of each opens at a time.
+	cheatsWindow  *ui.Window
+	gamepadWindow *ui.Window
 }
 
 // MakeSettingsWindow initializes a settings window for any scene.
@@ -87,6 +97,7 @@ func NewSettingsWindow(cfg Settings) *ui.Window {
 	cfg.makeOptionsTab(tabFrame, Width, Height)
 	cfg.makeControlsTab(tabFrame, Width, Height)
 	cfg.makeControllerTab(tabFrame, Width, Height)
+	cfg.makeAudioTab(tabFrame, Width, Height)
 	cfg.makeExperimentalTab(tabFrame, Width, Height)
 
 	tabFrame.Supervise(cfg.Supervisor)
@@ -115,6 +126,9 @@ func (c Settings) makeOptionsTab(tabFrame *ui.TabFrame, Width, Height int) *ui.F
 	// so we can write the updated info to disk.
 	onClick := func() {
 		saveGameSettings()
+		if c.OnApply != nil {
+			c.OnApply()
+		}
 	}
 
 	// The CrosshairSize is ideally a 0-100 (percent) how big the editor
@@ -122,6 +136,15 @@ func (c Settings) makeOptionsTab(tabFrame *ui.TabFrame, Width, Height int) *ui.F
 	// this as a checkbox for now.
 	var crosshairEnabled = *c.CrosshairSize > 0
 
+	// Sizes for the editor's undo history.
+	var undoHistory []magicform.Option
+	for _, size := range []int{balance.UndoHistory, 50, 100, 250, 500} {
+		undoHistory = append(undoHistory, magicform.Option{
+			Label: fmt.Sprintf("%d", size),
+			Value: size,
+		})
+	}
+
 	form := magicform.Form{
 		Supervisor: c.Supervisor,
 		Engine:     c.Engine,
@@ -160,6 +183,21 @@ func (c Settings) makeOptionsTab(tabFrame *ui.TabFrame, Width, Height int) *ui.F
 			BoolVariable: c.DisableAutosave,
 			OnClick:      onClick,
 		},
+		{
+			Label:       "Undo history steps:",
+			Font:        balance.UIFont,
+			Type:        magicform.Selectbox,
+			Options:     undoHistory,
+			SelectValue: balance.UndoHistoryDepth(),
+			Tooltip: ui.Tooltip{
+				Text: "The oldest steps are forgotten if the history is already bigger.",
+				Edge: ui.Top,
+			},
+			OnSelect: func(v interface{}) {
+				*c.UndoHistory, _ = v.(int)
+				onClick()
+			},
+		},
 		{
 			Label:        "Draw a crosshair at the mouse cursor.",
 			Font:         balance.UIFont,
@@ -221,189 +259,203 @@ func (c Settings) makeControlsTab(tabFrame *ui.TabFrame, Width, Height int) *ui.
 	frame.Resize(render.NewRect(Width-4, Height-frame.Size().H-46))
 
 	var (
-		halfWidth        = (Width - 4) / 2 // the 4 is for window borders, TODO
-		shortcutTabWidth = float64(halfWidth) * 0.5
-		infoTabWidth     = float64(halfWidth) * 0.5
-		rowHeight        = 20
-
-		shortcutTabSize = render.NewRect(int(shortcutTabWidth), rowHeight)
-		infoTabSize     = render.NewRect(int(infoTabWidth), rowHeight)
+		keymap    = c.Keymap
+		rowHeight = 20
+		labelSize = render.NewRect(140, rowHeight)
+		keySize   = render.NewRect(180, rowHeight)
+
+		rows     = []*ui.Frame{}
+		refresh  = []func(){} // update the key labels after a reset
+		context  = usercfg.KeyContext(-1)
+		perPage  = 9
+		page     = 1
+		rowsPage = func(i int) bool {
+			return i >= (page-1)*perPage && i < page*perPage
+		}
 	)
+	if keymap == nil {
+		keymap = &usercfg.Current.Keymap
+	}
 
-	controls := []struct {
-		Header   string
-		Label    string
-		Shortcut string
-	}{
-		{
-			Header: "Universal Shortcut Keys",
-		},
-		{
-			Shortcut: "Escape",
-			Label:    "Exit game",
-		},
-		{
-			Shortcut: "F1",
-			Label:    "Guidebook",
-		},
-		{
-			Shortcut: "`",
-			Label:    "Dev console",
-		},
-		{
-			Header: "Gameplay Controls (Play Mode)",
-		},
-		{
-			Shortcut: "Up or W",
-			Label:    "Jump",
-		},
-		{
-			Shortcut: "Space",
-			Label:    "Activate",
-		},
-		{
-			Shortcut: "Left or A",
-			Label:    "Move left",
-		},
-		{
-			Shortcut: "Right or D",
-			Label:    "Move right",
-		},
-		{
-			Header: "Level Editor Shortcuts",
-		},
-		{
-			Shortcut: "Ctrl-N",
-			Label:    "New level",
-		},
-		{
-			Shortcut: "Ctrl-O",
-			Label:    "Open drawing",
-		},
-		{
-			Shortcut: "Ctrl-S",
-			Label:    "Save drawing",
-		},
-		{
-			Shortcut: "Shift-Ctrl-S",
-			Label:    "Save a copy",
-		},
-		{
-			Shortcut: "Ctrl-Z",
-			Label:    "Undo stroke",
-		},
-		{
-			Shortcut: "Ctrl-Y",
-			Label:    "Redo stroke",
-		},
-		{
-			Shortcut: "P",
-			Label:    "Playtest",
-		},
-		{
-			Shortcut: "0",
-			Label:    "Scroll to origin",
-		},
-		{
-			Shortcut: "q",
-			Label:    "Doodads",
-		},
-		{
-			Shortcut: "f",
-			Label:    "Pencil Tool",
-		},
-		{
-			Shortcut: "l",
-			Label:    "Line Tool",
-		},
-		{
-			Shortcut: "r",
-			Label:    "Rectangle Tool",
-		},
-		{
-			Shortcut: "c",
-			Label:    "Ellipse Tool",
-		},
-		{
-			Shortcut: "x",
-			Label:    "Eraser Tool",
-		},
+	// Text for the keys bound to an action.
+	shortcut := func(action string) string {
+		return strings.Join(keymap.Get(action), " or ")
 	}
-	var curFrame = ui.NewFrame("Frame")
-	frame.Pack(curFrame, ui.Pack{
-		Side:  ui.N,
-		FillX: true,
-	})
-	var i = -1 // manually controlled
-	for _, row := range controls {
-		i++
-		row := row
-
-		if row.Header != "" {
-			// Close out a previous Frame?
-			if i != 0 {
-				curFrame = ui.NewFrame("Header Row")
-				frame.Pack(curFrame, ui.Pack{
-					Side:  ui.N,
-					FillX: true,
-				})
+
+	// Capture a key to bind to an action: replacing its keys, or adding
+	// another key to them.
+	var cancelPending func() // restores the button of a pending capture
+	rebind := func(action usercfg.KeyAction, text *string, add bool) {
+		if cancelPending != nil {
+			cancelPending()
+		}
+		cancelPending = func() {
+			*text = shortcut(action.Name)
+		}
+
+		*text = "Press a key..."
+		keybind.Capture(func(key string) {
+			cancelPending = nil
+			defer func() {
+				*text = shortcut(action.Name)
+			}()
+			if key == "" {
+				return
+			}
+
+			if conflicts := keymap.Conflicts(action.Name, key); len(conflicts) > 0 {
+				var labels = []string{}
+				for _, name := range conflicts {
+					if other, ok := usercfg.GetKeyAction(name); ok {
+						labels = append(labels, other.Label)
+					}
+				}
+				shmem.FlashError("%s is already bound to: %s", key, strings.Join(labels, ", "))
+				return
+			}
+
+			if add {
+				keymap.Add(action.Name, key)
+			} else {
+				keymap.Set(action.Name, []string{key})
 			}
+			saveGameSettings()
+		})
+	}
 
+	for _, action := range usercfg.KeyActions {
+		var action = action // rescope
+
+		// Header row for the next context of actions.
+		if action.Context != context {
+			context = action.Context
+
+			row := ui.NewFrame("Header Row")
 			label := ui.NewLabel(ui.Label{
-				Text: row.Header,
+				Text: context.String(),
 				Font: balance.LabelFont,
 			})
-			curFrame.Pack(label, ui.Pack{
+			row.Pack(label, ui.Pack{
 				Side: ui.W,
 			})
-
-			// Set up the next series of shortcut keys.
-			i = -1
-			curFrame = ui.NewFrame("Frame")
-			frame.Pack(curFrame, ui.Pack{
-				Side:  ui.N,
-				FillX: true,
-			})
-			continue
+			rows = append(rows, row)
 		}
 
-		// Cut a new frame every 2 items.
-		if i > 0 && i%2 == 0 {
-			curFrame = ui.NewFrame("Frame")
-			frame.Pack(curFrame, ui.Pack{
-				Side:  ui.N,
-				FillX: true,
-				PadY:  1,
-			})
-		}
+		row := ui.NewFrame("Key Row " + action.Name)
+		rows = append(rows, row)
 
-		keyLabel := ui.NewLabel(ui.Label{
-			Text: row.Shortcut,
-			Font: balance.CodeLiteralFont,
+		helpLabel := ui.NewLabel(ui.Label{
+			Text: action.Label,
+			Font: balance.UIFont,
 		})
-		keyLabel.Configure(ui.Config{
-			Background:  render.RGBA(255, 255, 220, 255),
-			BorderSize:  1,
-			BorderStyle: ui.BorderSunken,
-			BorderColor: render.DarkGrey,
+		helpLabel.Resize(labelSize)
+		row.Pack(helpLabel, ui.Pack{
+			Side: ui.W,
+			PadX: 1,
 		})
-		keyLabel.Resize(shortcutTabSize)
-		curFrame.Pack(keyLabel, ui.Pack{
+
+		// Button showing the bound keys: click to rebind.
+		var text = shortcut(action.Name)
+		refresh = append(refresh, func() {
+			text = shortcut(action.Name)
+		})
+
+		keyButton := ui.NewButton("Key "+action.Name, ui.NewLabel(ui.Label{
+			TextVariable: &text,
+			Font:         balance.CodeLiteralFont,
+		}))
+		keyButton.Resize(keySize)
+		keyButton.Handle(ui.Click, func(ed ui.EventData) error {
+			rebind(action, &text, false)
+			return nil
+		})
+		c.Supervisor.Add(keyButton)
+		row.Pack(keyButton, ui.Pack{
 			Side: ui.W,
 			PadX: 1,
 		})
 
-		helpLabel := ui.NewLabel(ui.Label{
-			Text: row.Label,
+		// Button to add another key.
+		addButton := ui.NewButton("Add "+action.Name, ui.NewLabel(ui.Label{
+			Text: "+",
 			Font: balance.UIFont,
+		}))
+		addButton.Handle(ui.Click, func(ed ui.EventData) error {
+			rebind(action, &text, true)
+			return nil
 		})
-		helpLabel.Resize(infoTabSize)
-		curFrame.Pack(helpLabel, ui.Pack{
+		c.Supervisor.Add(addButton)
+		row.Pack(addButton, ui.Pack{
 			Side: ui.W,
 			PadX: 1,
 		})
 	}
 
+	for i, row := range rows {
+		if !rowsPage(i) {
+			row.Hide()
+		}
+		frame.Pack(row, ui.Pack{
+			Side:  ui.N,
+			FillX: true,
+			PadY:  1,
+		})
+	}
+
+	/******************
+	 * Pager and Reset button.
+	 ******************/
+
+	bottomFrame := ui.NewFrame("Button Frame")
+	frame.Pack(bottomFrame, ui.Pack{
+		Side:  ui.S,
+		FillX: true,
+	})
+
+	pager := ui.NewPager(ui.Pager{
+		Name:           "Controls Pager",
+		Page:           page,
+		Pages:          (len(rows) + perPage - 1) / perPage,
+		PerPage:        perPage,
+		MaxPageButtons: 6,
+		Font:           balance.MenuFont,
+		OnChange: func(newPage, perPage int) {
+			page = newPage
+			for i, row := range rows {
+				if rowsPage(i) {
+					row.Show()
+				} else {
+					row.Hide()
+				}
+			}
+		},
+	})
+	pager.Compute(c.Engine)
+	pager.Supervise(c.Supervisor)
+	bottomFrame.Pack(pager, ui.Pack{
+		Side: ui.W,
+		PadX: 4,
+	})
+
+	btnReset := ui.NewButton("Reset Keys", ui.NewLabel(ui.Label{
+		Text: "Reset to Defaults",
+		Font: balance.MenuFont,
+	}))
+	btnReset.Handle(ui.Click, func(ed ui.EventData) error {
+		keymap.ResetAll()
+		saveGameSettings()
+		for _, f := range refresh {
+			f()
+		}
+		shmem.Flash("All keys have been reset to their defaults.")
+		return nil
+	})
+	c.Supervisor.Add(btnReset)
+	bottomFrame.Pack(btnReset, ui.Pack{
+		Side: ui.E,
+		PadX: 4,
+	})
+
 	return frame
 }
 
@@ -498,6 +550,73 @@ func (c Settings) makeExperimentalTab(tabFrame *ui.TabFrame, Width, Height int)
 	return tab
 }
 
+// Settings Window "Audio" Tab
+func (c Settings) makeAudioTab(tabFrame *ui.TabFrame, Width, Height int) *ui.Frame {
+	tab := tabFrame.AddTab("Audio", ui.NewLabel(ui.Label{
+		Text: "Audio",
+		Font: balance.TabFont,
+	}))
+	tab.Resize(render.NewRect(Width-4, Height-tab.Size().H-46))
+
+	// Apply and save the volume settings.
+	onChange := func() {
+		sound.SetVolume(*c.MusicVolume, *c.SoundVolume, *c.MuteAudio)
+		saveGameSettings()
+	}
+
+	// Volume levels in percent.
+	var volumes []magicform.Option
+	for i := 100; i >= 0; i -= 10 {
+		volumes = append(volumes, magicform.Option{
+			Label: fmt.Sprintf("%d%%", i),
+			Value: i,
+		})
+	}
+
+	form := magicform.Form{
+		Supervisor: c.Supervisor,
+		Engine:     c.Engine,
+		Vertical:   true,
+		LabelWidth: 150,
+	}
+	form.Create(tab, []magicform.Field{
+		{
+			Label: "Volume",
+			Font:  balance.LabelFont,
+		},
+		{
+			Label:       "Music volume:",
+			Font:        balance.UIFont,
+			Type:        magicform.Selectbox,
+			Options:     volumes,
+			SelectValue: *c.MusicVolume,
+			OnSelect: func(v interface{}) {
+				*c.MusicVolume, _ = v.(int)
+				onChange()
+			},
+		},
+		{
+			Label:       "Sound effects volume:",
+			Font:        balance.UIFont,
+			Type:        magicform.Selectbox,
+			Options:     volumes,
+			SelectValue: *c.SoundVolume,
+			OnSelect: func(v interface{}) {
+				*c.SoundVolume, _ = v.(int)
+				onChange()
+			},
+		},
+		{
+			Label:        "Mute all music and sound effects",
+			Font:         balance.UIFont,
+			BoolVariable: c.MuteAudio,
+			OnClick:      onChange,
+		},
+	})
+
+	return tab
+}
+
 // Settings Window "Controller" Tab
 func (c Settings) makeControllerTab(tabFrame *ui.TabFrame, Width, Height int) *ui.Frame {
 	tab := tabFrame.AddTab("Gamepad", ui.NewLabel(ui.Label{
@@ -521,7 +640,7 @@ func (c Settings) makeControllerTab(tabFrame *ui.TabFrame, Width, Height int) *u
 		{
 			Label: "If you have a Nintendo-style controller (your A button is on\n" +
 				"the right and B button on bottom), pick 'N Style' to reverse\n" +
-				"the A/B and X/Y buttons.",
+				"the A/B and X/Y buttons of new controllers.",
 			Font: balance.UIFont,
 		},
 		{
@@ -541,14 +660,12 @@ func (c Settings) makeControllerTab(tabFrame *ui.TabFrame, Width, Height int) *u
 			SelectValue: &c.ControllerStyle,
 			OnSelect: func(v interface{}) {
 				style, _ := v.(int)
-				log.Error("style: %d", style)
-				gamepad.SetStyle(gamepad.Style(style))
 				*c.ControllerStyle = style
 				saveGameSettings()
 			},
 		},
 		{
-			Label: "The gamepad controls vary between two modes:",
+			Label: "The default controls vary between two modes:",
 			Font:  balance.UIFont,
 		},
 		{
@@ -568,10 +685,34 @@ func (c Settings) makeControllerTab(tabFrame *ui.TabFrame, Width, Height int) *u
 		},
 		{
 			Label: "Left stick or D-Pad to move the player around.\n" +
-				"A or X: 'Use'    B or Y: 'Jump'\n" +
+				"A or X: 'Use'    B or Y: 'Jump'    Select: Items\n" +
 				"R1: Toggle between Mouse and Gameplay controls.",
 			Font: balance.UIFont,
 		},
+		{
+			Buttons: []magicform.Field{
+				{
+					ButtonStyle: &balance.ButtonPrimary,
+					Label:       "Remap Buttons",
+					Font: balance.UIFont.Update(render.Text{
+						PadY: 0,
+					}),
+					OnClick: func() {
+						if c.gamepadWindow != nil {
+							c.gamepadWindow.Hide()
+							c.gamepadWindow.Destroy()
+							c.gamepadWindow = nil
+						}
+
+						c.gamepadWindow = MakeGamepadWindow(Gamepad{
+							Supervisor: c.Supervisor,
+							Engine:     c.Engine,
+						})
+						c.gamepadWindow.Show()
+					},
+				},
+			},
+		},
 	})
 
 	return tab